@@ -0,0 +1,138 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// ActionHandler is the callback that runs when an Action is triggered. It
+// receives the primary keys of the rows selected in the UI and returns an
+// optional message to show to the user.
+type ActionHandler func(ctx context.Context, db *sql.DB, ids []string) (string, error)
+
+// Action is a named, application-defined operation that can be run against
+// selected rows of a table (e.g. "Resend invoice email" or "Ban user").
+type Action struct {
+	Name        string
+	Description string
+	// Role is the role required to list and run the action, e.g. RoleAdmin
+	// for "Ban user". Users whose role allows the commands of Role can run
+	// it too, and every user allowed to run RunAction can when it's empty.
+	Role    Role
+	Handler ActionHandler
+}
+
+type actionInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// RegisterAction registers a custom action for the given table. Registering an
+// action with the same name twice replaces the previous one.
+func (a *Admin) RegisterAction(table string, action Action) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.actions[table] == nil {
+		a.actions[table] = make(map[string]Action)
+	}
+	a.actions[table][action.Name] = action
+}
+
+func (a *Admin) getAction(table, name string) (Action, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	action, ok := a.actions[table][name]
+	return action, ok
+}
+
+func (a *Admin) listActions(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	table, ok := params["tableName"].(string)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrMissingTableName.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: ListActions, table=%s", table))
+
+	role := RoleFromContext(ctx)
+	a.mu.RLock()
+	actions := make([]actionInfo, 0, len(a.actions[table]))
+	for _, action := range a.actions[table] {
+		if !role.includes(action.Role) {
+			continue
+		}
+		actions = append(actions, actionInfo{Name: action.Name, Description: action.Description})
+	}
+	a.mu.RUnlock()
+
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].Name < actions[j].Name
+	})
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"actions": actions})
+}
+
+func (a *Admin) runAction(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return
+	}
+
+	table, ok := params["tableName"].(string)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrMissingTableName.Error()))
+		return
+	}
+
+	name, ok := params["action"].(string)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrMissingAction.Error()))
+		return
+	}
+
+	ids, ok := convertToStrSlice(params["ids"])
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrInvalidOrMissingIds.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: RunAction, table=%s, action=%s, ids=%v", table, name, ids))
+
+	action, ok := a.getAction(table, name)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrUnknownAction.Error()))
+		return
+	}
+	if role := RoleFromContext(ctx); !role.includes(action.Role) {
+		a.logger.Info(fmt.Sprintf("Audit: %s denied action %s on table %s as %s", UserFromContext(ctx), name, table, role))
+		writeError(w, apiErrForbidden(fmt.Sprintf("%s: %s can't run %s", ErrActionNotAllowed, role, name)))
+		return
+	}
+
+	strIds := make([]string, len(ids))
+	for i, id := range ids {
+		strIds[i] = id.(string)
+	}
+
+	message, err := action.Handler(ctx, db, strIds)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error running action %s on table %s: %v", name, table, err))
+		writeError(w, apiErrSomethingWentWrong())
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: action %s ran on table %s for ids=%v", name, table, strIds))
+	a.notify(EventActionCompleted, fmt.Sprintf("Action %s ran on table %s", name, table), map[string]interface{}{
+		"tableName": table,
+		"action":    name,
+		"ids":       strIds,
+		"message":   message,
+	})
+
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok", "message": message})
+}
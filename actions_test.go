@@ -0,0 +1,70 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActionRoles(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.Users = []sqliteadmin.User{
+			{Username: "ed", Password: "secret", Role: sqliteadmin.RoleEditor},
+		}
+	})
+	defer close()
+
+	var ran []string
+	handler := func(name string) sqliteadmin.ActionHandler {
+		return func(ctx context.Context, db *sql.DB, ids []string) (string, error) {
+			ran = append(ran, name)
+			return "", nil
+		}
+	}
+	ts.admin.RegisterAction("users", sqliteadmin.Action{Name: "Ban", Role: sqliteadmin.RoleAdmin, Handler: handler("Ban")})
+	ts.admin.RegisterAction("users", sqliteadmin.Action{Name: "Resend", Role: sqliteadmin.RoleEditor, Handler: handler("Resend")})
+	ts.admin.RegisterAction("users", sqliteadmin.Action{Name: "Touch", Handler: handler("Touch")})
+
+	do := func(credentials string, command sqliteadmin.Command, params map[string]interface{}) (int, map[string]interface{}) {
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: command, Params: params})
+		req.Header.Set("Authorization", credentials)
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res.StatusCode, readBody(t, res.Body)
+	}
+	names := func(body map[string]interface{}) []string {
+		var names []string
+		for _, action := range body["actions"].([]interface{}) {
+			names = append(names, action.(map[string]interface{})["name"].(string))
+		}
+		return names
+	}
+	run := func(name string) map[string]interface{} {
+		return map[string]interface{}{"tableName": "users", "action": name, "ids": []string{"1"}}
+	}
+
+	// Editors don't see nor run the actions of admins
+	status, body := do("ed:secret", sqliteadmin.ListActions, map[string]interface{}{"tableName": "users"})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []string{"Resend", "Touch"}, names(body))
+	status, body = do("ed:secret", sqliteadmin.RunAction, run("Ban"))
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, "Forbidden: action not allowed for the role: editor can't run Ban", body["message"])
+	status, _ = do("ed:secret", sqliteadmin.RunAction, run("Resend"))
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = do("ed:secret", sqliteadmin.RunAction, run("Touch"))
+	assert.Equal(t, http.StatusOK, status)
+
+	// Admins can run the actions of every role
+	status, body = do("user:password", sqliteadmin.ListActions, map[string]interface{}{"tableName": "users"})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []string{"Ban", "Resend", "Touch"}, names(body))
+	status, _ = do("user:password", sqliteadmin.RunAction, run("Ban"))
+	assert.Equal(t, http.StatusOK, status)
+
+	assert.Equal(t, []string{"Resend", "Touch", "Ban"}, ran)
+}
@@ -0,0 +1,222 @@
+package sqliteadmin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	mathrand "math/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joelseq/sqliteadmin-go/querybuilder"
+)
+
+// Strategies of AnonymizeTableParams.Columns.
+const (
+	// AnonymizeFake replaces the values with realistic fake ones guessed from
+	// the name and type of the column, as generated by SeedTable.
+	AnonymizeFake = "fake"
+	// AnonymizeHash replaces the values with their pseudonym, as for the
+	// Anonymize column policy. Equal values get the same pseudonym, so the
+	// values of different tables can still be matched with each other.
+	AnonymizeHash = "hash"
+	// AnonymizeNull clears the values.
+	AnonymizeNull = "null"
+)
+
+// anonymizeBatchSize is the number of rows read at a time when anonymizing a
+// table.
+const anonymizeBatchSize = 500
+
+// AnonymizeTableParams are the params of AnonymizeTable.
+type AnonymizeTableParams struct {
+	Database  string `json:"database,omitempty" mapstructure:"database"`
+	TableName string `json:"tableName" mapstructure:"tableName"`
+	// Columns are the strategies of the columns to overwrite, by column.
+	Columns map[string]string `json:"columns" mapstructure:"columns"`
+	// Seed makes the fake values reproducible, they are random when nil.
+	Seed *int64 `json:"seed,omitempty" mapstructure:"seed"`
+}
+
+func (p *AnonymizeTableParams) validate() error {
+	if p.TableName == "" {
+		return ErrMissingTableName
+	}
+	if len(p.Columns) == 0 {
+		return ErrMissingColumn
+	}
+	for column, strategy := range p.Columns {
+		if strategy != AnonymizeFake && strategy != AnonymizeHash && strategy != AnonymizeNull {
+			return fmt.Errorf("%w: %s for %s", ErrUnknownStrategy, strategy, column)
+		}
+	}
+	return nil
+}
+
+// anonymizeTable overwrites columns of every row of a table, e.g. the names
+// and emails of a copy of a production database before sharing it. Unlike the
+// Anonymize column policy, which hides the values from the clients, the
+// values are replaced in the database, so like SeedTable it is only available
+// in development mode.
+func (a *Admin) anonymizeTable(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	if !a.development {
+		writeError(w, apiErrForbidden(ErrAnonymizationDisabled.Error()))
+		return
+	}
+	var p AnonymizeTableParams
+	if err := a.decodeParams(params, &p); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return
+	}
+	table := p.TableName
+
+	a.logger.Info(fmt.Sprintf("Command: AnonymizeTable, table=%s, columns=%v", table, p.Columns))
+
+	exists, err := checkTableExists(ctx, a.cached(db), table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error checking table existence: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if !exists || isInternalTable(table) {
+		writeError(w, apiErrTableNotFound())
+		return
+	}
+	if !a.checkNotShadowTable(ctx, w, a.cached(db), table) {
+		return
+	}
+	// Rows out of the scope would be anonymized along with the others
+	if a.scoped(table) {
+		writeError(w, apiErrForbidden(ErrOutOfScope.Error()))
+		return
+	}
+
+	seed := time.Now().UnixNano()
+	if p.Seed != nil {
+		seed = *p.Seed
+	}
+	tx, err := a.beginImmediateTx(ctx, db)
+	if err != nil {
+		writeError(w, a.apiErr(readOnlyError(fmt.Errorf("error starting transaction: %v", err))))
+		return
+	}
+	defer tx.Rollback()
+
+	n, err := a.anonymizeRows(ctx, tx, table, p.Columns, mathrand.New(mathrand.NewSource(seed)))
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error anonymizing table: %v", err))
+		writeError(w, a.rowError(readOnlyError(err)))
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: anonymized %v of %d row(s) of %s", sortedColumns(p.Columns), n, table))
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "rowsUpdated": n})
+}
+
+// anonymizeRows overwrites the columns of every row of the table with the
+// values of their strategy and returns the number of rows updated. The primary
+// key identifies the rows, so it can't be anonymized.
+func (a *Admin) anonymizeRows(ctx context.Context, q queryer, table string, strategies map[string]string, r *mathrand.Rand) (int64, error) {
+	columns, err := getColumns(ctx, q, table)
+	if err != nil {
+		return 0, err
+	}
+	enums, err := getEnumValues(ctx, q, table)
+	if err != nil {
+		return 0, err
+	}
+	unique, err := getUniqueColumns(ctx, q, table)
+	if err != nil {
+		return 0, err
+	}
+	key, err := getPrimaryKeyColumns(ctx, q, table)
+	if err != nil {
+		return 0, err
+	}
+
+	s := &seeder{rand: r, now: time.Now()}
+	names := sortedColumns(strategies)
+	byName := map[string]column{}
+	for _, c := range columns {
+		byName[c.Name] = c
+	}
+	fake := map[string]*seedColumn{}
+	for _, name := range names {
+		c, ok := byName[name]
+		if !ok {
+			return 0, fmt.Errorf("%s: %w", name, ErrUnknownColumn)
+		}
+		if contains(key, name) {
+			return 0, invalidRowError{fmt.Errorf("%w: %s", ErrPrimaryKeyCell, name)}
+		}
+		if strategies[name] != AnonymizeFake {
+			continue
+		}
+		sc := &seedColumn{name: name, dataType: strings.ToUpper(c.DataType), notNull: c.NotNull, unique: unique[name], enum: enums[name]}
+		// The values of the rows not updated yet are still in the table
+		if sc.unique {
+			if sc.taken, err = distinctValues(ctx, q, table, name); err != nil {
+				return 0, err
+			}
+		}
+		fake[name] = sc
+	}
+
+	orderBy := strings.Join(querybuilder.Idents(key), ", ")
+	var updated int64
+	for offset := 0; ; offset += anonymizeBatchSize {
+		query, args := querybuilder.Select(append(append([]string{}, key...), names...)...).
+			From(table).
+			OrderBy(orderBy).
+			Page(anonymizeBatchSize, offset).
+			Build()
+		rows, err := queryRows(ctx, q, query, args...)
+		if err != nil {
+			return 0, err
+		}
+		for _, row := range rows {
+			first, last := pick(s.rand, firstNames), pick(s.rand, lastNames)
+			b := querybuilder.Update(table)
+			for _, name := range names {
+				var v interface{}
+				switch strategies[name] {
+				case AnonymizeFake:
+					v = s.uniqueValue(fake[name], s.value(fake[name], first, last))
+				case AnonymizeHash:
+					v = a.anonymize(row[name])
+				}
+				b.Set(name, v)
+			}
+			for _, k := range key {
+				b.Where(querybuilder.Eq(k, row[k]))
+			}
+			query, args := b.Build()
+			if _, err := q.ExecContext(ctx, query, args...); err != nil {
+				return 0, err
+			}
+			updated++
+		}
+		if len(rows) < anonymizeBatchSize {
+			return updated, nil
+		}
+	}
+}
+
+// sortedColumns returns the columns of the map in order.
+func sortedColumns(m map[string]string) []string {
+	columns := make([]string, 0, len(m))
+	for c := range m {
+		columns = append(columns, c)
+	}
+	sort.Strings(columns)
+	return columns
+}
@@ -0,0 +1,131 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnonymizeTable(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.Development = true
+	})
+	defer close()
+
+	_, err := ts.db.Exec(`
+		CREATE TABLE contacts (
+			email TEXT PRIMARY KEY,
+			phone TEXT UNIQUE,
+			notes TEXT
+		) WITHOUT ROWID;
+		INSERT INTO contacts VALUES ('a@example.com', '555-0001', 'Likes cats'), ('b@example.com', '555-0002', NULL);
+	`)
+	assert.NoError(t, err)
+
+	runTestCases([]TestCase{
+		{
+			name: "Success: Rowid table",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"columns":   map[string]interface{}{"name": "fake", "email": "hash"},
+			},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok", "rowsUpdated": float64(9)},
+		},
+		{
+			name: "Success: WITHOUT ROWID table",
+			params: map[string]interface{}{
+				"tableName": "contacts",
+				"columns":   map[string]interface{}{"phone": "fake", "notes": "null"},
+			},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok", "rowsUpdated": float64(2)},
+		},
+		{
+			name: "Failure: Primary key",
+			params: map[string]interface{}{
+				"tableName": "contacts",
+				"columns":   map[string]interface{}{"email": "hash"},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: the primary key of a row cannot be updated: email",
+			},
+		},
+		{
+			name: "Failure: Unknown strategy",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"columns":   map[string]interface{}{"name": "shuffle"},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: unknown anonymization strategy: shuffle for name",
+			},
+		},
+		{
+			name: "Failure: Unknown column",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"columns":   map[string]interface{}{"age": "null"},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "COLUMN_NOT_FOUND",
+				"message":    "Bad request: unknown column",
+				"detail":     "age: unknown column",
+			},
+		},
+	}, sqliteadmin.AnonymizeTable, t, ts.server)
+
+	rows, err := getTableValues(ts.db, "users")
+	assert.NoError(t, err)
+	assert.Len(t, rows, 9)
+	assert.Equal(t, int64(1), rows[0]["id"])
+	assert.NotEqual(t, "Alice", rows[0]["name"])
+	assert.Contains(t, rows[0]["email"], "anon_")
+	assert.NotEqual(t, rows[0]["email"], rows[1]["email"])
+
+	contacts, err := getTableValues(ts.db, "contacts")
+	assert.NoError(t, err)
+	assert.Equal(t, "a@example.com", contacts[0]["email"])
+	assert.NotEqual(t, "555-0001", contacts[0]["phone"])
+	assert.NotEqual(t, contacts[0]["phone"], contacts[1]["phone"])
+	assert.Nil(t, contacts[0]["notes"])
+
+	n, err := ts.admin.AnonymizeTable(context.Background(), sqliteadmin.AnonymizeTableParams{
+		TableName: "contacts",
+		Columns:   map[string]string{"notes": sqliteadmin.AnonymizeFake},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+}
+
+func TestAnonymizeTableDisabled(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	runTestCases([]TestCase{
+		{
+			name: "Failure: Not in Development Mode",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"columns":   map[string]interface{}{"name": "fake"},
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusForbidden),
+				"code":       "FORBIDDEN",
+				"message":    "Forbidden: tables can only be anonymized in development mode",
+			},
+		},
+	}, sqliteadmin.AnonymizeTable, t, ts.server)
+}
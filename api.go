@@ -0,0 +1,262 @@
+package sqliteadmin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// WithPrincipal returns a copy of ctx running the commands of Do as the
+// principal, e.g. for the row scopes and the audit logs.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey, p)
+}
+
+// Do runs the command with the params like HandlePost does, without going
+// through HTTP, so that Go programs like tests and CLIs can embed the same
+// logic. The response is decoded into result unless it is nil, or copied as
+// is into a *[]byte. Errors of the command are returned as an APIError.
+//
+// Since there is no request to authenticate, the command runs as the
+// principal of ctx, if any, and with the privileges of elevated users.
+func (a *Admin) Do(ctx context.Context, command Command, params map[string]interface{}, result interface{}) error {
+	return a.do(ctx, command, params, result)
+}
+
+// do runs the command with params of any type encoded as JSON, so that the
+// handlers get the same values as from HandlePost, e.g. numbers as float64.
+func (a *Admin) do(ctx context.Context, command Command, params interface{}, result interface{}) error {
+	cr := CommandRequest{Command: command}
+	b, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("error encoding params: %v", err)
+	}
+	if err := json.Unmarshal(b, &cr.Params); err != nil {
+		return fmt.Errorf("error decoding params: %v", err)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, "/", nil)
+	if err != nil {
+		return err
+	}
+	res := &bufferedResponse{header: http.Header{}}
+	a, w, r := a.startRequest(res, r)
+	if a.logRequests {
+		defer a.logRequest(&cr)
+	}
+	if p := PrincipalFromContext(ctx); p != nil {
+		r = a.withRequestValue(r, userKey, p.User)
+	}
+	r = a.withRequestValue(r, elevatedKey, true)
+	r = a.withRequestValue(r, unsealKey, true)
+	a.handleCommand(w, r, &cr)
+
+	if status := res.status; status >= http.StatusBadRequest {
+		var e APIError
+		if err := json.Unmarshal(res.body.Bytes(), &e); err != nil || e.Message == "" {
+			// Errors written with http.Error are plain text
+			code := CodeInternal
+			if status < http.StatusInternalServerError {
+				code = CodeBadRequest
+			}
+			e = APIError{StatusCode: status, Code: code, Message: strings.TrimSpace(res.body.String())}
+		}
+		return e
+	}
+	switch result := result.(type) {
+	case nil:
+		return nil
+	case *[]byte:
+		*result = res.body.Bytes()
+		return nil
+	}
+	if err := json.Unmarshal(res.body.Bytes(), result); err != nil {
+		return fmt.Errorf("error decoding response: %v", err)
+	}
+	return nil
+}
+
+// ListTables returns the names of the tables of the database matching the
+// filter, sorted.
+func (a *Admin) ListTables(ctx context.Context, params ListTablesParams) (*ListTablesResponse, error) {
+	var res ListTablesResponse
+	if err := a.do(ctx, ListTables, params, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetTable returns a page of the rows of the table matching the condition,
+// with the values decoded from JSON, e.g. numbers as float64.
+func (a *Admin) GetTable(ctx context.Context, params GetTableParams) (*GetTableResponse, error) {
+	if params.Stream || params.Since != nil {
+		return nil, APIError{StatusCode: http.StatusBadRequest, Code: CodeBadRequest, Message: "Bad request: streams and deltas are only supported by Do"}
+	}
+	var res GetTableResponse
+	if err := a.do(ctx, GetTable, params, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetTableWindow returns the rows of a table following or preceding a key,
+// for scrolling through large tables.
+func (a *Admin) GetTableWindow(ctx context.Context, params GetTableWindowParams) (*TableWindow, error) {
+	var res TableWindow
+	if err := a.do(ctx, GetTableWindow, params, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// UpdateRow updates the row with the primary key of params.Row.
+func (a *Admin) UpdateRow(ctx context.Context, params UpdateRowParams) (*UpdateRowResponse, error) {
+	var res UpdateRowResponse
+	if err := a.do(ctx, UpdateRow, params, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// UpdateCell updates a single column of the row with the primary key
+// params.Key.
+func (a *Admin) UpdateCell(ctx context.Context, params UpdateCellParams) (*UpdateRowResponse, error) {
+	var res UpdateRowResponse
+	if err := a.do(ctx, UpdateCell, params, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// InsertRow inserts params.Row and returns it as stored, with the default
+// values of the columns left out.
+func (a *Admin) InsertRow(ctx context.Context, params InsertRowParams) (*InsertRowResponse, error) {
+	var res InsertRowResponse
+	if err := a.do(ctx, InsertRow, params, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// InsertRows inserts params.Rows in a single transaction and reports the rows
+// that failed. Whether the others are inserted depends on params.Mode.
+func (a *Admin) InsertRows(ctx context.Context, params InsertRowsParams) (*InsertRowsResponse, error) {
+	var res InsertRowsResponse
+	if err := a.do(ctx, InsertRows, params, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// SeedTable inserts params.Rows rows of generated data into a table and
+// returns how many were inserted. It requires Config.Development.
+func (a *Admin) SeedTable(ctx context.Context, params SeedTableParams) (int, error) {
+	var res struct {
+		RowsInserted int `json:"rowsInserted"`
+	}
+	if err := a.do(ctx, SeedTable, params, &res); err != nil {
+		return 0, err
+	}
+	return res.RowsInserted, nil
+}
+
+// AnonymizeTable overwrites columns of every row of a table according to
+// their strategy and returns the number of rows updated. It requires
+// Config.Development.
+func (a *Admin) AnonymizeTable(ctx context.Context, params AnonymizeTableParams) (int64, error) {
+	var res struct {
+		RowsUpdated int64 `json:"rowsUpdated"`
+	}
+	if err := a.do(ctx, AnonymizeTable, params, &res); err != nil {
+		return 0, err
+	}
+	return res.RowsUpdated, nil
+}
+
+// CloneDatabase starts a job copying tables of a database to a new file, which
+// can be polled with GetJob until it is done.
+func (a *Admin) CloneDatabase(ctx context.Context, params CloneDatabaseParams) (*Job, error) {
+	var res Job
+	if err := a.do(ctx, CloneDatabase, params, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetJob returns the status of a job started by a command like CloneDatabase.
+func (a *Admin) GetJob(ctx context.Context, id string) (*Job, error) {
+	var res Job
+	if err := a.do(ctx, GetJob, map[string]interface{}{"jobId": id}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ListExtensions returns the known SQLite extensions available on a database.
+func (a *Admin) ListExtensions(ctx context.Context, params ListExtensionsParams) ([]Extension, error) {
+	var res struct {
+		Extensions []Extension `json:"extensions"`
+	}
+	if err := a.do(ctx, ListExtensions, params, &res); err != nil {
+		return nil, err
+	}
+	return res.Extensions, nil
+}
+
+// DeleteRows deletes the rows with the given primary keys and returns the
+// number of rows deleted.
+func (a *Admin) DeleteRows(ctx context.Context, params DeleteRowsParams) (int64, error) {
+	if params.PreviewCascade {
+		return 0, APIError{StatusCode: http.StatusBadRequest, Code: CodeBadRequest, Message: "Bad request: previews are only supported by PreviewDeleteRows"}
+	}
+	var res DeleteRowsResponse
+	if err := a.do(ctx, DeleteRows, params, &res); err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(res.RowsAffected, 10, 64)
+}
+
+// PreviewDeleteRows reports what deleting the rows with the given primary keys
+// would do to the rows referencing them, without deleting anything.
+func (a *Admin) PreviewDeleteRows(ctx context.Context, params DeleteRowsParams) (*DeleteRowsPreviewResponse, error) {
+	params.PreviewCascade = true
+	var res DeleteRowsPreviewResponse
+	if err := a.do(ctx, DeleteRows, params, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// WhoAmI returns the identity and permissions of the caller, which run as an
+// admin through Do.
+func (a *Admin) WhoAmI(ctx context.Context) (WhoAmIResponse, error) {
+	var res WhoAmIResponse
+	err := a.do(ctx, WhoAmI, nil, &res)
+	return res, err
+}
+
+// CreateAPIKey creates an API key, returned along with its details.
+func (a *Admin) CreateAPIKey(ctx context.Context, params CreateAPIKeyParams) (CreateAPIKeyResponse, error) {
+	var res CreateAPIKeyResponse
+	err := a.do(ctx, CreateAPIKey, params, &res)
+	return res, err
+}
+
+// ListAPIKeys returns the API keys, including the revoked and expired ones.
+func (a *Admin) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	var res struct {
+		APIKeys []APIKey `json:"apiKeys"`
+	}
+	if err := a.do(ctx, ListAPIKeys, nil, &res); err != nil {
+		return nil, err
+	}
+	return res.APIKeys, nil
+}
+
+// RevokeAPIKey revokes an API key.
+func (a *Admin) RevokeAPIKey(ctx context.Context, params RevokeAPIKeyParams) error {
+	return a.do(ctx, RevokeAPIKey, params, nil)
+}
@@ -0,0 +1,71 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgrammaticAPI(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+	ctx := context.Background()
+
+	tables, err := ts.admin.ListTables(ctx, sqliteadmin.ListTablesParams{})
+	assert.NoError(t, err)
+	assert.Contains(t, tables.Tables, "users")
+
+	limit := 2
+	res, err := ts.admin.GetTable(ctx, sqliteadmin.GetTableParams{
+		TableName: "users",
+		Condition: &sqliteadmin.Condition{
+			Cases: []sqliteadmin.Case{
+				sqliteadmin.Filter{Column: "email", Operator: sqliteadmin.OperatorLike, Value: "@gmail.com"},
+			},
+		},
+		Limit:  &limit,
+		Offset: 1,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{
+		{"id": float64(2), "name": "Bob", "email": "bob@gmail.com"},
+		{"id": float64(3), "name": "Charlie", "email": "charlie@gmail.com"},
+	}, res.Rows)
+
+	updated, err := ts.admin.UpdateRow(ctx, sqliteadmin.UpdateRowParams{
+		TableName: "users",
+		Row:       map[string]interface{}{"id": 1, "name": "Alicia"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", updated.Status)
+	var name string
+	assert.NoError(t, ts.db.QueryRow("SELECT name FROM users WHERE id = 1").Scan(&name))
+	assert.Equal(t, "Alicia", name)
+
+	deleted, err := ts.admin.DeleteRows(ctx, sqliteadmin.DeleteRowsParams{TableName: "users", IDs: []string{"8", "9"}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	// All the commands can be run with Do
+	var preview map[string]interface{}
+	err = ts.admin.Do(ctx, sqliteadmin.PreviewTable, map[string]interface{}{"tableName": "users", "rows": 1}, &preview)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, preview["head"])
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := ts.admin.GetTable(ctx, sqliteadmin.GetTableParams{TableName: "missing"})
+		var apiErr sqliteadmin.APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+		assert.Equal(t, sqliteadmin.CodeTableNotFound, apiErr.Code)
+
+		err = ts.admin.Do(ctx, "Unknown", nil, nil)
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, sqliteadmin.CodeBadRequest, apiErr.Code)
+		assert.Equal(t, "Invalid command", apiErr.Message)
+	})
+}
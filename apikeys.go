@@ -0,0 +1,342 @@
+package sqliteadmin
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// apiKeysTable is where the API keys are stored, hashed, in the default
+// database.
+const apiKeysTable = "_sqliteadmin_api_keys"
+
+// APIKeyPrefix starts the API keys, which are sent as
+// "Authorization: Bearer <key>".
+const APIKeyPrefix = "sqa_"
+
+// APIKey is an API key created with CreateAPIKey. The key itself is only
+// returned when it is created.
+type APIKey struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+	// Commands restrict the key to some of the commands of its role, all of
+	// them when empty.
+	Commands  []Command `json:"commands,omitempty"`
+	CreatedBy string    `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	// ExpiresAt is nil for keys which don't expire.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+// CreateAPIKeyParams are the params of CreateAPIKey.
+type CreateAPIKeyParams struct {
+	Name     string    `json:"name" mapstructure:"name"`
+	Role     Role      `json:"role" mapstructure:"role"`
+	Commands []Command `json:"commands,omitempty" mapstructure:"commands"`
+	// ExpiresAt is when the key stops working, never when zero.
+	ExpiresAt time.Time `json:"expiresAt" mapstructure:"expiresAt"`
+}
+
+func (p *CreateAPIKeyParams) validate() error {
+	if p.Name == "" {
+		return ErrMissingAPIKeyName
+	}
+	switch p.Role {
+	case RoleAdmin, RoleEditor, RoleViewer:
+	default:
+		return fmt.Errorf("%w: invalid role %q", ErrInvalidInput, p.Role)
+	}
+	for _, c := range p.Commands {
+		if !p.Role.allows(c) {
+			return fmt.Errorf("%w: %s can't run %s", ErrRoleNotAllowed, p.Role, c)
+		}
+	}
+	if !p.ExpiresAt.IsZero() && p.ExpiresAt.Before(time.Now()) {
+		return fmt.Errorf("%w: expiresAt is in the past", ErrInvalidInput)
+	}
+	return nil
+}
+
+// CreateAPIKeyResponse is the response of CreateAPIKey.
+type CreateAPIKeyResponse struct {
+	// Key is only returned once, only its hash being stored.
+	Key    string `json:"key"`
+	APIKey APIKey `json:"apiKey"`
+}
+
+// RevokeAPIKeyParams are the params of RevokeAPIKey.
+type RevokeAPIKeyParams struct {
+	ID string `json:"id" mapstructure:"id"`
+}
+
+func (p *RevokeAPIKeyParams) validate() error {
+	if p.ID == "" {
+		return ErrMissingAPIKeyID
+	}
+	return nil
+}
+
+// hashAPIKeySecret hashes the random part of a key. A fast hash is enough
+// since the secrets are random rather than chosen by users.
+func hashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// createAPIKey creates a key for scripts and UIs to authenticate with
+// instead of the credentials of a user.
+func (a *Admin) createAPIKey(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	if a.db == nil {
+		writeError(w, apiErrBadRequest(ErrMissingDatabase.Error()))
+		return
+	}
+	var p CreateAPIKeyParams
+	if err := a.decodeParams(params, &p); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: CreateAPIKey, name=%s, role=%s", p.Name, p.Role))
+
+	// Restricted keys can only create keys restricted to some of their commands
+	if commands, _ := ctx.Value(commandsKey).([]Command); commands != nil {
+		if len(p.Commands) == 0 {
+			writeError(w, apiErrForbidden(fmt.Sprintf("%s: the new key must be restricted to some of its commands", ErrAPIKeyNotAllowed)))
+			return
+		}
+		for _, c := range p.Commands {
+			if !slices.Contains(commands, c) {
+				writeError(w, apiErrForbidden(fmt.Sprintf("%s: %s", ErrAPIKeyNotAllowed, c)))
+				return
+			}
+		}
+	}
+
+	id, err := newID()
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error generating API key id: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	secret, err := newID()
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error generating API key: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	key := APIKey{
+		ID:        id,
+		Name:      p.Name,
+		Role:      p.Role,
+		Commands:  p.Commands,
+		CreatedBy: UserFromContext(ctx),
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	if !p.ExpiresAt.IsZero() {
+		expires := p.ExpiresAt.UTC()
+		key.ExpiresAt = &expires
+	}
+
+	if err := saveAPIKey(ctx, a.db, key, hashAPIKeySecret(secret)); err != nil {
+		a.logger.Error(fmt.Sprintf("Error saving API key: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: created API key %s (%s) as %s", key.ID, key.Name, key.Role))
+
+	json.NewEncoder(w).Encode(CreateAPIKeyResponse{Key: APIKeyPrefix + id + "_" + secret, APIKey: key})
+}
+
+func saveAPIKey(ctx context.Context, q queryer, key APIKey, hash string) error {
+	_, err := q.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		hash TEXT NOT NULL,
+		role TEXT NOT NULL,
+		commands TEXT NOT NULL,
+		created_by TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		expires_at TEXT,
+		revoked_at TEXT
+	)`, quoteIdent(apiKeysTable)))
+	if err != nil {
+		return fmt.Errorf("error creating API keys table: %v", err)
+	}
+
+	commands, err := json.Marshal(key.Commands)
+	if err != nil {
+		return err
+	}
+	var expires sql.NullString
+	if key.ExpiresAt != nil {
+		expires = sql.NullString{String: key.ExpiresAt.Format(time.RFC3339), Valid: true}
+	}
+	_, err = q.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s (id, name, hash, role, commands, created_by, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, quoteIdent(apiKeysTable)),
+		key.ID, key.Name, hash, key.Role, string(commands), key.CreatedBy, key.CreatedAt.Format(time.RFC3339), expires)
+	if err != nil {
+		return fmt.Errorf("error inserting API key: %v", err)
+	}
+	return nil
+}
+
+// listAPIKeys lists the keys, including the revoked and expired ones.
+func (a *Admin) listAPIKeys(ctx context.Context, w http.ResponseWriter) {
+	a.logger.Info("Command: ListAPIKeys")
+
+	keys, err := a.getAPIKeys(ctx)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error listing API keys: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"apiKeys": keys})
+}
+
+func (a *Admin) getAPIKeys(ctx context.Context) ([]APIKey, error) {
+	keys := []APIKey{}
+	if a.db == nil {
+		return keys, nil
+	}
+	exists, err := checkTableExists(ctx, a.db, apiKeysTable)
+	if err != nil || !exists {
+		return keys, err
+	}
+
+	rows, err := a.db.QueryContext(ctx, fmt.Sprintf(`SELECT id, name, role, commands, created_by, created_at, expires_at, revoked_at
+		FROM %s ORDER BY created_at, id`, quoteIdent(apiKeysTable)))
+	if err != nil {
+		return nil, fmt.Errorf("error querying API keys: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key APIKey
+		var commands, created string
+		var expires, revoked sql.NullString
+		if err := rows.Scan(&key.ID, &key.Name, &key.Role, &commands, &key.CreatedBy, &created, &expires, &revoked); err != nil {
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		if err := key.decode(commands, created, expires, revoked); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
+	return keys, nil
+}
+
+// decode sets the fields of the key stored as text.
+func (k *APIKey) decode(commands, created string, expires, revoked sql.NullString) error {
+	if err := json.Unmarshal([]byte(commands), &k.Commands); err != nil {
+		return fmt.Errorf("error decoding commands of API key %s: %v", k.ID, err)
+	}
+	var err error
+	if k.CreatedAt, err = time.Parse(time.RFC3339, created); err != nil {
+		return fmt.Errorf("error decoding API key %s: %v", k.ID, err)
+	}
+	for _, field := range []struct {
+		value sql.NullString
+		dst   **time.Time
+	}{{expires, &k.ExpiresAt}, {revoked, &k.RevokedAt}} {
+		if !field.value.Valid {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, field.value.String)
+		if err != nil {
+			return fmt.Errorf("error decoding API key %s: %v", k.ID, err)
+		}
+		*field.dst = &t
+	}
+	return nil
+}
+
+// revokeAPIKey revokes a key, which stops working right away. Revoked keys
+// are kept for the audit trail.
+func (a *Admin) revokeAPIKey(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	var p RevokeAPIKeyParams
+	if err := a.decodeParams(params, &p); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: RevokeAPIKey, id=%s", p.ID))
+
+	revoked := int64(0)
+	if a.db != nil {
+		exists, err := checkTableExists(ctx, a.db, apiKeysTable)
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Error checking table existence: %v", err))
+			writeError(w, a.apiErr(err))
+			return
+		}
+		if exists {
+			res, err := a.db.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL", quoteIdent(apiKeysTable)),
+				time.Now().UTC().Format(time.RFC3339), p.ID)
+			if err != nil {
+				a.logger.Error(fmt.Sprintf("Error revoking API key: %v", err))
+				writeError(w, a.apiErr(err))
+				return
+			}
+			revoked, _ = res.RowsAffected()
+		}
+	}
+	if revoked == 0 {
+		writeError(w, apiErrBadRequest(ErrUnknownAPIKey.Error()))
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: revoked API key %s", p.ID))
+
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// authenticateAPIKey returns the identity of a key that is neither revoked
+// nor expired, named after its ID.
+func (a *Admin) authenticateAPIKey(ctx context.Context, token string) (identity, error) {
+	id, secret, ok := strings.Cut(strings.TrimPrefix(token, APIKeyPrefix), "_")
+	if !ok || a.db == nil {
+		return identity{}, ErrUnknownAPIKey
+	}
+
+	var key APIKey
+	var hash, commands, created string
+	var expires, revoked sql.NullString
+	err := a.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT id, name, hash, role, commands, created_at, expires_at, revoked_at
+		FROM %s WHERE id = ?`, quoteIdent(apiKeysTable)), id).Scan(&key.ID, &key.Name, &hash, &key.Role, &commands, &created, &expires, &revoked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return identity{}, ErrUnknownAPIKey
+	}
+	if err != nil {
+		return identity{}, fmt.Errorf("error reading API key: %v", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(hashAPIKeySecret(secret)), []byte(hash)) != 1 {
+		return identity{}, ErrUnknownAPIKey
+	}
+	if err := key.decode(commands, created, expires, revoked); err != nil {
+		return identity{}, err
+	}
+	if key.RevokedAt != nil {
+		return identity{}, fmt.Errorf("%w: API key %s is revoked", ErrInvalidToken, key.ID)
+	}
+	if key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt) {
+		return identity{}, fmt.Errorf("%w: API key %s expired", ErrInvalidToken, key.ID)
+	}
+
+	i := identity{user: "apikey:" + key.ID, role: key.Role}
+	if len(key.Commands) > 0 {
+		i.commands = key.Commands
+	}
+	return i, nil
+}
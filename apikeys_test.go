@@ -0,0 +1,173 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeys(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+	ctx := context.Background()
+
+	do := func(key string, command sqliteadmin.Command, params map[string]interface{}) (int, map[string]interface{}) {
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: command, Params: params})
+		req.Header.Set("Authorization", "Bearer "+key)
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res.StatusCode, readBody(t, res.Body)
+	}
+
+	created, err := ts.admin.CreateAPIKey(ctx, sqliteadmin.CreateAPIKeyParams{
+		Name:      "ci",
+		Role:      sqliteadmin.RoleEditor,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Key, sqliteadmin.APIKeyPrefix))
+	assert.Equal(t, "ci", created.APIKey.Name)
+	assert.NotNil(t, created.APIKey.ExpiresAt)
+
+	status, body := do(created.Key, sqliteadmin.WhoAmI, nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "apikey:"+created.APIKey.ID, body["user"])
+	assert.Equal(t, "editor", body["role"])
+
+	status, _ = do(created.Key, sqliteadmin.UpdateRow, map[string]interface{}{"tableName": "users", "row": map[string]interface{}{"id": 1, "name": "Alicia"}})
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = do(created.Key, sqliteadmin.ListAPIKeys, nil)
+	assert.Equal(t, http.StatusForbidden, status)
+
+	// Only the hash of the key is stored, and the table can't be read or
+	// edited like the others
+	var hash string
+	err = ts.db.QueryRow("SELECT hash FROM _sqliteadmin_api_keys WHERE id = ?", created.APIKey.ID).Scan(&hash)
+	assert.NoError(t, err)
+	assert.NotContains(t, created.Key, hash)
+	status, body = do(created.Key, sqliteadmin.GetTable, map[string]interface{}{"tableName": "_sqliteadmin_api_keys"})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, string(sqliteadmin.CodeTableNotFound), body["code"])
+
+	// Table names ignore case, so editors can't insert a key of their own
+	// under another spelling of the table
+	stolen := map[string]interface{}{"id": "x", "name": "x", "hash": hash, "role": "admin", "created_at": "2024-01-01T00:00:00Z"}
+	for command, params := range map[sqliteadmin.Command]map[string]interface{}{
+		sqliteadmin.InsertRow:  {"tableName": "_SQLITEADMIN_API_KEYS", "row": stolen},
+		sqliteadmin.InsertRows: {"tableName": "_SQLiteAdmin_API_Keys", "rows": []interface{}{stolen}},
+		sqliteadmin.UpdateCell: {"tableName": "_SQLITEADMIN_API_KEYS", "key": created.APIKey.ID, "column": "role", "value": "admin"},
+	} {
+		status, body = do(created.Key, command, params)
+		assert.Equal(t, http.StatusBadRequest, status, command)
+		assert.Equal(t, string(sqliteadmin.CodeTableNotFound), body["code"], command)
+	}
+	status, body = do(created.Key, sqliteadmin.WhoAmI, nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "editor", body["role"])
+
+	status, _ = do(created.Key+"x", sqliteadmin.WhoAmI, nil)
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	assert.NoError(t, ts.admin.RevokeAPIKey(ctx, sqliteadmin.RevokeAPIKeyParams{ID: created.APIKey.ID}))
+	status, _ = do(created.Key, sqliteadmin.WhoAmI, nil)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	err = ts.admin.RevokeAPIKey(ctx, sqliteadmin.RevokeAPIKeyParams{ID: created.APIKey.ID})
+	assert.ErrorContains(t, err, "Bad request: unknown API key")
+
+	keys, err := ts.admin.ListAPIKeys(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 1)
+	assert.NotNil(t, keys[0].RevokedAt)
+
+	// Expired keys are rejected
+	expiring, err := ts.admin.CreateAPIKey(ctx, sqliteadmin.CreateAPIKeyParams{Name: "old", Role: sqliteadmin.RoleViewer})
+	assert.NoError(t, err)
+	_, err = ts.db.Exec("UPDATE _sqliteadmin_api_keys SET expires_at = '2000-01-01T00:00:00Z' WHERE id = ?", expiring.APIKey.ID)
+	assert.NoError(t, err)
+	status, _ = do(expiring.Key, sqliteadmin.WhoAmI, nil)
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestAPIKeyCommands(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.Sessions = true
+	})
+	defer close()
+	ctx := context.Background()
+
+	_, err := ts.admin.CreateAPIKey(ctx, sqliteadmin.CreateAPIKeyParams{
+		Name:     "too much",
+		Role:     sqliteadmin.RoleViewer,
+		Commands: []sqliteadmin.Command{sqliteadmin.DeleteRows},
+	})
+	assert.ErrorContains(t, err, "Bad request: command not allowed for the role: viewer can't run DeleteRows")
+
+	created, err := ts.admin.CreateAPIKey(ctx, sqliteadmin.CreateAPIKeyParams{
+		Name:     "reports",
+		Role:     sqliteadmin.RoleViewer,
+		Commands: []sqliteadmin.Command{sqliteadmin.GetTable, sqliteadmin.Login},
+	})
+	assert.NoError(t, err)
+
+	req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: sqliteadmin.GetTable, Params: map[string]interface{}{"tableName": "users"}})
+	req.Header.Set("Authorization", "Bearer "+created.Key)
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	req = makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: sqliteadmin.ListTables})
+	req.Header.Set("Authorization", "Bearer "+created.Key)
+	res, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	assert.Equal(t, "Forbidden: command not allowed for the API key: ListTables", readBody(t, res.Body)["message"])
+
+	// Sessions would outlive the revocation of the key
+	req = makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: sqliteadmin.Login})
+	req.Header.Set("Authorization", "Bearer "+created.Key)
+	res, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	assert.Equal(t, "Forbidden: "+sqliteadmin.ErrBearerSession.Error(), readBody(t, res.Body)["message"])
+	assert.Empty(t, res.Cookies())
+}
+
+func TestAPIKeyCreatedByKey(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	created, err := ts.admin.CreateAPIKey(context.Background(), sqliteadmin.CreateAPIKeyParams{
+		Name:     "provisioner",
+		Role:     sqliteadmin.RoleAdmin,
+		Commands: []sqliteadmin.Command{sqliteadmin.CreateAPIKey, sqliteadmin.GetTable},
+	})
+	assert.NoError(t, err)
+
+	create := func(commands []sqliteadmin.Command) (int, map[string]interface{}) {
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{
+			Command: sqliteadmin.CreateAPIKey,
+			Params:  map[string]interface{}{"name": "child", "role": "admin", "commands": commands},
+		})
+		req.Header.Set("Authorization", "Bearer "+created.Key)
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res.StatusCode, readBody(t, res.Body)
+	}
+
+	// Restricted keys can't create keys with more commands than theirs
+	status, body := create(nil)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, "Forbidden: command not allowed for the API key: the new key must be restricted to some of its commands", body["message"])
+	status, body = create([]sqliteadmin.Command{sqliteadmin.GetTable, sqliteadmin.DeleteRows})
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, "Forbidden: command not allowed for the API key: DeleteRows", body["message"])
+
+	status, body = create([]sqliteadmin.Command{sqliteadmin.GetTable})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []interface{}{"GetTable"}, body["apiKey"].(map[string]interface{})["commands"])
+}
@@ -0,0 +1,172 @@
+package sqliteadmin
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/binary"
+	"hash"
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Encrypted archives are zip files whose entry is encrypted with AES-256 as
+// specified by WinZip (AE-2), which 7-Zip, WinZip and the archive tools of
+// macOS and most Linux desktops can open with the passphrase.
+const (
+	// zipMethodAES is the compression method of the entries encrypted with
+	// AES, whose actual method is in their extra field.
+	zipMethodAES = 99
+	// zipAESVersion is the version of the zip format needed to extract them.
+	zipAESVersion = 51
+	// zipExtraAES is the ID of the extra field of the AES entries.
+	zipExtraAES = 0x9901
+	// zipAESStrength is the strength of AES-256 in the extra field.
+	zipAESStrength  = 3
+	zipAESKeySize   = 32
+	zipAESSaltSize  = 16
+	zipAESMACSize   = 10
+	zipAESIteration = 1000
+)
+
+// writeEncryptedZip writes a zip archive to dst holding the file at src under
+// the given name, compressed and encrypted with the passphrase.
+func writeEncryptedZip(dst, src, name, passphrase string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	// The sizes go in the header before the data, so the entry is encrypted
+	// to a temporary file first
+	tmp, err := os.CreateTemp("", "sqliteadmin-archive-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	salt := make([]byte, zipAESSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	keys := pbkdf2.Key([]byte(passphrase), salt, zipAESIteration, 2*zipAESKeySize+2, sha1.New)
+	if _, err := tmp.Write(append(salt, keys[2*zipAESKeySize:]...)); err != nil {
+		return err
+	}
+	enc, err := newZipAESWriter(tmp, keys[:zipAESKeySize], keys[zipAESKeySize:2*zipAESKeySize])
+	if err != nil {
+		return err
+	}
+	fw, err := flate.NewWriter(enc, flate.DefaultCompression)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(fw, in); err != nil {
+		return err
+	}
+	if err := fw.Close(); err != nil {
+		return err
+	}
+	if _, err := tmp.Write(enc.mac.Sum(nil)[:zipAESMACSize]); err != nil {
+		return err
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	zw := zip.NewWriter(out)
+	// The CRC is left out with AE-2, the MAC authenticating the data instead
+	extra := binary.LittleEndian.AppendUint16(nil, zipExtraAES)
+	extra = binary.LittleEndian.AppendUint16(extra, 7)
+	extra = binary.LittleEndian.AppendUint16(extra, 2)
+	extra = append(extra, 'A', 'E', zipAESStrength)
+	extra = binary.LittleEndian.AppendUint16(extra, zip.Deflate)
+	// Raw entries are written as is, so the version and the MS-DOS time of
+	// modification are set here
+	now := time.Now()
+	header := &zip.FileHeader{
+		Name:               name,
+		CreatorVersion:     zipAESVersion,
+		ReaderVersion:      zipAESVersion,
+		Flags:              0x1,
+		Method:             zipMethodAES,
+		ModifiedTime:       uint16(now.Second()/2 + now.Minute()<<5 + now.Hour()<<11),
+		ModifiedDate:       uint16(now.Day() + int(now.Month())<<5 + (now.Year()-1980)<<9),
+		Extra:              extra,
+		CompressedSize64:   uint64(size),
+		UncompressedSize64: uint64(info.Size()),
+	}
+	w, err := zw.CreateRaw(header)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, tmp); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// zipAESWriter encrypts the data written to it with AES in CTR mode, whose
+// counter is little endian and starts at 1 in zip archives, and computes
+// the MAC of the encrypted data.
+type zipAESWriter struct {
+	w       io.Writer
+	block   cipher.Block
+	counter [aes.BlockSize]byte
+	stream  [aes.BlockSize]byte
+	used    int
+	mac     hash.Hash
+}
+
+func newZipAESWriter(w io.Writer, key, macKey []byte) (*zipAESWriter, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &zipAESWriter{w: w, block: block, used: aes.BlockSize, mac: hmac.New(sha1.New, macKey)}, nil
+}
+
+func (z *zipAESWriter) Write(p []byte) (int, error) {
+	out := make([]byte, len(p))
+	for i, b := range p {
+		if z.used == aes.BlockSize {
+			for j := range z.counter {
+				z.counter[j]++
+				if z.counter[j] != 0 {
+					break
+				}
+			}
+			z.block.Encrypt(z.stream[:], z.counter[:])
+			z.used = 0
+		}
+		out[i] = b ^ z.stream[z.used]
+		z.used++
+	}
+	z.mac.Write(out)
+	return z.w.Write(out)
+}
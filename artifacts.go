@@ -0,0 +1,158 @@
+package sqliteadmin
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultArtifactTTL is how long a published artifact can be downloaded when
+// Config.ArtifactTTL is zero.
+const DefaultArtifactTTL = 15 * time.Minute
+
+// Artifact is a file published for download with HandleArtifact, such as an
+// export or a backup written by a background task.
+type Artifact struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// URL is the path of the artifact relative to where HandleArtifact is
+	// mounted, with its signed token, e.g. "<id>?token=...".
+	URL     string    `json:"url"`
+	Expires time.Time `json:"expires"`
+}
+
+// artifact is a published file waiting to be downloaded.
+type artifact struct {
+	path    string
+	name    string
+	expires time.Time
+}
+
+// PublishArtifact makes the file at path downloadable with HandleArtifact
+// until Config.ArtifactTTL elapses, under the name given to the browser. The
+// file is streamed from disk rather than loaded in memory, and the Admin owns
+// it from then on: it is removed once it expires.
+func (a *Admin) PublishArtifact(filePath, name string) (Artifact, error) {
+	if _, err := os.Stat(filePath); err != nil {
+		return Artifact{}, err
+	}
+	id, err := newID()
+	if err != nil {
+		return Artifact{}, err
+	}
+	if name == "" {
+		name = path.Base(filePath)
+	}
+	expires := time.Now().Add(a.artifactTTL)
+
+	a.mu.Lock()
+	a.pruneArtifacts()
+	a.artifacts[id] = artifact{path: filePath, name: name, expires: expires}
+	a.mu.Unlock()
+
+	a.logger.Info(fmt.Sprintf("Audit: published artifact %s (%s) until %s", id, name, expires.Format(time.RFC3339)))
+
+	token := a.signArtifact(id, expires)
+	return Artifact{
+		ID:      id,
+		Name:    name,
+		URL:     id + "?token=" + url.QueryEscape(token),
+		Expires: expires,
+	}, nil
+}
+
+// HandleArtifact serves the files published with PublishArtifact. It is meant
+// to be mounted on its own GET route ending with the artifact ID, e.g.
+// "/artifacts/{id}", and authenticates the requests with the "token" query
+// param of the artifact URL rather than the credentials of HandlePost, so
+// that the link can be opened by a browser. Range requests are supported.
+func (a *Admin) HandleArtifact(w http.ResponseWriter, r *http.Request) {
+	id := path.Base(r.URL.Path)
+	if !a.verifyArtifact(id, r.URL.Query().Get("token"), time.Now()) {
+		w.Header().Set("Content-Type", "application/json")
+		writeError(w, apiErrForbidden(ErrInvalidArtifactToken.Error()))
+		return
+	}
+
+	a.mu.Lock()
+	a.pruneArtifacts()
+	art, ok := a.artifacts[id]
+	a.mu.Unlock()
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		writeError(w, apiErrNotFound())
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Artifact: %s", id))
+
+	f, err := os.Open(art.path)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error opening artifact: %v", err))
+		w.Header().Set("Content-Type", "application/json")
+		writeError(w, apiErrSomethingWentWrong())
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error opening artifact: %v", err))
+		w.Header().Set("Content-Type", "application/json")
+		writeError(w, apiErrSomethingWentWrong())
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", art.name))
+	w.Header().Set("Cache-Control", "private, no-store")
+	http.ServeContent(w, r, art.name, info.ModTime(), f)
+}
+
+// signArtifact returns the token of the artifact URL, which is its expiration
+// time and a MAC of it along with the ID.
+func (a *Admin) signArtifact(id string, expires time.Time) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	return exp + "." + base64.RawURLEncoding.EncodeToString(a.artifactMAC(id, exp))
+}
+
+// verifyArtifact reports whether the token was signed for the artifact and
+// hasn't expired.
+func (a *Admin) verifyArtifact(id, token string, now time.Time) bool {
+	exp, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, a.artifactMAC(id, exp)) {
+		return false
+	}
+	expires, err := strconv.ParseInt(exp, 10, 64)
+	return err == nil && now.Unix() < expires
+}
+
+func (a *Admin) artifactMAC(id, exp string) []byte {
+	mac := hmac.New(sha256.New, a.artifactKey)
+	mac.Write([]byte(id + "." + exp))
+	return mac.Sum(nil)
+}
+
+// pruneArtifacts removes the expired artifacts along with their files. It
+// must be called with a.mu held.
+func (a *Admin) pruneArtifacts() {
+	now := time.Now()
+	for id, art := range a.artifacts {
+		if now.After(art.expires) {
+			delete(a.artifacts, id)
+			if err := os.Remove(art.path); err != nil && !os.IsNotExist(err) {
+				a.logger.Error(fmt.Sprintf("Error removing artifact: %v", err))
+			}
+		}
+	}
+}
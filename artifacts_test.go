@@ -0,0 +1,91 @@
+package sqliteadmin_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleArtifact(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+
+	a := sqliteadmin.New(sqliteadmin.Config{DB: db, ArtifactKey: []byte("secret")})
+	mux := http.NewServeMux()
+	mux.Handle("/artifacts/", http.HandlerFunc(a.HandleArtifact))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "export.csv")
+	assert.NoError(t, os.WriteFile(path, []byte("id,name\n1,Alice\n"), 0o600))
+
+	art, err := a.PublishArtifact(path, "users.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, "users.csv", art.Name)
+	assert.True(t, strings.HasPrefix(art.URL, art.ID+"?token="))
+
+	res, err := http.Get(server.URL + "/artifacts/" + art.URL)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, `attachment; filename="users.csv"`, res.Header.Get("Content-Disposition"))
+	assert.Equal(t, "id,name\n1,Alice\n", string(body))
+
+	// Tokens are only valid for the artifact they were signed for
+	for _, u := range []string{
+		art.ID,
+		art.URL + "x",
+		"0123456789abcdef0123456789abcdef" + strings.TrimPrefix(art.URL, art.ID),
+	} {
+		res, err := http.Get(server.URL + "/artifacts/" + u)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode, u)
+		assert.Equal(t, map[string]interface{}{
+			"statusCode": float64(403),
+			"code":       "FORBIDDEN",
+			"message":    "Forbidden: invalid or expired download link",
+		}, readBody(t, res.Body))
+	}
+}
+
+func TestHandleArtifactExpired(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+
+	a := sqliteadmin.New(sqliteadmin.Config{DB: db, ArtifactTTL: time.Millisecond})
+	server := httptest.NewServer(http.HandlerFunc(a.HandleArtifact))
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "backup.db")
+	assert.NoError(t, os.WriteFile(path, []byte("backup"), 0o600))
+	art, err := a.PublishArtifact(path, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "backup.db", art.Name)
+
+	time.Sleep(10 * time.Millisecond)
+	res, err := http.Get(server.URL + "/" + art.URL)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+
+	// Expired artifacts are removed once another one is published
+	other := filepath.Join(dir, "other.db")
+	assert.NoError(t, os.WriteFile(other, []byte("other"), 0o600))
+	_, err = a.PublishArtifact(other, "")
+	assert.NoError(t, err)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = a.PublishArtifact(filepath.Join(dir, "missing.db"), "")
+	assert.Error(t, err)
+}
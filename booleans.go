@@ -0,0 +1,56 @@
+package sqliteadmin
+
+import (
+	"strings"
+)
+
+// isBooleanType reports whether a column declared with the given type holds
+// booleans, which SQLite stores as the integers 0 and 1.
+func isBooleanType(dataType string) bool {
+	switch strings.ToUpper(strings.TrimSpace(dataType)) {
+	case "BOOLEAN", "BOOL":
+		return true
+	}
+	return false
+}
+
+// convertBooleans converts the 0 and 1 of boolean columns to false and true.
+// Other values are left as stored.
+func convertBooleans(rows []map[string]interface{}, types map[string]string) {
+	for _, row := range rows {
+		for column, value := range row {
+			if v, ok := value.(int64); ok && (v == 0 || v == 1) && isBooleanType(types[column]) {
+				row[column] = v == 1
+			}
+		}
+	}
+}
+
+// storeBooleans converts the booleans sent for boolean columns, including as
+// "true" and "false", to the integers they are stored as, since not every
+// driver binds booleans.
+func storeBooleans(row map[string]interface{}, types map[string]string) {
+	for column, value := range row {
+		if !isBooleanType(types[column]) {
+			continue
+		}
+		switch v := value.(type) {
+		case bool:
+			row[column] = boolToInt(v)
+		case string:
+			switch strings.ToLower(v) {
+			case "true":
+				row[column] = 1
+			case "false":
+				row[column] = 0
+			}
+		}
+	}
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
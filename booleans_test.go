@@ -0,0 +1,62 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBooleanColumns(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	_, err := ts.db.Exec(`
+		CREATE TABLE features (id INTEGER PRIMARY KEY, enabled BOOLEAN, beta bool, rollout INTEGER);
+		INSERT INTO features VALUES (1, 1, 0, 1), (2, 0, NULL, 0);
+	`)
+	assert.NoError(t, err)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Success: Booleans",
+			params:         map[string]interface{}{"tableName": "features"},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"rows": []interface{}{
+					map[string]interface{}{"id": float64(1), "enabled": true, "beta": false, "rollout": float64(1)},
+					map[string]interface{}{"id": float64(2), "enabled": false, "beta": nil, "rollout": float64(0)},
+				},
+			},
+		},
+	}, sqliteadmin.GetTable, t, ts.server)
+
+	runTestCases([]TestCase{
+		{
+			name: "Success: Update Booleans",
+			params: map[string]interface{}{
+				"tableName": "features",
+				"row":       map[string]interface{}{"id": 1, "enabled": false, "beta": "true"},
+			},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok"},
+		},
+	}, sqliteadmin.UpdateRow, t, ts.server)
+
+	var enabled, beta int
+	assert.NoError(t, ts.db.QueryRow("SELECT enabled, beta FROM features WHERE id = 1").Scan(&enabled, &beta))
+	assert.Equal(t, 0, enabled)
+	assert.Equal(t, 1, beta)
+
+	res, err := ts.admin.GetTable(context.Background(), sqliteadmin.GetTableParams{TableName: "features", IncludeInfo: true})
+	assert.NoError(t, err)
+	var booleans []string
+	for _, c := range res.TableInfo.Columns {
+		if c.Boolean {
+			booleans = append(booleans, c.Name)
+		}
+	}
+	assert.Equal(t, []string{"enabled", "beta"}, booleans)
+}
@@ -0,0 +1,111 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultBusyTimeout is how long mutations wait for the locks held by other
+// connections when Config.BusyTimeout is zero.
+const DefaultBusyTimeout = 5 * time.Second
+
+// isBusy reports whether err is SQLite failing to get a lock held by another
+// connection, e.g. while the application is writing.
+func isBusy(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "database is locked") ||
+		strings.Contains(msg, "database table is locked") ||
+		strings.Contains(msg, "SQLITE_BUSY") ||
+		strings.Contains(msg, "SQLITE_LOCKED")
+}
+
+// immediateTx is a write transaction on a dedicated connection, started with
+// BEGIN IMMEDIATE so that the write lock is taken before anything is read.
+// SQLite only retries getting the lock of a transaction that hasn't read yet,
+// since it could otherwise deadlock with the connection holding the lock.
+type immediateTx struct {
+	*sql.Conn
+	logger Logger
+	// previous is the busy timeout of the connection to restore.
+	previous int64
+	done     bool
+	// db and schemas are where the columns of tables are cached.
+	db      *sql.DB
+	schemas *schemaCache
+}
+
+// beginImmediateTx starts a write transaction on a connection of db which
+// waits up to the busy timeout for the locks held by other connections, with
+// SQLite's own backoff between retries.
+func (a *Admin) beginImmediateTx(ctx context.Context, db *sql.DB) (*immediateTx, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting connection: %v", err)
+	}
+	tx := &immediateTx{Conn: conn, logger: a.logger, previous: -1, db: db, schemas: a.schemas}
+	if a.busyTimeout >= 0 {
+		if err := conn.QueryRowContext(ctx, "PRAGMA busy_timeout").Scan(&tx.previous); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("error reading busy timeout: %v", err)
+		}
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("PRAGMA busy_timeout = %d", a.busyTimeout.Milliseconds())); err != nil {
+			tx.release()
+			return nil, fmt.Errorf("error setting busy timeout: %v", err)
+		}
+	}
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		tx.release()
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (t *immediateTx) schemaCache() (*schemaCache, *sql.DB) {
+	return t.schemas, t.db
+}
+
+func (t *immediateTx) Commit() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	// A failed commit is still rolled back by Rollback
+	if _, err := t.Conn.ExecContext(context.Background(), "COMMIT"); err != nil {
+		return err
+	}
+	t.release()
+	return nil
+}
+
+func (t *immediateTx) Rollback() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	// The background context makes sure the changes are undone even if the
+	// request was canceled.
+	_, err := t.Conn.ExecContext(context.Background(), "ROLLBACK")
+	t.release()
+	return err
+}
+
+// release gives the connection back to the pool with its busy timeout.
+func (t *immediateTx) release() {
+	t.done = true
+	if t.previous >= 0 {
+		if _, err := t.Conn.ExecContext(context.Background(), fmt.Sprintf("PRAGMA busy_timeout = %d", t.previous)); err != nil {
+			// The connection is discarded rather than given back to the
+			// application with another timeout
+			t.logger.Error(fmt.Sprintf("Error restoring busy timeout: %v", err))
+			t.Conn.Raw(func(interface{}) error {
+				return driver.ErrBadConn
+			})
+		}
+	}
+	t.Conn.Close()
+}
@@ -0,0 +1,79 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBusyDatabase(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.BusyTimeout = 500 * time.Millisecond
+	})
+	defer close()
+
+	path := filepath.Join(t.TempDir(), "app.db")
+	db, err := sql.Open("sqlite", path)
+	assert.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("CREATE TABLE t (id INTEGER PRIMARY KEY, name TEXT); INSERT INTO t VALUES (1, 'a')")
+	assert.NoError(t, err)
+	ts.admin.AddDatabase("app", db)
+
+	// The application, holding the write lock on its own connection
+	app, err := sql.Open("sqlite", path)
+	assert.NoError(t, err)
+	defer app.Close()
+	lock := func() *sql.Conn {
+		conn, err := app.Conn(context.Background())
+		assert.NoError(t, err)
+		_, err = conn.ExecContext(context.Background(), "BEGIN IMMEDIATE")
+		assert.NoError(t, err)
+		return conn
+	}
+	unlock := func(conn *sql.Conn) {
+		_, err := conn.ExecContext(context.Background(), "COMMIT")
+		assert.NoError(t, err)
+		conn.Close()
+	}
+	update := func(name string) (int, map[string]interface{}) {
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{
+			Command: sqliteadmin.UpdateRow,
+			Params:  map[string]interface{}{"database": "app", "tableName": "t", "row": map[string]interface{}{"id": 1, "name": name}},
+		})
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res.StatusCode, readBody(t, res.Body)
+	}
+
+	t.Run("Retried Until Unlocked", func(t *testing.T) {
+		conn := lock()
+		time.AfterFunc(100*time.Millisecond, func() { unlock(conn) })
+
+		status, _ := update("b")
+		assert.Equal(t, http.StatusOK, status)
+	})
+
+	t.Run("Failure: Still Locked", func(t *testing.T) {
+		conn := lock()
+		defer unlock(conn)
+
+		status, body := update("c")
+		assert.Equal(t, http.StatusConflict, status)
+		assert.Equal(t, "Conflict: "+sqliteadmin.ErrDatabaseBusy.Error(), body["message"])
+	})
+
+	// The connections are given back to the pool with their busy timeout
+	var timeout int
+	assert.NoError(t, db.QueryRow("PRAGMA busy_timeout").Scan(&timeout))
+	assert.Equal(t, 0, timeout)
+	var name string
+	assert.NoError(t, db.QueryRow("SELECT name FROM t").Scan(&name))
+	assert.Equal(t, "b", name)
+}
@@ -0,0 +1,239 @@
+package sqliteadmin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// cascadeBatchSize is the number of rowids looked up per query when walking
+// the foreign keys, to stay below the limit of SQL variables.
+const cascadeBatchSize = 500
+
+// CascadeEffect is what deleting rows does to the rows of another table
+// referencing them through a foreign key.
+type CascadeEffect struct {
+	Table   string   `json:"tableName"`
+	Columns []string `json:"columns"`
+	// Action is the ON DELETE action of the foreign key: CASCADE, SET NULL,
+	// SET DEFAULT, RESTRICT or NO ACTION.
+	Action string `json:"action"`
+	// Rows is the number of rows deleted, updated or blocking the delete,
+	// depending on the action.
+	Rows int `json:"rows"`
+}
+
+// DeleteRowsPreviewResponse is the response of DeleteRows when
+// PreviewCascade is set.
+type DeleteRowsPreviewResponse struct {
+	// Rows is the number of rows of the table that would be deleted.
+	Rows    int             `json:"rows"`
+	Effects []CascadeEffect `json:"effects"`
+	// Blocked is set when rows referencing the deleted rows with RESTRICT or
+	// NO ACTION would make the delete fail.
+	Blocked bool `json:"blocked"`
+	// ForeignKeys is whether the database enforces foreign keys, without
+	// which deletes neither cascade nor are blocked.
+	ForeignKeys bool `json:"foreignKeys"`
+}
+
+// reference is a foreign key of Table referencing another table, with its
+// columns matched in order.
+type reference struct {
+	Table    string
+	From     []string
+	To       []string
+	OnDelete string
+}
+
+// previewCascade reports the rows of other tables that deleting the rows of
+// the table with the given primary keys would delete, update or be blocked
+// by, following the ON DELETE CASCADE foreign keys of the deleted rows in
+// turn. Nothing is written.
+func previewCascade(ctx context.Context, q queryer, table, primaryKey string, keys []interface{}) (*DeleteRowsPreviewResponse, error) {
+	preview := &DeleteRowsPreviewResponse{Effects: []CascadeEffect{}}
+	if err := q.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&preview.ForeignKeys); err != nil {
+		return nil, fmt.Errorf("error reading foreign_keys: %v", err)
+	}
+	references, err := getReferences(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	// The rows are tracked by rowid across tables
+	deleted := map[string]map[int64]bool{table: {}}
+	var rowids []int64
+	for start := 0; start < len(keys); start += cascadeBatchSize {
+		batch := keys[start:min(start+cascadeBatchSize, len(keys))]
+		query := fmt.Sprintf("SELECT rowid FROM %s WHERE %s IN (%s)", quoteIdent(table), quoteIdent(primaryKey), placeholders(len(batch)))
+		ids, err := queryRowIDs(ctx, q, query, batch...)
+		if err != nil {
+			return nil, err
+		}
+		rowids = append(rowids, ids...)
+	}
+	for _, id := range rowids {
+		deleted[table][id] = true
+	}
+	preview.Rows = len(deleted[table])
+
+	type pending struct {
+		table  string
+		rowids []int64
+	}
+	type effect struct {
+		reference
+		rowids map[int64]bool
+	}
+	var effects []*effect
+	byReference := map[string]*effect{}
+	queue := []pending{{table, rowids}}
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		for _, ref := range references[parent.table] {
+			var matched []int64
+			for start := 0; start < len(parent.rowids); start += cascadeBatchSize {
+				batch := parent.rowids[start:min(start+cascadeBatchSize, len(parent.rowids))]
+				args := make([]interface{}, len(batch))
+				for i, id := range batch {
+					args[i] = id
+				}
+				query := fmt.Sprintf("SELECT rowid FROM %s WHERE (%s) IN (SELECT %s FROM %s WHERE rowid IN (%s))",
+					quoteIdent(ref.Table), quoteIdents(ref.From), quoteIdents(ref.To), quoteIdent(parent.table), placeholders(len(batch)))
+				ids, err := queryRowIDs(ctx, q, query, args...)
+				if err != nil {
+					return nil, err
+				}
+				matched = append(matched, ids...)
+			}
+			if len(matched) == 0 {
+				continue
+			}
+
+			key := ref.Table + "\x00" + strings.Join(ref.From, "\x00")
+			e := byReference[key]
+			if e == nil {
+				e = &effect{reference: ref, rowids: map[int64]bool{}}
+				byReference[key] = e
+				effects = append(effects, e)
+			}
+			if deleted[ref.Table] == nil {
+				deleted[ref.Table] = map[int64]bool{}
+			}
+			var cascaded []int64
+			for _, id := range matched {
+				e.rowids[id] = true
+				if ref.OnDelete == "CASCADE" && !deleted[ref.Table][id] {
+					deleted[ref.Table][id] = true
+					cascaded = append(cascaded, id)
+				}
+			}
+			if len(cascaded) > 0 {
+				queue = append(queue, pending{ref.Table, cascaded})
+			}
+		}
+	}
+
+	for _, e := range effects {
+		rows := len(e.rowids)
+		// The rows deleted through another foreign key are neither updated
+		// nor blocking
+		if e.OnDelete != "CASCADE" {
+			rows = 0
+			for id := range e.rowids {
+				if !deleted[e.Table][id] {
+					rows++
+				}
+			}
+		}
+		if rows == 0 {
+			continue
+		}
+		if e.OnDelete == "RESTRICT" || e.OnDelete == "NO ACTION" {
+			preview.Blocked = preview.ForeignKeys
+		}
+		preview.Effects = append(preview.Effects, CascadeEffect{Table: e.Table, Columns: e.From, Action: e.OnDelete, Rows: rows})
+	}
+	return preview, nil
+}
+
+// getReferences returns the foreign keys of all the tables, by the table they
+// reference.
+func getReferences(ctx context.Context, q queryer) (map[string][]reference, error) {
+	tables, err := getTableNames(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(tables)
+
+	references := map[string][]reference{}
+	for _, t := range tables {
+		fks, err := getForeignKeys(ctx, q, t)
+		if err != nil {
+			return nil, err
+		}
+		// The columns of composite foreign keys share the same ID
+		for i := 0; i < len(fks); {
+			fk := fks[i]
+			ref := reference{Table: t, OnDelete: fk.OnDelete}
+			for ; i < len(fks) && fks[i].ID == fk.ID; i++ {
+				ref.From = append(ref.From, fks[i].From)
+				ref.To = append(ref.To, fks[i].To)
+			}
+			// A foreign key without target columns references the primary
+			// key
+			if ref.To[0] == "" {
+				columns, err := getColumns(ctx, q, fk.Table)
+				if err != nil {
+					return nil, err
+				}
+				sort.Slice(columns, func(i, j int) bool { return columns[i].PK < columns[j].PK })
+				ref.To = nil
+				for _, c := range columns {
+					if c.PK > 0 {
+						ref.To = append(ref.To, c.Name)
+					}
+				}
+			}
+			// Such foreign keys are reported as mismatched by SQLite when
+			// the referenced table has no primary key
+			if len(ref.To) == 0 {
+				continue
+			}
+			references[fk.Table] = append(references[fk.Table], ref)
+		}
+	}
+	return references, nil
+}
+
+func queryRowIDs(ctx context.Context, q queryer, query string, args ...interface{}) ([]int64, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error finding referencing rows: %v", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func quoteIdents(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = quoteIdent(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
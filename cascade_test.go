@@ -0,0 +1,61 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreviewCascade(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "app.db")+"?_pragma=foreign_keys(1)")
+	assert.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(`
+		CREATE TABLE authors (id INTEGER PRIMARY KEY, name TEXT);
+		CREATE TABLE books (id INTEGER PRIMARY KEY, author_id INTEGER REFERENCES authors ON DELETE CASCADE);
+		CREATE TABLE reviews (id INTEGER PRIMARY KEY, book_id INTEGER REFERENCES books (id) ON DELETE CASCADE);
+		CREATE TABLE loans (id INTEGER PRIMARY KEY, book_id INTEGER REFERENCES books (id));
+		CREATE TABLE picks (id INTEGER PRIMARY KEY, author_id INTEGER REFERENCES authors (id) ON DELETE SET NULL);
+		INSERT INTO authors VALUES (1, 'a'), (2, 'b');
+		INSERT INTO books VALUES (1, 1), (2, 1), (3, 2);
+		INSERT INTO reviews VALUES (1, 1), (2, 1), (3, 2), (4, 3);
+		INSERT INTO picks VALUES (1, 1), (2, 2);
+	`)
+	assert.NoError(t, err)
+	ts.admin.AddDatabase("app", db)
+
+	preview, err := ts.admin.PreviewDeleteRows(context.Background(), sqliteadmin.DeleteRowsParams{Database: "app", TableName: "authors", IDs: []string{"1"}})
+	assert.NoError(t, err)
+	assert.Equal(t, &sqliteadmin.DeleteRowsPreviewResponse{
+		Rows: 1,
+		Effects: []sqliteadmin.CascadeEffect{
+			{Table: "books", Columns: []string{"author_id"}, Action: "CASCADE", Rows: 2},
+			{Table: "picks", Columns: []string{"author_id"}, Action: "SET NULL", Rows: 1},
+			{Table: "reviews", Columns: []string{"book_id"}, Action: "CASCADE", Rows: 3},
+		},
+		ForeignKeys: true,
+	}, preview)
+
+	// Nothing was deleted
+	var reviews int
+	assert.NoError(t, db.QueryRow("SELECT count(*) FROM reviews").Scan(&reviews))
+	assert.Equal(t, 4, reviews)
+
+	_, err = db.Exec("INSERT INTO loans VALUES (1, 3)")
+	assert.NoError(t, err)
+	preview, err = ts.admin.PreviewDeleteRows(context.Background(), sqliteadmin.DeleteRowsParams{Database: "app", TableName: "authors", IDs: []string{"1", "2"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, preview.Rows)
+	assert.True(t, preview.Blocked)
+	assert.Contains(t, preview.Effects, sqliteadmin.CascadeEffect{Table: "loans", Columns: []string{"book_id"}, Action: "NO ACTION", Rows: 1})
+
+	_, err = ts.admin.DeleteRows(context.Background(), sqliteadmin.DeleteRowsParams{Database: "app", TableName: "authors", IDs: []string{"1", "2"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,67 @@
+package sqliteadmin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// UpdateCellParams are the params of UpdateCell.
+type UpdateCellParams struct {
+	Database  string `json:"database,omitempty" mapstructure:"database"`
+	TableName string `json:"tableName" mapstructure:"tableName"`
+	// Key is the primary key of the row.
+	Key    interface{} `json:"key" mapstructure:"key"`
+	Column string      `json:"column" mapstructure:"column"`
+	// Value is the new value of the column, NULL when nil.
+	Value       interface{} `json:"value" mapstructure:"value"`
+	Transaction string      `json:"transaction,omitempty" mapstructure:"transaction"`
+}
+
+func (p *UpdateCellParams) validate() error {
+	if p.TableName == "" {
+		return ErrMissingTableName
+	}
+	if p.Key == nil {
+		return ErrMissingKey
+	}
+	if p.Column == "" {
+		return ErrMissingColumn
+	}
+	return nil
+}
+
+// updateCell updates a single column of a row, for inline editing. Unlike
+// UpdateRow with the whole row, it leaves the other columns as they are, so
+// that the concurrent edits of other columns aren't overwritten.
+func (a *Admin) updateCell(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	var p UpdateCellParams
+	if err := a.decodeParams(params, &p); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: UpdateCell, table=%s, key=%v, column=%s", p.TableName, p.Key, p.Column))
+
+	primaryKey, err := getPrimaryKey(ctx, a.cached(db), p.TableName)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error getting primary key: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if p.Column == primaryKey {
+		writeError(w, apiErrBadRequest(ErrPrimaryKeyCell.Error()))
+		return
+	}
+
+	a.updateRowValues(ctx, w, db, params, UpdateRowParams{
+		Database:    p.Database,
+		TableName:   p.TableName,
+		Row:         map[string]interface{}{primaryKey: p.Key, p.Column: p.Value},
+		Transaction: p.Transaction,
+	})
+}
@@ -0,0 +1,106 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateCell(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	testCases := []TestCase{
+		{
+			name: "Success: Only the column is updated",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"key":       1,
+				"column":    "name",
+				"value":     "Alicia",
+			},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok"},
+		},
+		{
+			name: "Success: NULL value",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"key":       2,
+				"column":    "email",
+				"value":     nil,
+			},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok"},
+		},
+		{
+			name: "Failure: Missing key",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"column":    "name",
+				"value":     "Alicia",
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: missing primary key",
+			},
+		},
+		{
+			name: "Failure: Primary key",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"key":       1,
+				"column":    "id",
+				"value":     100,
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: the primary key of a row cannot be updated",
+			},
+		},
+		{
+			name: "Failure: Unknown column",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"key":       1,
+				"column":    "age",
+				"value":     30,
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "COLUMN_NOT_FOUND",
+				"message":    "Bad request: unknown column",
+				"detail":     "age: unknown column",
+			},
+		},
+	}
+
+	runTestCases(testCases, sqliteadmin.UpdateCell, t, ts.server)
+
+	rows, err := getTableValues(ts.db, "users")
+	assert.NoError(t, err)
+	assert.Equal(t, "Alicia", rows[0]["name"])
+	assert.Equal(t, "alice@gmail.com", rows[0]["email"])
+	assert.Equal(t, "Bob", rows[1]["name"])
+	assert.Nil(t, rows[1]["email"])
+
+	_, err = ts.admin.UpdateCell(context.Background(), sqliteadmin.UpdateCellParams{
+		TableName: "users",
+		Key:       3,
+		Column:    "email",
+		Value:     "charlie@example.com",
+	})
+	assert.NoError(t, err)
+	rows, err = getTableValues(ts.db, "users")
+	assert.NoError(t, err)
+	assert.Equal(t, "Charlie", rows[2]["name"])
+	assert.Equal(t, "charlie@example.com", rows[2]["email"])
+}
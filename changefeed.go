@@ -0,0 +1,268 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// snapshotsRetained is the number of snapshots kept per table, i.e. how many
+// cursors of a table remain valid for GetChanges.
+const snapshotsRetained = 8
+
+const (
+	trackedCursorPrefix  = "t:"
+	snapshotCursorPrefix = "s:"
+)
+
+// tableSnapshot is the version of every row of a table at the time a cursor
+// was returned by GetChanges.
+type tableSnapshot struct {
+	key      string
+	versions map[string]string
+	created  time.Time
+}
+
+// getChanges returns the rows inserted, updated or deleted since the cursor of
+// a previous call. Tables tracked with TrackChanges are read from the changes
+// recorded by their triggers. Other tables are compared with a snapshot of the
+// versions of their rows taken by the previous call, which also catches
+// changes made outside the admin but scans the whole table, and only returns
+// the key of deleted rows.
+func (a *Admin) getChanges(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getReadDB(w, params)
+	if !ok {
+		return
+	}
+	table, ok := params["tableName"].(string)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrMissingTableName.Error()))
+		return
+	}
+	cursor, _ := params["cursor"].(string)
+
+	a.logger.Info(fmt.Sprintf("Command: GetChanges, table=%s, cursor=%s", table, cursor))
+
+	exists, err := checkTableExists(ctx, a.cached(db), table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error checking table existence: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if !exists || hasInternalPrefix(table) {
+		writeError(w, apiErrTableNotFound())
+		return
+	}
+
+	tracked := strings.HasPrefix(cursor, trackedCursorPrefix)
+	if cursor == "" {
+		tracked, err = isTracked(ctx, a.cached(db), table)
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Error checking change tracking: %v", err))
+			writeError(w, a.apiErr(err))
+			return
+		}
+	} else if !tracked && !strings.HasPrefix(cursor, snapshotCursorPrefix) {
+		writeError(w, apiErrBadRequest(ErrInvalidCheckpoint.Error()))
+		return
+	}
+
+	var changes []Change
+	if tracked {
+		changes, cursor, err = a.trackedChanges(ctx, db, table, cursor)
+	} else {
+		name, _ := params["database"].(string)
+		changes, cursor, err = a.snapshotChanges(ctx, db, name, table, cursor)
+	}
+	if err == errUnknownCursor {
+		writeError(w, apiErrBadRequest(ErrInvalidCheckpoint.Error()))
+		return
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error getting changes: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.recordRows(len(changes))
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"changes": changes,
+		"cursor":  cursor,
+	})
+}
+
+var errUnknownCursor = errors.New("unknown cursor")
+
+func isTracked(ctx context.Context, q queryer, table string) (bool, error) {
+	var n int
+	err := q.QueryRowContext(ctx, "SELECT count(*) FROM sqlite_master WHERE type = 'trigger' AND name = ?",
+		changeTriggerName(table, changeOperations[0].name)).Scan(&n)
+	return n > 0, err
+}
+
+func (a *Admin) trackedChanges(ctx context.Context, db *sql.DB, table, cursor string) ([]Change, string, error) {
+	var after int64
+	if cursor != "" {
+		var err error
+		after, err = strconv.ParseInt(strings.TrimPrefix(cursor, trackedCursorPrefix), 10, 64)
+		if err != nil || after < 0 {
+			return nil, "", errUnknownCursor
+		}
+	}
+
+	limit := a.maxLimit
+	if limit < 0 {
+		limit = -1
+	}
+	changes, err := getChanges(ctx, a.cached(db), table, after, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(changes) > 0 {
+		after = changes[len(changes)-1].ID
+	}
+	changes, err = a.visibleChanges(ctx, changes)
+	if err != nil {
+		return nil, "", err
+	}
+	return changes, trackedCursorPrefix + strconv.FormatInt(after, 10), nil
+}
+
+func (a *Admin) snapshotChanges(ctx context.Context, db *sql.DB, database, table, cursor string) ([]Change, string, error) {
+	key := database + "\x00" + table
+	var previous *tableSnapshot
+	if cursor != "" {
+		a.mu.RLock()
+		previous = a.snapshots[strings.TrimPrefix(cursor, snapshotCursorPrefix)]
+		a.mu.RUnlock()
+		if previous == nil || previous.key != key {
+			return nil, "", errUnknownCursor
+		}
+	}
+
+	scope, err := a.rowScope(ctx, table)
+	if err != nil {
+		return nil, "", err
+	}
+	rows, err := queryTable(ctx, a.cached(db), table, scope, "", -1, 0, a.logger)
+	if err != nil {
+		return nil, "", err
+	}
+	page, err := versionPage(ctx, a.cached(db), table, rows)
+	if err != nil {
+		return nil, "", err
+	}
+	columns, err := getColumns(ctx, a.cached(db), table)
+	if err != nil {
+		return nil, "", err
+	}
+
+	snapshot := &tableSnapshot{key: key, versions: make(map[string]string, len(rows)), created: time.Now()}
+	for i, k := range page.Keys {
+		snapshot.versions[k] = page.Versions[i]
+	}
+
+	changes := []Change{}
+	if previous != nil {
+		changedAt := snapshot.created.UTC().Format(time.RFC3339Nano)
+		var changed []map[string]interface{}
+		for i, k := range page.Keys {
+			version, ok := previous.versions[k]
+			if ok && version == page.Versions[i] {
+				continue
+			}
+			operation := "insert"
+			if ok {
+				operation = "update"
+			}
+			changes = append(changes, Change{TableName: table, Operation: operation, Key: changeKey(columns, k), ChangedAt: changedAt})
+			changed = append(changed, rows[i])
+		}
+		a.protectRows(ctx, table, changed)
+		a.encodeRows(changed)
+		for i, row := range changed {
+			b, err := json.Marshal(row)
+			if err != nil {
+				return nil, "", fmt.Errorf("error encoding row: %v", err)
+			}
+			changes[i].Row = b
+		}
+		var deleted []string
+		for k := range previous.versions {
+			if _, ok := snapshot.versions[k]; !ok {
+				deleted = append(deleted, k)
+			}
+		}
+		sort.Strings(deleted)
+		for _, k := range deleted {
+			changes = append(changes, Change{TableName: table, Operation: "delete", Key: changeKey(columns, k), ChangedAt: changedAt})
+		}
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, "", err
+	}
+	a.saveSnapshot(id, snapshot)
+	return changes, snapshotCursorPrefix + id, nil
+}
+
+// saveSnapshot stores the snapshot, dropping the oldest ones of the table
+// beyond snapshotsRetained.
+func (a *Admin) saveSnapshot(id string, snapshot *tableSnapshot) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.snapshots[id] = snapshot
+	var ids []string
+	for id, s := range a.snapshots {
+		if s.key == snapshot.key {
+			ids = append(ids, id)
+		}
+	}
+	for len(ids) > snapshotsRetained {
+		oldest := 0
+		for i, id := range ids {
+			if a.snapshots[id].created.Before(a.snapshots[ids[oldest]].created) {
+				oldest = i
+			}
+		}
+		delete(a.snapshots, ids[oldest])
+		ids = append(ids[:oldest], ids[oldest+1:]...)
+	}
+}
+
+// changeKey converts the key of a row computed by versionPage to the form of
+// the keys recorded by the change triggers, e.g. {"id": 1}. Rows of tables
+// without a primary key are identified by their version instead.
+func changeKey(columns []column, key string) json.RawMessage {
+	var values []json.RawMessage
+	if err := json.Unmarshal([]byte(key), &values); err != nil {
+		b, _ := json.Marshal(map[string]string{"version": key})
+		return b
+	}
+
+	var b strings.Builder
+	b.WriteString("{")
+	i := 0
+	for _, c := range columns {
+		if c.PK == 0 || i >= len(values) {
+			continue
+		}
+		if i > 0 {
+			b.WriteString(",")
+		}
+		name, _ := json.Marshal(c.Name)
+		fmt.Fprintf(&b, "%s:%s", name, values[i])
+		i++
+	}
+	b.WriteString("}")
+	return json.RawMessage(b.String())
+}
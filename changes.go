@@ -0,0 +1,290 @@
+package sqliteadmin
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// changesTable is where the triggers created by TrackChanges record the
+// changes made to the tracked tables.
+const changesTable = "_sqliteadmin_changes"
+
+// Change is a row-level change recorded for a tracked table.
+type Change struct {
+	ID        int64  `json:"id"`
+	TableName string `json:"tableName"`
+	// Operation is one of "insert", "update" or "delete".
+	Operation string `json:"operation"`
+	// Key identifies the row, with the values of its primary key columns (or
+	// its rowid if it doesn't have one).
+	Key json.RawMessage `json:"key"`
+	// Row is the row after the change, or before it for deletes.
+	Row       json.RawMessage `json:"row"`
+	ChangedAt string          `json:"changedAt"`
+}
+
+var changeOperations = []struct {
+	name string
+	// row is the trigger's reference to the changed row
+	row string
+}{
+	{"insert", "NEW"},
+	{"update", "NEW"},
+	{"delete", "OLD"},
+}
+
+func (a *Admin) trackChanges(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return
+	}
+
+	table, ok := params["tableName"].(string)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrMissingTableName.Error()))
+		return
+	}
+	enabled := params["enabled"] != false
+
+	a.logger.Info(fmt.Sprintf("Command: TrackChanges, table=%s, enabled=%t", table, enabled))
+
+	if table == changesTable {
+		writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+		return
+	}
+	exists, err := checkTableExists(ctx, db, table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error checking table existence: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if !exists {
+		writeError(w, apiErrTableNotFound())
+		return
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error starting transaction: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	defer tx.Rollback()
+
+	if enabled {
+		err = createChangeTriggers(ctx, tx, table)
+	} else {
+		err = dropChangeTriggers(ctx, tx, table)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error updating change tracking: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// createChangeTriggers (re)creates the triggers recording the changes of the
+// table. Since the triggers list the columns of the table, they must be
+// recreated when its columns change.
+func createChangeTriggers(ctx context.Context, tx *sql.Tx, table string) error {
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		table_name TEXT NOT NULL,
+		operation TEXT NOT NULL,
+		row_key TEXT NOT NULL,
+		row_data TEXT NOT NULL,
+		changed_at TEXT NOT NULL DEFAULT (strftime('%%Y-%%m-%%dT%%H:%%M:%%fZ', 'now'))
+	)`, quoteIdent(changesTable)))
+	if err != nil {
+		return fmt.Errorf("error creating changes table: %v", err)
+	}
+
+	columns, err := getColumns(ctx, tx, table)
+	if err != nil {
+		return err
+	}
+
+	if err := dropChangeTriggers(ctx, tx, table); err != nil {
+		return err
+	}
+
+	for _, op := range changeOperations {
+		var keyArgs, rowArgs []string
+		for _, c := range columns {
+			arg := fmt.Sprintf("%s, %s.%s", quoteLiteral(c.Name), op.row, quoteIdent(c.Name))
+			rowArgs = append(rowArgs, arg)
+			if c.PK > 0 {
+				keyArgs = append(keyArgs, arg)
+			}
+		}
+		if len(keyArgs) == 0 {
+			keyArgs = []string{fmt.Sprintf("'rowid', %s.rowid", op.row)}
+		}
+
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(`CREATE TRIGGER %s AFTER %s ON %s BEGIN
+			INSERT INTO %s (table_name, operation, row_key, row_data)
+			VALUES (%s, '%s', json_object(%s), json_object(%s));
+		END`,
+			quoteIdent(changeTriggerName(table, op.name)), strings.ToUpper(op.name), quoteIdent(table),
+			quoteIdent(changesTable), quoteLiteral(table), op.name,
+			strings.Join(keyArgs, ", "), strings.Join(rowArgs, ", "),
+		))
+		if err != nil {
+			return fmt.Errorf("error creating %s trigger: %v", op.name, err)
+		}
+	}
+	return nil
+}
+
+func dropChangeTriggers(ctx context.Context, tx *sql.Tx, table string) error {
+	for _, op := range changeOperations {
+		_, err := tx.ExecContext(ctx, fmt.Sprintf("DROP TRIGGER IF EXISTS %s", quoteIdent(changeTriggerName(table, op.name))))
+		if err != nil {
+			return fmt.Errorf("error dropping %s trigger: %v", op.name, err)
+		}
+	}
+	return nil
+}
+
+func changeTriggerName(table, operation string) string {
+	return fmt.Sprintf("%s_%s_%s", changesTable, table, operation)
+}
+
+func (a *Admin) getChangesSince(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getReadDB(w, params)
+	if !ok {
+		return
+	}
+
+	// The checkpoint is opaque to clients, it is the ID of the last change
+	// they received.
+	checkpoint := "0"
+	if c, ok := params["checkpoint"].(string); ok && c != "" {
+		checkpoint = c
+	}
+	after, err := strconv.ParseInt(checkpoint, 10, 64)
+	if err != nil || after < 0 {
+		writeError(w, apiErrBadRequest(ErrInvalidCheckpoint.Error()))
+		return
+	}
+
+	limit := a.defaultLimit
+	if params["limit"] != nil {
+		limit, ok = convertNumber(params["limit"])
+		if !ok || limit <= 0 {
+			writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+			return
+		}
+	}
+	if exceeds(limit, a.maxLimit) {
+		writeError(w, apiErrBadRequest(ErrLimitTooLarge.Error()))
+		return
+	}
+
+	table, _ := params["tableName"].(string)
+	// The changes hold the rows, out of the scope or not
+	if a.scoped(table) {
+		writeError(w, apiErrForbidden(ErrOutOfScope.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: GetChangesSince, table=%s, checkpoint=%s, limit=%d", table, checkpoint, limit))
+
+	changes, err := getChanges(ctx, a.cached(db), table, after, limit)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error getting changes: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if len(changes) > 0 {
+		checkpoint = strconv.FormatInt(changes[len(changes)-1].ID, 10)
+	}
+	// The checkpoint skips the changes left out as well
+	changes, err = a.visibleChanges(ctx, changes)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error reading changes: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.recordRows(len(changes))
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"changes":    changes,
+		"checkpoint": checkpoint,
+	})
+}
+
+// getChanges returns the changes recorded after the given ID, only for table
+// if it isn't empty.
+func getChanges(ctx context.Context, q queryer, table string, after int64, limit int) ([]Change, error) {
+	changes := []Change{}
+
+	exists, err := checkTableExists(ctx, q, changesTable)
+	if err != nil || !exists {
+		// Nothing has been tracked yet
+		return changes, err
+	}
+
+	query := fmt.Sprintf(`SELECT id, table_name, operation, row_key, row_data, changed_at FROM %s
+		WHERE id > ? AND (? = '' OR table_name = ? COLLATE NOCASE) ORDER BY id LIMIT ?`, quoteIdent(changesTable))
+	rows, err := q.QueryContext(ctx, query, after, table, table, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying changes: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c Change
+		var key, row string
+		if err := rows.Scan(&c.ID, &c.TableName, &c.Operation, &key, &row, &c.ChangedAt); err != nil {
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		c.Key = json.RawMessage(key)
+		c.Row = json.RawMessage(row)
+		changes = append(changes, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
+	return changes, nil
+}
+
+// visibleChanges returns the changes of the tables exposed to the request,
+// with their rows protected by the policies of their table. The changes of
+// scoped tables are left out, since they hold the rows out of the scope too.
+func (a *Admin) visibleChanges(ctx context.Context, changes []Change) ([]Change, error) {
+	visible := []Change{}
+	for _, c := range changes {
+		if !a.tableAllowed(c.TableName) || a.scoped(c.TableName) {
+			continue
+		}
+		var row map[string]interface{}
+		d := json.NewDecoder(bytes.NewReader(c.Row))
+		d.UseNumber()
+		if err := d.Decode(&row); err != nil {
+			return nil, fmt.Errorf("error decoding change %d: %v", c.ID, err)
+		}
+		if row != nil {
+			rows := []map[string]interface{}{row}
+			a.protectRows(ctx, c.TableName, rows)
+			protected, err := json.Marshal(rows[0])
+			if err != nil {
+				return nil, err
+			}
+			c.Row = protected
+		}
+		visible = append(visible, c)
+	}
+	return visible, nil
+}
@@ -0,0 +1,251 @@
+package sqliteadmin_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChanges(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Unknown Table",
+			params:         map[string]interface{}{"tableName": "unknown"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "TABLE_NOT_FOUND",
+				"message":    "Bad request: " + sqliteadmin.ErrUnknownTable.Error(),
+			},
+		},
+		{
+			name:             "Success",
+			params:           map[string]interface{}{"tableName": "users"},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok"},
+		},
+	}, sqliteadmin.TrackChanges, t, ts.server)
+
+	_, err := ts.db.Exec(`
+    INSERT INTO users (name, email) VALUES ('Jack', 'jack@gmail.com');
+    UPDATE users SET email = 'alice@outlook.com' WHERE id = 1;
+    DELETE FROM users WHERE id = 2;
+  `)
+	assert.NoError(t, err)
+
+	getChanges := func(params map[string]interface{}) map[string]interface{} {
+		res, err := http.DefaultClient.Do(makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{
+			Command: sqliteadmin.GetChangesSince,
+			Params:  params,
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		return readBody(t, res.Body)
+	}
+
+	result := getChanges(map[string]interface{}{"limit": 2})
+	changes := result["changes"].([]interface{})
+	assert.Len(t, changes, 2)
+	insert := changes[0].(map[string]interface{})
+	assert.Equal(t, "users", insert["tableName"])
+	assert.Equal(t, "insert", insert["operation"])
+	assert.Equal(t, map[string]interface{}{"id": float64(10)}, insert["key"])
+	assert.Equal(t, map[string]interface{}{"id": float64(10), "name": "Jack", "email": "jack@gmail.com"}, insert["row"])
+	update := changes[1].(map[string]interface{})
+	assert.Equal(t, "update", update["operation"])
+	assert.Equal(t, "alice@outlook.com", update["row"].(map[string]interface{})["email"])
+
+	result = getChanges(map[string]interface{}{"tableName": "users", "checkpoint": result["checkpoint"]})
+	changes = result["changes"].([]interface{})
+	assert.Len(t, changes, 1)
+	deleted := changes[0].(map[string]interface{})
+	assert.Equal(t, "delete", deleted["operation"])
+	assert.Equal(t, "Bob", deleted["row"].(map[string]interface{})["name"])
+
+	checkpoint := result["checkpoint"]
+	result = getChanges(map[string]interface{}{"checkpoint": checkpoint})
+	assert.Empty(t, result["changes"])
+	assert.Equal(t, checkpoint, result["checkpoint"])
+
+	runTestCases([]TestCase{
+		{
+			name:             "Success: Stop Tracking",
+			params:           map[string]interface{}{"tableName": "users", "enabled": false},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok"},
+		},
+	}, sqliteadmin.TrackChanges, t, ts.server)
+
+	_, err = ts.db.Exec("DELETE FROM users WHERE id = 3")
+	assert.NoError(t, err)
+	result = getChanges(map[string]interface{}{"checkpoint": checkpoint})
+	assert.Empty(t, result["changes"])
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Invalid Checkpoint",
+			params:         map[string]interface{}{"checkpoint": "invalid"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid checkpoint",
+			},
+		},
+	}, sqliteadmin.GetChangesSince, t, ts.server)
+}
+
+func TestGetChanges(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	getChanges := func(params map[string]interface{}) map[string]interface{} {
+		params["tableName"] = "users"
+		res, err := http.DefaultClient.Do(makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{
+			Command: sqliteadmin.GetChanges,
+			Params:  params,
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		return readBody(t, res.Body)
+	}
+	operations := func(result map[string]interface{}) []string {
+		var ops []string
+		for _, c := range result["changes"].([]interface{}) {
+			change := c.(map[string]interface{})
+			key, _ := json.Marshal(change["key"])
+			ops = append(ops, fmt.Sprintf("%s %s", change["operation"], key))
+		}
+		return ops
+	}
+
+	t.Run("Snapshot", func(t *testing.T) {
+		result := getChanges(map[string]interface{}{})
+		assert.Empty(t, result["changes"])
+		cursor := result["cursor"].(string)
+		assert.True(t, strings.HasPrefix(cursor, "s:"))
+
+		_, err := ts.db.Exec(`
+      INSERT INTO users (name, email) VALUES ('Jack', 'jack@gmail.com');
+      UPDATE users SET email = 'alice@outlook.com' WHERE id = 1;
+      DELETE FROM users WHERE id = 2;
+    `)
+		assert.NoError(t, err)
+
+		result = getChanges(map[string]interface{}{"cursor": cursor})
+		assert.Equal(t, []string{`update {"id":1}`, `insert {"id":10}`, `delete {"id":2}`}, operations(result))
+		update := result["changes"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, map[string]interface{}{"id": float64(1), "name": "Alice", "email": "alice@outlook.com"}, update["row"])
+
+		result = getChanges(map[string]interface{}{"cursor": result["cursor"]})
+		assert.Empty(t, result["changes"])
+
+		// Previous cursors remain valid
+		result = getChanges(map[string]interface{}{"cursor": cursor})
+		assert.Len(t, result["changes"], 3)
+	})
+
+	t.Run("Tracked", func(t *testing.T) {
+		runTestCases([]TestCase{
+			{
+				name:             "Track",
+				params:           map[string]interface{}{"tableName": "users"},
+				expectedStatus:   http.StatusOK,
+				expectedResponse: map[string]interface{}{"status": "ok"},
+			},
+		}, sqliteadmin.TrackChanges, t, ts.server)
+
+		_, err := ts.db.Exec("UPDATE users SET email = NULL WHERE id = 3")
+		assert.NoError(t, err)
+
+		result := getChanges(map[string]interface{}{})
+		assert.Equal(t, []string{`update {"id":3}`}, operations(result))
+		cursor := result["cursor"].(string)
+		assert.True(t, strings.HasPrefix(cursor, "t:"))
+
+		result = getChanges(map[string]interface{}{"cursor": cursor})
+		assert.Empty(t, result["changes"])
+	})
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Unknown Cursor",
+			params:         map[string]interface{}{"tableName": "users", "cursor": "s:unknown"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid checkpoint",
+			},
+		},
+		{
+			name:           "Failure: Missing Table Name",
+			params:         map[string]interface{}{},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: missing table name",
+			},
+		},
+	}, sqliteadmin.GetChanges, t, ts.server)
+}
+
+func TestChangesVisibility(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.MaskedColumns = []string{"users.email"}
+		c.ExcludeTables = []string{"secret"}
+		c.RowScopes = map[string]sqliteadmin.Condition{
+			"posts": {Cases: []sqliteadmin.Case{sqliteadmin.Filter{Column: "id", Operator: sqliteadmin.OperatorEquals, Value: "1"}}},
+		}
+	})
+	defer close()
+
+	runTestCases([]TestCase{
+		{
+			name:             "Track",
+			params:           map[string]interface{}{"tableName": "users"},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok"},
+		},
+	}, sqliteadmin.TrackChanges, t, ts.server)
+
+	// The changes of the hidden and scoped tables were tracked before they
+	// were hidden
+	_, err := ts.db.Exec(`
+		INSERT INTO _sqliteadmin_changes (table_name, operation, row_key, row_data) VALUES
+			('secret', 'insert', '{"id":1}', '{"id":1,"value":"s3cret"}'),
+			('posts', 'insert', '{"id":2}', '{"id":2,"title":"Other tenant"}');
+		UPDATE users SET name = 'Alicia' WHERE id = 1;
+	`)
+	assert.NoError(t, err)
+
+	getChanges := func(params map[string]interface{}) map[string]interface{} {
+		res, err := http.DefaultClient.Do(makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{
+			Command: sqliteadmin.GetChangesSince,
+			Params:  params,
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		return readBody(t, res.Body)
+	}
+
+	for _, params := range []map[string]interface{}{{}, {"tableName": "USERS"}} {
+		result := getChanges(params)
+		assert.Equal(t, "3", result["checkpoint"], params)
+		changes := result["changes"].([]interface{})
+		if assert.Len(t, changes, 1, params) {
+			change := changes[0].(map[string]interface{})
+			assert.Equal(t, "users", change["tableName"])
+			assert.Equal(t, map[string]interface{}{"id": float64(1), "name": "Alicia", "email": sqliteadmin.RedactedValue}, change["row"])
+		}
+	}
+}
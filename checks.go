@@ -0,0 +1,158 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// addCheckConstraint adds a named CHECK constraint to a table. Since SQLite
+// can't add constraints to an existing table, the table is rebuilt, which
+// fails if existing rows violate the constraint.
+func (a *Admin) addCheckConstraint(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, table, name, ok := a.getCheckConstraintParams(ctx, w, params)
+	if !ok {
+		return
+	}
+
+	expression, ok := params["expression"].(string)
+	if !ok || !isSQLExpression(expression) {
+		writeError(w, apiErrBadRequest(ErrInvalidExpression.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: AddCheckConstraint, table=%s, name=%s, expression=%s", table, name, expression))
+
+	err := rebuildTable(ctx, db, table, func(createSQL string) (string, error) {
+		if findCheckConstraint(createSQL, name) != nil {
+			return "", ErrConstraintExists
+		}
+		end := tableBodyEnd(createSQL)
+		if end < 0 {
+			return "", fmt.Errorf("invalid table definition")
+		}
+		return fmt.Sprintf("%s, CONSTRAINT %s CHECK (%s)%s", createSQL[:end], quoteIdent(name), expression, createSQL[end:]), nil
+	})
+	if err != nil {
+		a.writeRebuildError(w, err)
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: added check constraint %s to %s", name, table))
+
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// dropCheckConstraint removes a named CHECK constraint from a table by
+// rebuilding it.
+func (a *Admin) dropCheckConstraint(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, table, name, ok := a.getCheckConstraintParams(ctx, w, params)
+	if !ok {
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: DropCheckConstraint, table=%s, name=%s", table, name))
+
+	err := rebuildTable(ctx, db, table, func(createSQL string) (string, error) {
+		loc := findCheckConstraint(createSQL, name)
+		if loc == nil {
+			return "", ErrUnknownConstraint
+		}
+		return createSQL[:loc[0]] + createSQL[loc[1]:], nil
+	})
+	if err != nil {
+		a.writeRebuildError(w, err)
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: dropped check constraint %s from %s", name, table))
+
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+func (a *Admin) writeRebuildError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrConstraintExists), errors.Is(err, ErrUnknownConstraint):
+		writeError(w, apiErrBadRequest(err.Error()))
+	case strings.Contains(err.Error(), "CHECK constraint failed"):
+		writeError(w, apiErrBadRequest(ErrCheckConstraintViolated.Error()))
+	default:
+		a.logger.Error(fmt.Sprintf("Error rebuilding table: %v", err))
+		writeError(w, a.apiErr(err))
+	}
+}
+
+func (a *Admin) getCheckConstraintParams(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) (*sql.DB, string, string, bool) {
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return nil, "", "", false
+	}
+
+	table, ok := params["tableName"].(string)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrMissingTableName.Error()))
+		return nil, "", "", false
+	}
+
+	name, ok := params["name"].(string)
+	if !ok || name == "" {
+		writeError(w, apiErrBadRequest(ErrMissingConstraintName.Error()))
+		return nil, "", "", false
+	}
+
+	exists, err := checkTableExists(ctx, db, table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error checking table existence: %v", err))
+		writeError(w, a.apiErr(err))
+		return nil, "", "", false
+	}
+	if !exists {
+		writeError(w, apiErrTableNotFound())
+		return nil, "", "", false
+	}
+	return db, table, name, true
+}
+
+// tableBodyEnd returns the position of the parenthesis closing the column
+// definitions of a CREATE TABLE statement, which may be followed by table
+// options such as WITHOUT ROWID.
+func tableBodyEnd(createSQL string) int {
+	sc := &sqlScanner{s: createSQL}
+	end := -1
+	for c := sc.next(); c != 0; c = sc.next() {
+		if c == ')' && sc.depth == 0 {
+			end = sc.i - 1
+		}
+	}
+	return end
+}
+
+// findCheckConstraint returns the start and end of the named CHECK constraint
+// in a CREATE TABLE statement, including the comma separating it from the
+// previous definition, or nil if there is no such constraint.
+func findCheckConstraint(createSQL, name string) []int {
+	names := []string{
+		regexp.QuoteMeta(name),
+		regexp.QuoteMeta(quoteIdent(name)),
+		regexp.QuoteMeta("`" + name + "`"),
+		regexp.QuoteMeta("[" + name + "]"),
+		regexp.QuoteMeta(quoteLiteral(name)),
+	}
+	re := regexp.MustCompile(`(?i)(\s*,)?\s*CONSTRAINT\s+(` + strings.Join(names, "|") + `)\s+CHECK\s*\(`)
+	loc := re.FindStringIndex(createSQL)
+	if loc == nil {
+		return nil
+	}
+
+	// Find the parenthesis closing the expression
+	sc := &sqlScanner{s: createSQL, i: loc[1], depth: 1}
+	for c := sc.next(); c != 0; c = sc.next() {
+		if c == ')' && sc.depth == 0 {
+			return []int{loc[0], sc.i}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package sqliteadmin_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckConstraints(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	_, err := ts.db.Exec(`
+    CREATE INDEX users_email ON users (email);
+    CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users (id));
+    INSERT INTO posts (user_id) VALUES (1);
+    CREATE VIEW user_posts AS SELECT users.name, posts.id FROM users JOIN posts ON posts.user_id = users.id;
+    CREATE TRIGGER users_lowercase AFTER INSERT ON users BEGIN
+      UPDATE users SET email = lower(NEW.email) WHERE id = NEW.id;
+    END;
+  `)
+	assert.NoError(t, err)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Invalid Expression",
+			params:         map[string]interface{}{"tableName": "users", "name": "bad", "expression": "1); DROP TABLE posts; --"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid expression",
+			},
+		},
+		{
+			name:           "Failure: Existing Rows Violate Constraint",
+			params:         map[string]interface{}{"tableName": "users", "name": "email_required", "expression": "email IS NOT NULL"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: existing rows violate the check constraint",
+			},
+		},
+		{
+			name:             "Success",
+			params:           map[string]interface{}{"tableName": "users", "name": "name_not_empty", "expression": "length(name) > 0"},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok"},
+		},
+		{
+			name:           "Failure: Constraint Exists",
+			params:         map[string]interface{}{"tableName": "users", "name": "name_not_empty", "expression": "name != 'x'"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: constraint already exists",
+			},
+		},
+	}, sqliteadmin.AddCheckConstraint, t, ts.server)
+
+	_, err = ts.db.Exec("INSERT INTO users (name) VALUES ('')")
+	assert.ErrorContains(t, err, "CHECK constraint failed")
+
+	// The rows, indexes, triggers and views survive the rebuild
+	var count int
+	assert.NoError(t, ts.db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count))
+	assert.Equal(t, 9, count)
+	assert.NoError(t, ts.db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name IN ('users_email', 'users_lowercase', 'user_posts')").Scan(&count))
+	assert.Equal(t, 3, count)
+	assert.NoError(t, ts.db.QueryRow("SELECT COUNT(*) FROM user_posts").Scan(&count))
+	assert.Equal(t, 1, count)
+
+	runTestCases([]TestCase{
+		{
+			name:             "Success",
+			params:           map[string]interface{}{"tableName": "users", "name": "name_not_empty"},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok"},
+		},
+		{
+			name:           "Failure: Unknown Constraint",
+			params:         map[string]interface{}{"tableName": "users", "name": "name_not_empty"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: unknown constraint",
+			},
+		},
+	}, sqliteadmin.DropCheckConstraint, t, ts.server)
+
+	_, err = ts.db.Exec("INSERT INTO users (name, email) VALUES ('', 'EMPTY@GMAIL.COM')")
+	assert.NoError(t, err)
+	var email string
+	assert.NoError(t, ts.db.QueryRow("SELECT email FROM users WHERE name = ''").Scan(&email))
+	assert.Equal(t, "empty@gmail.com", email)
+}
@@ -0,0 +1,319 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	mathrand "math/rand"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joelseq/sqliteadmin-go/querybuilder"
+)
+
+// cloneSchema is the schema the copy made by CloneDatabase is attached as
+// while the rows are copied.
+const cloneSchema = "sqliteadmin_clone"
+
+// CloneDatabaseParams are the params of CloneDatabase.
+type CloneDatabaseParams struct {
+	Database string `json:"database,omitempty" mapstructure:"database"`
+	// Tables are the tables to copy, every table when empty.
+	Tables []string `json:"tables,omitempty" mapstructure:"tables"`
+	// Sample copies a random sample of the given number of rows of the
+	// tables, by table. The other tables are copied whole.
+	Sample map[string]int `json:"sample,omitempty" mapstructure:"sample"`
+	// Anonymize are the strategies of the columns to anonymize in the copy,
+	// by table and column, as for AnonymizeTable.
+	Anonymize map[string]map[string]string `json:"anonymize,omitempty" mapstructure:"anonymize"`
+	// Seed makes the fake values reproducible, they are random when nil.
+	Seed *int64 `json:"seed,omitempty" mapstructure:"seed"`
+	// Passphrase encrypts the copy in a zip archive with AES-256, so that it
+	// can be sent outside the system. The passphrase is only used by the job
+	// and isn't kept nor logged.
+	Passphrase string `json:"passphrase,omitempty" mapstructure:"passphrase"`
+}
+
+func (p *CloneDatabaseParams) validate() error {
+	for _, n := range p.Sample {
+		if n <= 0 {
+			return ErrInvalidInput
+		}
+	}
+	for table, columns := range p.Anonymize {
+		strategies := AnonymizeTableParams{TableName: table, Columns: columns}
+		if err := strategies.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cloneDatabase starts a job copying tables of a database to a new file, e.g.
+// to make a staging dataset out of production. The copy holds the tables with
+// their indexes, but not the triggers and views, nor the virtual tables. The
+// foreign keys of sampled tables may reference rows that weren't sampled.
+// Columns whose values are hidden by their policy must be anonymized, so that
+// the copy doesn't reveal them.
+func (a *Admin) cloneDatabase(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	var p CloneDatabaseParams
+	if err := a.decodeParams(params, &p); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: CloneDatabase, tables=%v, sample=%v, encrypted=%t", p.Tables, p.Sample, p.Passphrase != ""))
+
+	q := a.cached(db)
+	tables, err := a.cloneTables(ctx, q, p)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error listing tables: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	for _, table := range tables {
+		// Rows out of the scope would be copied along with the others
+		if a.scoped(table) {
+			writeError(w, apiErrForbidden(ErrOutOfScope.Error()))
+			return
+		}
+		columns, err := getColumnNames(ctx, q, table)
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Error getting columns: %v", err))
+			writeError(w, a.apiErr(err))
+			return
+		}
+		if err := checkColumns(sortedColumns(p.Anonymize[table]), columns); err != nil {
+			writeError(w, a.apiErr(err))
+			return
+		}
+		for _, c := range columns {
+			if policy, ok := a.columnPolicy(table, c); ok && policy.hides() && p.Anonymize[table][c] == "" {
+				writeError(w, apiErrBadRequest(fmt.Sprintf("%s: %s.%s", ErrCloneHiddenColumn, table, c)))
+				return
+			}
+		}
+	}
+
+	seed := time.Now().UnixNano()
+	if p.Seed != nil {
+		seed = *p.Seed
+	}
+	name := "clone.db"
+	if file, err := databaseFile(ctx, db); err == nil && file != "" {
+		name = strings.TrimSuffix(path.Base(file), path.Ext(file)) + "-clone.db"
+	}
+	job, err := a.startJob(CloneDatabase, func(ctx context.Context) (*Artifact, error) {
+		f, err := os.CreateTemp("", "sqliteadmin-clone-*.db")
+		if err != nil {
+			return nil, err
+		}
+		f.Close()
+		if err := a.clone(ctx, db, f.Name(), tables, p, mathrand.New(mathrand.NewSource(seed))); err != nil {
+			os.Remove(f.Name())
+			return nil, err
+		}
+		file, artifactName := f.Name(), name
+		if p.Passphrase != "" {
+			// Only the archive is kept, the copy must not linger in plaintext
+			file, artifactName = f.Name()+".zip", strings.TrimSuffix(name, ".db")+".zip"
+			err := writeEncryptedZip(file, f.Name(), name, p.Passphrase)
+			os.Remove(f.Name())
+			if err != nil {
+				os.Remove(file)
+				return nil, err
+			}
+		}
+		artifact, err := a.PublishArtifact(file, artifactName)
+		if err != nil {
+			os.Remove(file)
+			return nil, err
+		}
+		return &artifact, nil
+	})
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error starting job: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(job)
+}
+
+// cloneTables returns the tables to copy, in order, which are the tables
+// named by the params or else every table exposed.
+func (a *Admin) cloneTables(ctx context.Context, q queryer, p CloneDatabaseParams) ([]string, error) {
+	names, err := getTableNames(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	var tables []string
+	for _, name := range names {
+		if isInternalTable(name) || !a.tableAllowed(name) {
+			continue
+		}
+		if len(p.Tables) == 0 || contains(p.Tables, name) {
+			tables = append(tables, name)
+		}
+	}
+	// The tables sampled or anonymized must be copied as well
+	named := append([]string{}, p.Tables...)
+	for table := range p.Sample {
+		named = append(named, table)
+	}
+	for table := range p.Anonymize {
+		named = append(named, table)
+	}
+	for _, table := range named {
+		if !contains(tables, table) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownTable, table)
+		}
+	}
+	sort.Strings(tables)
+	return tables, nil
+}
+
+// clone copies the tables of db to a new database file at path, then
+// anonymizes the copy.
+func (a *Admin) clone(ctx context.Context, db *sql.DB, path string, tables []string, p CloneDatabaseParams, r *mathrand.Rand) error {
+	if err := a.copyTables(ctx, db, path, tables, p.Sample); err != nil {
+		return err
+	}
+	if len(p.Anonymize) == 0 {
+		return nil
+	}
+
+	// The copy is opened on its own, as the queries of AnonymizeTable are
+	// about the main schema
+	clone := sql.OpenDB(fileConnector{driver: db.Driver(), path: path})
+	defer clone.Close()
+	tx, err := clone.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+	anonymized := make([]string, 0, len(p.Anonymize))
+	for table := range p.Anonymize {
+		anonymized = append(anonymized, table)
+	}
+	sort.Strings(anonymized)
+	for _, table := range anonymized {
+		if _, err := a.anonymizeRows(ctx, tx, table, p.Anonymize[table], r); err != nil {
+			return fmt.Errorf("error anonymizing %s: %w", table, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// indexPrefix matches the start of a CREATE INDEX statement, up to the name of
+// the index.
+var indexPrefix = regexp.MustCompile(`(?is)^\s*CREATE\s+(UNIQUE\s+)?INDEX\s+(IF\s+NOT\s+EXISTS\s+)?`)
+
+// copyTables copies the tables of db with their indexes to the database file
+// at path, attached to a dedicated connection. The tables are created from
+// their CREATE TABLE statement in the new schema, the same way rebuildTable
+// creates the new table.
+func (a *Admin) copyTables(ctx context.Context, db *sql.DB, path string, tables []string, sample map[string]int) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting connection: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("ATTACH DATABASE ? AS %s", quoteIdent(cloneSchema)), path); err != nil {
+		return fmt.Errorf("error attaching database: %v", err)
+	}
+	defer a.detach(conn, cloneSchema)
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var copied []string
+	for _, table := range tables {
+		var createSQL string
+		err := tx.QueryRowContext(ctx, "SELECT sql FROM main.sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&createSQL)
+		if err != nil {
+			return fmt.Errorf("error reading table definition: %v", err)
+		}
+		start := strings.Index(createSQL, "(")
+		if !strings.HasPrefix(strings.ToUpper(createSQL), "CREATE TABLE") || start < 0 {
+			continue
+		}
+		createSQL = fmt.Sprintf("CREATE TABLE %s.%s %s", quoteIdent(cloneSchema), quoteIdent(table), createSQL[start:])
+		if _, err := tx.ExecContext(ctx, createSQL); err != nil {
+			return fmt.Errorf("error creating table %s: %v", table, err)
+		}
+
+		columns, err := getColumnNames(ctx, tx, table)
+		if err != nil {
+			return err
+		}
+		generated, err := getGeneratedColumns(ctx, tx, table)
+		if err != nil {
+			return err
+		}
+		var stored []string
+		for _, c := range columns {
+			if _, ok := generated[c]; !ok {
+				stored = append(stored, c)
+			}
+		}
+		list := strings.Join(querybuilder.Idents(stored), ", ")
+		insert := fmt.Sprintf("INSERT INTO %s.%s (%s) SELECT %s FROM main.%s", quoteIdent(cloneSchema), quoteIdent(table), list, list, quoteIdent(table))
+		var args []interface{}
+		if n, ok := sample[table]; ok {
+			insert += " ORDER BY random() LIMIT ?"
+			args = append(args, n)
+		}
+		if _, err := tx.ExecContext(ctx, insert, args...); err != nil {
+			return fmt.Errorf("error copying rows of %s: %v", table, err)
+		}
+		copied = append(copied, table)
+	}
+
+	indexes, err := queryRows(ctx, tx, "SELECT tbl_name, sql FROM main.sqlite_master WHERE type = 'index' AND sql IS NOT NULL")
+	if err != nil {
+		return err
+	}
+	for _, index := range indexes {
+		table, _ := index["tbl_name"].(string)
+		createSQL, _ := index["sql"].(string)
+		if !contains(copied, table) || !indexPrefix.MatchString(createSQL) {
+			continue
+		}
+		createSQL = indexPrefix.ReplaceAllString(createSQL, "CREATE ${1}INDEX ${2}"+quoteIdent(cloneSchema)+".")
+		if _, err := tx.ExecContext(ctx, createSQL); err != nil {
+			return fmt.Errorf("error creating index: %v", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// fileConnector opens the database file at path with the driver of another
+// database.
+type fileConnector struct {
+	driver driver.Driver
+	path   string
+}
+
+func (c fileConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.path)
+}
+
+func (c fileConnector) Driver() driver.Driver {
+	return c.driver
+}
@@ -0,0 +1,213 @@
+package sqliteadmin_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+func TestCloneDatabase(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.MaskedColumns = []string{"users.email"}
+	})
+	defer close()
+
+	_, err := ts.db.Exec(`
+		CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users(id), title TEXT);
+		CREATE INDEX posts_user_id ON posts (user_id);
+		CREATE TABLE logs (message TEXT);
+		INSERT INTO posts (user_id, title) VALUES (1, 'First'), (2, 'Second'), (3, 'Third'), (1, 'Fourth');
+		INSERT INTO logs VALUES ('started');
+	`)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	job, err := ts.admin.CloneDatabase(ctx, sqliteadmin.CloneDatabaseParams{
+		Tables: []string{"users", "posts"},
+		Sample: map[string]int{"posts": 2},
+		Anonymize: map[string]map[string]string{
+			"users": {"email": sqliteadmin.AnonymizeHash, "name": sqliteadmin.AnonymizeFake},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, sqliteadmin.CloneDatabase, job.Command)
+	assert.Equal(t, sqliteadmin.JobRunning, job.Status)
+
+	for deadline := time.Now().Add(5 * time.Second); job.Status == sqliteadmin.JobRunning && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		job, err = ts.admin.GetJob(ctx, job.ID)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, sqliteadmin.JobDone, job.Status)
+	assert.Nil(t, job.Error)
+	if !assert.NotNil(t, job.Artifact) {
+		return
+	}
+	assert.Equal(t, "clone.db", job.Artifact.Name)
+
+	rec := httptest.NewRecorder()
+	ts.admin.HandleArtifact(rec, httptest.NewRequest(http.MethodGet, "/artifacts/"+job.Artifact.URL, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	path := filepath.Join(t.TempDir(), "clone.db")
+	assert.NoError(t, os.WriteFile(path, rec.Body.Bytes(), 0o600))
+	clone, err := sql.Open("sqlite", path)
+	assert.NoError(t, err)
+	defer clone.Close()
+
+	users, err := getTableValues(clone, "users")
+	assert.NoError(t, err)
+	assert.Len(t, users, 9)
+	assert.NotEqual(t, "Alice", users[0]["name"])
+	assert.Contains(t, users[0]["email"], "anon_")
+	posts, err := getTableValues(clone, "posts")
+	assert.NoError(t, err)
+	assert.Len(t, posts, 2)
+
+	var tables []string
+	rows, err := clone.Query("SELECT name FROM sqlite_master WHERE type IN ('table', 'index') ORDER BY name")
+	assert.NoError(t, err)
+	for rows.Next() {
+		var name string
+		assert.NoError(t, rows.Scan(&name))
+		tables = append(tables, name)
+	}
+	assert.Equal(t, []string{"posts", "posts_user_id", "users"}, tables)
+
+	// The source is left as is
+	users, err = getTableValues(ts.db, "users")
+	assert.NoError(t, err)
+	assert.Equal(t, "Alice", users[0]["name"])
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Masked column not anonymized",
+			params:         map[string]interface{}{"tables": []interface{}{"users"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: redacted, masked, encrypted or anonymized columns must be anonymized in the clone: users.email",
+			},
+		},
+		{
+			name:           "Failure: Sampled table not copied",
+			params:         map[string]interface{}{"tables": []interface{}{"logs"}, "sample": map[string]interface{}{"posts": 1}},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "TABLE_NOT_FOUND",
+				"message":    "Bad request: unknown table",
+				"detail":     "unknown table: posts",
+			},
+		},
+		{
+			name:           "Failure: Invalid sample",
+			params:         map[string]interface{}{"tables": []interface{}{"logs"}, "sample": map[string]interface{}{"logs": 0}},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid input",
+			},
+		},
+	}, sqliteadmin.CloneDatabase, t, ts.server)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Unknown job",
+			params:         map[string]interface{}{"jobId": "unknown"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: unknown or expired job id",
+			},
+		},
+	}, sqliteadmin.GetJob, t, ts.server)
+}
+
+func TestCloneDatabaseEncrypted(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+	ctx := context.Background()
+
+	job, err := ts.admin.CloneDatabase(ctx, sqliteadmin.CloneDatabaseParams{Tables: []string{"users"}, Passphrase: "correct horse"})
+	assert.NoError(t, err)
+	for deadline := time.Now().Add(5 * time.Second); job.Status == sqliteadmin.JobRunning && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		job, err = ts.admin.GetJob(ctx, job.ID)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, sqliteadmin.JobDone, job.Status)
+	if !assert.NotNil(t, job.Artifact) {
+		return
+	}
+	assert.Equal(t, "clone.zip", job.Artifact.Name)
+
+	rec := httptest.NewRecorder()
+	ts.admin.HandleArtifact(rec, httptest.NewRequest(http.MethodGet, "/artifacts/"+job.Artifact.URL, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	archive, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	assert.NoError(t, err)
+	if !assert.Len(t, archive.File, 1) {
+		return
+	}
+	entry := archive.File[0]
+	assert.Equal(t, "clone.db", entry.Name)
+	assert.Equal(t, uint16(99), entry.Method)
+	assert.Equal(t, []byte{0x01, 0x99, 7, 0, 2, 0, 'A', 'E', 3, 8, 0}, entry.Extra[:11])
+
+	// The entry is the salt, the passphrase verifier, the data and its MAC
+	r, err := entry.OpenRaw()
+	assert.NoError(t, err)
+	raw, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	salt, verifier, data, mac := raw[:16], raw[16:18], raw[18:len(raw)-10], raw[len(raw)-10:]
+	wrong := pbkdf2.Key([]byte("wrong horse"), salt, 1000, 66, sha1.New)
+	assert.NotEqual(t, verifier, wrong[64:])
+	keys := pbkdf2.Key([]byte("correct horse"), salt, 1000, 66, sha1.New)
+	assert.Equal(t, verifier, keys[64:])
+	h := hmac.New(sha1.New, keys[32:64])
+	h.Write(data)
+	assert.Equal(t, mac, h.Sum(nil)[:10])
+
+	block, err := aes.NewCipher(keys[:32])
+	assert.NoError(t, err)
+	var counter, stream [16]byte
+	for i := range data {
+		if i%16 == 0 {
+			binary.LittleEndian.PutUint64(counter[:], uint64(i/16+1))
+			block.Encrypt(stream[:], counter[:])
+		}
+		data[i] ^= stream[i%16]
+	}
+	plain, err := io.ReadAll(flate.NewReader(bytes.NewReader(data)))
+	assert.NoError(t, err)
+	assert.Equal(t, entry.UncompressedSize64, uint64(len(plain)))
+
+	path := filepath.Join(t.TempDir(), "clone.db")
+	assert.NoError(t, os.WriteFile(path, plain, 0o600))
+	clone, err := sql.Open("sqlite", path)
+	assert.NoError(t, err)
+	defer clone.Close()
+	users, err := getTableValues(clone, "users")
+	assert.NoError(t, err)
+	assert.Len(t, users, 9)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/spf13/cobra"
+)
+
+var (
+	anonymizeColumns []string
+	anonymizeSeed    int64
+)
+
+func init() {
+	anonymizeCmd.Flags().StringSliceVar(&anonymizeColumns, "column", nil, "Columns to overwrite, as TABLE.COLUMN=STRATEGY where STRATEGY is fake, hash or null")
+	anonymizeCmd.Flags().Int64Var(&anonymizeSeed, "seed", 0, "Seed of the fake values, to reproduce them (random by default)")
+	anonymizeCmd.MarkFlagRequired("column")
+	anonymizeCmd.RegisterFlagCompletionFunc("column", completeColumns("."))
+	rootCmd.AddCommand(anonymizeCmd)
+}
+
+var anonymizeCmd = &cobra.Command{
+	Use:               "anonymize DB_PATH",
+	Short:             "Overwrite sensitive columns of a database with fake values or hashes",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDBPath,
+	Run: func(cmd *cobra.Command, args []string) {
+		strategies := map[string]map[string]string{}
+		for _, c := range anonymizeColumns {
+			column, strategy, ok := strings.Cut(c, "=")
+			table, column, dot := strings.Cut(column, ".")
+			if !ok || !dot {
+				log.Fatalf("Invalid column %q, expected TABLE.COLUMN=STRATEGY", c)
+			}
+			if strategies[table] == nil {
+				strategies[table] = map[string]string{}
+			}
+			strategies[table][column] = strategy
+		}
+
+		db, err := openDB(args[0])
+		if err != nil {
+			log.Fatalf("Error opening database: %v", err)
+		}
+		defer db.Close()
+
+		// The same admin anonymizes every table so that the hashes of equal
+		// values match across tables
+		admin := sqliteadmin.New(sqliteadmin.Config{DB: db, Development: true})
+		tables := make([]string, 0, len(strategies))
+		for table := range strategies {
+			tables = append(tables, table)
+		}
+		sort.Strings(tables)
+		for _, table := range tables {
+			params := sqliteadmin.AnonymizeTableParams{TableName: table, Columns: strategies[table]}
+			if cmd.Flags().Changed("seed") {
+				params.Seed = &anonymizeSeed
+			}
+			n, err := admin.AnonymizeTable(context.Background(), params)
+			if err != nil {
+				log.Fatalf("Error anonymizing %s: %v", table, err)
+			}
+			log.Printf("Anonymized %d row(s) of %s", n, table)
+		}
+	},
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// dbExtensions are the extensions of the files suggested for DB_PATH.
+var dbExtensions = []string{"db", "sqlite", "sqlite3"}
+
+func completeDBPath(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return dbExtensions, cobra.ShellCompDirectiveFilterFileExt
+}
+
+// completeColumns suggests the columns of the database given as the first
+// argument, as TABLE<sep>COLUMN.
+func completeColumns(sep string) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		// Completing must not create the database if it doesn't exist
+		db, err := openReadOnlyDB(args[0])
+		if db == nil && err == nil {
+			db, err = openDB(args[0])
+		}
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		defer db.Close()
+
+		columns, err := listColumns(cmd.Context(), db, sep)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		// Comma separated flags are completed one value at a time
+		prefix := ""
+		if i := strings.LastIndex(toComplete, ","); i >= 0 {
+			prefix = toComplete[:i+1]
+		}
+		for i := range columns {
+			columns[i] = prefix + columns[i]
+		}
+		return columns, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func listColumns(ctx context.Context, db *sql.DB, sep string) ([]string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	rows, err := db.QueryContext(ctx, `SELECT m.name, p.name FROM sqlite_master m
+		JOIN pragma_table_info(m.name) p
+		WHERE m.type = 'table'
+		ORDER BY m.name, p.cid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(table, "sqlite_") || strings.HasPrefix(table, "_sqliteadmin_") {
+			continue
+		}
+		columns = append(columns, table+sep+column)
+	}
+	return columns, rows.Err()
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"database/sql"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/tursodatabase/libsql-client-go/libsql"
+	_ "modernc.org/sqlite"
+)
+
+var authToken string
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&authToken, "auth-token", os.Getenv("SQLITEADMIN_AUTH_TOKEN"), "Auth token used when connecting to a libSQL/Turso database")
+}
+
+// remoteSchemes are the URL schemes that are opened with the libSQL client
+// instead of being treated as a path to a local SQLite file.
+var remoteSchemes = []string{"libsql://", "https://", "http://", "wss://", "ws://"}
+
+func isRemoteDB(dbPath string) bool {
+	for _, scheme := range remoteSchemes {
+		if strings.HasPrefix(dbPath, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// openDB opens either a local SQLite file or a remote libSQL/Turso database
+// depending on the form of dbPath.
+func openDB(dbPath string) (*sql.DB, error) {
+	if !isRemoteDB(dbPath) {
+		return sql.Open("sqlite", dbPath)
+	}
+
+	var opts []libsql.Option
+	if authToken != "" {
+		opts = append(opts, libsql.WithAuthToken(authToken))
+	}
+	connector, err := libsql.NewConnector(dbPath, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(connector), nil
+}
+
+// openReadOnlyDB opens a read-only connection pool to a local SQLite file. It
+// returns nil for remote databases, which don't support it.
+func openReadOnlyDB(dbPath string) (*sql.DB, error) {
+	if isRemoteDB(dbPath) {
+		return nil, nil
+	}
+
+	dsn := "file:" + dbPath + "?" + url.Values{
+		"mode":    {"ro"},
+		"_pragma": {"query_only(1)"},
+	}.Encode()
+	return sql.Open("sqlite", dsn)
+}
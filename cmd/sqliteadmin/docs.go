@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/spf13/cobra"
+)
+
+var (
+	docsOut        string
+	docsFormat     string
+	docsSampleRows int
+)
+
+func init() {
+	docsCmd.Flags().StringVar(&docsOut, "out", "./site", "Directory to write the documentation to")
+	docsCmd.Flags().StringVar(&docsFormat, "format", string(sqliteadmin.DocsFormatMarkdown), "Format of the pages: markdown or html")
+	docsCmd.Flags().IntVar(&docsSampleRows, "sample-rows", 5, "Number of sample rows shown for each table")
+	docsCmd.Flags().StringVar(&policyFile, "column-policies", "", "JSON file of column policies applied to the sample rows")
+	docsCmd.Flags().StringSliceVar(&masked, "mask", nil, "Columns to redact in the sample rows, as TABLE.COLUMN where TABLE may be *")
+	docsCmd.RegisterFlagCompletionFunc("mask", completeColumns("."))
+	rootCmd.AddCommand(docsCmd)
+}
+
+var docsCmd = &cobra.Command{
+	Use:               "docs DB_PATH",
+	Short:             "Generate documentation of the schema of a database",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDBPath,
+	Run: func(cmd *cobra.Command, args []string) {
+		db, err := openDB(args[0])
+		if err != nil {
+			log.Fatalf("Error opening database: %v", err)
+		}
+		defer db.Close()
+
+		var policies sqliteadmin.ColumnPolicies
+		if policyFile != "" {
+			policies, err = loadColumnPolicies(policyFile)
+			if err != nil {
+				log.Fatalf("Error loading column policies: %v", err)
+			}
+		}
+
+		admin := sqliteadmin.New(sqliteadmin.Config{
+			DB:             db,
+			ColumnPolicies: policies,
+			MaskedColumns:  masked,
+		})
+		opts := sqliteadmin.DocsOptions{
+			Format:     sqliteadmin.DocsFormat(docsFormat),
+			SampleRows: docsSampleRows,
+		}
+		if err := admin.GenerateDocs(context.Background(), docsOut, opts); err != nil {
+			log.Fatalf("Error generating docs: %v", err)
+		}
+		log.Printf("Documentation written to %s", docsOut)
+	},
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(extensionsCmd)
+}
+
+var extensionsCmd = &cobra.Command{
+	Use:               "extensions DB_PATH",
+	Short:             "List the SQLite extensions available on a database",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeDBPath,
+	Run: func(cmd *cobra.Command, args []string) {
+		db, err := openDB(args[0])
+		if err != nil {
+			log.Fatalf("Error opening database: %v", err)
+		}
+		defer db.Close()
+
+		admin := sqliteadmin.New(sqliteadmin.Config{DB: db})
+		extensions, err := admin.ListExtensions(context.Background(), sqliteadmin.ListExtensionsParams{})
+		if err != nil {
+			log.Fatalf("Error listing extensions: %v", err)
+		}
+		for _, e := range extensions {
+			fmt.Printf("%s\t%s\n", e.Name, strings.Join(append(e.Functions, e.Modules...), ", "))
+		}
+	},
+}
@@ -1,5 +1,9 @@
 package main
 
+// Embed the time zone database so that --time-zone and the timeZone param
+// work on systems without zoneinfo installed.
+import _ "time/tzdata"
+
 func main() {
 	Execute()
 }
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/joelseq/sqliteadmin-go/migrate"
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrationsDir  string
+	migrationSteps int
+)
+
+func init() {
+	migrateCmd.PersistentFlags().StringVar(&migrationsDir, "dir", "./migrations", "Directory of the migration files, named VERSION_NAME.up.sql and VERSION_NAME.down.sql")
+	migrateDownCmd.Flags().IntVar(&migrationSteps, "steps", 1, "Number of migrations to revert")
+	for _, cmd := range []*cobra.Command{migrateUpCmd, migrateDownCmd, migrateStatusCmd} {
+		cmd.Args = cobra.ExactArgs(1)
+		cmd.ValidArgsFunction = completeDBPath
+		migrateCmd.AddCommand(cmd)
+	}
+	rootCmd.AddCommand(migrateCmd)
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply or revert the schema migrations of a database",
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up DB_PATH",
+	Short: "Apply the pending migrations",
+	Run: func(cmd *cobra.Command, args []string) {
+		applied, err := getMigrator(args[0]).Up(context.Background())
+		for _, m := range applied {
+			fmt.Printf("Applied %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Error applying migrations: %v", err)
+		}
+	},
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down DB_PATH",
+	Short: "Revert the last applied migrations",
+	Run: func(cmd *cobra.Command, args []string) {
+		reverted, err := getMigrator(args[0]).Down(context.Background(), migrationSteps)
+		if err != nil {
+			log.Fatalf("Error reverting migrations: %v", err)
+		}
+		for _, m := range reverted {
+			fmt.Printf("Reverted %d_%s\n", m.Version, m.Name)
+		}
+	},
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status DB_PATH",
+	Short: "List the migrations and whether they are applied",
+	Run: func(cmd *cobra.Command, args []string) {
+		statuses, err := getMigrator(args[0]).Status(context.Background())
+		if err != nil {
+			log.Fatalf("Error getting migration status: %v", err)
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = "applied " + s.AppliedAt.Local().Format(time.DateTime)
+			}
+			name := s.Name
+			if name == "" {
+				name = "(missing file)"
+			}
+			fmt.Printf("%d\t%s\t%s\n", s.Version, name, applied)
+		}
+	},
+}
+
+func getMigrator(dbPath string) *migrate.Migrator {
+	db, err := openDB(dbPath)
+	if err != nil {
+		log.Fatalf("Error opening database: %v", err)
+	}
+	m, err := migrate.New(migrate.Config{DB: db, FS: os.DirFS(migrationsDir)})
+	if err != nil {
+		log.Fatalf("Error reading migrations: %v", err)
+	}
+	return m
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/spf13/cobra"
+)
+
+var (
+	seedRows int
+	seedSeed int64
+)
+
+func init() {
+	seedCmd.Flags().IntVar(&seedRows, "rows", 10, "Number of rows to generate")
+	seedCmd.Flags().Int64Var(&seedSeed, "seed", 0, "Seed of the generated values, to reproduce them (random by default)")
+	rootCmd.AddCommand(seedCmd)
+}
+
+var seedCmd = &cobra.Command{
+	Use:               "seed DB_PATH TABLE",
+	Short:             "Insert rows of generated data into a table",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeDBPath,
+	Run: func(cmd *cobra.Command, args []string) {
+		db, err := openDB(args[0])
+		if err != nil {
+			log.Fatalf("Error opening database: %v", err)
+		}
+		defer db.Close()
+
+		// Seeding is restricted to development mode, which the command is
+		admin := sqliteadmin.New(sqliteadmin.Config{DB: db, Development: true, MaxLimit: -1})
+		params := sqliteadmin.SeedTableParams{TableName: args[1], Rows: seedRows}
+		if cmd.Flags().Changed("seed") {
+			params.Seed = &seedSeed
+		}
+		n, err := admin.SeedTable(context.Background(), params)
+		if err != nil {
+			log.Fatalf("Error seeding table: %v", err)
+		}
+		log.Printf("Inserted %d row(s) into %s", n, args[1])
+	},
+}
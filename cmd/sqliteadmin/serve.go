@@ -3,12 +3,14 @@ package main
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -17,26 +19,132 @@ import (
 	"github.com/go-chi/cors"
 	"github.com/joelseq/sqliteadmin-go"
 	"github.com/spf13/cobra"
-	_ "modernc.org/sqlite"
 )
 
-var port uint
+var (
+	port        uint
+	watchDir    string
+	readPool    bool
+	timeZone    string
+	compress    bool
+	logRequests bool
+	timeout     time.Duration
+	slowQueries time.Duration
+	busyTimeout time.Duration
+	writeWait   time.Duration
+	showErrors  bool
+	hideTables  bool
+	include     []string
+	exclude     []string
+	ttlColumns  map[string]string
+	ttlInterval time.Duration
+	policyFile  string
+	usersFile   string
+	masked      []string
+	sessions    bool
+	tlsCert     string
+	tlsKey      string
+	autoTLS     []string
+	tlsCacheDir string
+	maxLimit    int
+	pageSize    int
+
+	oidcIssuer    string
+	oidcAudience  string
+	oidcUserClaim string
+	oidcRoleClaim string
+	oidcRoles     map[string]string
+
+	publicTables  []string
+	publicColumns []string
+	publicMaxRows int
+
+	development bool
+	fixturesDir string
+)
 
 func init() {
 	serveCmd.Flags().UintVarP(&port, "port", "p", 8080, "Port to run server on")
+	serveCmd.Flags().BoolVar(&readPool, "read-pool", true, "Use a separate read-only connection pool for commands that only read")
+	serveCmd.Flags().StringVar(&timeZone, "time-zone", "", "IANA time zone used to display timestamps (e.g. Europe/Paris)")
+	serveCmd.Flags().BoolVar(&compress, "compress", true, "Compress responses for clients that accept gzip or deflate")
+	serveCmd.Flags().IntVar(&pageSize, "page-size", sqliteadmin.DefaultLimit, "Number of rows returned when the UI doesn't ask for a limit")
+	serveCmd.Flags().IntVar(&maxLimit, "max-limit", sqliteadmin.DefaultMaxLimit, "Maximum number of rows returned by a request, larger limits being clamped (-1 for no maximum)")
+	serveCmd.Flags().BoolVar(&logRequests, "log-requests", false, "Log a structured summary of every command")
+	serveCmd.Flags().DurationVar(&timeout, "query-timeout", 0, "Cancel the queries of commands running longer than this (e.g. 30s)")
+	serveCmd.Flags().DurationVar(&busyTimeout, "busy-timeout", sqliteadmin.DefaultBusyTimeout, "How long edits wait for the application to release its write lock before failing")
+	serveCmd.Flags().DurationVar(&writeWait, "write-queue-timeout", sqliteadmin.DefaultWriteQueueTimeout, "How long edits wait for the edits of other requests before failing")
+	serveCmd.Flags().BoolVar(&showErrors, "show-error-details", false, "Include the messages of database errors, which can disclose the schema, in the error responses")
+	serveCmd.Flags().BoolVar(&hideTables, "hide-internal-tables", false, "Leave the sqlite_* and _sqliteadmin_* tables out of the table list")
+	serveCmd.Flags().StringSliceVar(&include, "include-tables", nil, "Tables to expose, as names or globs (e.g. users,orders_*); all tables when empty")
+	serveCmd.Flags().StringSliceVar(&exclude, "exclude-tables", nil, "Tables to hide, as names or globs, even if included")
+	serveCmd.Flags().DurationVar(&slowQueries, "slow-query-threshold", 0, "Log the commands taking longer than this along with their SQL (e.g. 500ms)")
+	serveCmd.Flags().StringToStringVar(&ttlColumns, "ttl", nil, "Delete expired rows, given as TABLE=COLUMN where COLUMN holds the expiration time")
+	serveCmd.Flags().DurationVar(&ttlInterval, "ttl-interval", sqliteadmin.DefaultTTLSweepInterval, "How often to delete expired rows")
+	serveCmd.Flags().StringVar(&policyFile, "column-policies", "", "JSON file of column policies (redaction, anonymization, normalization, allowed values) keyed by table and column")
+	serveCmd.Flags().StringSliceVar(&masked, "mask", nil, "Columns to redact and protect from updates, as TABLE.COLUMN where TABLE may be * (e.g. users.password_hash,*.ssn)")
+	serveCmd.Flags().StringVar(&usersFile, "users", "", "JSON file of the users allowed to sign in besides $SQLITEADMIN_USERNAME, as an array of {username, password, role} with the role admin, editor or viewer")
+	serveCmd.Flags().StringVar(&oidcIssuer, "oidc-issuer", "", "URL of an OpenID Connect provider whose bearer tokens authenticate users, requires --oidc-audience")
+	serveCmd.Flags().StringVar(&oidcAudience, "oidc-audience", "", "Audience the OIDC tokens must be issued for, usually the client ID of the UI")
+	serveCmd.Flags().StringVar(&oidcUserClaim, "oidc-user-claim", "sub", "Claim of the OIDC tokens used as the user name (e.g. email)")
+	serveCmd.Flags().StringVar(&oidcRoleClaim, "oidc-role-claim", "roles", "Claim of the OIDC tokens holding the roles or groups of the user")
+	serveCmd.Flags().StringToStringVar(&oidcRoles, "oidc-roles", nil, "Roles of the values of the role claim, as VALUE=ROLE with the role admin, editor or viewer (e.g. dba=admin,support=viewer)")
+	serveCmd.Flags().BoolVar(&sessions, "sessions", false, "Let the UI exchange the credentials for a session cookie protected by a CSRF token")
+	serveCmd.Flags().StringVar(&tlsCert, "tls-cert", "", "Certificate file to serve HTTPS with, requires --tls-key")
+	serveCmd.Flags().StringVar(&tlsKey, "tls-key", "", "Private key file of the --tls-cert certificate")
+	serveCmd.Flags().StringSliceVar(&autoTLS, "auto-tls", nil, "Domains to serve HTTPS for with certificates from Let's Encrypt (port 80 must be reachable)")
+	serveCmd.Flags().StringVar(&tlsCacheDir, "tls-cache-dir", "", "Directory to store the --auto-tls certificates in (defaults to the user cache directory)")
+	serveCmd.Flags().StringSliceVar(&publicTables, "public", nil, "Tables anyone can read without credentials on GET /public (e.g. cities,countries)")
+	serveCmd.Flags().StringSliceVar(&publicColumns, "public-columns", nil, "Only expose these columns of the public tables, as TABLE.COLUMN")
+	serveCmd.Flags().IntVar(&publicMaxRows, "public-max-rows", sqliteadmin.DefaultPublicMaxRows, "Maximum number of rows returned by a request to a public table")
+	serveCmd.Flags().BoolVar(&development, "dev", false, "Enable the commands meant for development and staging instances, like seeding fixtures")
+	serveCmd.Flags().StringVar(&fixturesDir, "fixtures", "", "Directory of JSON fixture sets the UI can load with --dev")
+	serveCmd.Flags().StringVar(&watchDir, "watch-dir", "", "Directory to watch for .db files to register as additional databases")
+	serveCmd.RegisterFlagCompletionFunc("ttl", completeColumns("="))
+	serveCmd.RegisterFlagCompletionFunc("mask", completeColumns("."))
 	rootCmd.AddCommand(serveCmd)
 }
 
 var serveCmd = &cobra.Command{
-	Use:   "serve [DB_PATH]",
+	Use:   "serve [DB_PATH | LIBSQL_URL]",
 	Short: "Spin up an HTTP server to serve requests to the SQLiteAdmin UI",
-	Args:  cobra.MinimumNArgs(1),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := cobra.MaximumNArgs(1)(cmd, args); err != nil {
+			return err
+		}
+		if len(args) == 0 && watchDir == "" {
+			return fmt.Errorf("requires a DB_PATH or the --watch-dir flag")
+		}
+		return validateTLSFlags()
+	},
+	ValidArgsFunction: completeDBPath,
 	Run: func(cmd *cobra.Command, args []string) {
-		dbPath := args[0]
+		var dbPath string
+		if len(args) > 0 {
+			dbPath = args[0]
+		}
 		username := os.Getenv("SQLITEADMIN_USERNAME")
 		password := os.Getenv("SQLITEADMIN_PASSWORD")
 
-		r := getRouter(dbPath, username, password)
+		admin := getAdmin(dbPath, username, password)
+		r := getRouter(admin)
+
+		if len(ttlColumns) > 0 {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go admin.RunTTLSweeper(ctx, ttlInterval)
+		}
+
+		if watchDir != "" {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				err := admin.WatchDir(ctx, watchDir, sqliteadmin.DefaultWatchInterval, openDB)
+				if err != nil {
+					log.Fatalf("Error watching directory: %v", err)
+				}
+			}()
+		}
 
 		addr := fmt.Sprintf(":%d", port)
 
@@ -48,7 +156,16 @@ var serveCmd = &cobra.Command{
 		// Run graceful shutdown in a separate goroutine
 		go gracefulShutdown(httpServer, done)
 
-		err := httpServer.ListenAndServe()
+		var err error
+		switch {
+		case len(autoTLS) > 0:
+			configureTLS(httpServer)
+			err = httpServer.ListenAndServeTLS("", "")
+		case tlsCert != "":
+			err = httpServer.ListenAndServeTLS(tlsCert, tlsKey)
+		default:
+			err = httpServer.ListenAndServe()
+		}
 		if err != nil && err != http.ErrServerClosed {
 			panic(fmt.Sprintf("http server error: %s", err))
 		}
@@ -69,33 +186,172 @@ func newHTTPServer(addr string, mux *chi.Mux) *http.Server {
 	}
 }
 
-func getRouter(dbPath, username, password string) *chi.Mux {
-	db, err := sql.Open("sqlite", dbPath)
-	if err != nil {
-		log.Fatalf("Error opening database: %v", err)
+func getAdmin(dbPath, username, password string) *sqliteadmin.Admin {
+	var db, readDB *sql.DB
+	if dbPath != "" {
+		var err error
+		db, err = openDB(dbPath)
+		if err != nil {
+			log.Fatalf("Error opening database: %v", err)
+		}
+		if readPool {
+			readDB, err = openReadOnlyDB(dbPath)
+			if err != nil {
+				log.Fatalf("Error opening read-only database: %v", err)
+			}
+		}
 	}
 
 	logger := slog.Default()
 
+	var loc *time.Location
+	if timeZone != "" {
+		var err error
+		loc, err = time.LoadLocation(timeZone)
+		if err != nil {
+			log.Fatalf("Error loading time zone: %v", err)
+		}
+	}
+
+	var policies sqliteadmin.ColumnPolicies
+	if policyFile != "" {
+		var err error
+		policies, err = loadColumnPolicies(policyFile)
+		if err != nil {
+			log.Fatalf("Error loading column policies: %v", err)
+		}
+	}
+
+	var users []sqliteadmin.User
+	if usersFile != "" {
+		var err error
+		users, err = loadUsers(usersFile)
+		if err != nil {
+			log.Fatalf("Error loading users: %v", err)
+		}
+	}
+
 	// Setup the handler for SQLiteAdmin
 	config := sqliteadmin.Config{
 		DB:       db,
+		ReadDB:   readDB,
 		Username: username,
 		Password: password,
+		Users:    users,
 		Logger:   logger,
+		TimeZone: loc,
+		Compress: compress,
+
+		DefaultLimit: pageSize,
+		MaxLimit:     maxLimit,
+
+		LogRequests:        logRequests,
+		QueryTimeout:       timeout,
+		BusyTimeout:        busyTimeout,
+		WriteQueueTimeout:  writeWait,
+		ShowErrorDetails:   showErrors,
+		HideInternalTables: hideTables,
+		IncludeTables:      include,
+		ExcludeTables:      exclude,
+		SlowQueryThreshold: slowQueries,
+		TTLColumns:         ttlColumns,
+
+		ColumnPolicies: policies,
+		MaskedColumns:  masked,
+		Sessions:       sessions,
+		// The UI is served from another origin, like for CORS below
+		WebSocketOrigins: []string{"*"},
+
+		OIDC:         getOIDCConfig(),
+		PublicTables: getPublicTables(),
+
+		Development: development,
+	}
+	if fixturesDir != "" {
+		config.Fixtures = os.DirFS(fixturesDir)
+	}
+	return sqliteadmin.New(config)
+}
+
+func getOIDCConfig() *sqliteadmin.OIDCConfig {
+	if oidcIssuer == "" {
+		return nil
+	}
+	if oidcAudience == "" {
+		log.Fatalf("--oidc-issuer requires --oidc-audience")
+	}
+	// Without --oidc-roles the values of the role claim are the roles
+	var roles map[string]sqliteadmin.Role
+	if len(oidcRoles) > 0 {
+		roles = make(map[string]sqliteadmin.Role, len(oidcRoles))
+	}
+	for value, role := range oidcRoles {
+		roles[value] = sqliteadmin.Role(role)
+	}
+	return &sqliteadmin.OIDCConfig{
+		Issuer:        oidcIssuer,
+		Audience:      oidcAudience,
+		UsernameClaim: oidcUserClaim,
+		RoleClaim:     oidcRoleClaim,
+		Roles:         roles,
+	}
+}
+
+func getPublicTables() map[string]sqliteadmin.PublicTable {
+	tables := make(map[string]sqliteadmin.PublicTable, len(publicTables))
+	for _, name := range publicTables {
+		tables[name] = sqliteadmin.PublicTable{MaxRows: publicMaxRows}
 	}
-	admin := sqliteadmin.New(config)
+	for _, c := range publicColumns {
+		table, column, ok := strings.Cut(c, ".")
+		if !ok {
+			log.Fatalf("Invalid public column %q, expected TABLE.COLUMN", c)
+		}
+		t, ok := tables[table]
+		if !ok {
+			log.Fatalf("Public column %q of a table not passed to --public", c)
+		}
+		t.Columns = append(t.Columns, column)
+		tables[table] = t
+	}
+	return tables
+}
+
+func loadColumnPolicies(path string) (sqliteadmin.ColumnPolicies, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var policies sqliteadmin.ColumnPolicies
+	if err := json.Unmarshal(b, &policies); err != nil {
+		return nil, err
+	}
+	return policies, policies.Validate()
+}
+
+func loadUsers(path string) ([]sqliteadmin.User, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var users []sqliteadmin.User
+	return users, json.Unmarshal(b, &users)
+}
 
+func getRouter(admin *sqliteadmin.Admin) *chi.Mux {
 	r := chi.NewRouter()
 	r.Use(middleware.Logger)
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", sqliteadmin.CSRFHeader},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
 	r.Post("/", admin.HandlePost)
+	r.Get("/ws", admin.HandleWebSocket)
+	r.Get("/public", admin.HandlePublic)
+	r.Get("/artifacts/{id}", admin.HandleArtifact)
 
 	return r
 }
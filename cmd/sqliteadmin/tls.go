@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// validateTLSFlags checks that at most one way of serving over TLS is used.
+func validateTLSFlags() error {
+	if (tlsCert == "") != (tlsKey == "") {
+		return fmt.Errorf("--tls-cert and --tls-key must be used together")
+	}
+	if tlsCert != "" && len(autoTLS) > 0 {
+		return fmt.Errorf("--auto-tls cannot be used with --tls-cert and --tls-key")
+	}
+	return nil
+}
+
+// configureTLS sets up the server to get certificates from Let's Encrypt for
+// the --auto-tls domains. Since Let's Encrypt validates the domains over plain
+// HTTP, the challenges are answered on port 80, which must be reachable.
+func configureTLS(server *http.Server) {
+	cacheDir := tlsCacheDir
+	if cacheDir == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			log.Fatalf("Error finding cache directory for certificates: %v", err)
+		}
+		cacheDir = filepath.Join(dir, "sqliteadmin", "autocert")
+	}
+
+	m := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(autoTLS...),
+		Cache:      autocert.DirCache(cacheDir),
+	}
+	server.TLSConfig = m.TLSConfig()
+
+	go func() {
+		err := http.ListenAndServe(":80", m.HTTPHandler(nil))
+		if err != nil {
+			log.Fatalf("Error serving ACME challenges: %v", err)
+		}
+	}()
+}
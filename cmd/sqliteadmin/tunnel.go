@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+var (
+	relayUser    string
+	remoteAddr   string
+	identityFile string
+	knownHosts   string
+)
+
+func init() {
+	tunnelCmd.Flags().StringVarP(&relayUser, "user", "u", os.Getenv("USER"), "User to log in to the relay as")
+	tunnelCmd.Flags().StringVar(&remoteAddr, "remote-addr", "localhost:8080", "Address the relay listens on and forwards to this server")
+	tunnelCmd.Flags().StringVarP(&identityFile, "identity", "i", "", "Private key to authenticate with (defaults to the keys of the SSH agent)")
+	tunnelCmd.Flags().StringVar(&knownHosts, "known-hosts", "", "known_hosts file to verify the relay with (defaults to ~/.ssh/known_hosts)")
+	tunnelCmd.Flags().StringVar(&policyFile, "column-policies", "", "JSON file of column policies (redaction, anonymization, normalization, allowed values) keyed by table and column")
+	tunnelCmd.Flags().StringSliceVar(&masked, "mask", nil, "Columns to redact and protect from updates, as TABLE.COLUMN where TABLE may be * (e.g. users.password_hash,*.ssn)")
+	tunnelCmd.RegisterFlagCompletionFunc("mask", completeColumns("."))
+	rootCmd.AddCommand(tunnelCmd)
+}
+
+var tunnelCmd = &cobra.Command{
+	Use:   "tunnel DB_PATH RELAY_HOST[:PORT]",
+	Short: "Serve a database through an SSH tunnel to a relay, without opening inbound ports",
+	Long: `Connects to the relay over SSH and asks it to forward the connections made
+to --remote-addr on the relay back to this process, like "ssh -R". The UI can
+then reach the database through the relay (e.g. behind a reverse proxy
+terminating HTTPS) while the database stays behind its firewall.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeDBPath,
+	Run: func(cmd *cobra.Command, args []string) {
+		username := os.Getenv("SQLITEADMIN_USERNAME")
+		password := os.Getenv("SQLITEADMIN_PASSWORD")
+		if username == "" || password == "" {
+			log.Printf("Warning: SQLITEADMIN_USERNAME and SQLITEADMIN_PASSWORD are not set, anyone reaching the relay can access the database")
+		}
+
+		admin := getAdmin(args[0], username, password)
+		r := getRouter(admin)
+
+		client, err := dialRelay(args[1])
+		if err != nil {
+			log.Fatalf("Error connecting to relay: %v", err)
+		}
+		defer client.Close()
+
+		listener, err := client.Listen("tcp", remoteAddr)
+		if err != nil {
+			log.Fatalf("Error listening on the relay: %v", err)
+		}
+		log.Printf("Forwarding %s on %s to sqliteadmin", remoteAddr, args[1])
+
+		httpServer := newHTTPServer("", r)
+		go func() {
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+			<-ctx.Done()
+			httpServer.Close()
+		}()
+
+		err = httpServer.Serve(listener)
+		if err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Error serving through the tunnel: %v", err)
+		}
+	},
+}
+
+func dialRelay(host string) (*ssh.Client, error) {
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "22")
+	}
+
+	path := knownHosts
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	hostKeyCallback, err := knownhosts.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading known hosts: %v", err)
+	}
+
+	auth, err := sshAuth()
+	if err != nil {
+		return nil, err
+	}
+
+	return ssh.Dial("tcp", host, &ssh.ClientConfig{
+		User:            relayUser,
+		Auth:            []ssh.AuthMethod{auth},
+		HostKeyCallback: hostKeyCallback,
+	})
+}
+
+func sshAuth() (ssh.AuthMethod, error) {
+	if identityFile != "" {
+		key, err := os.ReadFile(identityFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading identity: %v", err)
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing identity: %v", err)
+		}
+		return ssh.PublicKeys(signer), nil
+	}
+
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		return nil, fmt.Errorf("no --identity provided and no SSH agent running")
+	}
+	conn, err := net.Dial("unix", sock)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to SSH agent: %v", err)
+	}
+	return ssh.PublicKeysCallback(agent.NewClient(conn).Signers), nil
+}
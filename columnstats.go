@@ -0,0 +1,145 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/joelseq/sqliteadmin-go/querybuilder"
+)
+
+// DefaultTopValues is the number of most frequent values returned by
+// GetColumnStats when the "top" param is not provided.
+const DefaultTopValues = 10
+
+// ColumnStats profiles the values of a column.
+type ColumnStats struct {
+	Name          string       `json:"name"`
+	Type          string       `json:"type"`
+	Count         int64        `json:"count"`
+	NullCount     int64        `json:"nullCount"`
+	DistinctCount int64        `json:"distinctCount"`
+	Min           interface{}  `json:"min"`
+	Max           interface{}  `json:"max"`
+	AvgLength     *float64     `json:"avgLength"`
+	TopValues     []ValueCount `json:"topValues"`
+}
+
+// ValueCount is the number of rows having a value.
+type ValueCount struct {
+	Value interface{} `json:"value"`
+	Count int64       `json:"count"`
+}
+
+func (a *Admin) getColumnStats(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getReadDB(w, params)
+	if !ok {
+		return
+	}
+
+	table, ok := params["tableName"].(string)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrMissingTableName.Error()))
+		return
+	}
+
+	columnName, ok := params["column"].(string)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrMissingColumn.Error()))
+		return
+	}
+
+	top := DefaultTopValues
+	if params["top"] != nil {
+		top, ok = convertNumber(params["top"])
+		if !ok || top < 0 {
+			writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+			return
+		}
+	}
+	if exceeds(top, a.maxLimit) {
+		writeError(w, apiErrBadRequest(ErrLimitTooLarge.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: GetColumnStats, table=%s, column=%s, top=%d", table, columnName, top))
+
+	columns, err := getColumns(ctx, a.cached(db), table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error getting columns: %v", err))
+		writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+		return
+	}
+	var col *column
+	for i := range columns {
+		if columns[i].Name == columnName {
+			col = &columns[i]
+		}
+	}
+	if col == nil {
+		writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+		return
+	}
+
+	scope, err := a.rowScope(ctx, table)
+	if err != nil {
+		a.writeScopeError(w, err)
+		return
+	}
+	stats, err := profileColumn(ctx, a.cached(db), table, *col, top, scope)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error getting column stats: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.protectStats(table, stats)
+
+	json.NewEncoder(w).Encode(stats)
+}
+
+// profileColumn computes the stats of the column over the rows in the scope,
+// or all of them when it is nil.
+func profileColumn(ctx context.Context, q queryer, table string, col column, top int, scope *Condition) (*ColumnStats, error) {
+	stats := &ColumnStats{Name: col.Name, Type: col.DataType, TopValues: []ValueCount{}}
+
+	var nonNull int64
+	var avgLength sql.NullFloat64
+	where, args := scopeWhere(scope)
+	query := fmt.Sprintf(
+		"SELECT count(*), count(%[2]s), count(DISTINCT %[2]s), min(%[2]s), max(%[2]s), avg(length(%[2]s)) FROM %[1]s%[3]s",
+		quoteIdent(table), quoteIdent(col.Name), where,
+	)
+	err := q.QueryRowContext(ctx, query, args...).Scan(&stats.Count, &nonNull, &stats.DistinctCount, &stats.Min, &stats.Max, &avgLength)
+	if err != nil {
+		return nil, fmt.Errorf("error computing column stats: %v", err)
+	}
+	stats.NullCount = stats.Count - nonNull
+	if avgLength.Valid {
+		stats.AvgLength = &avgLength.Float64
+	}
+
+	where, args = querybuilder.And(querybuilder.IsNotNull(col.Name), conditionExpr(scope)).SQL()
+	query = fmt.Sprintf(
+		"SELECT %[2]s, count(*) AS n FROM %[1]s WHERE %[3]s GROUP BY %[2]s ORDER BY n DESC, %[2]s LIMIT ?",
+		quoteIdent(table), quoteIdent(col.Name), where,
+	)
+	rows, err := q.QueryContext(ctx, query, append(args, top)...)
+	if err != nil {
+		return nil, fmt.Errorf("error computing top values: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v ValueCount
+		if err := rows.Scan(&v.Value, &v.Count); err != nil {
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		stats.TopValues = append(stats.TopValues, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,74 @@
+package sqliteadmin
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// compressResponseWriter compresses everything written to the underlying
+// ResponseWriter with the encoding negotiated with the client.
+type compressResponseWriter struct {
+	http.ResponseWriter
+	w interface {
+		io.WriteCloser
+		Flush() error
+	}
+}
+
+// compressResponse wraps w so that the response is compressed with gzip or
+// deflate if the request accepts it. The returned function must be called once
+// the response has been written to flush the remaining compressed data.
+func compressResponse(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, func()) {
+	encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+	if encoding == "" {
+		return w, func() {}
+	}
+
+	w.Header().Set("Content-Encoding", encoding)
+	w.Header().Add("Vary", "Accept-Encoding")
+	w.Header().Del("Content-Length")
+
+	cw := &compressResponseWriter{ResponseWriter: w}
+	if encoding == "gzip" {
+		cw.w = gzip.NewWriter(w)
+	} else {
+		// flate.NewWriter only fails for invalid compression levels.
+		cw.w, _ = flate.NewWriter(w, flate.DefaultCompression)
+	}
+	return cw, func() { cw.w.Close() }
+}
+
+// negotiateEncoding returns the preferred encoding we support from the
+// Accept-Encoding header, or an empty string if there is none.
+func negotiateEncoding(header string) string {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.ReplaceAll(strings.TrimSpace(params), " ", "") == "q=0" {
+			continue
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	for _, encoding := range []string{"gzip", "deflate"} {
+		if accepted[encoding] {
+			return encoding
+		}
+	}
+	return ""
+}
+
+func (c *compressResponseWriter) Write(b []byte) (int, error) {
+	return c.w.Write(b)
+}
+
+// Flush flushes the compressed data written so far so that streamed responses
+// reach the client in chunks.
+func (c *compressResponseWriter) Flush() {
+	c.w.Flush()
+	if f, ok := c.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
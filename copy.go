@@ -0,0 +1,263 @@
+package sqliteadmin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Values of the "onConflict" param of CopyRows, deciding what happens to the
+// rows conflicting with rows of the target table.
+const (
+	copyConflictError   = "error"
+	copyConflictSkip    = "skip"
+	copyConflictReplace = "replace"
+)
+
+// copyRows inserts the rows of a table matching the condition into a target
+// table, optionally of another database ("targetDatabase"), e.g. to archive
+// them or move them between tenants. The "columns" param maps source columns
+// to target columns, and defaults to the columns both tables have in common.
+// The source rows are read with the scope of the table, and the rows written
+// go through the policies, secret scanning and validators of the target table
+// like the writes of UpdateRow.
+func (a *Admin) copyRows(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	sourceName, _ := params["database"].(string)
+	source, ok := a.getNamedDB(w, sourceName)
+	if !ok {
+		return
+	}
+	table, ok := params["tableName"].(string)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrMissingTableName.Error()))
+		return
+	}
+
+	targetName, ok := params["targetDatabase"].(string)
+	if !ok {
+		targetName = sourceName
+	}
+	target, ok := a.getNamedDB(w, targetName)
+	if !ok {
+		return
+	}
+	targetTable, _ := params["targetTable"].(string)
+	if targetTable == "" {
+		targetTable = table
+	}
+
+	var insert string
+	onConflict, _ := params["onConflict"].(string)
+	switch onConflict {
+	case "", copyConflictError:
+		insert = "INSERT"
+	case copyConflictSkip:
+		insert = "INSERT OR IGNORE"
+	case copyConflictReplace:
+		insert = "INSERT OR REPLACE"
+	default:
+		writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+		return
+	}
+
+	var condition *Condition
+	if conditionParam, ok := params["condition"]; ok {
+		condition, ok = toCondition(conditionParam, a.logger)
+		if !ok {
+			writeError(w, apiErrBadRequest("Invalid condition"))
+			return
+		}
+		if exceeds(conditionDepth(condition), a.maxConditionDepth) {
+			writeError(w, apiErrBadRequest(ErrConditionTooDeep.Error()))
+			return
+		}
+		if !a.checkCondition(w, table, condition) {
+			return
+		}
+	}
+
+	var mapping map[string]string
+	if params["columns"] != nil {
+		rawColumns, ok := params["columns"].(map[string]interface{})
+		if !ok {
+			writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+			return
+		}
+		mapping = make(map[string]string, len(rawColumns))
+		for s, t := range rawColumns {
+			t, ok := t.(string)
+			if !ok {
+				writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+				return
+			}
+			mapping[s] = t
+		}
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: CopyRows, table=%s, database=%s, targetTable=%s, targetDatabase=%s", table, sourceName, targetTable, targetName))
+
+	if hasInternalPrefix(table) || hasInternalPrefix(targetTable) {
+		writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+		return
+	}
+	sourceColumns, err := getColumnNames(ctx, a.cached(source), table)
+	if err == nil && len(sourceColumns) > 0 {
+		var targetColumns []string
+		targetColumns, err = getColumnNames(ctx, a.cached(target), targetTable)
+		if err == nil {
+			mapping, err = copyMapping(mapping, sourceColumns, targetColumns)
+		}
+	} else if err == nil {
+		err = errInvalidMapping
+	}
+	if errors.Is(err, errInvalidMapping) {
+		writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+		return
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error getting columns: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	for s := range mapping {
+		// Hidden values would be revealed in the target table
+		if p, ok := a.columnPolicy(table, s); ok && p.hides() {
+			writeError(w, apiErrBadRequest(fmt.Sprintf("%s: %s", ErrCopyHiddenColumn, s)))
+			return
+		}
+	}
+
+	scope, err := a.rowScope(ctx, table)
+	if err != nil {
+		a.writeScopeError(w, err)
+		return
+	}
+	if scope != nil {
+		condition = scopeCondition(scope, condition)
+	}
+	// Rows without a key can't be checked against the scope of the target
+	targetScope, err := a.rowScope(ctx, targetTable)
+	if err != nil || targetScope != nil {
+		a.writeScopeError(w, fmt.Errorf("%w: copy into scoped table %s", ErrOutOfScope, targetTable))
+		return
+	}
+
+	tx, ok := a.beginMutation(ctx, w, target, targetTable, params)
+	if !ok {
+		return
+	}
+	defer tx.Rollback()
+
+	var q queryer = a.cached(source)
+	if source == target {
+		q = tx
+	}
+	rows, err := queryTable(ctx, q, table, condition, "", -1, 0, a.logger)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error reading rows: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+
+	written := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		out := make(map[string]interface{}, len(mapping))
+		for s, t := range mapping {
+			out[t] = row[s]
+		}
+		if err := a.applyWritePolicies(ctx, targetTable, out); err != nil {
+			if errors.Is(err, ErrMaskedColumn) {
+				writeError(w, apiErrForbidden(err.Error()))
+			} else {
+				writeError(w, apiErrBadRequest(err.Error()))
+			}
+			return
+		}
+		written[i] = out
+	}
+	findings, ok := a.checkSecrets(w, targetTable, written...)
+	if !ok {
+		return
+	}
+
+	inserted := []map[string]interface{}{}
+	for _, row := range written {
+		returned, err := insertRow(ctx, tx, insert, targetTable, row)
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Error copying rows: %v", err))
+			writeError(w, a.apiErr(err))
+			return
+		}
+		inserted = append(inserted, returned...)
+	}
+	// Validators are given the rows as written, with their default values
+	if err := a.validateRows(targetTable, inserted); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		a.logger.Error(fmt.Sprintf("Error copying rows: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: copied %d row(s) from %s to %s", len(inserted), table, targetTable))
+
+	response := map[string]interface{}{"status": "ok", "rowsCopied": len(inserted)}
+	if len(findings) > 0 {
+		response["secretWarnings"] = findings
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+var errInvalidMapping = errors.New("invalid column mapping")
+
+// copyMapping checks the mapping of source columns to target columns, or
+// defaults it to the columns both tables have.
+func copyMapping(mapping map[string]string, sourceColumns, targetColumns []string) (map[string]string, error) {
+	if mapping == nil {
+		mapping = make(map[string]string)
+		for _, c := range sourceColumns {
+			if contains(targetColumns, c) {
+				mapping[c] = c
+			}
+		}
+	}
+	if len(mapping) == 0 {
+		return nil, errInvalidMapping
+	}
+	seen := make(map[string]bool, len(mapping))
+	for s, t := range mapping {
+		if !contains(sourceColumns, s) || !contains(targetColumns, t) || seen[t] {
+			return nil, errInvalidMapping
+		}
+		seen[t] = true
+	}
+	return mapping, nil
+}
+
+// insertRow inserts the row with the given INSERT verb and returns it as
+// stored, or nothing if it was ignored.
+func insertRow(ctx context.Context, q queryer, insert, table string, row map[string]interface{}) ([]map[string]interface{}, error) {
+	columns := make([]string, 0, len(row))
+	for c := range row {
+		columns = append(columns, c)
+	}
+	sort.Strings(columns)
+
+	if len(columns) == 0 {
+		return queryRows(ctx, q, fmt.Sprintf("%s INTO %s DEFAULT VALUES RETURNING *", insert, quoteIdent(table)))
+	}
+	quoted := make([]string, len(columns))
+	args := make([]interface{}, len(columns))
+	for i, c := range columns {
+		quoted[i] = quoteIdent(c)
+		args[i] = row[c]
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(columns)), ",")
+	query := fmt.Sprintf("%s INTO %s (%s) VALUES (%s) RETURNING *", insert, quoteIdent(table), strings.Join(quoted, ", "), placeholders)
+	return queryRows(ctx, q, query, args...)
+}
@@ -0,0 +1,147 @@
+package sqliteadmin_test
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyRows(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	_, err := ts.db.Exec("CREATE TABLE users_copy (id INTEGER PRIMARY KEY, name TEXT)")
+	assert.NoError(t, err)
+
+	archive, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "archive.db"))
+	assert.NoError(t, err)
+	defer archive.Close()
+	_, err = archive.Exec("CREATE TABLE archived_users (id INTEGER PRIMARY KEY, full_name TEXT, archived INTEGER DEFAULT 1)")
+	assert.NoError(t, err)
+	ts.admin.AddDatabase("archive", archive)
+
+	condition := func(operator sqliteadmin.Operator, value string) sqliteadmin.Condition {
+		return sqliteadmin.Condition{
+			Cases:           []sqliteadmin.Case{sqliteadmin.Filter{Column: "id", Operator: operator, Value: value}},
+			LogicalOperator: sqliteadmin.LogicalOperatorAnd,
+		}
+	}
+	ts.admin.RegisterValidator("archived_users", func(row map[string]any) error {
+		if row["archived"] != int64(1) {
+			return errors.New("rows must be archived")
+		}
+		if row["full_name"] == "Alice" {
+			return errors.New("Alice can't be archived")
+		}
+		return nil
+	})
+
+	runTestCases([]TestCase{
+		{
+			name: "Success: Same Database",
+			params: map[string]interface{}{
+				"tableName":   "users",
+				"targetTable": "users_copy",
+				"condition":   condition(sqliteadmin.OperatorLessThanOrEquals, "2"),
+			},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok", "rowsCopied": float64(2)},
+		},
+		{
+			name: "Success: Skip Conflicts",
+			params: map[string]interface{}{
+				"tableName":   "users",
+				"targetTable": "users_copy",
+				"condition":   condition(sqliteadmin.OperatorLessThanOrEquals, "3"),
+				"onConflict":  "skip",
+			},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok", "rowsCopied": float64(1)},
+		},
+		{
+			name: "Success: Other Database",
+			params: map[string]interface{}{
+				"tableName":      "users",
+				"targetDatabase": "archive",
+				"targetTable":    "archived_users",
+				"columns":        map[string]interface{}{"id": "id", "name": "full_name"},
+				"condition":      condition(sqliteadmin.OperatorGreaterThanOrEquals, "8"),
+			},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok", "rowsCopied": float64(2)},
+		},
+		{
+			name: "Failure: Validator",
+			params: map[string]interface{}{
+				"tableName":      "users",
+				"targetDatabase": "archive",
+				"targetTable":    "archived_users",
+				"columns":        map[string]interface{}{"id": "id", "name": "full_name"},
+				"condition":      condition(sqliteadmin.OperatorLessThanOrEquals, "2"),
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(400),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid row: Alice can't be archived",
+			},
+		},
+		{
+			name: "Failure: Unknown Column",
+			params: map[string]interface{}{
+				"tableName":      "users",
+				"targetDatabase": "archive",
+				"targetTable":    "archived_users",
+				"columns":        map[string]interface{}{"name": "name"},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(400),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: " + sqliteadmin.ErrInvalidInput.Error(),
+			},
+		},
+		{
+			name:           "Failure: Invalid On Conflict",
+			params:         map[string]interface{}{"tableName": "users", "targetTable": "users_copy", "onConflict": "merge"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(400),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: " + sqliteadmin.ErrInvalidInput.Error(),
+			},
+		},
+		{
+			name:           "Failure: Missing Table Name",
+			params:         map[string]interface{}{"targetTable": "users_copy"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(400),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: " + sqliteadmin.ErrMissingTableName.Error(),
+			},
+		},
+	}, sqliteadmin.CopyRows, t, ts.server)
+
+	var copied int
+	err = ts.db.QueryRow("SELECT count(*) FROM users_copy").Scan(&copied)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, copied)
+
+	rows, err := archive.Query("SELECT id, full_name FROM archived_users ORDER BY id")
+	assert.NoError(t, err)
+	defer rows.Close()
+	var archived []string
+	for rows.Next() {
+		var id int
+		var name string
+		assert.NoError(t, rows.Scan(&id, &name))
+		archived = append(archived, name)
+	}
+	assert.Equal(t, []string{"Henry", "Ivy"}, archived)
+}
@@ -0,0 +1,180 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultWatchInterval is how often WatchDir rescans the directory when no
+// interval is provided.
+const DefaultWatchInterval = 5 * time.Second
+
+// AddDatabase registers an additional database under the given name. Clients
+// select it by passing the name as the "database" param of a command.
+func (a *Admin) AddDatabase(name string, db *sql.DB) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.dbs[name] = db
+}
+
+// RemoveDatabase unregisters the database with the given name and returns it
+// so that the caller can close it. It returns nil if no such database exists.
+func (a *Admin) RemoveDatabase(name string) *sql.DB {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	db := a.dbs[name]
+	delete(a.dbs, name)
+	if db != nil {
+		a.stmts.forget(db)
+		a.schemas.forget(db)
+		a.versions.forget(db)
+	}
+	return db
+}
+
+// Databases returns the names of all the registered databases.
+func (a *Admin) Databases() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	names := make([]string, 0, len(a.dbs))
+	for name := range a.dbs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getDB returns the database selected by the "database" param, falling back
+// to the default database from the Config. It writes an error response and
+// returns false if no database could be resolved.
+func (a *Admin) getDB(w http.ResponseWriter, params map[string]interface{}) (*sql.DB, bool) {
+	name, _ := params["database"].(string)
+	return a.getNamedDB(w, name)
+}
+
+// getNamedDB returns the database registered under name, or the default
+// database if name is empty. It writes an error response and returns false if
+// no database could be resolved.
+func (a *Admin) getNamedDB(w http.ResponseWriter, name string) (*sql.DB, bool) {
+	if name == "" {
+		if a.db == nil {
+			writeError(w, apiErrBadRequest(ErrMissingDatabase.Error()))
+			return nil, false
+		}
+		return a.db, true
+	}
+
+	a.mu.RLock()
+	db, ok := a.dbs[name]
+	a.mu.RUnlock()
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrUnknownDatabase.Error()))
+		return nil, false
+	}
+	return db, true
+}
+
+// getReadDB is like getDB but returns the read-only pool of the default
+// database when one is configured. It must only be used by commands that
+// don't write.
+func (a *Admin) getReadDB(w http.ResponseWriter, params map[string]interface{}) (*sql.DB, bool) {
+	name, ok := params["database"].(string)
+	if (!ok || name == "") && a.readDB != nil {
+		return a.readDB, true
+	}
+	return a.getDB(w, params)
+}
+
+func (a *Admin) listDatabases(w http.ResponseWriter) {
+	a.logger.Info("Command: ListDatabases")
+	json.NewEncoder(w).Encode(map[string]interface{}{"databases": a.Databases()})
+}
+
+// WatchDir registers every ".db" file in dir as a database named after the
+// file (without the extension) and keeps polling the directory until ctx is
+// done, registering newly created files and removing (and closing) the ones
+// that are deleted. The open function is used to open each file, which keeps
+// the choice of SQLite driver up to the caller.
+func (a *Admin) WatchDir(ctx context.Context, dir string, interval time.Duration, open func(path string) (*sql.DB, error)) error {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	watched := make(map[string]bool)
+	defer func() {
+		for name := range watched {
+			if db := a.RemoveDatabase(name); db != nil {
+				db.Close()
+			}
+		}
+	}()
+
+	if err := a.scanDir(dir, watched, open); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := a.scanDir(dir, watched, open); err != nil {
+				a.logger.Error(fmt.Sprintf("Error scanning directory %s: %v", dir, err))
+			}
+		}
+	}
+}
+
+func (a *Admin) scanDir(dir string, watched map[string]bool, open func(path string) (*sql.DB, error)) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("error reading directory: %v", err)
+	}
+
+	found := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".db" {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), ".db")
+		found[name] = true
+		if watched[name] {
+			continue
+		}
+
+		db, err := open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Error opening database %s: %v", entry.Name(), err))
+			continue
+		}
+		a.AddDatabase(name, db)
+		watched[name] = true
+		a.logger.Info(fmt.Sprintf("Registered database %s", name))
+	}
+
+	for name := range watched {
+		if found[name] {
+			continue
+		}
+		if db := a.RemoveDatabase(name); db != nil {
+			db.Close()
+		}
+		delete(watched, name)
+		a.logger.Info(fmt.Sprintf("Removed database %s", name))
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListDatabases(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	ts.admin.AddDatabase("tenant", setupDB(t))
+
+	body := sqliteadmin.CommandRequest{
+		Command: sqliteadmin.ListDatabases,
+	}
+
+	req := makeRequest(t, ts.server.URL, body)
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	result := readBody(t, res.Body)
+	assert.Equal(t, []interface{}{"tenant"}, result["databases"])
+
+	cases := []TestCase{
+		{
+			name: "Failure: Unknown Database",
+			params: map[string]interface{}{
+				"database": "missing",
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: unknown database",
+			},
+		},
+		{
+			name: "Success: List Tables in Named Database",
+			params: map[string]interface{}{
+				"database": "tenant",
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"tables": []interface{}{"users"},
+			},
+		},
+	}
+
+	runTestCases(cases, sqliteadmin.ListTables, t, ts.server)
+}
+
+func TestWatchDir(t *testing.T) {
+	dir := t.TempDir()
+	a := sqliteadmin.New(sqliteadmin.Config{})
+
+	open := func(path string) (*sql.DB, error) {
+		return sql.Open("sqlite", path)
+	}
+
+	createDB := func(name string) {
+		db, err := open(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		_, err = db.Exec("CREATE TABLE t (id INTEGER PRIMARY KEY)")
+		assert.NoError(t, err)
+		db.Close()
+	}
+	createDB("a.db")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- a.WatchDir(ctx, dir, 10*time.Millisecond, open)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"a"}, a.Databases())
+	}, time.Second, 10*time.Millisecond)
+
+	createDB("b.db")
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"a", "b"}, a.Databases())
+	}, time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, os.Remove(filepath.Join(dir, "a.db")))
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"b"}, a.Databases())
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.NoError(t, <-done)
+	assert.Empty(t, a.Databases())
+}
+
+func TestReadDB(t *testing.T) {
+	readDB := setupDB(t)
+	defer readDB.Close()
+	_, err := readDB.Exec("DELETE FROM users WHERE id > 1")
+	assert.NoError(t, err)
+
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.ReadDB = readDB
+	})
+	defer close()
+
+	cases := []TestCase{
+		{
+			name: "Success: Reads use the read-only pool",
+			params: map[string]interface{}{
+				"tableName": "users",
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponse: makeGetTableResponse([]responseRow{
+				{id: 1, name: "Alice", email: "alice@gmail.com"},
+			}),
+		},
+	}
+
+	runTestCases(cases, sqliteadmin.GetTable, t, ts.server)
+
+	updateCases := []TestCase{
+		{
+			name: "Success: Writes use the main pool",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"row": map[string]interface{}{
+					"id":   "2",
+					"name": "Bobby",
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"status": "ok",
+			},
+		},
+	}
+
+	runTestCases(updateCases, sqliteadmin.UpdateRow, t, ts.server)
+
+	rows, err := getTableValues(ts.db, "users")
+	assert.NoError(t, err)
+	assert.Equal(t, "Bobby", rows[1]["name"])
+}
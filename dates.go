@@ -0,0 +1,120 @@
+package sqliteadmin
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// DateFormat is how the value of a date column is stored, since SQLite has
+// no date type.
+type DateFormat string
+
+const (
+	DateFormatISO        DateFormat = "iso"
+	DateFormatUnix       DateFormat = "unix"
+	DateFormatUnixMillis DateFormat = "unixMillis"
+	DateFormatJulianDay  DateFormat = "julianDay"
+)
+
+// DateValue is the value of a date column normalized as ISO-8601, along
+// with the value as stored.
+type DateValue struct {
+	Value  string      `json:"value"`
+	Raw    interface{} `json:"raw"`
+	Format DateFormat  `json:"format"`
+}
+
+// isoLayouts are the layouts of the dates stored as text that are
+// recognized, the ones without an offset being in UTC as for SQLite's date
+// functions.
+var isoLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+const (
+	// julianDayUnixEpoch is the Julian day of 1970-01-01T00:00:00Z.
+	julianDayUnixEpoch = 2440587.5
+	// unixMillisThreshold is the smallest epoch taken as milliseconds rather
+	// than seconds, 1973 in milliseconds and the year 5138 in seconds.
+	unixMillisThreshold = 100_000_000_000
+)
+
+// isDateColumn reports whether the column holds dates, either because of its
+// declared type or because it is named like created_at.
+func isDateColumn(name, dataType string) bool {
+	dataType = strings.ToUpper(dataType)
+	return strings.Contains(dataType, "DATE") || strings.Contains(dataType, "TIME") ||
+		strings.HasSuffix(strings.ToLower(name), "_at")
+}
+
+// isCalendarDate reports whether a column declared with the given type holds
+// calendar dates rather than points in time, e.g. DATE columns.
+func isCalendarDate(dataType string) bool {
+	return strings.Contains(strings.ToUpper(dataType), "DATE") && !isTimestampType(dataType)
+}
+
+// normalizeDates returns the values of the date columns of rows normalized
+// as ISO-8601, in the time zone if not nil, with one map per row. Values that
+// aren't recognized as dates are left out.
+func normalizeDates(rows []map[string]interface{}, types map[string]string, loc *time.Location) []map[string]DateValue {
+	dates := make([]map[string]DateValue, len(rows))
+	for i, row := range rows {
+		dates[i] = map[string]DateValue{}
+		for column, value := range row {
+			if !isDateColumn(column, types[column]) {
+				continue
+			}
+			t, format, ok := parseDate(value)
+			if !ok {
+				continue
+			}
+			d := DateValue{Raw: value, Format: format}
+			// Calendar dates aren't shifted between time zones
+			if isCalendarDate(types[column]) {
+				d.Value = t.Format(time.DateOnly)
+			} else {
+				if loc != nil {
+					t = t.In(loc)
+				}
+				d.Value = t.Format(time.RFC3339Nano)
+			}
+			dates[i][column] = d
+		}
+	}
+	return dates
+}
+
+// parseDate returns the time of a value stored in one of the recognized
+// formats.
+func parseDate(value interface{}) (time.Time, DateFormat, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, DateFormatISO, true
+	case string:
+		for _, layout := range isoLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, DateFormatISO, true
+			}
+		}
+	case int64:
+		if v >= unixMillisThreshold || v <= -unixMillisThreshold {
+			return time.UnixMilli(v).UTC(), DateFormatUnixMillis, true
+		}
+		return time.Unix(v, 0).UTC(), DateFormatUnix, true
+	case float64:
+		// Julian days between the years 0 and 9999
+		if v >= 1721059.5 && v < 5373484.5 {
+			ms := math.Round((v - julianDayUnixEpoch) * 86400_000)
+			return time.UnixMilli(int64(ms)).UTC(), DateFormatJulianDay, true
+		}
+		sec, frac := math.Modf(v)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC(), DateFormatUnix, true
+	}
+	return time.Time{}, "", false
+}
@@ -0,0 +1,72 @@
+package sqliteadmin_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTableDates(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	// The same instant, 2024-01-01T12:00:00Z, stored in each format
+	_, err := ts.db.Exec(`
+		CREATE TABLE logs (
+			id INTEGER PRIMARY KEY,
+			created_at INTEGER,
+			sent_at INTEGER,
+			seen_at REAL,
+			logged_at TEXT,
+			due DATE,
+			note TEXT
+		);
+		INSERT INTO logs VALUES (1, 1704110400, 1704110400000, 2460311.0, '2024-01-01 12:00:00', '2024-01-01', 'hello');
+		INSERT INTO logs (id, created_at) VALUES (2, NULL);
+	`)
+	assert.NoError(t, err)
+
+	value := func(value string, raw interface{}, format sqliteadmin.DateFormat) map[string]interface{} {
+		return map[string]interface{}{"value": value, "raw": raw, "format": string(format)}
+	}
+	runTestCases([]TestCase{
+		{
+			name: "Success: Dates in Time Zone",
+			params: map[string]interface{}{
+				"tableName": "logs",
+				"timeZone":  "America/New_York",
+				"dates":     true,
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"rows": []interface{}{
+					map[string]interface{}{"id": float64(1), "created_at": float64(1704110400), "sent_at": float64(1704110400000), "seen_at": float64(2460311), "logged_at": "2024-01-01 12:00:00", "due": "2024-01-01T00:00:00Z", "note": "hello"},
+					map[string]interface{}{"id": float64(2), "created_at": nil, "sent_at": nil, "seen_at": nil, "logged_at": nil, "due": nil, "note": nil},
+				},
+				"dates": []interface{}{
+					map[string]interface{}{
+						"created_at": value("2024-01-01T07:00:00-05:00", float64(1704110400), sqliteadmin.DateFormatUnix),
+						"sent_at":    value("2024-01-01T07:00:00-05:00", float64(1704110400000), sqliteadmin.DateFormatUnixMillis),
+						"seen_at":    value("2024-01-01T07:00:00-05:00", float64(2460311), sqliteadmin.DateFormatJulianDay),
+						"logged_at":  value("2024-01-01T07:00:00-05:00", "2024-01-01 12:00:00", sqliteadmin.DateFormatISO),
+						// Calendar dates aren't shifted
+						"due": value("2024-01-01", "2024-01-01T00:00:00Z", sqliteadmin.DateFormatISO),
+					},
+					map[string]interface{}{},
+				},
+			},
+		},
+		{
+			name:           "Failure: Dates in Stream",
+			params:         map[string]interface{}{"tableName": "logs", "dates": true, "stream": true},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: " + sqliteadmin.ErrInvalidInput.Error(),
+			},
+		},
+	}, sqliteadmin.GetTable, t, ts.server)
+}
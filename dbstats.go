@@ -0,0 +1,285 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// VacuumThreshold is the share of free pages, or of unused space in the pages
+// of a table or index, from which GetDatabaseStats suggests a VACUUM.
+const VacuumThreshold = 0.1
+
+// Values of DatabaseStats.AutoVacuum.
+const (
+	AutoVacuumNone        = "none"
+	AutoVacuumFull        = "full"
+	AutoVacuumIncremental = "incremental"
+)
+
+// GetDatabaseStatsParams are the params of GetDatabaseStats.
+type GetDatabaseStatsParams struct {
+	Database string `json:"database,omitempty" mapstructure:"database"`
+}
+
+func (p *GetDatabaseStatsParams) validate() error {
+	return nil
+}
+
+// DatabaseStats reports what the database consumes on disk. Sizes are in
+// bytes.
+type DatabaseStats struct {
+	// FileSize and WALSize are 0 for in-memory databases, and WALSize for
+	// databases not in WAL mode.
+	FileSize    int64  `json:"fileSize"`
+	WALSize     int64  `json:"walSize"`
+	JournalMode string `json:"journalMode"`
+	PageSize    int64  `json:"pageSize"`
+	PageCount   int64  `json:"pageCount"`
+	// FreelistPages are the unused pages that VACUUM would release, which
+	// are FreePercent of the pages and Reclaimable bytes.
+	FreelistPages int64   `json:"freelistPages"`
+	FreePercent   float64 `json:"freePercent"`
+	Reclaimable   int64   `json:"reclaimable"`
+	// AutoVacuum is AutoVacuumNone, AutoVacuumFull or AutoVacuumIncremental.
+	// Only incremental auto-vacuum databases can be vacuumed with
+	// IncrementalVacuum.
+	AutoVacuum string `json:"autoVacuum"`
+	// DBStat is whether SQLite was compiled with the dbstat virtual table,
+	// without which Objects is empty.
+	DBStat bool `json:"dbstat"`
+	// Objects are the tables and indexes, largest first.
+	Objects []ObjectSize `json:"objects"`
+	// Suggestions are the ways to reclaim space worth trying.
+	Suggestions []string `json:"suggestions"`
+}
+
+// ObjectSize is the size of a table or index as reported by dbstat.
+type ObjectSize struct {
+	Name string `json:"name"`
+	// Type is "table" or "index".
+	Type string `json:"type"`
+	// Table is the table of an index, or the name of a table.
+	Table string `json:"tableName"`
+	Pages int64  `json:"pages"`
+	Size  int64  `json:"size"`
+	// Unused is the space of the pages of the object not holding data.
+	Unused int64 `json:"unused"`
+}
+
+// getDatabaseStats reports the size of the database file and of its WAL, its
+// pages and the size of each table and index. Sizing the objects reads every
+// page of the database.
+func (a *Admin) getDatabaseStats(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	var p GetDatabaseStatsParams
+	if err := a.decodeParams(params, &p); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	db, ok := a.getReadDB(w, params)
+	if !ok {
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: GetDatabaseStats, database=%s", p.Database))
+
+	stats := DatabaseStats{Objects: []ObjectSize{}, Suggestions: []string{}}
+	q := a.cached(db)
+	var autoVacuum int
+	err := q.QueryRowContext(ctx, "SELECT page_size, page_count, freelist_count, journal_mode, auto_vacuum FROM pragma_page_size, pragma_page_count, pragma_freelist_count, pragma_journal_mode, pragma_auto_vacuum").
+		Scan(&stats.PageSize, &stats.PageCount, &stats.FreelistPages, &stats.JournalMode, &autoVacuum)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error reading database stats: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+
+	path, err := databaseFile(ctx, db)
+	if err == nil && path != "" {
+		stats.FileSize, err = fileSize(path)
+		if err == nil {
+			stats.WALSize, err = fileSize(path + "-wal")
+		}
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error reading database file size: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+
+	objects, err := getObjectSizes(ctx, q)
+	if err != nil && !strings.Contains(err.Error(), "no such table: dbstat") {
+		a.logger.Error(fmt.Sprintf("Error reading object sizes: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	stats.DBStat = err == nil
+	for _, o := range objects {
+		if a.tableAllowed(o.Table) {
+			stats.Objects = append(stats.Objects, o)
+		}
+	}
+	stats.AutoVacuum = []string{AutoVacuumNone, AutoVacuumFull, AutoVacuumIncremental}[autoVacuum]
+	if stats.PageCount > 0 {
+		stats.FreePercent = float64(stats.FreelistPages) / float64(stats.PageCount) * 100
+	}
+	stats.Reclaimable = stats.FreelistPages * stats.PageSize
+	stats.Suggestions = vacuumSuggestions(&stats)
+
+	json.NewEncoder(w).Encode(stats)
+}
+
+// vacuumSuggestions returns how the space reported by the stats could be
+// reclaimed: the free pages are released by VACUUM, or IncrementalVacuum for
+// incremental auto-vacuum databases, and VACUUM also repacks the tables and
+// indexes whose pages are mostly empty.
+func vacuumSuggestions(stats *DatabaseStats) []string {
+	suggestions := []string{}
+	if stats.FreePercent >= VacuumThreshold*100 {
+		switch stats.AutoVacuum {
+		case AutoVacuumIncremental:
+			suggestions = append(suggestions, fmt.Sprintf("Run IncrementalVacuum to release %d free page(s) (%d bytes)", stats.FreelistPages, stats.Reclaimable))
+		case AutoVacuumNone:
+			suggestions = append(suggestions, fmt.Sprintf("Run VACUUM to release %d free page(s) (%d bytes)", stats.FreelistPages, stats.Reclaimable))
+		}
+	}
+	for _, o := range stats.Objects {
+		// Objects of a few pages are always partly empty
+		if o.Pages < 8 || float64(o.Unused) < float64(o.Size)*VacuumThreshold {
+			continue
+		}
+		suggestions = append(suggestions, fmt.Sprintf("Run VACUUM to repack %s %s, %.0f%% of whose space is unused (%d bytes)", o.Type, o.Name, float64(o.Unused)/float64(o.Size)*100, o.Unused))
+	}
+	return suggestions
+}
+
+// IncrementalVacuumParams are the params of IncrementalVacuum.
+type IncrementalVacuumParams struct {
+	Database string `json:"database,omitempty" mapstructure:"database"`
+	// Pages is the number of free pages to release, all of them when 0.
+	Pages int `json:"pages,omitempty" mapstructure:"pages"`
+}
+
+func (p *IncrementalVacuumParams) validate() error {
+	if p.Pages < 0 {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+// incrementalVacuum releases free pages of a database in incremental
+// auto-vacuum mode, shrinking its file without the full copy of VACUUM.
+func (a *Admin) incrementalVacuum(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	var p IncrementalVacuumParams
+	if err := a.decodeParams(params, &p); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: IncrementalVacuum, database=%s, pages=%d", p.Database, p.Pages))
+
+	var autoVacuum int
+	if err := db.QueryRowContext(ctx, "PRAGMA auto_vacuum").Scan(&autoVacuum); err != nil {
+		a.logger.Error(fmt.Sprintf("Error reading auto_vacuum: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if autoVacuum != 2 {
+		writeError(w, apiErrBadRequest(ErrIncrementalVacuumOff.Error()))
+		return
+	}
+
+	freed, err := a.vacuumPages(ctx, db, p.Pages)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error vacuuming database: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: released %d free page(s)", freed))
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "pagesFreed": freed})
+}
+
+// vacuumPages runs an incremental vacuum of up to pages pages, all the free
+// pages when 0, and returns how many were released.
+func (a *Admin) vacuumPages(ctx context.Context, db *sql.DB, pages int) (int64, error) {
+	tx, err := a.beginImmediateTx(ctx, db)
+	if err != nil {
+		return 0, readOnlyError(fmt.Errorf("error starting transaction: %v", err))
+	}
+	defer tx.Rollback()
+
+	var before, after int64
+	if err := tx.QueryRowContext(ctx, "PRAGMA freelist_count").Scan(&before); err != nil {
+		return 0, err
+	}
+	// A page is released each time the pragma is stepped, so its rows must
+	// be read rather than the pragma executed
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("PRAGMA incremental_vacuum(%d)", pages))
+	if err != nil {
+		return 0, readOnlyError(err)
+	}
+	for rows.Next() {
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, readOnlyError(err)
+	}
+	if err := tx.QueryRowContext(ctx, "PRAGMA freelist_count").Scan(&after); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, readOnlyError(err)
+	}
+	return before - after, nil
+}
+
+// fileSize returns the size of the file, 0 if it doesn't exist.
+func fileSize(path string) (int64, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// getObjectSizes returns the size of the tables and indexes of the database,
+// largest first, using the dbstat virtual table.
+func getObjectSizes(ctx context.Context, q queryer) ([]ObjectSize, error) {
+	// The schema table itself isn't listed in sqlite_master
+	rows, err := q.QueryContext(ctx, `
+		SELECT s.name, coalesce(m.type, 'table'), coalesce(m.tbl_name, s.name), count(*), sum(s.pgsize), sum(s.unused)
+		FROM dbstat AS s LEFT JOIN sqlite_master AS m ON m.name = s.name
+		GROUP BY s.name
+		ORDER BY 5 DESC, 1`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var objects []ObjectSize
+	for rows.Next() {
+		var o ObjectSize
+		if err := rows.Scan(&o.Name, &o.Type, &o.Table, &o.Pages, &o.Size, &o.Unused); err != nil {
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		objects = append(objects, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
+	return objects, nil
+}
@@ -0,0 +1,115 @@
+package sqliteadmin_test
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDatabaseStats(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	path := filepath.Join(t.TempDir(), "app.db")
+	db, err := sql.Open("sqlite", path)
+	assert.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(`
+		PRAGMA journal_mode = WAL;
+		CREATE TABLE events (id INTEGER PRIMARY KEY, payload TEXT);
+		CREATE INDEX events_payload ON events (payload);
+		WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 500)
+		INSERT INTO events (payload) SELECT printf('%0200d', i) FROM n;
+	`)
+	assert.NoError(t, err)
+	ts.admin.AddDatabase("app", db)
+
+	req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: sqliteadmin.GetDatabaseStats, Params: map[string]interface{}{"database": "app"}})
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	body := readBody(t, res.Body)
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(info.Size()), body["fileSize"])
+	assert.Greater(t, body["walSize"], float64(0))
+	assert.Equal(t, "wal", body["journalMode"])
+	assert.Equal(t, true, body["dbstat"])
+
+	objects := map[string]map[string]interface{}{}
+	list, _ := body["objects"].([]interface{})
+	for i, o := range list {
+		o := o.(map[string]interface{})
+		objects[o["name"].(string)] = o
+		if i > 0 {
+			assert.LessOrEqual(t, o["size"], list[i-1].(map[string]interface{})["size"])
+		}
+	}
+	if assert.Contains(t, objects, "events") {
+		assert.Equal(t, "table", objects["events"]["type"])
+		assert.Greater(t, objects["events"]["pages"], float64(1))
+	}
+	if assert.Contains(t, objects, "events_payload") {
+		assert.Equal(t, "index", objects["events_payload"]["type"])
+		assert.Equal(t, "events", objects["events_payload"]["tableName"])
+	}
+}
+
+func TestIncrementalVacuum(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "app.db"))
+	assert.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(`
+		PRAGMA auto_vacuum = INCREMENTAL;
+		CREATE TABLE events (id INTEGER PRIMARY KEY, payload TEXT);
+		WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 500)
+		INSERT INTO events (payload) SELECT printf('%0200d', i) FROM n;
+		DELETE FROM events;
+	`)
+	assert.NoError(t, err)
+	ts.admin.AddDatabase("app", db)
+
+	send := func(command sqliteadmin.Command, params map[string]interface{}) (int, map[string]interface{}) {
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: command, Params: params})
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res.StatusCode, readBody(t, res.Body)
+	}
+
+	status, stats := send(sqliteadmin.GetDatabaseStats, map[string]interface{}{"database": "app"})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "incremental", stats["autoVacuum"])
+	free := stats["freelistPages"].(float64)
+	assert.Greater(t, free, float64(10))
+	assert.Greater(t, stats["freePercent"], float64(50))
+	assert.Equal(t, free*stats["pageSize"].(float64), stats["reclaimable"])
+	assert.Equal(t, []interface{}{
+		fmt.Sprintf("Run IncrementalVacuum to release %.0f free page(s) (%.0f bytes)", free, stats["reclaimable"]),
+	}, stats["suggestions"])
+
+	status, body := send(sqliteadmin.IncrementalVacuum, map[string]interface{}{"database": "app", "pages": 5})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]interface{}{"status": "ok", "pagesFreed": float64(5)}, body)
+	status, body = send(sqliteadmin.IncrementalVacuum, map[string]interface{}{"database": "app"})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, free-5, body["pagesFreed"])
+
+	var remaining int
+	assert.NoError(t, db.QueryRow("PRAGMA freelist_count").Scan(&remaining))
+	assert.Equal(t, 0, remaining)
+
+	// The test database doesn't use auto-vacuum
+	status, body = send(sqliteadmin.IncrementalVacuum, map[string]interface{}{})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "Bad request: incremental vacuum requires auto_vacuum = INCREMENTAL", body["message"])
+}
@@ -0,0 +1,134 @@
+package sqliteadmin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// PageVersion identifies the rows of a page returned by GetTable with the
+// "delta" param, to be sent back as the "since" param of the next call.
+type PageVersion struct {
+	// ETag changes whenever any row of the page, or their order, changes.
+	ETag string `json:"etag"`
+	// Keys identify the rows of the page in order, with the values of their
+	// primary key (or their content if the table doesn't have one).
+	Keys []string `json:"keys"`
+	// Versions are the hashes of the rows, in the same order as Keys.
+	Versions []string `json:"versions"`
+}
+
+// PageDelta lists the changes to a page since the version sent by the
+// client. The client rebuilds the page by taking the rows of Keys, in order,
+// from Added and Changed or else from the rows it already has.
+type PageDelta struct {
+	PageVersion
+	Unchanged bool                     `json:"unchanged,omitempty"`
+	Added     []map[string]interface{} `json:"added"`
+	Changed   []map[string]interface{} `json:"changed"`
+	Removed   []string                 `json:"removed"`
+}
+
+// versionPage computes the version of the rows of a page as they are sent to
+// the client.
+func versionPage(ctx context.Context, q queryer, table string, rows []map[string]interface{}) (*PageVersion, error) {
+	columns, err := getColumns(ctx, q, table)
+	if err != nil {
+		return nil, err
+	}
+	var keyColumns []string
+	for _, c := range columns {
+		if c.PK > 0 {
+			keyColumns = append(keyColumns, c.Name)
+		}
+	}
+
+	page := &PageVersion{Keys: make([]string, len(rows)), Versions: make([]string, len(rows))}
+	etag := sha256.New()
+	for i, row := range rows {
+		version, err := hashJSON(row)
+		if err != nil {
+			return nil, err
+		}
+		key := version
+		if len(keyColumns) > 0 {
+			values := make([]interface{}, len(keyColumns))
+			for j, c := range keyColumns {
+				values[j] = row[c]
+			}
+			b, err := json.Marshal(values)
+			if err != nil {
+				return nil, fmt.Errorf("error encoding row key: %v", err)
+			}
+			key = string(b)
+		}
+		page.Keys[i] = key
+		page.Versions[i] = version
+		fmt.Fprintf(etag, "%s\x00%s\x00", key, version)
+	}
+	page.ETag = hex.EncodeToString(etag.Sum(nil)[:16])
+	return page, nil
+}
+
+func hashJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("error encoding row: %v", err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// diffPage returns the rows of the current page that the client doesn't have
+// in the version it sent.
+func diffPage(since *PageVersion, page *PageVersion, rows []map[string]interface{}) *PageDelta {
+	delta := &PageDelta{
+		PageVersion: *page,
+		Added:       []map[string]interface{}{},
+		Changed:     []map[string]interface{}{},
+		Removed:     []string{},
+	}
+	if since.ETag == page.ETag {
+		delta.Unchanged = true
+		return delta
+	}
+
+	previous := make(map[string]string, len(since.Keys))
+	for i, key := range since.Keys {
+		if i < len(since.Versions) {
+			previous[key] = since.Versions[i]
+		}
+	}
+	current := make(map[string]bool, len(page.Keys))
+	for i, key := range page.Keys {
+		current[key] = true
+		version, ok := previous[key]
+		switch {
+		case !ok:
+			delta.Added = append(delta.Added, rows[i])
+		case version != page.Versions[i]:
+			delta.Changed = append(delta.Changed, rows[i])
+		}
+	}
+	for _, key := range since.Keys {
+		if !current[key] {
+			delta.Removed = append(delta.Removed, key)
+		}
+	}
+	return delta
+}
+
+// toPageVersion converts the "since" param of GetTable.
+func toPageVersion(v interface{}) (*PageVersion, bool) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	var page PageVersion
+	if err := json.Unmarshal(b, &page); err != nil || page.ETag == "" || len(page.Keys) != len(page.Versions) {
+		return nil, false
+	}
+	return &page, true
+}
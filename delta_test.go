@@ -0,0 +1,81 @@
+package sqliteadmin_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTableDelta(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	getTable := func(params map[string]interface{}) map[string]interface{} {
+		params["tableName"] = "users"
+		params["limit"] = 3
+		params["delta"] = true
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: sqliteadmin.GetTable, Params: params})
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		return readBody(t, res.Body)
+	}
+
+	body := getTable(map[string]interface{}{})
+	assert.Len(t, body["rows"], 3)
+	version := body["version"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"[1]", "[2]", "[3]"}, version["keys"])
+
+	t.Run("Unchanged", func(t *testing.T) {
+		body := getTable(map[string]interface{}{"since": version})
+		delta := body["delta"].(map[string]interface{})
+		assert.Equal(t, true, delta["unchanged"])
+		assert.Equal(t, version["etag"], delta["etag"])
+		assert.Empty(t, delta["added"])
+		assert.Empty(t, delta["changed"])
+		assert.Empty(t, delta["removed"])
+	})
+
+	t.Run("Changed", func(t *testing.T) {
+		_, err := ts.db.Exec("DELETE FROM users WHERE id = 1; UPDATE users SET email = 'bob@example.com' WHERE id = 2")
+		assert.NoError(t, err)
+
+		body := getTable(map[string]interface{}{"since": version})
+		delta := body["delta"].(map[string]interface{})
+		assert.Nil(t, delta["unchanged"])
+		assert.NotEqual(t, version["etag"], delta["etag"])
+		assert.Equal(t, []interface{}{"[2]", "[3]", "[4]"}, delta["keys"])
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"id": float64(4), "name": "David", "email": "david@gmail.com"},
+		}, delta["added"])
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"id": float64(2), "name": "Bob", "email": "bob@example.com"},
+		}, delta["changed"])
+		assert.Equal(t, []interface{}{"[1]"}, delta["removed"])
+	})
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Since Without Delta",
+			params:         map[string]interface{}{"tableName": "users", "since": version},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid input",
+			},
+		},
+		{
+			name:           "Failure: Delta With Stream",
+			params:         map[string]interface{}{"tableName": "users", "delta": true, "stream": true},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid input",
+			},
+		},
+	}, sqliteadmin.GetTable, t, ts.server)
+}
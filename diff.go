@@ -0,0 +1,400 @@
+package sqliteadmin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RowUpdate is a row that exists on both sides of a diff with different
+// values.
+type RowUpdate struct {
+	Before map[string]interface{} `json:"before"`
+	After  map[string]interface{} `json:"after"`
+}
+
+// TableDiff lists the changes needed for the target table to match the
+// source, matching rows by primary key.
+type TableDiff struct {
+	Inserts []map[string]interface{} `json:"inserts"`
+	Updates []RowUpdate              `json:"updates"`
+	Deletes []map[string]interface{} `json:"deletes"`
+}
+
+// diffTable compares a table between two databases, or between an uploaded
+// snapshot ("sourceRows") and a database, and optionally applies the
+// differences to the target database in a single transaction. The values are
+// compared as stored, so tables with columns whose values are hidden or
+// transformed can't be diffed. Applied diffs go through the policies, secret
+// scanning, validators and hooks of the target table like the writes of
+// InsertRow, UpdateRow and DeleteRows.
+func (a *Admin) diffTable(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	table, ok := params["tableName"].(string)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrMissingTableName.Error()))
+		return
+	}
+
+	targetName, _ := params["targetDatabase"].(string)
+	target, ok := a.getNamedDB(w, targetName)
+	if !ok {
+		return
+	}
+
+	// Rows out of the scope would be compared and copied along with the
+	// others
+	if a.scoped(table) {
+		writeError(w, apiErrForbidden(ErrOutOfScope.Error()))
+		return
+	}
+
+	apply := params["apply"] == true
+	a.logger.Info(fmt.Sprintf("Command: DiffTable, table=%s, target=%s, apply=%t", table, targetName, apply))
+
+	primaryKey, err := getPrimaryKey(ctx, target, table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error getting primary key: %v", err))
+		writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+		return
+	}
+	columns, err := getColumnNames(ctx, target, table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error getting columns: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	for _, c := range columns {
+		if p, ok := a.columnPolicy(table, c); (ok && p.hides()) || a.transformed(table, c) {
+			writeError(w, apiErrBadRequest(fmt.Sprintf("%s: %s", ErrDiffHiddenColumn, c)))
+			return
+		}
+	}
+
+	var sourceRows []map[string]interface{}
+	if params["sourceRows"] != nil {
+		rows, ok := params["sourceRows"].([]interface{})
+		if !ok {
+			writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+			return
+		}
+		for _, r := range rows {
+			row, ok := r.(map[string]interface{})
+			if !ok || !sameColumns(row, columns) {
+				writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+				return
+			}
+			sourceRows = append(sourceRows, row)
+		}
+	} else {
+		sourceName, _ := params["sourceDatabase"].(string)
+		source, ok := a.getNamedDB(w, sourceName)
+		if !ok {
+			return
+		}
+		if source == target {
+			writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+			return
+		}
+		sourceColumns, err := getColumnNames(ctx, source, table)
+		if err != nil || strings.Join(sourceColumns, ",") != strings.Join(columns, ",") {
+			// Rows can only be compared between tables with the same columns
+			writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+			return
+		}
+		sourceRows, err = queryRows(ctx, source, fmt.Sprintf("SELECT * FROM %s", quoteIdent(table)))
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Error reading source rows: %v", err))
+			writeError(w, a.apiErr(err))
+			return
+		}
+	}
+
+	var q queryer = target
+	var tx mutationTx
+	if apply {
+		tx, ok = a.beginMutation(ctx, w, target, table, params)
+		if !ok {
+			return
+		}
+		defer tx.Rollback()
+		q = tx
+	}
+
+	// Reading the target rows in the same transaction as the writes makes
+	// sure the applied diff is the one that is returned.
+	targetRows, err := queryRows(ctx, q, fmt.Sprintf("SELECT * FROM %s", quoteIdent(table)))
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error reading target rows: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+
+	diff, err := diffRows(sourceRows, targetRows, primaryKey, columns)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error diffing rows: %v", err))
+		writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+		return
+	}
+
+	var findings []SecretFinding
+	if apply {
+		findings, ok = a.applyTableDiff(ctx, w, tx, targetName, table, primaryKey, columns, diff)
+		if !ok {
+			return
+		}
+	}
+
+	response := map[string]interface{}{
+		"diff":    a.protectDiff(ctx, table, diff),
+		"applied": apply,
+	}
+	if len(findings) > 0 {
+		response["secretWarnings"] = findings
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// applyTableDiff writes the diff in the transaction and commits it, running
+// the checks and hooks of the inserts, updates and deletes. It writes an error
+// response and returns false if the diff can't be applied.
+func (a *Admin) applyTableDiff(ctx context.Context, w http.ResponseWriter, tx mutationTx, database, table, primaryKey string, columns []string, diff *TableDiff) ([]SecretFinding, bool) {
+	inserted := Mutation{Database: database, Table: table, PrimaryKey: primaryKey, Keys: []interface{}{}, New: diff.Inserts}
+	updated := Mutation{Database: database, Table: table, PrimaryKey: primaryKey, Keys: []interface{}{}}
+	deleted := Mutation{Database: database, Table: table, PrimaryKey: primaryKey, Keys: []interface{}{}, Old: diff.Deletes}
+	for _, row := range diff.Inserts {
+		inserted.Keys = append(inserted.Keys, row[primaryKey])
+	}
+	for _, u := range diff.Updates {
+		updated.Keys = append(updated.Keys, u.Before[primaryKey])
+		updated.Old = append(updated.Old, u.Before)
+		updated.New = append(updated.New, u.After)
+	}
+	for _, row := range diff.Deletes {
+		deleted.Keys = append(deleted.Keys, row[primaryKey])
+	}
+
+	written := append(append([]map[string]interface{}{}, inserted.New...), updated.New...)
+	for _, row := range written {
+		if err := a.applyWritePolicies(ctx, table, row); err != nil {
+			if errors.Is(err, ErrMaskedColumn) {
+				writeError(w, apiErrForbidden(err.Error()))
+			} else {
+				writeError(w, apiErrBadRequest(err.Error()))
+			}
+			return nil, false
+		}
+	}
+	findings, ok := a.checkSecrets(w, table, written...)
+	if !ok {
+		return nil, false
+	}
+	if err := a.validateRows(table, written); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return nil, false
+	}
+	if len(deleted.Keys) > 0 {
+		if err := runBeforeHook(ctx, a.hooks.BeforeDelete, deleted); err != nil {
+			writeError(w, apiErrBadRequest(err.Error()))
+			return nil, false
+		}
+	}
+	if len(updated.Keys) > 0 {
+		if err := runBeforeHook(ctx, a.hooks.BeforeUpdate, updated); err != nil {
+			writeError(w, apiErrBadRequest(err.Error()))
+			return nil, false
+		}
+	}
+
+	err := applyDiff(ctx, tx, table, primaryKey, columns, diff)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error applying diff: %v", err))
+		writeError(w, a.apiErr(err))
+		return nil, false
+	}
+	// BeforeInsert is given the rows once inserted, as for InsertRow
+	if len(inserted.Keys) > 0 {
+		if err := runBeforeHook(ctx, a.hooks.BeforeInsert, inserted); err != nil {
+			writeError(w, apiErrBadRequest(err.Error()))
+			return nil, false
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		a.logger.Error(fmt.Sprintf("Error applying diff: %v", err))
+		writeError(w, a.apiErr(err))
+		return nil, false
+	}
+	a.logger.Info(fmt.Sprintf("Audit: applied diff to %s, inserts=%d, updates=%d, deletes=%d",
+		table, len(diff.Inserts), len(diff.Updates), len(diff.Deletes)))
+	tx.afterCommit(ctx, func(ctx context.Context) {
+		if len(deleted.Keys) > 0 {
+			a.afterMutation(ctx, a.hooks.AfterDelete, EventRowsDeleted, deleted)
+		}
+		if len(updated.Keys) > 0 {
+			a.afterMutation(ctx, a.hooks.AfterUpdate, EventRowsUpdated, updated)
+		}
+		if len(inserted.Keys) > 0 {
+			a.afterMutation(ctx, a.hooks.AfterInsert, EventRowsInserted, inserted)
+		}
+	})
+	return findings, true
+}
+
+// protectDiff returns a copy of the diff with the values protected by the
+// policies of the table, leaving the rows given to the hooks as stored.
+func (a *Admin) protectDiff(ctx context.Context, table string, diff *TableDiff) *TableDiff {
+	protect := func(rows []map[string]interface{}) []map[string]interface{} {
+		copied := make([]map[string]interface{}, len(rows))
+		for i, row := range rows {
+			copied[i] = make(map[string]interface{}, len(row))
+			for k, v := range row {
+				copied[i][k] = v
+			}
+		}
+		a.protectRows(ctx, table, copied)
+		return copied
+	}
+	protected := &TableDiff{Inserts: protect(diff.Inserts), Updates: []RowUpdate{}, Deletes: protect(diff.Deletes)}
+	for _, u := range diff.Updates {
+		rows := protect([]map[string]interface{}{u.Before, u.After})
+		protected.Updates = append(protected.Updates, RowUpdate{Before: rows[0], After: rows[1]})
+	}
+	return protected
+}
+
+func sameColumns(row map[string]interface{}, columns []string) bool {
+	if len(row) != len(columns) {
+		return false
+	}
+	for _, c := range columns {
+		if _, ok := row[c]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func diffRows(source, target []map[string]interface{}, primaryKey string, columns []string) (*TableDiff, error) {
+	diff := &TableDiff{
+		Inserts: []map[string]interface{}{},
+		Updates: []RowUpdate{},
+		Deletes: []map[string]interface{}{},
+	}
+
+	targetByKey := make(map[string]map[string]interface{}, len(target))
+	for _, row := range target {
+		targetByKey[diffValue(row[primaryKey])] = row
+	}
+
+	seen := make(map[string]bool, len(source))
+	for _, row := range source {
+		key := diffValue(row[primaryKey])
+		if row[primaryKey] == nil || seen[key] {
+			return nil, fmt.Errorf("source rows must have a unique %s", primaryKey)
+		}
+		seen[key] = true
+
+		existing, ok := targetByKey[key]
+		if !ok {
+			diff.Inserts = append(diff.Inserts, row)
+			continue
+		}
+		for _, c := range columns {
+			if diffValue(row[c]) != diffValue(existing[c]) {
+				diff.Updates = append(diff.Updates, RowUpdate{Before: existing, After: row})
+				break
+			}
+		}
+	}
+
+	for _, row := range target {
+		if !seen[diffValue(row[primaryKey])] {
+			diff.Deletes = append(diff.Deletes, row)
+		}
+	}
+	sort.SliceStable(diff.Deletes, func(i, j int) bool {
+		return diffValue(diff.Deletes[i][primaryKey]) < diffValue(diff.Deletes[j][primaryKey])
+	})
+	return diff, nil
+}
+
+// diffValue returns a representation of the value that is the same for a
+// value read from the database and the same value from a JSON snapshot (e.g.
+// exported with GetTable), where numbers are float64 and times are strings.
+func diffValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		// Booleans are stored as integers
+		return fmt.Sprintf("n:%d", boolToInt(v))
+	case int64:
+		return fmt.Sprintf("n:%d", v)
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return fmt.Sprintf("n:%d", int64(v))
+		}
+		return fmt.Sprintf("n:%v", v)
+	case string:
+		return "s:" + v
+	case []byte:
+		return "s:" + string(v)
+	case time.Time:
+		return "s:" + v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprintf("%T:%v", v, v)
+	}
+}
+
+func applyDiff(ctx context.Context, tx queryer, table, primaryKey string, columns []string, diff *TableDiff) error {
+	quoted := make([]string, len(columns))
+	placeholders := make([]string, len(columns))
+	var assignments []string
+	for i, c := range columns {
+		quoted[i] = quoteIdent(c)
+		placeholders[i] = "?"
+		if c != primaryKey {
+			assignments = append(assignments, fmt.Sprintf("%s = ?", quoteIdent(c)))
+		}
+	}
+
+	for _, row := range diff.Deletes {
+		_, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s = ?", quoteIdent(table), quoteIdent(primaryKey)), row[primaryKey])
+		if err != nil {
+			return fmt.Errorf("error deleting row: %v", err)
+		}
+	}
+
+	if len(assignments) > 0 {
+		for _, u := range diff.Updates {
+			var args []interface{}
+			for _, c := range columns {
+				if c != primaryKey {
+					args = append(args, u.After[c])
+				}
+			}
+			args = append(args, u.Before[primaryKey])
+			_, err := tx.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET %s WHERE %s = ?", quoteIdent(table), strings.Join(assignments, ", "), quoteIdent(primaryKey)), args...)
+			if err != nil {
+				return fmt.Errorf("error updating row: %v", err)
+			}
+		}
+	}
+
+	for _, row := range diff.Inserts {
+		args := make([]interface{}, len(columns))
+		for i, c := range columns {
+			args[i] = row[c]
+		}
+		_, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", quoteIdent(table), strings.Join(quoted, ", "), strings.Join(placeholders, ", ")), args...)
+		if err != nil {
+			return fmt.Errorf("error inserting row: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffTable(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	staging := setupDB(t)
+	_, err := staging.Exec(`
+    UPDATE users SET email = 'alice@outlook.com' WHERE id = 1;
+    DELETE FROM users WHERE id = 2;
+    INSERT INTO users (id, name, email) VALUES (10, 'Jack', 'jack@gmail.com');
+  `)
+	assert.NoError(t, err)
+	ts.admin.AddDatabase("staging", staging)
+
+	expectedDiff := map[string]interface{}{
+		"inserts": []interface{}{
+			map[string]interface{}{"id": float64(10), "name": "Jack", "email": "jack@gmail.com"},
+		},
+		"updates": []interface{}{
+			map[string]interface{}{
+				"before": map[string]interface{}{"id": float64(1), "name": "Alice", "email": "alice@gmail.com"},
+				"after":  map[string]interface{}{"id": float64(1), "name": "Alice", "email": "alice@outlook.com"},
+			},
+		},
+		"deletes": []interface{}{
+			map[string]interface{}{"id": float64(2), "name": "Bob", "email": "bob@gmail.com"},
+		},
+	}
+
+	cases := []TestCase{
+		{
+			name:           "Failure: Same Database",
+			params:         map[string]interface{}{"tableName": "users"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid input",
+			},
+		},
+		{
+			name: "Success: Snapshot",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"sourceRows": []interface{}{
+					map[string]interface{}{"id": 1, "name": "Alice", "email": "alice@gmail.com"},
+					map[string]interface{}{"id": 2, "name": "Bob", "email": "bob@gmail.com"},
+					map[string]interface{}{"id": 3, "name": "Charlie", "email": "charlie@gmail.com"},
+					map[string]interface{}{"id": 4, "name": "David", "email": "david@gmail.com"},
+					map[string]interface{}{"id": 5, "name": "Eve", "email": "eve@outlook.com"},
+					map[string]interface{}{"id": 6, "name": "Frank", "email": "frank@yahoo.com"},
+					map[string]interface{}{"id": 7, "name": "Grace", "email": "grace@gmail.com"},
+					map[string]interface{}{"id": 8, "name": "Henry", "email": "henry@gmail.com"},
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"diff": map[string]interface{}{
+					"inserts": []interface{}{},
+					"updates": []interface{}{},
+					"deletes": []interface{}{
+						map[string]interface{}{"id": float64(9), "name": "Ivy", "email": nil},
+					},
+				},
+				"applied": false,
+			},
+		},
+		{
+			name:           "Success: Between Databases",
+			params:         map[string]interface{}{"tableName": "users", "sourceDatabase": "staging"},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"diff":    expectedDiff,
+				"applied": false,
+			},
+		},
+		{
+			name:           "Success: Apply",
+			params:         map[string]interface{}{"tableName": "users", "sourceDatabase": "staging", "apply": true},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"diff":    expectedDiff,
+				"applied": true,
+			},
+		},
+		{
+			name:           "Success: No Differences After Apply",
+			params:         map[string]interface{}{"tableName": "users", "sourceDatabase": "staging"},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"diff": map[string]interface{}{
+					"inserts": []interface{}{},
+					"updates": []interface{}{},
+					"deletes": []interface{}{},
+				},
+				"applied": false,
+			},
+		},
+	}
+
+	runTestCases(cases, sqliteadmin.DiffTable, t, ts.server)
+}
+
+func TestDiffTableHiddenColumns(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.MaskedColumns = []string{"users.email"}
+	})
+	defer close()
+
+	// An empty snapshot would return every row of the table as deleted
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Masked Column",
+			params:         map[string]interface{}{"tableName": "users", "sourceRows": []interface{}{}},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: cannot diff a redacted, masked, encrypted, anonymized or transformed column: email",
+			},
+		},
+	}, sqliteadmin.DiffTable, t, ts.server)
+}
+
+func TestDiffTableHooks(t *testing.T) {
+	var inserts, updates, deletes []sqliteadmin.Mutation
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.Hooks = sqliteadmin.Hooks{
+			BeforeDelete: func(ctx context.Context, m sqliteadmin.Mutation) error {
+				for _, row := range m.Old {
+					if row["name"] == "Ivy" {
+						return errors.New("Ivy must be kept")
+					}
+				}
+				return nil
+			},
+			AfterInsert: func(ctx context.Context, m sqliteadmin.Mutation) { inserts = append(inserts, m) },
+			AfterUpdate: func(ctx context.Context, m sqliteadmin.Mutation) { updates = append(updates, m) },
+			AfterDelete: func(ctx context.Context, m sqliteadmin.Mutation) { deletes = append(deletes, m) },
+		}
+	})
+	defer close()
+	ts.admin.RegisterValidator("users", func(row map[string]any) error {
+		if row["name"] == "Mallory" {
+			return errors.New("Mallory isn't welcome")
+		}
+		return nil
+	})
+
+	// The snapshots are the rows of the table with the given changes
+	snapshot := func(change func(rows []interface{}) []interface{}) map[string]interface{} {
+		users, err := getTableValues(ts.db, "users")
+		assert.NoError(t, err)
+		rows := make([]interface{}, len(users))
+		for i, row := range users {
+			rows[i] = row
+		}
+		return map[string]interface{}{"tableName": "users", "sourceRows": change(rows), "apply": true}
+	}
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Rejected By Hook",
+			params:         snapshot(func(rows []interface{}) []interface{} { return rows[:8] }),
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: Ivy must be kept",
+			},
+		},
+		{
+			name: "Failure: Rejected By Validator",
+			params: snapshot(func(rows []interface{}) []interface{} {
+				return append(rows, map[string]interface{}{"id": 10, "name": "Mallory", "email": nil})
+			}),
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid row: Mallory isn't welcome",
+			},
+		},
+	}, sqliteadmin.DiffTable, t, ts.server)
+	assert.Empty(t, inserts)
+	assert.Empty(t, deletes)
+
+	params := snapshot(func(rows []interface{}) []interface{} {
+		rows[0].(map[string]interface{})["email"] = "alice@outlook.com"
+		return append(append(rows[:7], rows[8]), map[string]interface{}{"id": 10, "name": "Jack", "email": nil})
+	})
+	req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: sqliteadmin.DiffTable, Params: params})
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, true, readBody(t, res.Body)["applied"])
+
+	if assert.Len(t, inserts, 1) {
+		assert.Equal(t, "Jack", inserts[0].New[0]["name"])
+	}
+	if assert.Len(t, updates, 1) {
+		assert.Equal(t, "alice@gmail.com", updates[0].Old[0]["email"])
+		assert.Equal(t, "alice@outlook.com", updates[0].New[0]["email"])
+	}
+	if assert.Len(t, deletes, 1) {
+		assert.Equal(t, "Henry", deletes[0].Old[0]["name"])
+	}
+}
@@ -0,0 +1,353 @@
+package sqliteadmin
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DocsFormat is the format of the pages written by GenerateDocs.
+type DocsFormat string
+
+const (
+	DocsFormatMarkdown DocsFormat = "markdown"
+	DocsFormatHTML     DocsFormat = "html"
+)
+
+// DocsOptions configures GenerateDocs.
+type DocsOptions struct {
+	// Format defaults to DocsFormatMarkdown.
+	Format DocsFormat
+	// SampleRows is the number of rows shown for each table, with the column
+	// policies applied. No rows are shown if it is 0.
+	SampleRows int
+}
+
+// tableDoc is everything documented about a table.
+type tableDoc struct {
+	Name         string
+	File         string
+	Count        int64
+	Columns      []columnDoc
+	ForeignKeys  []foreignKey
+	ReferencedBy []foreignKeyRef
+	Sample       []map[string]interface{}
+}
+
+type columnDoc struct {
+	column
+	Annotations string
+}
+
+// GenerateDocs writes documentation of the schema of the default database to
+// dir: an index page with the tables and a diagram of their foreign keys, and
+// a page per table with its columns, their policies, foreign keys and sample
+// rows.
+func (a *Admin) GenerateDocs(ctx context.Context, dir string, opts DocsOptions) error {
+	if a.db == nil {
+		return ErrMissingDatabase
+	}
+	if opts.Format == "" {
+		opts.Format = DocsFormatMarkdown
+	}
+	if opts.Format != DocsFormatMarkdown && opts.Format != DocsFormatHTML {
+		return fmt.Errorf("unknown docs format %q", opts.Format)
+	}
+
+	tables, err := a.documentTables(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	ext := ".md"
+	if opts.Format == DocsFormatHTML {
+		ext = ".html"
+	}
+	for i := range tables {
+		tables[i].File = docsFileName(tables[i].Name) + ext
+	}
+
+	write := func(name string, render func(io.Writer) error) error {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		if err := render(f); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+
+	if err := write("index"+ext, func(w io.Writer) error {
+		if opts.Format == DocsFormatHTML {
+			return htmlIndexTemplate.Execute(w, tables)
+		}
+		return writeMarkdownIndex(w, tables)
+	}); err != nil {
+		return fmt.Errorf("error writing index: %v", err)
+	}
+	for _, t := range tables {
+		if err := write(t.File, func(w io.Writer) error {
+			if opts.Format == DocsFormatHTML {
+				return htmlTableTemplate.Execute(w, t)
+			}
+			return writeMarkdownTable(w, t)
+		}); err != nil {
+			return fmt.Errorf("error writing page of %s: %v", t.Name, err)
+		}
+	}
+	return nil
+}
+
+func (a *Admin) documentTables(ctx context.Context, opts DocsOptions) ([]tableDoc, error) {
+	q := a.cached(a.db)
+	names, err := getTableNames(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	var tables []tableDoc
+	for _, name := range names {
+		if isInternalTable(name) || !a.tableAllowed(name) {
+			continue
+		}
+
+		t := tableDoc{Name: name}
+		columns, err := getColumns(ctx, q, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range columns {
+			t.Columns = append(t.Columns, columnDoc{column: c, Annotations: a.describePolicy(name, c.Name)})
+		}
+		if t.ForeignKeys, err = getForeignKeys(ctx, q, name); err != nil {
+			return nil, err
+		}
+		if err := q.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", quoteIdent(name))).Scan(&t.Count); err != nil {
+			return nil, fmt.Errorf("error counting rows: %v", err)
+		}
+		if opts.SampleRows > 0 {
+			t.Sample, err = queryRows(ctx, q, fmt.Sprintf("SELECT * FROM %s LIMIT ?", quoteIdent(name)), opts.SampleRows)
+			if err != nil {
+				return nil, err
+			}
+			a.protectRows(ctx, name, t.Sample)
+			a.encodeRows(t.Sample)
+		}
+		tables = append(tables, t)
+	}
+
+	// The references to each table are derived from the foreign keys
+	for i := range tables {
+		for _, t := range tables {
+			for _, fk := range t.ForeignKeys {
+				if fk.Table == tables[i].Name {
+					tables[i].ReferencedBy = append(tables[i].ReferencedBy, foreignKeyRef{Table: t.Name, Column: fk.From})
+				}
+			}
+		}
+	}
+	return tables, nil
+}
+
+// describePolicy summarizes the policy of the column, if any.
+func (a *Admin) describePolicy(table, column string) string {
+	p, ok := a.columnPolicy(table, column)
+	if !ok {
+		return ""
+	}
+	var parts []string
+	switch {
+	case p.Mask:
+		parts = append(parts, "masked")
+	case p.Redact:
+		parts = append(parts, "redacted")
+	case p.Anonymize:
+		parts = append(parts, "anonymized")
+	}
+	if len(p.Normalize) > 0 {
+		parts = append(parts, "normalized ("+strings.Join(p.Normalize, ", ")+")")
+	}
+	if len(p.Enum) > 0 {
+		parts = append(parts, "one of "+strings.Join(p.Enum, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+func docsFileName(table string) string {
+	return "table-" + unsafeFileChars.ReplaceAllString(table, "_")
+}
+
+// displayValue formats a value of a sample row.
+func displayValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
+
+// markdownCell escapes a value so that it fits in a cell of a Markdown table.
+func markdownCell(v interface{}) string {
+	s := strings.ReplaceAll(displayValue(v), "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// mermaidName returns a table name usable as an entity of a Mermaid diagram.
+func mermaidName(table string) string {
+	return unsafeFileChars.ReplaceAllString(table, "_")
+}
+
+func writeMarkdownIndex(w io.Writer, tables []tableDoc) error {
+	var b strings.Builder
+	b.WriteString("# Schema\n\n| Table | Columns | Rows |\n| --- | --- | --- |\n")
+	for _, t := range tables {
+		fmt.Fprintf(&b, "| [%s](%s) | %d | %d |\n", markdownCell(t.Name), t.File, len(t.Columns), t.Count)
+	}
+
+	b.WriteString("\n## Relationships\n\n```mermaid\nerDiagram\n")
+	for _, t := range tables {
+		fmt.Fprintf(&b, "    %s {\n", mermaidName(t.Name))
+		for _, c := range t.Columns {
+			dataType := c.DataType
+			if dataType == "" {
+				dataType = "ANY"
+			}
+			fmt.Fprintf(&b, "        %s %s\n", mermaidName(dataType), mermaidName(c.Name))
+		}
+		b.WriteString("    }\n")
+	}
+	for _, t := range tables {
+		for _, fk := range t.ForeignKeys {
+			fmt.Fprintf(&b, "    %s }o--|| %s : %q\n", mermaidName(t.Name), mermaidName(fk.Table), fk.From)
+		}
+	}
+	b.WriteString("```\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMarkdownTable(w io.Writer, t tableDoc) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n[Back to schema](index.md)\n\n%d rows.\n\n", markdownCell(t.Name), t.Count)
+
+	b.WriteString("## Columns\n\n| Name | Type | Not null | Default | Primary key | Notes |\n| --- | --- | --- | --- | --- | --- |\n")
+	for _, c := range t.Columns {
+		pk := ""
+		if c.PK > 0 {
+			pk = "yes"
+		}
+		notNull := ""
+		if c.NotNull {
+			notNull = "yes"
+		}
+		defaultValue := ""
+		if c.DefaultValue != nil {
+			defaultValue = markdownCell(c.DefaultValue)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+			markdownCell(c.Name), markdownCell(c.DataType), notNull, defaultValue, pk, markdownCell(c.Annotations))
+	}
+
+	if len(t.ForeignKeys) > 0 || len(t.ReferencedBy) > 0 {
+		b.WriteString("\n## Foreign keys\n\n")
+		for _, fk := range t.ForeignKeys {
+			fmt.Fprintf(&b, "- `%s` references [%s](%s)", fk.From, markdownCell(fk.Table), docsFileName(fk.Table)+".md")
+			if fk.To != "" {
+				fmt.Fprintf(&b, " `%s`", fk.To)
+			}
+			b.WriteString("\n")
+		}
+		for _, ref := range t.ReferencedBy {
+			fmt.Fprintf(&b, "- Referenced by [%s](%s) `%s`\n", markdownCell(ref.Table), docsFileName(ref.Table)+".md", ref.Column)
+		}
+	}
+
+	if len(t.Sample) > 0 {
+		b.WriteString("\n## Sample rows\n\n|")
+		for _, c := range t.Columns {
+			fmt.Fprintf(&b, " %s |", markdownCell(c.Name))
+		}
+		b.WriteString("\n|" + strings.Repeat(" --- |", len(t.Columns)) + "\n")
+		for _, row := range t.Sample {
+			b.WriteString("|")
+			for _, c := range t.Columns {
+				fmt.Fprintf(&b, " %s |", markdownCell(row[c.Name]))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var docsFuncs = template.FuncMap{
+	"cell": func(row map[string]interface{}, column string) string {
+		return displayValue(row[column])
+	},
+	"page": func(table string) string {
+		return docsFileName(table) + ".html"
+	},
+}
+
+var htmlIndexTemplate = template.Must(template.New("index").Funcs(docsFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Schema</title></head>
+<body>
+<h1>Schema</h1>
+<table>
+<tr><th>Table</th><th>Columns</th><th>Rows</th><th>References</th></tr>
+{{range .}}<tr>
+<td><a href="{{.File}}">{{.Name}}</a></td><td>{{len .Columns}}</td><td>{{.Count}}</td>
+<td>{{range .ForeignKeys}}<a href="{{page .Table}}">{{.Table}}</a> ({{.From}}) {{end}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+var htmlTableTemplate = template.Must(template.New("table").Funcs(docsFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Name}}</title></head>
+<body>
+<p><a href="index.html">Back to schema</a></p>
+<h1>{{.Name}}</h1>
+<p>{{.Count}} rows.</p>
+<h2>Columns</h2>
+<table>
+<tr><th>Name</th><th>Type</th><th>Not null</th><th>Default</th><th>Primary key</th><th>Notes</th></tr>
+{{range .Columns}}<tr><td>{{.Name}}</td><td>{{.DataType}}</td><td>{{if .NotNull}}yes{{end}}</td><td>{{if .DefaultValue}}{{.DefaultValue}}{{end}}</td><td>{{if .PK}}yes{{end}}</td><td>{{.Annotations}}</td></tr>
+{{end}}</table>
+{{if or .ForeignKeys .ReferencedBy}}<h2>Foreign keys</h2>
+<ul>
+{{range .ForeignKeys}}<li>{{.From}} references <a href="{{page .Table}}">{{.Table}}</a> {{.To}}</li>
+{{end}}{{range .ReferencedBy}}<li>Referenced by <a href="{{page .Table}}">{{.Table}}</a> {{.Column}}</li>
+{{end}}</ul>
+{{end}}{{if .Sample}}<h2>Sample rows</h2>
+<table>
+<tr>{{range .Columns}}<th>{{.Name}}</th>{{end}}</tr>
+{{$columns := .Columns}}{{range $row := .Sample}}<tr>{{range $columns}}<td>{{cell $row .Name}}</td>{{end}}</tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
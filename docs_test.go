@@ -0,0 +1,50 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateDocs(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.MaskedColumns = []string{"users.email"}
+	})
+	defer close()
+
+	_, err := ts.db.Exec(`
+    CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users (id), title TEXT);
+    INSERT INTO posts (user_id, title) VALUES (1, 'Hello | world');
+  `)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	err = ts.admin.GenerateDocs(context.Background(), dir, sqliteadmin.DocsOptions{SampleRows: 2})
+	assert.NoError(t, err)
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.md"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(index), "| [users](table-users.md) | 3 | 9 |")
+	assert.Contains(t, string(index), `posts }o--|| users : "user_id"`)
+
+	users, err := os.ReadFile(filepath.Join(dir, "table-users.md"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(users), "| email | TEXT |  |  |  | masked |")
+	assert.Contains(t, string(users), "- Referenced by [posts](table-posts.md) `user_id`")
+	assert.Contains(t, string(users), "| 1 | Alice | [REDACTED] |")
+	assert.NotContains(t, string(users), "alice@gmail.com")
+
+	posts, err := os.ReadFile(filepath.Join(dir, "table-posts.md"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(posts), `| 1 | 1 | Hello \| world |`)
+
+	err = ts.admin.GenerateDocs(context.Background(), dir, sqliteadmin.DocsOptions{Format: sqliteadmin.DocsFormatHTML})
+	assert.NoError(t, err)
+	page, err := os.ReadFile(filepath.Join(dir, "table-posts.html"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(page), `<a href="table-users.html">users</a>`)
+}
@@ -0,0 +1,369 @@
+package sqliteadmin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/joelseq/sqliteadmin-go/querybuilder"
+)
+
+// Values of the "keep" param of DeleteDuplicates.
+const (
+	// KeepFirst keeps the row of each group with the smallest primary key.
+	KeepFirst = "first"
+	// KeepLast keeps the row of each group with the largest primary key.
+	KeepLast = "last"
+)
+
+// FindDuplicatesParams are the params of FindDuplicates.
+type FindDuplicatesParams struct {
+	Database  string `json:"database,omitempty" mapstructure:"database"`
+	TableName string `json:"tableName" mapstructure:"tableName"`
+	// Columns are the columns whose values the rows of a group share.
+	Columns []string `json:"columns" mapstructure:"columns"`
+	// Limit is the number of groups to return, the default limit when nil.
+	Limit  *int `json:"limit,omitempty" mapstructure:"limit"`
+	Offset int  `json:"offset,omitempty" mapstructure:"offset"`
+}
+
+func (p *FindDuplicatesParams) validate() error {
+	if p.TableName == "" {
+		return ErrMissingTableName
+	}
+	if len(p.Columns) == 0 {
+		return ErrMissingColumn
+	}
+	if (p.Limit != nil && *p.Limit < 0) || p.Offset < 0 {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+// DuplicateGroup is a group of rows sharing the same values.
+type DuplicateGroup struct {
+	// Values are the values of the columns shared by the rows.
+	Values map[string]interface{} `json:"values"`
+	Count  int64                  `json:"count"`
+	// Keys are the primary keys of the rows, in order.
+	Keys []interface{} `json:"keys"`
+}
+
+type FindDuplicatesResponse struct {
+	// Groups are the groups of duplicates, the largest first.
+	Groups []DuplicateGroup `json:"groups"`
+	// Total is the number of groups.
+	Total int64 `json:"total"`
+	// Duplicates is the number of rows DeleteDuplicates would delete, every
+	// row of the groups but one.
+	Duplicates int64 `json:"duplicates"`
+}
+
+// DeleteDuplicatesParams are the params of DeleteDuplicates.
+type DeleteDuplicatesParams struct {
+	Database  string   `json:"database,omitempty" mapstructure:"database"`
+	TableName string   `json:"tableName" mapstructure:"tableName"`
+	Columns   []string `json:"columns" mapstructure:"columns"`
+	// Keep is the row of each group to keep, KeepFirst when empty.
+	Keep        string `json:"keep,omitempty" mapstructure:"keep"`
+	Transaction string `json:"transaction,omitempty" mapstructure:"transaction"`
+}
+
+func (p *DeleteDuplicatesParams) validate() error {
+	if p.TableName == "" {
+		return ErrMissingTableName
+	}
+	if len(p.Columns) == 0 {
+		return ErrMissingColumn
+	}
+	if p.Keep == "" {
+		p.Keep = KeepFirst
+	}
+	if p.Keep != KeepFirst && p.Keep != KeepLast {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+// duplicatesQuery is the part of the queries of the duplicates of a table
+// shared by FindDuplicates and DeleteDuplicates.
+type duplicatesQuery struct {
+	table   string
+	columns []string
+	scope   *Condition
+}
+
+// where returns the WHERE clause of the rows that can be duplicates. Like for
+// UNIQUE constraints, rows with a NULL in one of the columns are never
+// duplicates.
+func (d duplicatesQuery) where() (string, []interface{}) {
+	exprs := make([]querybuilder.Expr, 0, len(d.columns)+1)
+	for _, c := range d.columns {
+		exprs = append(exprs, querybuilder.IsNotNull(c))
+	}
+	exprs = append(exprs, conditionExpr(d.scope))
+	return querybuilder.And(exprs...).SQL()
+}
+
+func (d duplicatesQuery) groupBy() string {
+	return quoteIdents(d.columns)
+}
+
+// checkDuplicateColumns writes an error and returns false if the columns
+// aren't all columns of the table or one of them is hidden, since grouping on
+// hidden values would reveal which ones are equal.
+func (a *Admin) checkDuplicateColumns(ctx context.Context, w http.ResponseWriter, q queryer, table string, columns []string) bool {
+	names, err := getColumnNames(ctx, q, table)
+	if err == nil {
+		err = checkColumns(columns, names)
+	}
+	if err != nil {
+		writeError(w, a.apiErr(err))
+		return false
+	}
+	for _, c := range columns {
+		if p, ok := a.columnPolicy(table, c); ok && p.hides() {
+			writeError(w, apiErrBadRequest(fmt.Sprintf("%s: %s", ErrHiddenColumn, c)))
+			return false
+		}
+	}
+	return true
+}
+
+// findDuplicates returns the groups of rows of a table sharing the same values
+// in the given columns, with the primary keys of their rows.
+func (a *Admin) findDuplicates(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	var p FindDuplicatesParams
+	if err := a.decodeParams(params, &p); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	db, ok := a.getReadDB(w, params)
+	if !ok {
+		return
+	}
+	table := p.TableName
+	limit := a.defaultLimit
+	if p.Limit != nil {
+		limit = *p.Limit
+	}
+	if exceeds(limit, a.maxLimit) {
+		writeError(w, apiErrBadRequest(ErrLimitTooLarge.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: FindDuplicates, table=%s, columns=%v, limit=%d, offset=%d", table, p.Columns, limit, p.Offset))
+
+	q := a.cached(db)
+	if !a.checkDuplicateColumns(ctx, w, q, table, p.Columns) {
+		return
+	}
+	primaryKey, err := getPrimaryKey(ctx, q, table)
+	if err != nil {
+		writeError(w, a.apiErr(err))
+		return
+	}
+	scope, err := a.rowScope(ctx, table)
+	if err != nil {
+		a.writeScopeError(w, err)
+		return
+	}
+
+	res, err := queryDuplicates(ctx, q, duplicatesQuery{table: table, columns: p.Columns, scope: scope}, primaryKey, limit, p.Offset)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error finding duplicates: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	for i := range res.Groups {
+		a.encodeRows([]map[string]interface{}{res.Groups[i].Values})
+		for j, key := range res.Groups[i].Keys {
+			res.Groups[i].Keys[j] = a.encodeValue(key)
+		}
+	}
+
+	json.NewEncoder(w).Encode(res)
+}
+
+func queryDuplicates(ctx context.Context, q queryer, d duplicatesQuery, primaryKey string, limit, offset int) (*FindDuplicatesResponse, error) {
+	res := &FindDuplicatesResponse{Groups: []DuplicateGroup{}}
+	where, args := d.where()
+	groups := fmt.Sprintf("SELECT %[3]s, count(*) AS n FROM %[1]s WHERE %[2]s GROUP BY %[3]s HAVING count(*) > 1", quoteIdent(d.table), where, d.groupBy())
+
+	query := fmt.Sprintf("SELECT count(*), coalesce(sum(n - 1), 0) FROM (%s)", groups)
+	if err := q.QueryRowContext(ctx, query, args...).Scan(&res.Total, &res.Duplicates); err != nil {
+		return nil, fmt.Errorf("error counting duplicates: %v", err)
+	}
+
+	query = fmt.Sprintf("%s ORDER BY n DESC, %s LIMIT ? OFFSET ?", groups, d.groupBy())
+	rows, err := q.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("error finding duplicates: %v", err)
+	}
+	defer rows.Close()
+
+	// The groups are matched with their rows by their values
+	byValues := map[string]int{}
+	var values []interface{}
+	for rows.Next() {
+		group := make([]interface{}, len(d.columns)+1)
+		dest := make([]interface{}, len(group))
+		for i := range group {
+			dest[i] = &group[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		g := DuplicateGroup{Values: map[string]interface{}{}, Count: group[len(d.columns)].(int64), Keys: []interface{}{}}
+		for i, c := range d.columns {
+			g.Values[c] = group[i]
+		}
+		byValues[fmt.Sprintf("%#v", group[:len(d.columns)])] = len(res.Groups)
+		values = append(values, group[:len(d.columns)]...)
+		res.Groups = append(res.Groups, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
+	if len(res.Groups) == 0 {
+		return res, nil
+	}
+
+	tuple := "(" + placeholders(len(d.columns)) + ")"
+	tuples := strings.TrimSuffix(strings.Repeat(tuple+", ", len(res.Groups)), ", ")
+	query = fmt.Sprintf("SELECT %s, %s FROM %s WHERE (%s) IN (VALUES %s) AND %s ORDER BY %[1]s",
+		quoteIdent(primaryKey), d.groupBy(), quoteIdent(d.table), d.groupBy(), tuples, where)
+	rows, err = q.QueryContext(ctx, query, append(values, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("error finding duplicates: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		row := make([]interface{}, len(d.columns)+1)
+		dest := make([]interface{}, len(row))
+		for i := range row {
+			dest[i] = &row[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		if i, ok := byValues[fmt.Sprintf("%#v", row[1:])]; ok {
+			res.Groups[i].Keys = append(res.Groups[i].Keys, row[0])
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
+	return res, nil
+}
+
+// deleteDuplicates deletes every row of the groups of rows sharing the same
+// values in the given columns but the first or the last one, like DeleteRows.
+func (a *Admin) deleteDuplicates(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	var p DeleteDuplicatesParams
+	if err := a.decodeParams(params, &p); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return
+	}
+	table := p.TableName
+
+	a.logger.Info(fmt.Sprintf("Command: DeleteDuplicates, table=%s, columns=%v, keep=%s", table, p.Columns, p.Keep))
+
+	if !a.checkDuplicateColumns(ctx, w, a.cached(db), table, p.Columns) {
+		return
+	}
+	scope, err := a.rowScope(ctx, table)
+	if err != nil {
+		a.writeScopeError(w, err)
+		return
+	}
+
+	tx, ok := a.beginMutation(ctx, w, db, table, params)
+	if !ok {
+		return
+	}
+	defer tx.Rollback()
+
+	primaryKey, err := getPrimaryKey(ctx, tx, table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error deleting duplicates: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	keys, err := duplicateKeys(ctx, tx, duplicatesQuery{table: table, columns: p.Columns, scope: scope}, primaryKey, p.Keep)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error deleting duplicates: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if exceeds(len(keys), a.maxDeleteIDs) {
+		writeError(w, apiErrBadRequest(ErrTooManyIds.Error()))
+		return
+	}
+	m := Mutation{Database: p.Database, Table: table, PrimaryKey: primaryKey, Keys: keys}
+	m.Old, err = getRowsByPrimaryKey(ctx, tx, table, primaryKey, keys)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error deleting duplicates: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if err := runBeforeHook(ctx, a.hooks.BeforeDelete, m); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+
+	deleted, err := batchDelete(ctx, tx, table, keys)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error deleting duplicates: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Deleted %d duplicate row(s)", deleted))
+	if deleted > 0 {
+		tx.afterCommit(ctx, func(ctx context.Context) {
+			a.afterMutation(ctx, a.hooks.AfterDelete, EventRowsDeleted, m)
+		})
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "rowsDeleted": deleted})
+}
+
+// duplicateKeys returns the primary keys of the duplicates to delete, every
+// row of each group but the one with the smallest or largest primary key.
+func duplicateKeys(ctx context.Context, q queryer, d duplicatesQuery, primaryKey, keep string) ([]interface{}, error) {
+	kept := "min"
+	if keep == KeepLast {
+		kept = "max"
+	}
+	where, args := d.where()
+	query := fmt.Sprintf("SELECT %[1]s FROM %[2]s WHERE %[3]s AND %[1]s NOT IN (SELECT %[4]s(%[1]s) FROM %[2]s WHERE %[3]s GROUP BY %[5]s)",
+		quoteIdent(primaryKey), quoteIdent(d.table), where, kept, d.groupBy())
+	rows, err := q.QueryContext(ctx, query, append(args, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("error finding duplicates: %v", err)
+	}
+	defer rows.Close()
+
+	keys := []interface{}{}
+	for rows.Next() {
+		var key interface{}
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
+	return keys, nil
+}
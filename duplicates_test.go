@@ -0,0 +1,106 @@
+package sqliteadmin_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuplicates(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	_, err := ts.db.Exec(`
+		CREATE TABLE contacts (id INTEGER PRIMARY KEY, email TEXT, name TEXT);
+		INSERT INTO contacts VALUES
+			(1, 'a@example.com', 'A'),
+			(2, 'b@example.com', 'B'),
+			(3, 'a@example.com', 'A'),
+			(4, 'a@example.com', 'Alice'),
+			(5, 'b@example.com', 'B'),
+			(6, NULL, 'C'),
+			(7, NULL, 'C');
+	`)
+	assert.NoError(t, err)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Success: Groups",
+			params:         map[string]interface{}{"tableName": "contacts", "columns": []interface{}{"email"}},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"groups": []interface{}{
+					map[string]interface{}{"values": map[string]interface{}{"email": "a@example.com"}, "count": float64(3), "keys": []interface{}{float64(1), float64(3), float64(4)}},
+					map[string]interface{}{"values": map[string]interface{}{"email": "b@example.com"}, "count": float64(2), "keys": []interface{}{float64(2), float64(5)}},
+				},
+				"total":      float64(2),
+				"duplicates": float64(3),
+			},
+		},
+		{
+			name:           "Success: Several Columns, Paged",
+			params:         map[string]interface{}{"tableName": "contacts", "columns": []interface{}{"email", "name"}, "limit": 1, "offset": 1},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"groups": []interface{}{
+					map[string]interface{}{"values": map[string]interface{}{"email": "b@example.com", "name": "B"}, "count": float64(2), "keys": []interface{}{float64(2), float64(5)}},
+				},
+				"total":      float64(2),
+				"duplicates": float64(2),
+			},
+		},
+		{
+			name:           "Failure: Unknown Column",
+			params:         map[string]interface{}{"tableName": "contacts", "columns": []interface{}{"phone"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "COLUMN_NOT_FOUND",
+				"message":    "Bad request: unknown column",
+				"detail":     "phone: unknown column",
+			},
+		},
+		{
+			name:           "Failure: Missing Columns",
+			params:         map[string]interface{}{"tableName": "contacts"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: missing column",
+			},
+		},
+	}, sqliteadmin.FindDuplicates, t, ts.server)
+
+	runTestCases([]TestCase{
+		{
+			name:             "Success: Keep Last",
+			params:           map[string]interface{}{"tableName": "contacts", "columns": []interface{}{"email"}, "keep": "last"},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok", "rowsDeleted": float64(3)},
+		},
+		{
+			name:           "Failure: Invalid Keep",
+			params:         map[string]interface{}{"tableName": "contacts", "columns": []interface{}{"email"}, "keep": "middle"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid input",
+			},
+		},
+	}, sqliteadmin.DeleteDuplicates, t, ts.server)
+
+	rows, err := ts.db.Query("SELECT id FROM contacts ORDER BY id")
+	assert.NoError(t, err)
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		var id int
+		assert.NoError(t, rows.Scan(&id))
+		ids = append(ids, id)
+	}
+	assert.Equal(t, []int{4, 5, 6, 7}, ids)
+}
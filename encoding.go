@@ -0,0 +1,51 @@
+package sqliteadmin
+
+import (
+	"strconv"
+	"time"
+)
+
+// IntegerEncoding is how the integers of rows that JavaScript numbers can't
+// represent exactly are encoded in responses.
+type IntegerEncoding string
+
+const (
+	// IntegersAsNumbers encodes all integers as JSON numbers, which clients
+	// decoding them as doubles round beyond MaxSafeInteger.
+	IntegersAsNumbers IntegerEncoding = "number"
+	// IntegersAsStrings encodes the integers beyond MaxSafeInteger as
+	// strings, e.g. for snowflake IDs. SQLite converts them back when they
+	// are sent for columns with an INTEGER affinity.
+	IntegersAsStrings IntegerEncoding = "string"
+)
+
+// MaxSafeInteger is the largest integer that a double, and so a JavaScript
+// number, represents exactly.
+const MaxSafeInteger = 1<<53 - 1
+
+// encodeRows converts the values of rows that encoding/json would encode
+// in a way that mangles them in clients, according to the Config.
+func (a *Admin) encodeRows(rows []map[string]interface{}) {
+	if a.largeIntegers != IntegersAsStrings && a.timeFormat == "" {
+		return
+	}
+	for _, row := range rows {
+		for column, value := range row {
+			row[column] = a.encodeValue(value)
+		}
+	}
+}
+
+func (a *Admin) encodeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int64:
+		if a.largeIntegers == IntegersAsStrings && (v > MaxSafeInteger || v < -MaxSafeInteger) {
+			return strconv.FormatInt(v, 10)
+		}
+	case time.Time:
+		if a.timeFormat != "" {
+			return v.Format(a.timeFormat)
+		}
+	}
+	return value
+}
@@ -0,0 +1,54 @@
+package sqliteadmin_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoding(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.LargeIntegers = sqliteadmin.IntegersAsStrings
+		c.TimeFormat = "2006-01-02 15:04"
+	})
+	defer close()
+
+	_, err := ts.db.Exec(`
+		CREATE TABLE messages (id INTEGER PRIMARY KEY, at DATETIME);
+		INSERT INTO messages VALUES (1234567890123456789, '2024-01-02 12:30:00'), (42, NULL);
+	`)
+	assert.NoError(t, err)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Success: Large Integers as Strings",
+			params:         map[string]interface{}{"tableName": "messages"},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"rows": []interface{}{
+					map[string]interface{}{"id": float64(42), "at": nil},
+					map[string]interface{}{"id": "1234567890123456789", "at": "2024-01-02 12:30"},
+				},
+			},
+		},
+	}, sqliteadmin.GetTable, t, ts.server)
+
+	// The strings are converted back to integers when sent for the row
+	runTestCases([]TestCase{
+		{
+			name: "Success: Update with Large Integer",
+			params: map[string]interface{}{
+				"tableName": "messages",
+				"row":       map[string]interface{}{"id": "1234567890123456789", "at": "2024-01-03 08:00:00"},
+			},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok"},
+		},
+	}, sqliteadmin.UpdateRow, t, ts.server)
+
+	var updated int
+	assert.NoError(t, ts.db.QueryRow("SELECT count(*) FROM messages WHERE id = 1234567890123456789 AND at = '2024-01-03 08:00:00'").Scan(&updated))
+	assert.Equal(t, 1, updated)
+}
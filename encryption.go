@@ -0,0 +1,250 @@
+package sqliteadmin
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SealedValue replaces the values of encrypted columns for callers that are
+// not allowed to unseal them.
+const SealedValue = "[ENCRYPTED]"
+
+// encryptedPrefix starts the values of encrypted columns, followed by the ID
+// of the key and the base64 encoded nonce and ciphertext, separated by ":".
+const encryptedPrefix = "enc:v1:"
+
+// KeyProvider provides the AES keys (16, 24 or 32 bytes) of encrypted columns.
+// Values are encrypted with the current key and decrypted with the key they
+// were encrypted with, which allows rotating keys.
+type KeyProvider interface {
+	CurrentKey() (id string, key []byte, err error)
+	Key(id string) ([]byte, error)
+}
+
+// StaticKeyProvider is a KeyProvider with a fixed set of keys.
+type StaticKeyProvider struct {
+	// Current is the ID of the key used to encrypt.
+	Current string
+	Keys    map[string][]byte
+}
+
+func (p *StaticKeyProvider) CurrentKey() (string, []byte, error) {
+	key, err := p.Key(p.Current)
+	return p.Current, key, err
+}
+
+func (p *StaticKeyProvider) Key(id string) ([]byte, error) {
+	key, ok := p.Keys[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %s", id)
+	}
+	return key, nil
+}
+
+// canUnseal reports whether the request was made by a user allowed to see
+// the decrypted values of encrypted columns.
+func canUnseal(ctx context.Context) bool {
+	unseal, _ := ctx.Value(unsealKey).(bool)
+	return unseal
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptValue encrypts the value of a column. The table and column are
+// authenticated so that values can't be moved to another column, with the
+// table lowercased since it can be named with any case.
+func (a *Admin) encryptValue(table, column string, value interface{}) (string, error) {
+	if a.keyProvider == nil {
+		return "", ErrMissingKeyProvider
+	}
+	id, key, err := a.keyProvider.CurrentKey()
+	if err != nil {
+		return "", fmt.Errorf("error getting encryption key: %v", err)
+	}
+	if strings.Contains(id, ":") {
+		return "", fmt.Errorf("invalid key id %q", id)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", fmt.Errorf("error creating cipher: %v", err)
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(fmt.Sprint(value)), []byte(strings.ToLower(table)+"."+column))
+	return encryptedPrefix + id + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptValue decrypts a value of a column, returning it as is if it isn't
+// encrypted, e.g. because it was written before the column was.
+func (a *Admin) decryptValue(table, column string, value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok || !strings.HasPrefix(s, encryptedPrefix) {
+		return value, nil
+	}
+	if a.keyProvider == nil {
+		return nil, ErrMissingKeyProvider
+	}
+	id, encoded, ok := strings.Cut(strings.TrimPrefix(s, encryptedPrefix), ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid encrypted value")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted value: %v", err)
+	}
+	key, err := a.keyProvider.Key(id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting encryption key: %v", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %v", err)
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid encrypted value")
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(strings.ToLower(table)+"."+column))
+	if err != nil && strings.ToLower(table) != table {
+		// Values encrypted before the table was lowercased
+		plain, err = gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(table+"."+column))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting value: %v", err)
+	}
+	return string(plain), nil
+}
+
+// unsealValue returns the decrypted value, or SealedValue if it can't be
+// decrypted.
+func (a *Admin) unsealValue(table, column string, value interface{}) interface{} {
+	plain, err := a.decryptValue(table, column, value)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error decrypting %s.%s: %v", table, column, err))
+		return SealedValue
+	}
+	return plain
+}
+
+// isCurrentlyEncrypted reports whether the value is encrypted with the key.
+func isCurrentlyEncrypted(value interface{}, keyID string) bool {
+	s, ok := value.(string)
+	return ok && strings.HasPrefix(s, encryptedPrefix+keyID+":")
+}
+
+// rotateEncryptionKeys re-encrypts the values of the encrypted columns that
+// are not encrypted with the current key, including the ones written before
+// the column was encrypted.
+func (a *Admin) rotateEncryptionKeys(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return
+	}
+	if !canUnseal(ctx) {
+		writeError(w, apiErrForbidden(ErrCannotUnseal.Error()))
+		return
+	}
+	if a.keyProvider == nil {
+		writeError(w, apiErrBadRequest(ErrMissingKeyProvider.Error()))
+		return
+	}
+
+	a.logger.Info("Command: RotateEncryptionKeys")
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error starting transaction: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	defer tx.Rollback()
+
+	rotated, err := a.reencrypt(ctx, tx)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error rotating encryption keys: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: re-encrypted %d value(s)", rotated))
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"rotated": rotated})
+}
+
+func (a *Admin) reencrypt(ctx context.Context, db queryer) (int, error) {
+	keyID, _, err := a.keyProvider.CurrentKey()
+	if err != nil {
+		return 0, fmt.Errorf("error getting encryption key: %v", err)
+	}
+	tables, err := getTableNames(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	rotated := 0
+	for _, table := range tables {
+		columns, err := getColumns(ctx, db, table)
+		if err != nil {
+			return 0, err
+		}
+		// Tables without a single column primary key are updated by rowid
+		key, keys := "rowid", 0
+		var names []string
+		for _, c := range columns {
+			names = append(names, c.Name)
+			if c.PK > 0 {
+				key = c.Name
+				keys++
+			}
+		}
+		if keys != 1 {
+			key = "rowid"
+		}
+
+		for column, p := range a.tablePolicies(table) {
+			if !p.Encrypt || !contains(names, column) {
+				continue
+			}
+			rows, err := queryRows(ctx, db, fmt.Sprintf("SELECT %s AS key, %s AS value FROM %s WHERE %s IS NOT NULL", quoteIdent(key), quoteIdent(column), quoteIdent(table), quoteIdent(column)))
+			if err != nil {
+				return 0, err
+			}
+			for _, row := range rows {
+				if isCurrentlyEncrypted(row["value"], keyID) {
+					continue
+				}
+				plain, err := a.decryptValue(table, column, row["value"])
+				if err != nil {
+					return 0, fmt.Errorf("error decrypting %s.%s: %v", table, column, err)
+				}
+				encrypted, err := a.encryptValue(table, column, plain)
+				if err != nil {
+					return 0, err
+				}
+				_, err = db.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET %s = ? WHERE %s = ?", quoteIdent(table), quoteIdent(column), quoteIdent(key)), encrypted, row["key"])
+				if err != nil {
+					return 0, fmt.Errorf("error updating %s.%s: %v", table, column, err)
+				}
+				rotated++
+			}
+		}
+	}
+	return rotated, nil
+}
@@ -0,0 +1,90 @@
+package sqliteadmin_test
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptedColumns(t *testing.T) {
+	keys := &sqliteadmin.StaticKeyProvider{
+		Current: "k1",
+		Keys:    map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)},
+	}
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.EncryptedColumns = []string{"users.email"}
+		c.KeyProvider = keys
+		c.CanUnseal = func(r *http.Request) bool {
+			return r.Header.Get("X-Unseal") == "yes"
+		}
+	})
+	defer close()
+
+	do := func(unseal bool, command sqliteadmin.Command, params map[string]interface{}) (int, map[string]interface{}) {
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: command, Params: params})
+		if unseal {
+			req.Header.Set("X-Unseal", "yes")
+		}
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res.StatusCode, readBody(t, res.Body)
+	}
+	email := func(unseal bool) interface{} {
+		_, body := do(unseal, sqliteadmin.GetTable, map[string]interface{}{"tableName": "users", "limit": 1})
+		return body["rows"].([]interface{})[0].(map[string]interface{})["email"]
+	}
+	stored := func() string {
+		var s string
+		err := ts.db.QueryRow("SELECT email FROM users WHERE id = 1").Scan(&s)
+		assert.NoError(t, err)
+		return s
+	}
+
+	status, _ := do(false, sqliteadmin.UpdateRow, map[string]interface{}{"tableName": "users", "row": map[string]interface{}{"id": 1, "email": "alice@example.com"}})
+	assert.Equal(t, http.StatusOK, status)
+	assert.True(t, strings.HasPrefix(stored(), "enc:v1:k1:"))
+	assert.NotContains(t, stored(), "alice")
+
+	assert.Equal(t, sqliteadmin.SealedValue, email(false))
+	assert.Equal(t, "alice@example.com", email(true))
+
+	// Sending the sealed value back leaves it unchanged
+	before := stored()
+	status, _ = do(false, sqliteadmin.UpdateRow, map[string]interface{}{"tableName": "users", "row": map[string]interface{}{"id": 1, "name": "Alicia", "email": sqliteadmin.SealedValue}})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, before, stored())
+
+	// Rotating re-encrypts the values with the new key, including the ones
+	// written before the column was encrypted
+	keys.Keys["k2"] = bytes.Repeat([]byte{2}, 32)
+	keys.Current = "k2"
+
+	status, body := do(false, sqliteadmin.RotateEncryptionKeys, map[string]interface{}{})
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, "Forbidden: not allowed to unseal encrypted columns", body["message"])
+
+	status, body = do(true, sqliteadmin.RotateEncryptionKeys, map[string]interface{}{})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, float64(8), body["rotated"])
+	assert.True(t, strings.HasPrefix(stored(), "enc:v1:k2:"))
+
+	delete(keys.Keys, "k1")
+	assert.Equal(t, "alice@example.com", email(true))
+
+	// Rows written with another case of the table name are encrypted too,
+	// and can be read back
+	status, body = do(false, sqliteadmin.InsertRow, map[string]interface{}{"tableName": "USERS", "row": map[string]interface{}{"name": "Mallory", "email": "mallory@example.com"}})
+	assert.Equal(t, http.StatusOK, status)
+	var s string
+	assert.NoError(t, ts.db.QueryRow("SELECT email FROM users WHERE name = 'Mallory'").Scan(&s))
+	assert.True(t, strings.HasPrefix(s, "enc:v1:k2:"))
+	_, body = do(true, sqliteadmin.GetTable, map[string]interface{}{"tableName": "users", "condition": sqliteadmin.Condition{
+		Cases:           []sqliteadmin.Case{sqliteadmin.Filter{Column: "name", Operator: sqliteadmin.OperatorEquals, Value: "Mallory"}},
+		LogicalOperator: sqliteadmin.LogicalOperatorAnd,
+	}})
+	assert.Equal(t, "mallory@example.com", body["rows"].([]interface{})[0].(map[string]interface{})["email"])
+}
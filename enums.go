@@ -0,0 +1,99 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	checkKeyword = regexp.MustCompile(`^(?i)CHECK\s*\(`)
+	// enumCheck matches the expression of a CHECK constraint of the form
+	// col IN ('a', 'b', 'c').
+	enumCheck = regexp.MustCompile(`^(?is)\s*("(?:[^"]|"")+"|` + "`[^`]+`" + `|\[[^\]]+\]|\w+)\s+IN\s*\(((?:\s*'(?:[^']|'')*'\s*,)*\s*'(?:[^']|'')*')\s*\)\s*$`)
+	enumValue = regexp.MustCompile(`'((?:[^']|'')*)'`)
+)
+
+// getEnumValues returns the values allowed by the CHECK constraints of the
+// table of the form col IN ('a', 'b', 'c'), by column.
+func getEnumValues(ctx context.Context, q queryer, table string) (map[string][]string, error) {
+	var createSQL sql.NullString
+	err := q.QueryRowContext(ctx, "SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&createSQL)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading table schema: %v", err)
+	}
+	return parseEnumChecks(createSQL.String), nil
+}
+
+// parseEnumChecks returns the values allowed by the enum CHECK constraints of
+// a CREATE TABLE statement, whether they are column or table constraints.
+func parseEnumChecks(createSQL string) map[string][]string {
+	enums := map[string][]string{}
+	sc := &sqlScanner{s: createSQL}
+	for c := sc.next(); c != 0; c = sc.next() {
+		start := sc.i - 1
+		if c != 'C' && c != 'c' || (start > 0 && isIdentChar(createSQL[start-1])) {
+			continue
+		}
+		loc := checkKeyword.FindStringIndex(createSQL[start:])
+		if loc == nil {
+			continue
+		}
+
+		// Find the parenthesis closing the expression
+		body := &sqlScanner{s: createSQL, i: start + loc[1], depth: 1}
+		for body.depth > 0 && body.next() != 0 {
+		}
+		if body.depth > 0 {
+			break
+		}
+		m := enumCheck.FindStringSubmatch(createSQL[start+loc[1] : body.i-1])
+		if m == nil {
+			continue
+		}
+		column := unquoteIdent(m[1])
+		var values []string
+		for _, v := range enumValue.FindAllStringSubmatch(m[2], -1) {
+			values = append(values, strings.ReplaceAll(v[1], "''", "'"))
+		}
+		enums[column] = values
+	}
+	return enums
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// unquoteIdent returns an identifier quoted in any of the ways SQLite
+// accepts as is.
+func unquoteIdent(ident string) string {
+	switch ident[0] {
+	case '"':
+		return strings.ReplaceAll(ident[1:len(ident)-1], `""`, `"`)
+	case '`', '[':
+		return ident[1 : len(ident)-1]
+	}
+	return ident
+}
+
+// checkEnums returns an error if a value of the row isn't one of the values
+// allowed for its column. NULLs pass CHECK constraints.
+func checkEnums(row map[string]interface{}, enums map[string][]string) error {
+	for column, value := range row {
+		values, ok := enums[column]
+		if !ok || value == nil {
+			continue
+		}
+		if !slices.Contains(values, fmt.Sprint(value)) {
+			return fmt.Errorf("%w: %s must be one of %s", ErrInvalidEnumValue, column, strings.Join(values, ", "))
+		}
+	}
+	return nil
+}
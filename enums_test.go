@@ -0,0 +1,66 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnumColumns(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	_, err := ts.db.Exec(`
+		CREATE TABLE posts (
+			id INTEGER PRIMARY KEY,
+			status TEXT NOT NULL CHECK (status IN ('draft', 'published', 'won''t publish')),
+			"kind" TEXT,
+			price INTEGER CHECK (price > 0),
+			note TEXT DEFAULT 'CHECK (note IN (''x''))',
+			CONSTRAINT kind_enum CHECK ("kind" in ('article','video'))
+		);
+		INSERT INTO posts (id, status, kind) VALUES (1, 'draft', 'video');
+	`)
+	assert.NoError(t, err)
+
+	res, err := ts.admin.GetTable(context.Background(), sqliteadmin.GetTableParams{TableName: "posts", IncludeInfo: true})
+	assert.NoError(t, err)
+	enums := map[string][]string{}
+	for _, c := range res.TableInfo.Columns {
+		if c.Enum != nil {
+			enums[c.Name] = c.Enum
+		}
+	}
+	assert.Equal(t, map[string][]string{
+		"status": {"draft", "published", "won't publish"},
+		"kind":   {"article", "video"},
+	}, enums)
+
+	runTestCases([]TestCase{
+		{
+			name: "Success: Allowed Value",
+			params: map[string]interface{}{
+				"tableName": "posts",
+				"row":       map[string]interface{}{"id": 1, "status": "won't publish", "kind": nil},
+			},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok"},
+		},
+		{
+			name: "Failure: Value Not Allowed",
+			params: map[string]interface{}{
+				"tableName": "posts",
+				"row":       map[string]interface{}{"id": 1, "kind": "podcast"},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: value not allowed: kind must be one of article, video",
+			},
+		},
+	}, sqliteadmin.UpdateRow, t, ts.server)
+}
@@ -1,21 +1,175 @@
 package sqliteadmin
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
+	"strings"
 )
 
 var (
-	ErrMissingTableName    = errors.New("missing table name")
-	ErrMissingRow          = errors.New("missing row")
-	ErrInvalidOrMissingIds = errors.New("invalid or missing ids")
-	ErrInvalidInput        = errors.New("invalid input")
+	ErrMissingTableName         = errors.New("missing table name")
+	ErrMissingRow               = errors.New("missing row")
+	ErrInvalidOrMissingIds      = errors.New("invalid or missing ids")
+	ErrInvalidInput             = errors.New("invalid input")
+	ErrMissingAction            = errors.New("missing action")
+	ErrUnknownAction            = errors.New("unknown action")
+	ErrMissingDatabase          = errors.New("missing database")
+	ErrUnknownDatabase          = errors.New("unknown database")
+	ErrMissingOperation         = errors.New("missing operation")
+	ErrUnknownOperation         = errors.New("unknown operation")
+	ErrMissingOperationID       = errors.New("missing operation id")
+	ErrUnknownOperationID       = errors.New("unknown or expired operation id")
+	ErrOperationStepsCompleted  = errors.New("all operation steps have already been completed")
+	ErrOperationStepsIncomplete = errors.New("operation has incomplete steps")
+	ErrInvalidOrderBy           = errors.New("invalid orderBy")
+	ErrTooManyIds               = errors.New("too many ids")
+	ErrLimitTooLarge            = errors.New("limit too large")
+	ErrConditionTooDeep         = errors.New("condition nested too deeply")
+	ErrMissingSubscriptionID    = errors.New("missing subscription id")
+	ErrUnknownSubscriptionID    = errors.New("unknown subscription id")
+	ErrInvalidCheckpoint        = errors.New("invalid checkpoint")
+	ErrMissingColumn            = errors.New("missing column")
+	ErrMissingURL               = errors.New("missing url")
+	ErrMissingWebhookID         = errors.New("missing webhook id")
+	ErrUnknownWebhookID         = errors.New("unknown webhook id")
+	ErrMissingConstraintName    = errors.New("missing constraint name")
+	ErrInvalidExpression        = errors.New("invalid expression")
+	ErrConstraintExists         = errors.New("constraint already exists")
+	ErrUnknownConstraint        = errors.New("unknown constraint")
+	ErrCheckConstraintViolated  = errors.New("existing rows violate the check constraint")
+	ErrInvalidTrigger           = errors.New("invalid trigger")
+	ErrMissingTriggerName       = errors.New("missing trigger name")
+	ErrUnknownTrigger           = errors.New("unknown trigger")
+	ErrNoFlagsTable             = errors.New("no feature flags table found")
+	ErrMissingFlagName          = errors.New("missing flag name")
+	ErrUnknownFlag              = errors.New("unknown flag")
+	ErrInvalidFlagValue         = errors.New("invalid flag value")
+	ErrTableExists              = errors.New("table already exists")
+	ErrMissingSavedQueryName    = errors.New("missing saved query name")
+	ErrUnknownSavedQuery        = errors.New("unknown saved query")
+	ErrSavedQueryExists         = errors.New("saved query already exists")
+	ErrSecretDetected           = errors.New("value looks like a secret")
+	ErrValueNotAllowed          = errors.New("value not allowed")
+	ErrNotArchive               = errors.New("table is not an SQLite archive")
+	ErrMissingFileName          = errors.New("missing file name")
+	ErrUnknownFile              = errors.New("unknown file")
+	ErrOutOfScope               = errors.New("rows are out of scope")
+	ErrMissingKeyProvider       = errors.New("no key provider configured for encrypted columns")
+	ErrCannotUnseal             = errors.New("not allowed to unseal encrypted columns")
+	ErrSessionsDisabled         = errors.New("sessions are disabled")
+	ErrBearerSession            = errors.New("sessions can only be started with the credentials of a user")
+	ErrMaskedColumn             = errors.New("masked columns can only be updated by elevated users")
+	ErrInvalidRow               = errors.New("invalid row")
+	ErrInvalidEnumValue         = errors.New("value not allowed")
+	ErrHiddenColumn             = errors.New("cannot filter on a redacted or anonymized column")
+	ErrInvalidVersion           = errors.New("invalid version")
+	ErrLongPollUnsupported      = errors.New("waiting for changes requires a database with more than one connection")
+	ErrTransactionsUnsupported  = errors.New("transactions require a database with more than one connection")
+	ErrMissingTransactionID     = errors.New("missing transaction id")
+	ErrUnknownTransaction       = errors.New("unknown or expired transaction")
+	ErrTransactionDatabase      = errors.New("transaction belongs to another database")
+	ErrCopyHiddenColumn         = errors.New("cannot copy a redacted, masked, encrypted or anonymized column")
+	ErrDiffHiddenColumn         = errors.New("cannot diff a redacted, masked, encrypted, anonymized or transformed column")
+	ErrAttachUnsupported        = errors.New("only databases stored in a file can be joined with another database")
+	ErrFixturesDisabled         = errors.New("fixtures can only be seeded in development mode")
+	ErrMissingFixture           = errors.New("missing fixture")
+	ErrUnknownFixture           = errors.New("unknown fixture")
+	ErrInvalidFixture           = errors.New("invalid fixture")
+	ErrInvalidConfirmation      = errors.New("invalid or expired confirmation token")
+	ErrReadOnlyDatabase         = errors.New("database is read-only")
+	ErrDatabaseBusy             = errors.New("database is locked by another connection, retry later")
+	ErrUnknownTable             = errors.New("unknown table")
+	ErrNoPrimaryKey             = errors.New("table does not have a primary key")
+	ErrConstraintViolated       = errors.New("a constraint of the table was violated")
+	ErrQueryTimeout             = errors.New("query timed out")
+	ErrUnknownColumn            = errors.New("unknown column")
+	ErrInvalidCondition         = errors.New("Invalid condition")
+	ErrStrictType               = errors.New("value does not match the type of the column")
+	ErrSequencesDisabled        = errors.New("sequences can only be reset in development mode")
+	ErrNoSequence               = errors.New("table does not use AUTOINCREMENT")
+	ErrSequenceTooLow           = errors.New("sequence cannot be lower than the largest rowid of the table")
+	ErrIncrementalVacuumOff     = errors.New("incremental vacuum requires auto_vacuum = INCREMENTAL")
+	ErrInvalidArtifactToken     = errors.New("invalid or expired download link")
+	ErrWriteQueueTimeout        = errors.New("timed out waiting for other writes, retry later")
+	ErrMissingKey               = errors.New("missing primary key")
+	ErrPrimaryKeyCell           = errors.New("the primary key of a row cannot be updated")
+	ErrMissingValue             = errors.New("missing value of column")
+	ErrSeedingDisabled          = errors.New("tables can only be seeded in development mode")
+	ErrNoParentRows             = errors.New("no rows to reference in table")
+	ErrAnonymizationDisabled    = errors.New("tables can only be anonymized in development mode")
+	ErrUnknownStrategy          = errors.New("unknown anonymization strategy")
+	ErrCloneHiddenColumn        = errors.New("redacted, masked, encrypted or anonymized columns must be anonymized in the clone")
+	ErrMissingJobID             = errors.New("missing job id")
+	ErrUnknownJobID             = errors.New("unknown or expired job id")
+	ErrInvalidKey               = errors.New("invalid key")
+	ErrInvalidGeometry          = errors.New("invalid GeoJSON geometry")
+	ErrShadowTable              = errors.New("shadow tables can't be edited directly, edit their virtual table instead")
+	ErrRoleNotAllowed           = errors.New("command not allowed for the role")
+	ErrActionNotAllowed         = errors.New("action not allowed for the role")
+	ErrInvalidToken             = errors.New("invalid token")
+	ErrAPIKeyNotAllowed         = errors.New("command not allowed for the API key")
+	ErrUnknownAPIKey            = errors.New("unknown API key")
+	ErrMissingAPIKeyName        = errors.New("missing API key name")
+	ErrMissingAPIKeyID          = errors.New("missing API key id")
+	ErrMergeSameRow             = errors.New("cannot merge a row into itself")
+)
+
+// ErrorCode identifies the kind of error of an API response, so that clients
+// can branch on it rather than on the message.
+type ErrorCode string
+
+const (
+	CodeBadRequest          ErrorCode = "BAD_REQUEST"
+	CodeUnauthorized        ErrorCode = "UNAUTHORIZED"
+	CodeForbidden           ErrorCode = "FORBIDDEN"
+	CodeNotFound            ErrorCode = "NOT_FOUND"
+	CodeRequestTooLarge     ErrorCode = "REQUEST_TOO_LARGE"
+	CodeRateLimited         ErrorCode = "RATE_LIMITED"
+	CodeTableNotFound       ErrorCode = "TABLE_NOT_FOUND"
+	CodeColumnNotFound      ErrorCode = "COLUMN_NOT_FOUND"
+	CodeNoPrimaryKey        ErrorCode = "NO_PRIMARY_KEY"
+	CodeConstraintViolation ErrorCode = "CONSTRAINT_VIOLATION"
+	CodeReadOnly            ErrorCode = "READONLY"
+	CodeDatabaseBusy        ErrorCode = "DATABASE_BUSY"
+	CodeTimeout             ErrorCode = "TIMEOUT"
+	CodeInternal            ErrorCode = "INTERNAL"
 )
 
 type APIError struct {
-	StatusCode int    `json:"statusCode"`
-	Message    string `json:"message"`
+	StatusCode int       `json:"statusCode"`
+	Code       ErrorCode `json:"code"`
+	Message    string    `json:"message"`
+	// Detail is the error reported by the database, only included with
+	// Config.ShowErrorDetails.
+	Detail string `json:"detail,omitempty"`
+	// Constraint tells which constraint a write violated, so that the
+	// offending fields can be highlighted. It is hidden along with Detail.
+	Constraint *ConstraintViolation `json:"constraint,omitempty"`
+	RequestID  string               `json:"requestId,omitempty"`
+}
+
+// ConstraintType is the kind of constraint of a ConstraintViolation.
+type ConstraintType string
+
+const (
+	ConstraintUnique     ConstraintType = "UNIQUE"
+	ConstraintNotNull    ConstraintType = "NOT_NULL"
+	ConstraintCheck      ConstraintType = "CHECK"
+	ConstraintForeignKey ConstraintType = "FOREIGN_KEY"
+	ConstraintPrimaryKey ConstraintType = "PRIMARY_KEY"
+)
+
+type ConstraintViolation struct {
+	Type  ConstraintType `json:"type"`
+	Table string         `json:"table,omitempty"`
+	// Columns are the columns of UNIQUE and NOT NULL constraints. SQLite
+	// doesn't report the columns of CHECK and FOREIGN KEY constraints.
+	Columns []string `json:"columns,omitempty"`
+	// Name is the name of a CHECK constraint, or its expression if unnamed.
+	Name string `json:"name,omitempty"`
 }
 
 func (e APIError) Error() string {
@@ -23,13 +177,101 @@ func (e APIError) Error() string {
 }
 
 func apiErrUnauthorized() APIError {
-	return APIError{StatusCode: http.StatusUnauthorized, Message: "Invalid credentials"}
+	return APIError{StatusCode: http.StatusUnauthorized, Code: CodeUnauthorized, Message: "Invalid credentials"}
+}
+
+func apiErrForbidden(details string) APIError {
+	return APIError{StatusCode: http.StatusForbidden, Code: CodeForbidden, Message: "Forbidden: " + details}
 }
 
 func apiErrBadRequest(details string) APIError {
-	return APIError{StatusCode: http.StatusBadRequest, Message: "Bad request: " + details}
+	return APIError{StatusCode: http.StatusBadRequest, Code: CodeBadRequest, Message: "Bad request: " + details}
+}
+
+func apiErrTableNotFound() APIError {
+	return APIError{StatusCode: http.StatusBadRequest, Code: CodeTableNotFound, Message: "Bad request: " + ErrUnknownTable.Error()}
 }
 
 func apiErrSomethingWentWrong() APIError {
-	return APIError{StatusCode: http.StatusInternalServerError, Message: "Something went wrong"}
+	return APIError{StatusCode: http.StatusInternalServerError, Code: CodeInternal, Message: "Something went wrong"}
+}
+
+func apiErrRequestTooLarge() APIError {
+	return APIError{StatusCode: http.StatusRequestEntityTooLarge, Code: CodeRequestTooLarge, Message: "Request body too large"}
+}
+
+func apiErrNotFound() APIError {
+	return APIError{StatusCode: http.StatusNotFound, Code: CodeNotFound, Message: "Not found"}
+}
+
+func apiErrTooManyRequests() APIError {
+	return APIError{StatusCode: http.StatusTooManyRequests, Code: CodeRateLimited, Message: "Too many requests"}
+}
+
+// sqliteResultCode matches the result codes appended to the messages of
+// SQLite errors, e.g. "(2067)" or "(SQLITE_BUSY)".
+var sqliteResultCode = regexp.MustCompile(`\s*\((\d+|SQLITE_\w+)\)`)
+
+// sqliteConstraint matches the constraint errors of SQLite, e.g.
+// "UNIQUE constraint failed: users.email" or "FOREIGN KEY constraint failed".
+var sqliteConstraint = regexp.MustCompile(`(UNIQUE|NOT NULL|CHECK|FOREIGN KEY|PRIMARY KEY) constraint failed(?:: (.+))?`)
+
+// parseConstraintViolation returns the constraint named in the message of a
+// constraint error, or nil if it doesn't name one.
+func parseConstraintViolation(msg string) *ConstraintViolation {
+	m := sqliteConstraint.FindStringSubmatch(msg)
+	if m == nil {
+		return nil
+	}
+	c := &ConstraintViolation{Type: ConstraintType(strings.ReplaceAll(m[1], " ", "_"))}
+	switch c.Type {
+	case ConstraintUnique, ConstraintNotNull, ConstraintPrimaryKey:
+		// The columns are listed as "table.column, table.column"
+		for _, col := range strings.Split(m[2], ", ") {
+			table, column, ok := strings.Cut(col, ".")
+			if !ok {
+				continue
+			}
+			c.Table = table
+			c.Columns = append(c.Columns, column)
+		}
+	case ConstraintCheck:
+		c.Name = m[2]
+	}
+	return c
+}
+
+// apiErr is the response to an unexpected error, e.g. returned by the
+// database, with a code telling what went wrong and, unless hidden, the
+// message of the error without the SQLite result codes.
+func (a *Admin) apiErr(err error) APIError {
+	msg := err.Error()
+	var e APIError
+	switch {
+	case errors.Is(err, ErrNoPrimaryKey):
+		e = APIError{StatusCode: http.StatusBadRequest, Code: CodeNoPrimaryKey, Message: "Bad request: " + ErrNoPrimaryKey.Error()}
+	case errors.Is(err, ErrReadOnlyDatabase) || strings.Contains(msg, "readonly"):
+		e = APIError{StatusCode: http.StatusForbidden, Code: CodeReadOnly, Message: "Forbidden: " + ErrReadOnlyDatabase.Error()}
+	case isBusy(err):
+		e = APIError{StatusCode: http.StatusConflict, Code: CodeDatabaseBusy, Message: "Conflict: " + ErrDatabaseBusy.Error()}
+	case errors.Is(err, ErrWriteQueueTimeout):
+		e = APIError{StatusCode: http.StatusConflict, Code: CodeDatabaseBusy, Message: "Conflict: " + ErrWriteQueueTimeout.Error()}
+	case strings.Contains(msg, "constraint failed"):
+		e = APIError{StatusCode: http.StatusConflict, Code: CodeConstraintViolation, Message: "Conflict: " + ErrConstraintViolated.Error()}
+	case errors.Is(err, ErrUnknownTable) || strings.Contains(msg, "no such table"):
+		e = apiErrTableNotFound()
+	case errors.Is(err, ErrUnknownColumn) || strings.Contains(msg, "no such column"):
+		e = APIError{StatusCode: http.StatusBadRequest, Code: CodeColumnNotFound, Message: "Bad request: " + ErrUnknownColumn.Error()}
+	case errors.Is(err, context.DeadlineExceeded) || strings.Contains(msg, "interrupted"):
+		e = APIError{StatusCode: http.StatusGatewayTimeout, Code: CodeTimeout, Message: ErrQueryTimeout.Error()}
+	default:
+		e = apiErrSomethingWentWrong()
+	}
+	if a.showDetails {
+		e.Detail = sqliteResultCode.ReplaceAllString(msg, "")
+		if e.Code == CodeConstraintViolation {
+			e.Constraint = parseConstraintViolation(e.Detail)
+		}
+	}
+	return e
 }
@@ -0,0 +1,149 @@
+package sqliteadmin_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCodes(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	_, err := ts.db.Exec(`
+		CREATE TABLE events (name TEXT);
+		CREATE TABLE accounts (
+			id INTEGER PRIMARY KEY,
+			email TEXT UNIQUE,
+			balance INTEGER CONSTRAINT positive_balance CHECK (balance >= 0)
+		);
+		INSERT INTO accounts (email, balance) VALUES ('a@example.com', 1), ('b@example.com', 1);
+	`)
+	assert.NoError(t, err)
+
+	runTestCases([]TestCase{
+		{
+			name: "Failure: Constraint Violation",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"row":       map[string]interface{}{"id": 1, "name": nil},
+			},
+			expectedStatus: http.StatusConflict,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusConflict),
+				"code":       "CONSTRAINT_VIOLATION",
+				"message":    "Conflict: " + sqliteadmin.ErrConstraintViolated.Error(),
+				"detail":     "edit row failed: constraint failed: NOT NULL constraint failed: users.name",
+				"constraint": map[string]interface{}{
+					"type":    "NOT_NULL",
+					"table":   "users",
+					"columns": []interface{}{"name"},
+				},
+			},
+		},
+		{
+			name: "Failure: Unique Constraint Violation",
+			params: map[string]interface{}{
+				"tableName": "accounts",
+				"row":       map[string]interface{}{"id": 2, "email": "a@example.com"},
+			},
+			expectedStatus: http.StatusConflict,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusConflict),
+				"code":       "CONSTRAINT_VIOLATION",
+				"message":    "Conflict: " + sqliteadmin.ErrConstraintViolated.Error(),
+				"detail":     "edit row failed: constraint failed: UNIQUE constraint failed: accounts.email",
+				"constraint": map[string]interface{}{
+					"type":    "UNIQUE",
+					"table":   "accounts",
+					"columns": []interface{}{"email"},
+				},
+			},
+		},
+		{
+			name: "Failure: Check Constraint Violation",
+			params: map[string]interface{}{
+				"tableName": "accounts",
+				"row":       map[string]interface{}{"id": 2, "balance": -1},
+			},
+			expectedStatus: http.StatusConflict,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusConflict),
+				"code":       "CONSTRAINT_VIOLATION",
+				"message":    "Conflict: " + sqliteadmin.ErrConstraintViolated.Error(),
+				"detail":     "edit row failed: constraint failed: CHECK constraint failed: positive_balance",
+				"constraint": map[string]interface{}{
+					"type": "CHECK",
+					"name": "positive_balance",
+				},
+			},
+		},
+		{
+			name: "Failure: No Primary Key",
+			params: map[string]interface{}{
+				"tableName": "events",
+				"row":       map[string]interface{}{"name": "signup"},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "NO_PRIMARY_KEY",
+				"message":    "Bad request: " + sqliteadmin.ErrNoPrimaryKey.Error(),
+				"detail":     "events: table does not have a primary key",
+			},
+		},
+		{
+			name: "Failure: Table Named With Another Case",
+			params: map[string]interface{}{
+				"tableName": "USERS",
+				"row":       map[string]interface{}{"id": 1, "name": "Alicia"},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "TABLE_NOT_FOUND",
+				"message":    "Bad request: unknown table",
+				"detail":     "error getting primary key for edit: USERS: unknown table",
+			},
+		},
+	}, sqliteadmin.UpdateRow, t, ts.server)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Table Named With Another Case",
+			params:         map[string]interface{}{"tableName": "USERS", "key": 1, "column": "name", "value": "Alicia"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "TABLE_NOT_FOUND",
+				"message":    "Bad request: unknown table",
+				"detail":     "error getting primary key for edit: USERS: unknown table",
+			},
+		},
+	}, sqliteadmin.UpdateCell, t, ts.server)
+}
+
+func TestErrorDetailsHiddenByDefault(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.ShowErrorDetails = false
+	})
+	defer close()
+
+	runTestCases([]TestCase{
+		{
+			name: "Failure: Constraint Violation",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"row":       map[string]interface{}{"id": 1, "name": nil},
+			},
+			expectedStatus: http.StatusConflict,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusConflict),
+				"code":       "CONSTRAINT_VIOLATION",
+				"message":    "Conflict: " + sqliteadmin.ErrConstraintViolated.Error(),
+			},
+		},
+	}, sqliteadmin.UpdateRow, t, ts.server)
+}
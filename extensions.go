@@ -0,0 +1,130 @@
+package sqliteadmin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ListExtensionsParams are the params of ListExtensions.
+type ListExtensionsParams struct {
+	Database string `json:"database,omitempty" mapstructure:"database"`
+}
+
+func (p *ListExtensionsParams) validate() error {
+	return nil
+}
+
+// Extension is a SQLite extension found on the connections of a database,
+// either compiled into SQLite or loaded by the driver.
+type Extension struct {
+	Name string `json:"name"`
+	// Functions and Modules are the SQL functions and virtual table modules
+	// of the extension by which it was found.
+	Functions []string `json:"functions,omitempty"`
+	Modules   []string `json:"modules,omitempty"`
+}
+
+// knownExtensions are the extensions ListExtensions looks for, with a few of
+// their functions and modules. SQLite can't tell which extensions are loaded,
+// only which functions and modules are registered.
+var knownExtensions = []Extension{
+	{Name: "json", Functions: []string{"json", "json_extract", "jsonb"}},
+	{Name: "math", Functions: []string{"acos", "ln", "pow", "sqrt"}},
+	{Name: "fts5", Modules: []string{"fts5", "fts5vocab"}},
+	{Name: "fts4", Modules: []string{"fts3", "fts4"}},
+	{Name: "rtree", Modules: []string{"rtree", "rtree_i32"}},
+	{Name: "geopoly", Functions: []string{"geopoly_json", "geopoly_area"}, Modules: []string{"geopoly"}},
+	{Name: "dbstat", Modules: []string{"dbstat"}},
+	{Name: "spatialite", Functions: []string{"spatialite_version", "asgeojson", "geomfromgeojson"}, Modules: []string{"virtualspatialindex"}},
+	{Name: "sqlean", Functions: []string{"sqlean_version"}},
+	{Name: "sqlean-crypto", Functions: []string{"crypto_md5", "crypto_sha256", "crypto_encode"}},
+	{Name: "sqlean-define", Functions: []string{"define", "eval"}, Modules: []string{"define"}},
+	{Name: "sqlean-fileio", Functions: []string{"fileio_read", "fileio_write"}, Modules: []string{"fileio_ls"}},
+	{Name: "sqlean-fuzzy", Functions: []string{"fuzzy_damlev", "fuzzy_leven", "fuzzy_soundex"}},
+	{Name: "sqlean-ipaddr", Functions: []string{"ipfamily", "iphost", "ipcontains"}},
+	{Name: "sqlean-regexp", Functions: []string{"regexp_like", "regexp_substr", "regexp_replace"}},
+	{Name: "sqlean-stats", Functions: []string{"stats_median", "stats_perc"}},
+	{Name: "sqlean-text", Functions: []string{"text_substring", "text_split", "text_concat"}},
+	{Name: "sqlean-time", Functions: []string{"time_now", "time_unix", "time_fmt_iso"}},
+	{Name: "sqlean-uuid", Functions: []string{"uuid4", "uuid7"}},
+	{Name: "sqlean-vsv", Modules: []string{"vsv"}},
+}
+
+// listExtensions lists the known extensions available on the database, e.g.
+// so that the UI can offer a map for spatial tables when SpatiaLite is
+// loaded.
+func (a *Admin) listExtensions(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	var p ListExtensionsParams
+	if err := a.decodeParams(params, &p); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	db, ok := a.getReadDB(w, params)
+	if !ok {
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: ListExtensions, database=%s", p.Database))
+
+	extensions, err := getExtensions(ctx, a.cached(db))
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error listing extensions: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"extensions": extensions})
+}
+
+// getExtensions returns the known extensions with at least one of their
+// functions or modules registered on the connection.
+func getExtensions(ctx context.Context, q queryer) ([]Extension, error) {
+	// Function names are case insensitive and listed in lower case
+	functions, err := queryNames(ctx, q, "SELECT DISTINCT lower(name) FROM pragma_function_list")
+	if err != nil {
+		return nil, fmt.Errorf("error listing functions: %v", err)
+	}
+	modules, err := queryNames(ctx, q, "SELECT DISTINCT lower(name) FROM pragma_module_list")
+	if err != nil {
+		return nil, fmt.Errorf("error listing modules: %v", err)
+	}
+
+	extensions := []Extension{}
+	for _, known := range knownExtensions {
+		e := Extension{Name: known.Name}
+		for _, f := range known.Functions {
+			if functions[f] {
+				e.Functions = append(e.Functions, f)
+			}
+		}
+		for _, m := range known.Modules {
+			if modules[m] {
+				e.Modules = append(e.Modules, m)
+			}
+		}
+		if len(e.Functions) > 0 || len(e.Modules) > 0 {
+			extensions = append(extensions, e)
+		}
+	}
+	return extensions, nil
+}
+
+// queryNames returns the set of the values of the single column of a query.
+func queryNames(ctx context.Context, q queryer, query string) (map[string]bool, error) {
+	rows, err := q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names[name] = true
+	}
+	return names, rows.Err()
+}
@@ -0,0 +1,44 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+	"modernc.org/sqlite"
+)
+
+// registerUUID registers a function standing for the one of an extension
+// loaded by the driver. Functions are registered for the whole process.
+var registerUUID sync.Once
+
+func TestListExtensions(t *testing.T) {
+	registerUUID.Do(func() {
+		err := sqlite.RegisterScalarFunction("uuid4", 0, func(*sqlite.FunctionContext, []driver.Value) (driver.Value, error) {
+			return "a3c1b5e2-8f6d-4a7b-9c0e-1d2f3a4b5c6d", nil
+		})
+		assert.NoError(t, err)
+	})
+
+	ts, close := setupTestServer(t)
+	defer close()
+
+	extensions, err := ts.admin.ListExtensions(context.Background(), sqliteadmin.ListExtensionsParams{})
+	assert.NoError(t, err)
+	names := map[string]sqliteadmin.Extension{}
+	for _, e := range extensions {
+		names[e.Name] = e
+	}
+	// Compiled into the default driver
+	assert.Contains(t, names, "fts5")
+	assert.Contains(t, names, "rtree")
+	assert.Equal(t, sqliteadmin.Extension{
+		Name:      "geopoly",
+		Functions: []string{"geopoly_json", "geopoly_area"},
+		Modules:   []string{"geopoly"},
+	}, names["geopoly"])
+	assert.Equal(t, []string{"uuid4"}, names["sqlean-uuid"].Functions)
+}
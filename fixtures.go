@@ -0,0 +1,177 @@
+package sqliteadmin
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+)
+
+// Values of the "mode" param of SeedFixtures.
+const (
+	// fixtureModeReplace deletes the rows of the tables of the fixture set
+	// before loading it.
+	fixtureModeReplace = "replace"
+	// fixtureModeMerge loads the fixture set over the existing rows,
+	// replacing the ones with the same key.
+	fixtureModeMerge = "merge"
+)
+
+// FixtureTable is the rows of a table in a fixture set. A fixture set is a
+// JSON file holding an array of them, which are loaded in order so that
+// referenced tables can be listed first, e.g.
+//
+//	[{"tableName": "users", "rows": [{"id": 1, "name": "Alice"}]}]
+type FixtureTable struct {
+	TableName string                   `json:"tableName"`
+	Rows      []map[string]interface{} `json:"rows"`
+}
+
+// seedFixtures loads the fixture set named by the "fixture" param from
+// Config.Fixtures into the database, in a single transaction. The rows are
+// written as is, without going through the column policies, validators or
+// hooks, so it is only available in development mode.
+func (a *Admin) seedFixtures(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	if !a.development || a.fixtures == nil {
+		writeError(w, apiErrForbidden(ErrFixturesDisabled.Error()))
+		return
+	}
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return
+	}
+	name, ok := params["fixture"].(string)
+	if !ok || name == "" {
+		writeError(w, apiErrBadRequest(ErrMissingFixture.Error()))
+		return
+	}
+	mode, _ := params["mode"].(string)
+	if mode == "" {
+		mode = fixtureModeReplace
+	}
+	if mode != fixtureModeReplace && mode != fixtureModeMerge {
+		writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: SeedFixtures, fixture=%s, mode=%s", name, mode))
+
+	tables, err := loadFixture(a.fixtures, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		writeError(w, apiErrBadRequest(ErrUnknownFixture.Error()))
+		return
+	}
+	if err != nil {
+		writeError(w, apiErrBadRequest(fmt.Sprintf("%s: %v", ErrInvalidFixture, err)))
+		return
+	}
+	for _, t := range tables {
+		exists, err := checkTableExists(ctx, a.cached(db), t.TableName)
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Error checking table existence: %v", err))
+			writeError(w, a.apiErr(err))
+			return
+		}
+		if !exists || hasInternalPrefix(t.TableName) {
+			writeError(w, apiErrBadRequest(fmt.Sprintf("%s: unknown table %s", ErrInvalidFixture, t.TableName)))
+			return
+		}
+	}
+
+	inserted, err := a.loadFixtureTables(ctx, db, tables, mode)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error seeding fixtures: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: seeded fixture %s (%s), %d row(s) inserted", name, mode, inserted))
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "rowsInserted": inserted})
+}
+
+// loadFixture reads and decodes the fixture set with the given name.
+func loadFixture(fsys fs.FS, name string) ([]FixtureTable, error) {
+	file := name + ".json"
+	if !fs.ValidPath(file) {
+		return nil, fs.ErrNotExist
+	}
+	b, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, err
+	}
+
+	// Numbers are kept as is so that large integers don't lose precision
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var tables []FixtureTable
+	if err := d.Decode(&tables); err != nil {
+		return nil, err
+	}
+	for _, t := range tables {
+		if t.TableName == "" {
+			return nil, errors.New("missing table name")
+		}
+		for _, row := range t.Rows {
+			for column, value := range row {
+				if n, ok := value.(json.Number); ok {
+					row[column] = fixtureNumber(n)
+				}
+			}
+		}
+	}
+	return tables, nil
+}
+
+func fixtureNumber(n json.Number) interface{} {
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	f, _ := n.Float64()
+	return f
+}
+
+// loadFixtureTables writes the rows of the tables in a transaction and
+// returns how many were inserted.
+func (a *Admin) loadFixtureTables(ctx context.Context, db *sql.DB, tables []FixtureTable, mode string) (int, error) {
+	tx, err := a.beginImmediateTx(ctx, db)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	insert := "INSERT"
+	if mode == fixtureModeMerge {
+		insert = "INSERT OR REPLACE"
+	} else {
+		// Tables are emptied in reverse order so that referencing tables
+		// listed after the tables they reference are emptied first
+		for i := len(tables) - 1; i >= 0; i-- {
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s", quoteIdent(tables[i].TableName))); err != nil {
+				return 0, fmt.Errorf("error emptying %s: %v", tables[i].TableName, err)
+			}
+			if err := resetTableSequence(ctx, tx, tables[i].TableName); err != nil {
+				return 0, fmt.Errorf("error resetting the sequence of %s: %v", tables[i].TableName, err)
+			}
+		}
+	}
+
+	inserted := 0
+	for _, t := range tables {
+		for _, row := range t.Rows {
+			rows, err := insertRow(ctx, tx, insert, t.TableName, row)
+			if err != nil {
+				return 0, fmt.Errorf("error inserting into %s: %v", t.TableName, err)
+			}
+			inserted += len(rows)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing fixtures: %v", err)
+	}
+	return inserted, nil
+}
@@ -0,0 +1,118 @@
+package sqliteadmin_test
+
+import (
+	"net/http"
+	"testing"
+	"testing/fstest"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeedFixtures(t *testing.T) {
+	fixtures := fstest.MapFS{
+		"demo.json": {Data: []byte(`[
+			{"tableName": "users", "rows": [{"id": 1, "name": "Zed", "email": "zed@gmail.com"}]},
+			{"tableName": "posts", "rows": [{"title": "Hello", "user_id": 1}]}
+		]`)},
+		"extra.json": {Data: []byte(`[
+			{"tableName": "users", "rows": [{"id": 1, "name": "Zoe"}, {"id": 2, "name": "Yan"}]}
+		]`)},
+		"broken.json": {Data: []byte(`[{"tableName": "missing", "rows": []}]`)},
+	}
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.Development = true
+		c.Fixtures = fixtures
+	})
+	defer close()
+
+	_, err := ts.db.Exec(`CREATE TABLE posts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		title TEXT,
+		user_id INTEGER REFERENCES users (id)
+	)`)
+	assert.NoError(t, err)
+	_, err = ts.db.Exec("INSERT INTO posts (title, user_id) VALUES ('First', 1), ('Second', 2)")
+	assert.NoError(t, err)
+
+	runTestCases([]TestCase{
+		{
+			name:             "Success: Replace",
+			params:           map[string]interface{}{"fixture": "demo"},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok", "rowsInserted": float64(2)},
+		},
+	}, sqliteadmin.SeedFixtures, t, ts.server)
+
+	var users, postID int
+	assert.NoError(t, ts.db.QueryRow("SELECT count(*) FROM users").Scan(&users))
+	assert.Equal(t, 1, users)
+	// The sequence of the table was reset
+	assert.NoError(t, ts.db.QueryRow("SELECT id FROM posts").Scan(&postID))
+	assert.Equal(t, 1, postID)
+
+	runTestCases([]TestCase{
+		{
+			name:             "Success: Merge",
+			params:           map[string]interface{}{"fixture": "extra", "mode": "merge"},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok", "rowsInserted": float64(2)},
+		},
+		{
+			name:           "Failure: Unknown Fixture",
+			params:         map[string]interface{}{"fixture": "../demo"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(400),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: " + sqliteadmin.ErrUnknownFixture.Error(),
+			},
+		},
+		{
+			name:           "Failure: Unknown Table",
+			params:         map[string]interface{}{"fixture": "broken"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(400),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: " + sqliteadmin.ErrInvalidFixture.Error() + ": unknown table missing",
+			},
+		},
+		{
+			name:           "Failure: Invalid Mode",
+			params:         map[string]interface{}{"fixture": "demo", "mode": "append"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(400),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: " + sqliteadmin.ErrInvalidInput.Error(),
+			},
+		},
+	}, sqliteadmin.SeedFixtures, t, ts.server)
+
+	var name string
+	assert.NoError(t, ts.db.QueryRow("SELECT name FROM users WHERE id = 1").Scan(&name))
+	assert.Equal(t, "Zoe", name)
+	assert.NoError(t, ts.db.QueryRow("SELECT count(*) FROM users").Scan(&users))
+	assert.Equal(t, 2, users)
+}
+
+func TestSeedFixturesDisabled(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.Fixtures = fstest.MapFS{"demo.json": {Data: []byte(`[]`)}}
+	})
+	defer close()
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Not In Development Mode",
+			params:         map[string]interface{}{"fixture": "demo"},
+			expectedStatus: http.StatusForbidden,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(403),
+				"code":       "FORBIDDEN",
+				"message":    "Forbidden: " + sqliteadmin.ErrFixturesDisabled.Error(),
+			},
+		},
+	}, sqliteadmin.SeedFixtures, t, ts.server)
+}
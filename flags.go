@@ -0,0 +1,318 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultFlagsTable is the table created by CreateFlagsTable.
+const DefaultFlagsTable = "feature_flags"
+
+// flagsTableTemplate is the conventional layout of a feature flags table.
+const flagsTableTemplate = `CREATE TABLE %s (
+	name TEXT PRIMARY KEY,
+	enabled BOOLEAN NOT NULL DEFAULT 0,
+	description TEXT,
+	updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`
+
+// Conventional names of feature flag tables and of their columns, in order of
+// preference.
+var (
+	flagTableNames   = []string{DefaultFlagsTable, "flags", "features", "feature_toggles"}
+	flagKeyColumns   = []string{"name", "key", "flag", "feature"}
+	flagValueColumns = []string{"enabled", "is_enabled", "value", "active", "on"}
+)
+
+// flagTable is a table of feature flags, with the column identifying each flag
+// and the one holding its value.
+type flagTable struct {
+	Name      string
+	KeyColumn string
+	Column    column
+	HasUpdate bool
+}
+
+// Flag is the value of a feature flag, typed after the column holding it.
+type Flag struct {
+	Name      string      `json:"name"`
+	TableName string      `json:"tableName"`
+	Value     interface{} `json:"value"`
+}
+
+// findFlagTable returns the flags table, looking for a table with a
+// conventional name if table is empty.
+func findFlagTable(ctx context.Context, q queryer, table string) (*flagTable, error) {
+	candidates := flagTableNames
+	if table != "" {
+		candidates = []string{table}
+	}
+
+	for _, name := range candidates {
+		exists, err := checkTableExists(ctx, q, name)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		columns, err := getColumns(ctx, q, name)
+		if err != nil {
+			return nil, err
+		}
+
+		ft := &flagTable{Name: name}
+		for _, key := range flagKeyColumns {
+			if findColumn(columns, key) != nil {
+				ft.KeyColumn = key
+				break
+			}
+		}
+		for _, value := range flagValueColumns {
+			if c := findColumn(columns, value); c != nil {
+				ft.Column = *c
+				break
+			}
+		}
+		ft.HasUpdate = findColumn(columns, "updated_at") != nil
+		if ft.KeyColumn != "" && ft.Column.Name != "" {
+			return ft, nil
+		}
+	}
+	return nil, ErrNoFlagsTable
+}
+
+func findColumn(columns []column, name string) *column {
+	for i := range columns {
+		if strings.EqualFold(columns[i].Name, name) {
+			return &columns[i]
+		}
+	}
+	return nil
+}
+
+// isBoolean reports whether the flag values are on/off switches, either
+// because of the declared type or the name of the column.
+func (ft *flagTable) isBoolean() bool {
+	dataType := strings.ToUpper(ft.Column.DataType)
+	return strings.Contains(dataType, "BOOL") ||
+		(strings.Contains(dataType, "INT") && ft.Column.Name != "value")
+}
+
+// decode converts a stored value to the flag's type.
+func (ft *flagTable) decode(v interface{}) interface{} {
+	if ft.isBoolean() {
+		switch n := v.(type) {
+		case int64:
+			return n != 0
+		case float64:
+			return n != 0
+		case bool:
+			return n
+		}
+	}
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v
+}
+
+// encode validates a value sent by the client and converts it to what is
+// stored in the column.
+func (ft *flagTable) encode(v interface{}) (interface{}, bool) {
+	dataType := strings.ToUpper(ft.Column.DataType)
+	switch {
+	case ft.isBoolean():
+		switch b := v.(type) {
+		case bool:
+			if b {
+				return 1, true
+			}
+			return 0, true
+		case float64:
+			if b == 0 || b == 1 {
+				return int(b), true
+			}
+		}
+		return nil, false
+	case strings.Contains(dataType, "INT"), strings.Contains(dataType, "REAL"),
+		strings.Contains(dataType, "FLOA"), strings.Contains(dataType, "DOUB"), strings.Contains(dataType, "NUM"):
+		n, ok := v.(float64)
+		return n, ok
+	default:
+		if s, ok := v.(string); ok {
+			return s, true
+		}
+		// Structured values are stored as JSON
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, false
+		}
+		return string(b), true
+	}
+}
+
+func (ft *flagTable) get(ctx context.Context, q queryer, name string) (*Flag, error) {
+	var value interface{}
+	err := q.QueryRowContext(ctx, fmt.Sprintf("SELECT %s FROM %s WHERE %s = ?", quoteIdent(ft.Column.Name), quoteIdent(ft.Name), quoteIdent(ft.KeyColumn)), name).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil, ErrUnknownFlag
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading flag: %v", err)
+	}
+	return &Flag{Name: name, TableName: ft.Name, Value: ft.decode(value)}, nil
+}
+
+func (a *Admin) getFlagParams(ctx context.Context, w http.ResponseWriter, db *sql.DB, params map[string]interface{}) (*flagTable, string, bool) {
+	name, ok := params["name"].(string)
+	if !ok || name == "" {
+		writeError(w, apiErrBadRequest(ErrMissingFlagName.Error()))
+		return nil, "", false
+	}
+
+	table, _ := params["tableName"].(string)
+	ft, err := findFlagTable(ctx, a.cached(db), table)
+	if err == ErrNoFlagsTable {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return nil, "", false
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error finding flags table: %v", err))
+		writeError(w, a.apiErr(err))
+		return nil, "", false
+	}
+	return ft, name, true
+}
+
+func (a *Admin) getFlag(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getReadDB(w, params)
+	if !ok {
+		return
+	}
+	ft, name, ok := a.getFlagParams(ctx, w, db, params)
+	if !ok {
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: GetFlag, table=%s, name=%s", ft.Name, name))
+
+	flag, err := ft.get(ctx, a.cached(db), name)
+	if err == ErrUnknownFlag {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error getting flag: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(flag)
+}
+
+// setFlag changes the value of an existing flag, checking that the value has
+// the type of the flag.
+func (a *Admin) setFlag(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return
+	}
+	ft, name, ok := a.getFlagParams(ctx, w, db, params)
+	if !ok {
+		return
+	}
+
+	value, ok := ft.encode(params["value"])
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrInvalidFlagValue.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: SetFlag, table=%s, name=%s, value=%v", ft.Name, name, params["value"]))
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error starting transaction: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	defer tx.Rollback()
+
+	previous, err := ft.get(ctx, tx, name)
+	if err == ErrUnknownFlag {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error getting flag: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+
+	set := fmt.Sprintf("%s = ?", quoteIdent(ft.Column.Name))
+	if ft.HasUpdate {
+		set += `, "updated_at" = CURRENT_TIMESTAMP`
+	}
+	_, err = tx.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET %s WHERE %s = ?", quoteIdent(ft.Name), set, quoteIdent(ft.KeyColumn)), value, name)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error setting flag: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	flag, err := ft.get(ctx, tx, name)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error setting flag: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: set flag %s in %s from %v to %v", name, ft.Name, previous.Value, flag.Value))
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"flag":          flag,
+		"previousValue": previous.Value,
+	})
+}
+
+// createFlagsTable creates a feature flags table with the conventional layout
+// recognized by GetFlag and SetFlag.
+func (a *Admin) createFlagsTable(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return
+	}
+
+	table := DefaultFlagsTable
+	if t, ok := params["tableName"].(string); ok && t != "" {
+		table = t
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: CreateFlagsTable, table=%s", table))
+
+	exists, err := checkTableExists(ctx, db, table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error checking table existence: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if exists {
+		writeError(w, apiErrBadRequest(ErrTableExists.Error()))
+		return
+	}
+
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(flagsTableTemplate, quoteIdent(table))); err != nil {
+		a.logger.Error(fmt.Sprintf("Error creating flags table: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: created flags table %s", table))
+
+	json.NewEncoder(w).Encode(map[string]string{"tableName": table})
+}
@@ -0,0 +1,117 @@
+package sqliteadmin_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlags(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: No Flags Table",
+			params:         map[string]interface{}{"name": "dark_mode"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: no feature flags table found",
+			},
+		},
+	}, sqliteadmin.GetFlag, t, ts.server)
+
+	runTestCases([]TestCase{
+		{
+			name:             "Success",
+			params:           map[string]interface{}{},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"tableName": "feature_flags"},
+		},
+		{
+			name:           "Failure: Table Exists",
+			params:         map[string]interface{}{},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: table already exists",
+			},
+		},
+	}, sqliteadmin.CreateFlagsTable, t, ts.server)
+
+	_, err := ts.db.Exec(`
+    INSERT INTO feature_flags (name) VALUES ('dark_mode');
+    CREATE TABLE settings (key TEXT PRIMARY KEY, value TEXT);
+    INSERT INTO settings VALUES ('theme', 'light');
+  `)
+	assert.NoError(t, err)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Success",
+			params:         map[string]interface{}{"name": "dark_mode"},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"name": "dark_mode", "tableName": "feature_flags", "value": false,
+			},
+		},
+		{
+			name:           "Success: Custom Table",
+			params:         map[string]interface{}{"tableName": "settings", "name": "theme"},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"name": "theme", "tableName": "settings", "value": "light",
+			},
+		},
+		{
+			name:           "Failure: Unknown Flag",
+			params:         map[string]interface{}{"name": "beta"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: unknown flag",
+			},
+		},
+	}, sqliteadmin.GetFlag, t, ts.server)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Invalid Value",
+			params:         map[string]interface{}{"name": "dark_mode", "value": "yes"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid flag value",
+			},
+		},
+		{
+			name:           "Success",
+			params:         map[string]interface{}{"name": "dark_mode", "value": true},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"flag": map[string]interface{}{
+					"name": "dark_mode", "tableName": "feature_flags", "value": true,
+				},
+				"previousValue": false,
+			},
+		},
+		{
+			name:           "Success: Structured Value",
+			params:         map[string]interface{}{"tableName": "settings", "name": "theme", "value": map[string]interface{}{"mode": "dark"}},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"flag": map[string]interface{}{
+					"name": "theme", "tableName": "settings", "value": `{"mode":"dark"}`,
+				},
+				"previousValue": "light",
+			},
+		},
+	}, sqliteadmin.SetFlag, t, ts.server)
+}
@@ -0,0 +1,182 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Kinds of geometry columns, as reported by ColumnInfo.Geometry.
+const (
+	GeometryGeopoly    = "geopoly"
+	GeometrySpatiaLite = "spatialite"
+)
+
+var geopolyTable = regexp.MustCompile(`(?is)^\s*CREATE\s+VIRTUAL\s+TABLE\s.*\bUSING\s+geopoly\b`)
+
+// geometryColumn is a column holding geometries, which are returned as
+// GeoJSON and can be set from GeoJSON.
+type geometryColumn struct {
+	kind string
+	// srid is the spatial reference system of SpatiaLite geometries.
+	srid int
+}
+
+// getGeometryColumns returns the geometry columns of the table by name: the
+// _shape column of geopoly tables, and the columns registered in the
+// geometry_columns table of SpatiaLite when it is loaded, since its blobs
+// can only be converted by its functions.
+func getGeometryColumns(ctx context.Context, q queryer, table string) (map[string]geometryColumn, error) {
+	columns := map[string]geometryColumn{}
+	// Tables have geometries seldom enough to look for both in one query
+	var createSQL sql.NullString
+	var spatialite bool
+	err := q.QueryRowContext(ctx, `SELECT (SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?),
+		EXISTS (SELECT 1 FROM pragma_function_list WHERE lower(name) = 'asgeojson')
+		AND EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'geometry_columns')`, table).Scan(&createSQL, &spatialite)
+	if err != nil {
+		return nil, fmt.Errorf("error looking for geometry columns: %v", err)
+	}
+	if geopolyTable.MatchString(createSQL.String) {
+		columns["_shape"] = geometryColumn{kind: GeometryGeopoly}
+		return columns, nil
+	}
+	if !spatialite {
+		return columns, nil
+	}
+	rows, err := q.QueryContext(ctx, "SELECT f_geometry_column, srid FROM geometry_columns WHERE lower(f_table_name) = lower(?)", table)
+	if err != nil {
+		return nil, fmt.Errorf("error reading geometry columns: %v", err)
+	}
+	defer rows.Close()
+	registered := map[string]int{}
+	for rows.Next() {
+		var name string
+		var srid int
+		if err := rows.Scan(&name, &srid); err != nil {
+			return nil, fmt.Errorf("error reading geometry columns: %v", err)
+		}
+		registered[strings.ToLower(name)] = srid
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading geometry columns: %v", err)
+	}
+	if len(registered) == 0 {
+		return columns, nil
+	}
+
+	// SpatiaLite stores the names in lower case
+	names, err := getColumnNames(ctx, q, table)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if srid, ok := registered[strings.ToLower(name)]; ok {
+			columns[name] = geometryColumn{kind: GeometrySpatiaLite, srid: srid}
+		}
+	}
+	return columns, nil
+}
+
+// geoJSONRows replaces the geometries of the rows by their GeoJSON. Columns
+// whose values are hidden by a policy are left for protectRows.
+func (a *Admin) geoJSONRows(ctx context.Context, q queryer, table string, rows []map[string]interface{}) error {
+	columns, err := getGeometryColumns(ctx, q, table)
+	if err != nil || len(columns) == 0 {
+		return err
+	}
+	for name, column := range columns {
+		if policy, ok := a.columnPolicy(table, name); ok && policy.hides() {
+			continue
+		}
+		for _, row := range rows {
+			// Blobs are read as strings
+			value, ok := row[name].(string)
+			if !ok {
+				continue
+			}
+			geometry, err := column.toGeoJSON(ctx, q, []byte(value))
+			if err != nil {
+				return fmt.Errorf("error converting %s to GeoJSON: %v", name, err)
+			}
+			row[name] = geometry
+		}
+	}
+	return nil
+}
+
+func (c geometryColumn) toGeoJSON(ctx context.Context, q queryer, value []byte) (interface{}, error) {
+	var text sql.NullString
+	query := "SELECT AsGeoJSON(?)"
+	if c.kind == GeometryGeopoly {
+		query = "SELECT geopoly_json(?)"
+	}
+	if err := q.QueryRowContext(ctx, query, value).Scan(&text); err != nil {
+		return nil, err
+	}
+	// Values that aren't geometries are returned as is
+	if !text.Valid {
+		return string(value), nil
+	}
+
+	var geometry interface{}
+	if err := json.Unmarshal([]byte(text.String), &geometry); err != nil {
+		return nil, err
+	}
+	// geopoly_json returns the vertices of the polygon, which is closed
+	if c.kind == GeometryGeopoly {
+		geometry = map[string]interface{}{
+			"type":        "Polygon",
+			"coordinates": []interface{}{geometry},
+		}
+	}
+	return geometry, nil
+}
+
+// decodeGeometries replaces the GeoJSON objects given for the geometry
+// columns of a row by the values stored in the database. Other values, like
+// the JSON arrays of vertices accepted by geopoly, are left as they are.
+func decodeGeometries(ctx context.Context, q queryer, columns map[string]geometryColumn, row map[string]interface{}) error {
+	for name, column := range columns {
+		geometry, ok := row[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		value, err := column.fromGeoJSON(ctx, q, geometry)
+		if err != nil {
+			return err
+		}
+		row[name] = value
+	}
+	return nil
+}
+
+func (c geometryColumn) fromGeoJSON(ctx context.Context, q queryer, geometry map[string]interface{}) ([]byte, error) {
+	var value interface{} = geometry
+	query, args := "SELECT SetSRID(GeomFromGeoJSON(?), ?)", []interface{}{c.srid}
+	if c.kind == GeometryGeopoly {
+		// Geopoly only stores simple polygons, as their outer ring
+		rings, ok := geometry["coordinates"].([]interface{})
+		if geometry["type"] != "Polygon" || !ok || len(rings) != 1 {
+			return nil, fmt.Errorf("%w: geopoly columns hold polygons without holes", ErrInvalidGeometry)
+		}
+		value, query, args = rings[0], "SELECT geopoly_blob(?)", nil
+	}
+	text, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGeometry, err)
+	}
+
+	var blob []byte
+	if err := q.QueryRowContext(ctx, query, append([]interface{}{string(text)}, args...)...).Scan(&blob); err != nil {
+		return nil, fmt.Errorf("error converting GeoJSON: %v", err)
+	}
+	// The functions return NULL for invalid geometries
+	if len(blob) == 0 {
+		return nil, ErrInvalidGeometry
+	}
+	return blob, nil
+}
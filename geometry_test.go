@@ -0,0 +1,127 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+	"modernc.org/sqlite"
+)
+
+func TestGeopolyGeoJSON(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	_, err := ts.db.Exec(`
+		CREATE VIRTUAL TABLE zones USING geopoly(name);
+		INSERT INTO zones (_shape, name) VALUES ('[[0,0],[2,0],[2,2],[0,0]]', 'north'), ('[[0,0],[0,-1],[1,-1],[0,0]]', 'south');
+	`)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	res, err := ts.admin.GetTable(ctx, sqliteadmin.GetTableParams{TableName: "zones", IncludeInfo: true})
+	assert.NoError(t, err)
+	if !assert.Len(t, res.Rows, 2) {
+		return
+	}
+	assert.Equal(t, map[string]interface{}{
+		"type": "Polygon",
+		"coordinates": []interface{}{[]interface{}{
+			[]interface{}{float64(0), float64(0)},
+			[]interface{}{float64(2), float64(0)},
+			[]interface{}{float64(2), float64(2)},
+			[]interface{}{float64(0), float64(0)},
+		}},
+	}, res.Rows[0]["_shape"])
+	assert.Equal(t, sqliteadmin.GeometryGeopoly, res.TableInfo.Columns[0].Geometry)
+	assert.Empty(t, res.TableInfo.Columns[1].Geometry)
+
+	runTestCases([]TestCase{
+		{
+			name: "Failure: Not a polygon",
+			params: map[string]interface{}{
+				"tableName": "zones",
+				"row":       map[string]interface{}{"_shape": map[string]interface{}{"type": "Point", "coordinates": []interface{}{1, 2}}},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid GeoJSON geometry: geopoly columns hold polygons without holes",
+			},
+		},
+	}, sqliteadmin.InsertRow, t, ts.server)
+}
+
+// registerSpatiaLite registers functions standing for the ones of SpatiaLite,
+// storing the GeoJSON with a prefix as the geometry.
+var registerSpatiaLite sync.Once
+
+func TestSpatiaLiteGeoJSON(t *testing.T) {
+	registerSpatiaLite.Do(func() {
+		assert.NoError(t, sqlite.RegisterDeterministicScalarFunction("GeomFromGeoJSON", 1, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+			return []byte("GEOM" + args[0].(string)), nil
+		}))
+		assert.NoError(t, sqlite.RegisterDeterministicScalarFunction("SetSRID", 2, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+			return args[0], nil
+		}))
+		assert.NoError(t, sqlite.RegisterDeterministicScalarFunction("AsGeoJSON", 1, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+			geometry, _ := args[0].([]byte)
+			if !strings.HasPrefix(string(geometry), "GEOM") {
+				return nil, nil
+			}
+			return strings.TrimPrefix(string(geometry), "GEOM"), nil
+		}))
+	})
+
+	ts, close := setupTestServer(t)
+	defer close()
+
+	_, err := ts.db.Exec(`
+		CREATE TABLE geometry_columns (f_table_name TEXT, f_geometry_column TEXT, geometry_type INTEGER, coord_dimension INTEGER, srid INTEGER, spatial_index_enabled INTEGER);
+		INSERT INTO geometry_columns VALUES ('places', 'location', 1, 2, 4326, 0);
+		CREATE TABLE places (id INTEGER PRIMARY KEY, name TEXT, Location BLOB);
+		INSERT INTO places (name, location) VALUES ('Paris', CAST('GEOM{"type":"Point","coordinates":[2.35,48.85]}' AS BLOB));
+	`)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = ts.admin.UpdateRow(ctx, sqliteadmin.UpdateRowParams{
+		TableName: "places",
+		Row: map[string]interface{}{
+			"id":       "1",
+			"Location": map[string]interface{}{"type": "Point", "coordinates": []interface{}{2.29, 48.86}},
+		},
+	})
+	assert.NoError(t, err)
+
+	_, err = ts.admin.InsertRow(ctx, sqliteadmin.InsertRowParams{
+		TableName: "places",
+		Row: map[string]interface{}{
+			"name":     "Lyon",
+			"Location": map[string]interface{}{"type": "Point", "coordinates": []interface{}{4.83, 45.76}},
+		},
+	})
+	assert.NoError(t, err)
+
+	res, err := ts.admin.GetTable(ctx, sqliteadmin.GetTableParams{TableName: "places", IncludeInfo: true})
+	assert.NoError(t, err)
+	if !assert.Len(t, res.Rows, 2) {
+		return
+	}
+	assert.Equal(t, map[string]interface{}{
+		"type":        "Point",
+		"coordinates": []interface{}{2.29, 48.86},
+	}, res.Rows[0]["Location"])
+	assert.Equal(t, map[string]interface{}{
+		"type":        "Point",
+		"coordinates": []interface{}{4.83, 45.76},
+	}, res.Rows[1]["Location"])
+	assert.Equal(t, sqliteadmin.GeometrySpatiaLite, res.TableInfo.Columns[2].Geometry)
+	assert.Empty(t, res.TableInfo.Columns[1].Geometry)
+}
@@ -0,0 +1,79 @@
+package sqliteadmin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	// EventRowsInserted is sent after InsertRow inserted a row.
+	EventRowsInserted EventType = "rows.inserted"
+	// EventRowsUpdated is sent after UpdateRow changed a row.
+	EventRowsUpdated EventType = "rows.updated"
+	// EventRowsDeleted is sent after DeleteRows deleted rows.
+	EventRowsDeleted EventType = "rows.deleted"
+)
+
+// Mutation describes the rows changed by InsertRow, UpdateRow or DeleteRows.
+type Mutation struct {
+	Table string `json:"table"`
+	// Database is the name of the database, empty for the default one.
+	Database   string        `json:"database,omitempty"`
+	PrimaryKey string        `json:"primaryKey"`
+	Keys       []interface{} `json:"keys"`
+	// Old are the rows before the change and New the rows after it, which
+	// are respectively empty for inserts and deletes.
+	Old []map[string]interface{} `json:"old"`
+	New []map[string]interface{} `json:"new,omitempty"`
+}
+
+// Hooks are called around the writes made by InsertRow, UpdateRow and
+// DeleteRows, MergeRows which deletes a row and updates another, and DiffTable
+// when applying a diff, e.g. to invalidate caches, sync other systems or add
+// validation.
+type Hooks struct {
+	// The Before hooks run inside the transaction of the write, before it is
+	// committed. Returning an error cancels the write and its message is
+	// returned to the client with a 400. BeforeInsert runs once the row has
+	// been inserted so that it is given its default values.
+	BeforeInsert func(ctx context.Context, m Mutation) error
+	BeforeUpdate func(ctx context.Context, m Mutation) error
+	BeforeDelete func(ctx context.Context, m Mutation) error
+	// The After hooks run once the write has been committed.
+	AfterInsert func(ctx context.Context, m Mutation)
+	AfterUpdate func(ctx context.Context, m Mutation)
+	AfterDelete func(ctx context.Context, m Mutation)
+	// Webhooks are URLs that are sent the EventRowsInserted,
+	// EventRowsUpdated and EventRowsDeleted events.
+	Webhooks []string
+}
+
+// getRowsByPrimaryKey returns the rows of the table with the given primary key
+// values. Missing rows are skipped.
+func getRowsByPrimaryKey(ctx context.Context, q queryer, table, primaryKey string, keys []interface{}) ([]map[string]interface{}, error) {
+	if len(keys) == 0 {
+		return []map[string]interface{}{}, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(keys)), ",")
+	return queryRows(ctx, q, fmt.Sprintf("SELECT * FROM %s WHERE %s IN (%s)", quoteIdent(table), quoteIdent(primaryKey), placeholders), keys...)
+}
+
+// runBeforeHook calls the Before hook of the mutation, if any.
+func runBeforeHook(ctx context.Context, hook func(context.Context, Mutation) error, m Mutation) error {
+	if hook == nil {
+		return nil
+	}
+	return hook(ctx, m)
+}
+
+// afterMutation calls the After hook and notifies about the committed
+// mutation.
+func (a *Admin) afterMutation(ctx context.Context, hook func(context.Context, Mutation), eventType EventType, m Mutation) {
+	if hook != nil {
+		hook(ctx, m)
+	}
+	a.notify(eventType, fmt.Sprintf("%d row(s) of %s changed", max(len(m.Old), len(m.New)), m.Table), map[string]interface{}{
+		"mutation": m,
+	})
+}
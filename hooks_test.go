@@ -0,0 +1,109 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHooks(t *testing.T) {
+	var updates, deletes []sqliteadmin.Mutation
+	events := make(chanNotifier, 2)
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.Hooks = sqliteadmin.Hooks{
+			BeforeUpdate: func(ctx context.Context, m sqliteadmin.Mutation) error {
+				for _, row := range m.New {
+					if row["name"] == "" {
+						return errors.New("name must not be empty")
+					}
+				}
+				return nil
+			},
+			AfterUpdate: func(ctx context.Context, m sqliteadmin.Mutation) {
+				updates = append(updates, m)
+			},
+			BeforeDelete: func(ctx context.Context, m sqliteadmin.Mutation) error {
+				if len(m.Old) > 1 {
+					return errors.New("rows must be deleted one at a time")
+				}
+				return nil
+			},
+			AfterDelete: func(ctx context.Context, m sqliteadmin.Mutation) {
+				deletes = append(deletes, m)
+			},
+		}
+		c.Notifiers = map[sqliteadmin.EventType][]sqliteadmin.Notifier{
+			sqliteadmin.EventRowsDeleted: {events},
+		}
+	})
+	defer close()
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Rejected By Hook",
+			params:         map[string]interface{}{"tableName": "users", "row": map[string]interface{}{"id": 1, "name": ""}},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: name must not be empty",
+			},
+		},
+		{
+			name:             "Success",
+			params:           map[string]interface{}{"tableName": "users", "row": map[string]interface{}{"id": 1, "name": "Alicia"}},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok"},
+		},
+	}, sqliteadmin.UpdateRow, t, ts.server)
+
+	if assert.Len(t, updates, 1) {
+		m := updates[0]
+		assert.Equal(t, "users", m.Table)
+		assert.Equal(t, "id", m.PrimaryKey)
+		assert.Equal(t, "Alice", m.Old[0]["name"])
+		assert.Equal(t, "Alicia", m.New[0]["name"])
+	}
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Rejected By Hook",
+			params:         map[string]interface{}{"tableName": "users", "ids": []interface{}{"2", "3"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: rows must be deleted one at a time",
+			},
+		},
+		{
+			name:             "Success",
+			params:           map[string]interface{}{"tableName": "users", "ids": []interface{}{"2"}},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"rowsAffected": "1"},
+		},
+	}, sqliteadmin.DeleteRows, t, ts.server)
+
+	if assert.Len(t, deletes, 1) {
+		assert.Equal(t, "Bob", deletes[0].Old[0]["name"])
+		assert.Empty(t, deletes[0].New)
+	}
+
+	var count int
+	err := ts.db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, count)
+
+	select {
+	case event := <-events:
+		assert.Equal(t, sqliteadmin.EventRowsDeleted, event.Type)
+		assert.Equal(t, deletes[0], event.Data["mutation"])
+	case <-time.After(time.Second):
+		t.Fatal("event not sent")
+	}
+}
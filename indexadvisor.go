@@ -0,0 +1,145 @@
+package sqliteadmin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// IndexAdvisory recommends an index for foreign key columns that are not
+// indexed. Without it, every delete or update of the referenced row has to
+// scan the whole table to check the constraint.
+type IndexAdvisory struct {
+	Columns         []string `json:"columns"`
+	ReferencedTable string   `json:"referencedTable"`
+	SQL             string   `json:"sql"`
+}
+
+// getIndexAdvisories returns an advisory for each foreign key of the table
+// whose columns aren't the leading columns of an index.
+func getIndexAdvisories(ctx context.Context, q queryer, table string) ([]IndexAdvisory, error) {
+	fks, err := getForeignKeys(ctx, q, table)
+	if err != nil {
+		return nil, err
+	}
+
+	advisories := []IndexAdvisory{}
+	if len(fks) == 0 {
+		return advisories, nil
+	}
+
+	indexes, err := getIndexColumns(ctx, q, table)
+	if err != nil {
+		return nil, err
+	}
+
+	// An INTEGER PRIMARY KEY is the rowid and doesn't show up as an index
+	columns, err := getColumns(ctx, q, table)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range columns {
+		if c.PK == 1 && strings.EqualFold(c.DataType, "INTEGER") {
+			indexes = append(indexes, []string{c.Name})
+		}
+	}
+
+	// Group the columns of composite foreign keys, keeping their order
+	var order []int
+	grouped := make(map[int]*IndexAdvisory)
+	for _, fk := range fks {
+		advisory, ok := grouped[fk.ID]
+		if !ok {
+			advisory = &IndexAdvisory{ReferencedTable: fk.Table}
+			grouped[fk.ID] = advisory
+			order = append(order, fk.ID)
+		}
+		advisory.Columns = append(advisory.Columns, fk.From)
+	}
+
+	for _, id := range order {
+		advisory := grouped[id]
+		if isIndexed(indexes, advisory.Columns) {
+			continue
+		}
+
+		quoted := make([]string, len(advisory.Columns))
+		for i, c := range advisory.Columns {
+			quoted[i] = quoteIdent(c)
+		}
+		name := fmt.Sprintf("idx_%s_%s", table, strings.Join(advisory.Columns, "_"))
+		advisory.SQL = fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s)", quoteIdent(name), quoteIdent(table), strings.Join(quoted, ", "))
+		advisories = append(advisories, *advisory)
+	}
+	return advisories, nil
+}
+
+// getIndexColumns returns the columns of every index of the table in index
+// order.
+func getIndexColumns(ctx context.Context, q queryer, table string) ([][]string, error) {
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("SELECT name FROM pragma_index_list(%s)", quoteLiteral(table)))
+	if err != nil {
+		return nil, fmt.Errorf("error listing indexes: %v", err)
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
+
+	var indexes [][]string
+	for _, name := range names {
+		rows, err := q.QueryContext(ctx, fmt.Sprintf("SELECT name FROM pragma_index_info(%s) ORDER BY seqno", quoteLiteral(name)))
+		if err != nil {
+			return nil, fmt.Errorf("error getting index columns: %v", err)
+		}
+		var columns []string
+		for rows.Next() {
+			var column *string
+			if err := rows.Scan(&column); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("error scanning row: %v", err)
+			}
+			// Expressions in indexes have no column name
+			if column == nil {
+				break
+			}
+			columns = append(columns, *column)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error reading rows: %v", err)
+		}
+		indexes = append(indexes, columns)
+	}
+	return indexes, nil
+}
+
+// isIndexed reports whether the columns are the leading columns of one of
+// the indexes, in any order.
+func isIndexed(indexes [][]string, columns []string) bool {
+	for _, index := range indexes {
+		if len(index) < len(columns) {
+			continue
+		}
+		covered := true
+		for _, c := range columns {
+			if !contains(index[:len(columns)], c) {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertRow(t *testing.T) {
+	var inserted []sqliteadmin.Mutation
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.Hooks.AfterInsert = func(ctx context.Context, m sqliteadmin.Mutation) {
+			inserted = append(inserted, m)
+		}
+	})
+	defer close()
+
+	_, err := ts.db.Exec(`
+		CREATE TABLE tasks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			title TEXT NOT NULL,
+			status TEXT DEFAULT 'todo',
+			done BOOLEAN DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	res, err := ts.admin.GetTable(ctx, sqliteadmin.GetTableParams{TableName: "tasks", IncludeInfo: true})
+	assert.NoError(t, err)
+	defaults := map[string]string{}
+	for _, c := range res.TableInfo.Columns {
+		defaults[c.Name] = c.Default
+	}
+	assert.Equal(t, map[string]string{"id": "", "title": "", "status": "'todo'", "done": "0", "created_at": "CURRENT_TIMESTAMP"}, defaults)
+
+	t.Run("Defaults", func(t *testing.T) {
+		res, err := ts.admin.InsertRow(ctx, sqliteadmin.InsertRowParams{
+			TableName: "tasks",
+			Row:       map[string]interface{}{"title": "Write docs"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", res.Status)
+		assert.NotEmpty(t, res.Row["created_at"])
+		delete(res.Row, "created_at")
+		assert.Equal(t, map[string]interface{}{"id": float64(1), "title": "Write docs", "status": "todo", "done": false}, res.Row)
+
+		assert.Len(t, inserted, 1)
+		assert.Equal(t, []interface{}{int64(1)}, inserted[0].Keys)
+		assert.Equal(t, "todo", inserted[0].New[0]["status"])
+	})
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Missing Row",
+			params:         map[string]interface{}{"tableName": "tasks"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: " + sqliteadmin.ErrMissingRow.Error(),
+			},
+		},
+		{
+			name:           "Failure: Unknown Column",
+			params:         map[string]interface{}{"tableName": "tasks", "row": map[string]interface{}{"title": "a", "missing": 1}},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "COLUMN_NOT_FOUND",
+				"message":    "Bad request: " + sqliteadmin.ErrUnknownColumn.Error(),
+				"detail":     "missing: unknown column",
+			},
+		},
+		{
+			name:           "Failure: Column Without Default",
+			params:         map[string]interface{}{"tableName": "tasks", "row": map[string]interface{}{"status": "done"}},
+			expectedStatus: http.StatusConflict,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusConflict),
+				"code":       "CONSTRAINT_VIOLATION",
+				"message":    "Conflict: a constraint of the table was violated",
+				"detail":     "error querying table: constraint failed: NOT NULL constraint failed: tasks.title",
+				"constraint": map[string]interface{}{"type": "NOT_NULL", "table": "tasks", "columns": []interface{}{"title"}},
+			},
+		},
+	}, sqliteadmin.InsertRow, t, ts.server)
+}
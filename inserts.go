@@ -0,0 +1,306 @@
+package sqliteadmin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// Values of InsertRowsParams.Mode.
+const (
+	// InsertAllOrNothing inserts none of the rows when one of them fails.
+	InsertAllOrNothing = "allOrNothing"
+	// InsertBestEffort inserts the rows that can be, skipping the others.
+	InsertBestEffort = "bestEffort"
+)
+
+// rowSavepoint isolates the insert of a row, so that a row failing after
+// being inserted, e.g. outside of the scope, can be undone on its own.
+const rowSavepoint = "sqliteadmin_row"
+
+// InsertRowsParams are the params of InsertRows.
+type InsertRowsParams struct {
+	Database  string `json:"database,omitempty" mapstructure:"database"`
+	TableName string `json:"tableName" mapstructure:"tableName"`
+	// Rows hold the values of the columns to insert, as for InsertRow.
+	Rows []map[string]interface{} `json:"rows" mapstructure:"rows"`
+	// Mode is InsertAllOrNothing, the default, or InsertBestEffort.
+	Mode        string `json:"mode,omitempty" mapstructure:"mode"`
+	Transaction string `json:"transaction,omitempty" mapstructure:"transaction"`
+}
+
+func (p *InsertRowsParams) validate() error {
+	if p.TableName == "" {
+		return ErrMissingTableName
+	}
+	if len(p.Rows) == 0 {
+		return ErrMissingRow
+	}
+	if p.Mode != "" && p.Mode != InsertAllOrNothing && p.Mode != InsertBestEffort {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+// RowError is why a row of InsertRows wasn't inserted.
+type RowError struct {
+	// Index is the position of the row in InsertRowsParams.Rows.
+	Index int      `json:"index"`
+	Error APIError `json:"error"`
+}
+
+type InsertRowsResponse struct {
+	// Status is "ok" when rows were inserted, which in InsertAllOrNothing
+	// mode means all of them, and "failed" otherwise.
+	Status string `json:"status"`
+	// Rows are the rows inserted as stored, in order.
+	Rows   []map[string]interface{} `json:"rows"`
+	Errors []RowError               `json:"errors"`
+	// SecretWarnings are the possible secrets found in the rows.
+	SecretWarnings []SecretFinding `json:"secretWarnings,omitempty"`
+}
+
+// insertRows inserts rows in a single transaction, e.g. pasted from a
+// spreadsheet, going through the same checks, policies and hooks as InsertRow.
+// Every row is attempted so that all the failing ones are reported along with
+// why, after which nothing is inserted in InsertAllOrNothing mode.
+func (a *Admin) insertRows(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	var p InsertRowsParams
+	if err := a.decodeParams(params, &p); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	if exceeds(len(p.Rows), a.maxLimit) {
+		writeError(w, apiErrBadRequest(ErrLimitTooLarge.Error()))
+		return
+	}
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return
+	}
+	if p.Mode == "" {
+		p.Mode = InsertAllOrNothing
+	}
+	table := p.TableName
+
+	a.logger.Info(fmt.Sprintf("Command: InsertRows, table=%s, rows=%d, mode=%s", table, len(p.Rows), p.Mode))
+
+	t, err := getInsertTable(ctx, a.cached(db), table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error getting columns: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	res := InsertRowsResponse{Rows: []map[string]interface{}{}, Errors: []RowError{}}
+	var valid []int
+	for i, row := range p.Rows {
+		if err := a.prepareInsert(ctx, a.cached(db), t, row); err != nil {
+			res.Errors = append(res.Errors, RowError{Index: i, Error: a.rowError(err)})
+			continue
+		}
+		valid = append(valid, i)
+	}
+
+	validRows := make([]map[string]interface{}, len(valid))
+	for i, index := range valid {
+		validRows[i] = p.Rows[index]
+	}
+	findings, ok := a.checkSecrets(w, table, validRows...)
+	if !ok {
+		return
+	}
+	res.SecretWarnings = findings
+
+	scope, err := a.rowScope(ctx, table)
+	if err != nil {
+		a.writeScopeError(w, err)
+		return
+	}
+
+	tx, ok := a.beginMutation(ctx, w, db, table, params)
+	if !ok {
+		return
+	}
+	defer tx.Rollback()
+
+	primaryKey, err := getPrimaryKey(ctx, tx, table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error inserting rows: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	m := Mutation{Database: p.Database, Table: table, PrimaryKey: primaryKey, Keys: []interface{}{}, New: []map[string]interface{}{}}
+	for _, index := range valid {
+		inserted, err := a.insertRowSavepoint(ctx, tx, table, primaryKey, p.Rows[index], scope)
+		if err != nil {
+			res.Errors = append(res.Errors, RowError{Index: index, Error: a.rowError(err)})
+			continue
+		}
+		m.Keys = append(m.Keys, inserted[primaryKey])
+		m.New = append(m.New, inserted)
+	}
+	// The rows failing the checks were reported before the others
+	sort.SliceStable(res.Errors, func(i, j int) bool { return res.Errors[i].Index < res.Errors[j].Index })
+
+	if len(m.New) == 0 || (p.Mode == InsertAllOrNothing && len(res.Errors) > 0) {
+		a.logger.Info(fmt.Sprintf("No rows inserted, %d row(s) failed", len(res.Errors)))
+		res.Status = "failed"
+		json.NewEncoder(w).Encode(res)
+		return
+	}
+	if err := runBeforeHook(ctx, a.hooks.BeforeInsert, m); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		a.logger.Error(fmt.Sprintf("Error inserting rows: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: inserted %d row(s) into %s, %d row(s) failed", len(m.New), table, len(res.Errors)))
+	tx.afterCommit(ctx, func(ctx context.Context) {
+		a.afterMutation(ctx, a.hooks.AfterInsert, EventRowsInserted, m)
+	})
+
+	// The rows are copied so that the hooks are given the values as stored
+	for _, inserted := range m.New {
+		returned := make(map[string]interface{}, len(inserted))
+		for k, v := range inserted {
+			returned[k] = v
+		}
+		res.Rows = append(res.Rows, returned)
+	}
+	convertBooleans(res.Rows, t.columnTypes)
+	a.protectRows(ctx, table, res.Rows)
+	a.encodeRows(res.Rows)
+	res.Status = "ok"
+	json.NewEncoder(w).Encode(res)
+}
+
+// insertRowSavepoint inserts a row and checks it against the scope and the
+// validators of the table, undoing the insert if it fails.
+func (a *Admin) insertRowSavepoint(ctx context.Context, tx mutationTx, table, primaryKey string, row map[string]interface{}, scope *Condition) (map[string]interface{}, error) {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+rowSavepoint); err != nil {
+		return nil, err
+	}
+	inserted, err := a.insertCheckedRow(ctx, tx, table, primaryKey, row, scope)
+	if err != nil {
+		if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO "+rowSavepoint); rbErr != nil {
+			return nil, rbErr
+		}
+	}
+	if _, relErr := tx.ExecContext(ctx, "RELEASE "+rowSavepoint); relErr != nil {
+		return nil, relErr
+	}
+	return inserted, err
+}
+
+func (a *Admin) insertCheckedRow(ctx context.Context, tx mutationTx, table, primaryKey string, row map[string]interface{}, scope *Condition) (map[string]interface{}, error) {
+	inserted, err := insertRow(ctx, tx, "INSERT", table, row)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := inScope(ctx, tx, table, primaryKey, []interface{}{inserted[0][primaryKey]}, 1, scope)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrOutOfScope
+	}
+	if err := a.validateRows(table, inserted); err != nil {
+		return nil, invalidRowError{err}
+	}
+	return inserted[0], nil
+}
+
+// invalidRowError is a row rejected by the checks of its values, which is
+// reported with a 400.
+type invalidRowError struct {
+	err error
+}
+
+func (e invalidRowError) Error() string { return e.err.Error() }
+func (e invalidRowError) Unwrap() error { return e.err }
+
+// insertTable is what the rows inserted into a table are checked against.
+type insertTable struct {
+	name        string
+	columnTypes map[string]string
+	generated   map[string]string
+	strict      bool
+	enums       map[string][]string
+	geometries  map[string]geometryColumn
+}
+
+func getInsertTable(ctx context.Context, q queryer, table string) (*insertTable, error) {
+	t := &insertTable{name: table}
+	var err error
+	if t.columnTypes, err = getColumnTypes(ctx, q, table); err != nil {
+		return nil, err
+	}
+	if t.generated, err = getGeneratedColumns(ctx, q, table); err != nil {
+		return nil, err
+	}
+	if t.strict, err = isStrictTable(ctx, q, table); err != nil {
+		return nil, err
+	}
+	if t.enums, err = getEnumValues(ctx, q, table); err != nil {
+		return nil, err
+	}
+	if t.geometries, err = getGeometryColumns(ctx, q, table); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// prepareInsert converts the values of a row to insert and checks them. The
+// error is reported as by rowError.
+func (a *Admin) prepareInsert(ctx context.Context, q queryer, t *insertTable, row map[string]interface{}) error {
+	// Generated columns can't be inserted, like when updating a row
+	for column := range t.generated {
+		delete(row, column)
+	}
+	for column := range row {
+		if _, ok := t.columnTypes[column]; !ok {
+			return fmt.Errorf("%s: %w", column, ErrUnknownColumn)
+		}
+	}
+	if err := decodeGeometries(ctx, q, t.geometries, row); err != nil {
+		if errors.Is(err, ErrInvalidGeometry) {
+			return invalidRowError{err}
+		}
+		return err
+	}
+	storeBooleans(row, t.columnTypes)
+	if t.strict {
+		if err := checkStrictTypes(row, t.columnTypes); err != nil {
+			return invalidRowError{err}
+		}
+	}
+	if err := checkEnums(row, t.enums); err != nil {
+		return invalidRowError{err}
+	}
+	if err := a.applyWritePolicies(ctx, t.name, row); err != nil {
+		if errors.Is(err, ErrMaskedColumn) {
+			return err
+		}
+		return invalidRowError{err}
+	}
+	return nil
+}
+
+// rowError is the response to the error of a row: a 400 for invalid values, a
+// 403 for masked columns and rows out of scope, or else as for apiErr.
+func (a *Admin) rowError(err error) APIError {
+	var invalid invalidRowError
+	switch {
+	case errors.As(err, &invalid):
+		return apiErrBadRequest(err.Error())
+	case errors.Is(err, ErrMaskedColumn), errors.Is(err, ErrOutOfScope):
+		return apiErrForbidden(err.Error())
+	}
+	return a.apiErr(err)
+}
@@ -0,0 +1,92 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertRows(t *testing.T) {
+	var inserted []sqliteadmin.Mutation
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.Hooks.AfterInsert = func(ctx context.Context, m sqliteadmin.Mutation) {
+			inserted = append(inserted, m)
+		}
+	})
+	defer close()
+
+	_, err := ts.db.Exec(`
+		CREATE TABLE tasks (
+			id INTEGER PRIMARY KEY,
+			title TEXT NOT NULL UNIQUE,
+			status TEXT DEFAULT 'todo' CHECK (status IN ('todo', 'done'))
+		);
+	`)
+	assert.NoError(t, err)
+
+	rows := func() []map[string]interface{} {
+		return []map[string]interface{}{
+			{"title": "Write docs"},
+			{"title": nil},
+			{"title": "Ship", "status": "later"},
+			{"title": "Write docs"},
+			{"title": "Review", "status": "done"},
+			{"title": "Test", "owner": "alice"},
+		}
+	}
+	errorCodes := func(errs []sqliteadmin.RowError) map[int]sqliteadmin.ErrorCode {
+		codes := map[int]sqliteadmin.ErrorCode{}
+		for _, e := range errs {
+			codes[e.Index] = e.Error.Code
+		}
+		return codes
+	}
+	expectedErrors := map[int]sqliteadmin.ErrorCode{
+		1: sqliteadmin.CodeConstraintViolation,
+		2: sqliteadmin.CodeBadRequest,
+		3: sqliteadmin.CodeConstraintViolation,
+		5: sqliteadmin.CodeColumnNotFound,
+	}
+	count := func() int {
+		var n int
+		assert.NoError(t, ts.db.QueryRow("SELECT count(*) FROM tasks").Scan(&n))
+		return n
+	}
+	ctx := context.Background()
+
+	t.Run("All or nothing", func(t *testing.T) {
+		res, err := ts.admin.InsertRows(ctx, sqliteadmin.InsertRowsParams{TableName: "tasks", Rows: rows()})
+		assert.NoError(t, err)
+		assert.Equal(t, "failed", res.Status)
+		assert.Empty(t, res.Rows)
+		assert.Equal(t, expectedErrors, errorCodes(res.Errors))
+		assert.Equal(t, "Bad request: value not allowed: status must be one of todo, done", res.Errors[1].Error.Message)
+		assert.Equal(t, 0, count())
+		assert.Empty(t, inserted)
+	})
+
+	t.Run("Best effort", func(t *testing.T) {
+		res, err := ts.admin.InsertRows(ctx, sqliteadmin.InsertRowsParams{
+			TableName: "tasks",
+			Rows:      rows(),
+			Mode:      sqliteadmin.InsertBestEffort,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", res.Status)
+		assert.Equal(t, []map[string]interface{}{
+			{"id": float64(1), "title": "Write docs", "status": "todo"},
+			{"id": float64(2), "title": "Review", "status": "done"},
+		}, res.Rows)
+		assert.Equal(t, expectedErrors, errorCodes(res.Errors))
+		assert.Equal(t, 2, count())
+		assert.Len(t, inserted, 1)
+		assert.Equal(t, []interface{}{int64(1), int64(2)}, inserted[0].Keys)
+	})
+
+	t.Run("Invalid mode", func(t *testing.T) {
+		_, err := ts.admin.InsertRows(ctx, sqliteadmin.InsertRowsParams{TableName: "tasks", Rows: rows(), Mode: "some"})
+		assert.ErrorContains(t, err, "Bad request: invalid input")
+	})
+}
@@ -0,0 +1,109 @@
+package sqliteadmin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type JobStatus string
+
+const (
+	JobRunning JobStatus = "running"
+	JobDone    JobStatus = "done"
+	JobFailed  JobStatus = "failed"
+)
+
+// Job is a command that takes too long to answer the request, such as
+// CloneDatabase, and runs in the background instead. Its status is polled
+// with GetJob until it is done, after which the file it produced can be
+// downloaded from its artifact.
+type Job struct {
+	ID      string    `json:"id"`
+	Command Command   `json:"command"`
+	Status  JobStatus `json:"status"`
+	// Error is why the job failed.
+	Error    *APIError  `json:"error,omitempty"`
+	Artifact *Artifact  `json:"artifact,omitempty"`
+	Started  time.Time  `json:"started"`
+	Finished *time.Time `json:"finished,omitempty"`
+}
+
+// startJob runs the job in the background and returns it as started. The job
+// is given its own context, as it outlives the request starting it.
+func (a *Admin) startJob(command Command, run func(ctx context.Context) (*Artifact, error)) (Job, error) {
+	id, err := newID()
+	if err != nil {
+		return Job{}, err
+	}
+	job := &Job{ID: id, Command: command, Status: JobRunning, Started: time.Now()}
+
+	a.mu.Lock()
+	a.pruneJobs()
+	a.jobs[id] = job
+	started := *job
+	a.mu.Unlock()
+
+	a.logger.Info(fmt.Sprintf("Audit: started job %s (%s)", id, command))
+	go func() {
+		artifact, err := run(context.Background())
+		finished := time.Now()
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Job %s (%s) failed: %v", id, command, err))
+		} else {
+			a.logger.Info(fmt.Sprintf("Audit: job %s (%s) done", id, command))
+		}
+
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		job.Finished = &finished
+		if err != nil {
+			e := a.apiErr(err)
+			job.Status = JobFailed
+			job.Error = &e
+			return
+		}
+		job.Status = JobDone
+		job.Artifact = artifact
+	}()
+	return started, nil
+}
+
+// pruneJobs removes the jobs that finished before the lifetime of artifacts,
+// whose artifact can't be downloaded anymore. It must be called with a.mu
+// held.
+func (a *Admin) pruneJobs() {
+	cutoff := time.Now().Add(-a.artifactTTL)
+	for id, job := range a.jobs {
+		if job.Finished != nil && job.Finished.Before(cutoff) {
+			delete(a.jobs, id)
+		}
+	}
+}
+
+func (a *Admin) getJob(w http.ResponseWriter, params map[string]interface{}) {
+	id, ok := params["jobId"].(string)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrMissingJobID.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: GetJob, jobId=%s", id))
+
+	a.mu.Lock()
+	a.pruneJobs()
+	var job Job
+	found, ok := a.jobs[id]
+	if ok {
+		job = *found
+	}
+	a.mu.Unlock()
+
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrUnknownJobID.Error()))
+		return
+	}
+	json.NewEncoder(w).Encode(job)
+}
@@ -0,0 +1,428 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Values of the "join" param of JoinTables.
+const (
+	// joinInner returns the rows having a match in the other table.
+	joinInner = "inner"
+	// joinLeft returns all the rows, with their match in the other table if
+	// any.
+	joinLeft = "left"
+	// joinAnti returns the rows without a match in the other table.
+	joinAnti = "anti"
+)
+
+// attachedSchema is the schema the other database of JoinTables is attached
+// as for the duration of the command.
+const attachedSchema = "sqliteadmin_other"
+
+// joinMatchedColumn tells rows of a left join that have a match apart from
+// matches whose columns are all NULL.
+const joinMatchedColumn = "sqliteadmin_matched"
+
+// joinTables joins a table with a table of another registered database
+// ("otherDatabase"), e.g. to compare the users of two tenants. The other
+// database is attached read-only to a dedicated connection of the first one,
+// and detached before the connection goes back to the pool. The "on" param
+// maps the columns of the table to the columns of the other table they must
+// be equal to. Both tables are read with their scopes and column policies, as
+// GetTable would return them.
+func (a *Admin) joinTables(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getReadDB(w, params)
+	if !ok {
+		return
+	}
+	table, ok := params["tableName"].(string)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrMissingTableName.Error()))
+		return
+	}
+	otherName, ok := params["otherDatabase"].(string)
+	if !ok {
+		otherName, _ = params["database"].(string)
+	}
+	other, ok := a.getNamedDB(w, otherName)
+	if !ok {
+		return
+	}
+	otherTable, _ := params["otherTable"].(string)
+	if otherTable == "" {
+		otherTable = table
+	}
+
+	join, _ := params["join"].(string)
+	switch join {
+	case "":
+		join = joinInner
+	case joinInner, joinLeft, joinAnti:
+	default:
+		writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+		return
+	}
+
+	on, ok := toStringMap(params["on"])
+	if !ok || len(on) == 0 {
+		writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+		return
+	}
+	columns, ok := toStringSlice(params["columns"])
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+		return
+	}
+	otherColumns, ok := toStringSlice(params["otherColumns"])
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+		return
+	}
+
+	condition, ok := a.conditionParam(w, params, "condition", table)
+	if !ok {
+		return
+	}
+	otherCondition, ok := a.conditionParam(w, params, "otherCondition", otherTable)
+	if !ok {
+		return
+	}
+
+	limit := a.defaultLimit
+	if l, ok := convertNumber(params["limit"]); ok {
+		limit = l
+	}
+	offset := DefaultOffset
+	if o, ok := convertNumber(params["offset"]); ok {
+		offset = o
+	}
+	if exceeds(limit, a.maxLimit) || (a.maxLimit >= 0 && limit < 0) {
+		writeError(w, apiErrBadRequest(ErrLimitTooLarge.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: JoinTables, table=%s, otherDatabase=%s, otherTable=%s, join=%s, limit=%d, offset=%d",
+		table, otherName, otherTable, join, limit, offset))
+
+	if hasInternalPrefix(table) || hasInternalPrefix(otherTable) {
+		writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+		return
+	}
+
+	tableColumns, err := getColumnNames(ctx, a.cached(db), table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error getting columns: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	otherTableColumns, err := getColumnNames(ctx, a.cached(other), otherTable)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error getting columns: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	columns, ok = selectColumns(columns, tableColumns)
+	if ok {
+		otherColumns, ok = selectColumns(otherColumns, otherTableColumns)
+	}
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+		return
+	}
+	for _, c := range []struct {
+		condition *Condition
+		columns   []string
+	}{{condition, tableColumns}, {otherCondition, otherTableColumns}} {
+		if c.condition == nil {
+			continue
+		}
+		if err := checkColumns(conditionColumns(c.condition), slices.Concat(c.columns, rowidColumns)); err != nil {
+			writeError(w, a.apiErr(err))
+			return
+		}
+	}
+	for c, o := range on {
+		if !contains(tableColumns, c) || !contains(otherTableColumns, o) {
+			writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+			return
+		}
+		// Joining on hidden values would reveal which ones are equal
+		if p, ok := a.columnPolicy(table, c); ok && p.hides() {
+			writeError(w, apiErrBadRequest(fmt.Sprintf("%s: %s", ErrHiddenColumn, c)))
+			return
+		}
+		if p, ok := a.columnPolicy(otherTable, o); ok && p.hides() {
+			writeError(w, apiErrBadRequest(fmt.Sprintf("%s: %s", ErrHiddenColumn, o)))
+			return
+		}
+	}
+
+	scope, err := a.rowScope(ctx, table)
+	if err != nil {
+		a.writeScopeError(w, err)
+		return
+	}
+	if scope != nil {
+		condition = scopeCondition(scope, condition)
+	}
+	otherScope, err := a.rowScope(ctx, otherTable)
+	if err != nil {
+		a.writeScopeError(w, err)
+		return
+	}
+	if otherScope != nil {
+		otherCondition = scopeCondition(otherScope, otherCondition)
+	}
+
+	var attach string
+	if other != db {
+		path, err := databaseFile(ctx, db)
+		var otherPath string
+		if err == nil {
+			otherPath, err = databaseFile(ctx, other)
+		}
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Error getting database file: %v", err))
+			writeError(w, a.apiErr(err))
+			return
+		}
+		if otherPath == "" {
+			writeError(w, apiErrBadRequest(ErrAttachUnsupported.Error()))
+			return
+		}
+		// Tables of the same database are joined without attaching it
+		if otherPath != path {
+			attach = otherPath
+		}
+	}
+
+	rows, err := a.queryJoin(ctx, db, attach, joinQuery{
+		table: table, otherTable: otherTable, join: join, on: on,
+		columns: columns, otherColumns: otherColumns,
+		condition: condition, otherCondition: otherCondition,
+		limit: limit, offset: offset,
+	})
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error joining tables: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+
+	left := make([]map[string]interface{}, 0, len(rows))
+	right := make([]map[string]interface{}, 0, len(rows))
+	for _, r := range rows {
+		left = append(left, r.Row)
+		if r.Other != nil {
+			right = append(right, r.Other)
+		}
+	}
+	a.protectRows(ctx, table, left)
+	a.protectRows(ctx, otherTable, right)
+	a.encodeRows(left)
+	a.encodeRows(right)
+	a.recordRows(len(rows))
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"rows": rows})
+}
+
+// JoinedRow is a row returned by JoinTables with its match in the other table,
+// which is nil for the rows of a left join without a match and for anti joins.
+type JoinedRow struct {
+	Row   map[string]interface{} `json:"row"`
+	Other map[string]interface{} `json:"other,omitempty"`
+}
+
+type joinQuery struct {
+	table, otherTable         string
+	join                      string
+	on                        map[string]string
+	columns, otherColumns     []string
+	condition, otherCondition *Condition
+	limit, offset             int
+}
+
+// queryJoin runs the join on a dedicated connection to db, with the database
+// file at path attached if it isn't empty.
+func (a *Admin) queryJoin(ctx context.Context, db *sql.DB, path string, j joinQuery) ([]JoinedRow, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	otherSchema := "main"
+	if path != "" {
+		u := url.URL{Scheme: "file", Path: path, RawQuery: "mode=ro"}
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("ATTACH DATABASE ? AS %s", quoteIdent(attachedSchema)), u.String()); err != nil {
+			return nil, fmt.Errorf("error attaching database: %v", err)
+		}
+		defer a.detach(conn, attachedSchema)
+		otherSchema = attachedSchema
+	}
+
+	subquery := func(schema, table string, condition *Condition, extra string) (string, []interface{}) {
+		query := fmt.Sprintf("SELECT *%s FROM %s.%s", extra, quoteIdent(schema), quoteIdent(table))
+		if condition == nil || len(condition.Cases) == 0 {
+			return query, nil
+		}
+		where, args := conditionExpr(condition).SQL()
+		return query + " WHERE " + where, args
+	}
+	keys := make([]string, 0, len(j.on))
+	for c := range j.on {
+		keys = append(keys, c)
+	}
+	sort.Strings(keys)
+	var onClauses []string
+	for _, c := range keys {
+		onClauses = append(onClauses, fmt.Sprintf("l.%s = r.%s", quoteIdent(c), quoteIdent(j.on[c])))
+	}
+
+	var selected []string
+	for i, c := range j.columns {
+		selected = append(selected, fmt.Sprintf("l.%s AS \"l%d\"", quoteIdent(c), i))
+	}
+	left, args := subquery("main", j.table, j.condition, "")
+	right, otherArgs := subquery(otherSchema, j.otherTable, j.otherCondition, fmt.Sprintf(", 1 AS %s", quoteIdent(joinMatchedColumn)))
+	var query string
+	if j.join == joinAnti {
+		query = fmt.Sprintf("SELECT %s FROM (%s) AS l WHERE NOT EXISTS (SELECT 1 FROM (%s) AS r WHERE %s)",
+			strings.Join(selected, ", "), left, right, strings.Join(onClauses, " AND "))
+	} else {
+		for i, c := range j.otherColumns {
+			selected = append(selected, fmt.Sprintf("r.%s AS \"r%d\"", quoteIdent(c), i))
+		}
+		selected = append(selected, fmt.Sprintf("r.%s AS \"m\"", quoteIdent(joinMatchedColumn)))
+		query = fmt.Sprintf("SELECT %s FROM (%s) AS l %s JOIN (%s) AS r ON %s",
+			strings.Join(selected, ", "), left, strings.ToUpper(j.join), right, strings.Join(onClauses, " AND "))
+	}
+	query += " LIMIT ? OFFSET ?"
+	args = append(append(args, otherArgs...), j.limit, j.offset)
+
+	a.logger.Info(fmt.Sprintf("About to perform query: `%s`", query))
+	results, err := queryRows(ctx, conn, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]JoinedRow, len(results))
+	for i, result := range results {
+		rows[i].Row = make(map[string]interface{}, len(j.columns))
+		for k, c := range j.columns {
+			rows[i].Row[c] = result[fmt.Sprintf("l%d", k)]
+		}
+		if result["m"] == nil {
+			continue
+		}
+		rows[i].Other = make(map[string]interface{}, len(j.otherColumns))
+		for k, c := range j.otherColumns {
+			rows[i].Other[c] = result[fmt.Sprintf("r%d", k)]
+		}
+	}
+	return rows, nil
+}
+
+// detach detaches the schema from the connection. A connection that can't be
+// detached is discarded rather than returned to the pool with the database
+// still attached.
+func (a *Admin) detach(conn *sql.Conn, schema string) {
+	_, err := conn.ExecContext(context.Background(), fmt.Sprintf("DETACH DATABASE %s", quoteIdent(schema)))
+	if err == nil {
+		return
+	}
+	a.logger.Error(fmt.Sprintf("Error detaching database: %v", err))
+	conn.Raw(func(interface{}) error {
+		return driver.ErrBadConn
+	})
+}
+
+// databaseFile returns the path of the file of the database, which is empty
+// for in-memory databases.
+func databaseFile(ctx context.Context, db *sql.DB) (string, error) {
+	var seq int
+	var name, file string
+	err := db.QueryRowContext(ctx, "SELECT seq, name, file FROM pragma_database_list WHERE name = 'main'").Scan(&seq, &name, &file)
+	return file, err
+}
+
+// conditionParam parses the condition of the table in the params under key,
+// which is nil if there is none. It writes an error and returns false if the
+// condition is invalid.
+func (a *Admin) conditionParam(w http.ResponseWriter, params map[string]interface{}, key, table string) (*Condition, bool) {
+	raw, ok := params[key]
+	if !ok {
+		return nil, true
+	}
+	condition, ok := toCondition(raw, a.logger)
+	if !ok {
+		writeError(w, apiErrBadRequest("Invalid condition"))
+		return nil, false
+	}
+	if exceeds(conditionDepth(condition), a.maxConditionDepth) {
+		writeError(w, apiErrBadRequest(ErrConditionTooDeep.Error()))
+		return nil, false
+	}
+	if !a.checkCondition(w, table, condition) {
+		return nil, false
+	}
+	return condition, true
+}
+
+// selectColumns checks the selected columns against the columns of the
+// table, all of which are selected when none are. It returns false for tables
+// that don't exist.
+func selectColumns(selected, columns []string) ([]string, bool) {
+	if len(selected) == 0 {
+		return columns, len(columns) > 0
+	}
+	for _, c := range selected {
+		if !contains(columns, c) {
+			return nil, false
+		}
+	}
+	return selected, true
+}
+
+func toStringMap(val interface{}) (map[string]string, bool) {
+	raw, ok := val.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	m := make(map[string]string, len(raw))
+	for k, v := range raw {
+		s, ok := v.(string)
+		if !ok {
+			return nil, false
+		}
+		m[k] = s
+	}
+	return m, true
+}
+
+// toStringSlice converts a JSON array of strings, returning nil for a missing
+// value.
+func toStringSlice(val interface{}) ([]string, bool) {
+	if val == nil {
+		return nil, true
+	}
+	raw, ok := val.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	s := make([]string, len(raw))
+	for i, v := range raw {
+		if s[i], ok = v.(string); !ok {
+			return nil, false
+		}
+	}
+	return s, true
+}
@@ -0,0 +1,141 @@
+package sqliteadmin_test
+
+import (
+	"database/sql"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJoinTables(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	tenants := map[string]string{
+		"tenant_a": "INSERT INTO users (id, email, plan) VALUES (1, 'alice@gmail.com', 'pro'), (2, 'bob@gmail.com', 'free')",
+		"tenant_b": "INSERT INTO users (id, email, plan) VALUES (10, 'alice@gmail.com', 'free'), (11, 'carol@gmail.com', 'pro')",
+	}
+	dbs := map[string]*sql.DB{}
+	for name, insert := range tenants {
+		db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), name+".db"))
+		assert.NoError(t, err)
+		defer db.Close()
+		// A single connection makes sure the one used to attach is reused
+		db.SetMaxOpenConns(1)
+		_, err = db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT, plan TEXT)")
+		assert.NoError(t, err)
+		_, err = db.Exec(insert)
+		assert.NoError(t, err)
+		ts.admin.AddDatabase(name, db)
+		dbs[name] = db
+	}
+
+	params := func(join string) map[string]interface{} {
+		return map[string]interface{}{
+			"database":      "tenant_a",
+			"tableName":     "users",
+			"otherDatabase": "tenant_b",
+			"on":            map[string]interface{}{"email": "email"},
+			"columns":       []interface{}{"id", "email"},
+			"otherColumns":  []interface{}{"id", "plan"},
+			"join":          join,
+		}
+	}
+	alice := map[string]interface{}{
+		"row":   map[string]interface{}{"id": float64(1), "email": "alice@gmail.com"},
+		"other": map[string]interface{}{"id": float64(10), "plan": "free"},
+	}
+	bob := map[string]interface{}{
+		"row": map[string]interface{}{"id": float64(2), "email": "bob@gmail.com"},
+	}
+
+	runTestCases([]TestCase{
+		{
+			name:             "Success: Inner",
+			params:           params(""),
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"rows": []interface{}{alice}},
+		},
+		{
+			name:             "Success: Left",
+			params:           params("left"),
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"rows": []interface{}{alice, bob}},
+		},
+		{
+			name:             "Success: Anti",
+			params:           params("anti"),
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"rows": []interface{}{bob}},
+		},
+		{
+			name: "Success: Same Database",
+			params: map[string]interface{}{
+				"tableName":    "users",
+				"on":           map[string]interface{}{"id": "id"},
+				"columns":      []interface{}{"name"},
+				"otherColumns": []interface{}{"email"},
+				"condition": sqliteadmin.Condition{
+					Cases:           []sqliteadmin.Case{sqliteadmin.Filter{Column: "id", Operator: sqliteadmin.OperatorEquals, Value: "1"}},
+					LogicalOperator: sqliteadmin.LogicalOperatorAnd,
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{"rows": []interface{}{
+				map[string]interface{}{
+					"row":   map[string]interface{}{"name": "Alice"},
+					"other": map[string]interface{}{"email": "alice@gmail.com"},
+				},
+			}},
+		},
+		{
+			name: "Failure: In-Memory Database",
+			params: map[string]interface{}{
+				"database":      "tenant_a",
+				"tableName":     "users",
+				"otherDatabase": "",
+				"on":            map[string]interface{}{"id": "id"},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(400),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: " + sqliteadmin.ErrAttachUnsupported.Error(),
+			},
+		},
+		{
+			name: "Failure: Unknown Column",
+			params: map[string]interface{}{
+				"database":      "tenant_a",
+				"tableName":     "users",
+				"otherDatabase": "tenant_b",
+				"on":            map[string]interface{}{"name": "name"},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(400),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: " + sqliteadmin.ErrInvalidInput.Error(),
+			},
+		},
+		{
+			name:           "Failure: Missing On",
+			params:         map[string]interface{}{"database": "tenant_a", "tableName": "users", "otherDatabase": "tenant_b"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(400),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: " + sqliteadmin.ErrInvalidInput.Error(),
+			},
+		},
+	}, sqliteadmin.JoinTables, t, ts.server)
+
+	// The other database was detached from the connection
+	var attached int
+	err := dbs["tenant_a"].QueryRow("SELECT count(*) FROM pragma_database_list").Scan(&attached)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attached)
+}
@@ -0,0 +1,36 @@
+package sqliteadmin
+
+// Default request limits, used when the corresponding Config field is zero.
+const (
+	DefaultMaxBodySize       int64 = 10 << 20
+	DefaultMaxDeleteIDs            = 1000
+	DefaultMaxLimit                = 10000
+	DefaultMaxConditionDepth       = 16
+)
+
+// limitOrDefault returns the configured limit, falling back to the default
+// when it is zero. A negative value disables the limit.
+func limitOrDefault[T int | int64](configured, def T) T {
+	if configured == 0 {
+		return def
+	}
+	return configured
+}
+
+// exceeds reports whether n is over the limit, negative limits being
+// unlimited.
+func exceeds[T int | int64](n, limit T) bool {
+	return limit >= 0 && n > limit
+}
+
+// conditionDepth returns how deeply the condition is nested, a condition
+// without sub-conditions having a depth of 1.
+func conditionDepth(condition *Condition) int {
+	depth := 0
+	for _, c := range condition.Cases {
+		if sub, ok := c.(Condition); ok {
+			depth = max(depth, conditionDepth(&sub))
+		}
+	}
+	return depth + 1
+}
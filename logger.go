@@ -0,0 +1,170 @@
+package sqliteadmin
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ContextLogger can be implemented by a Logger to receive the context of the
+// request a line is logged for, e.g. to extract tracing information. The
+// request ID, user and command can be read from the context with
+// RequestIDFromContext, UserFromContext and CommandFromContext. *slog.Logger
+// implements it.
+type ContextLogger interface {
+	InfoContext(ctx context.Context, msg string, args ...any)
+	ErrorContext(ctx context.Context, msg string, args ...any)
+	DebugContext(ctx context.Context, msg string, args ...any)
+}
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	userKey
+	commandKey
+	elevatedKey
+	principalKey
+	unsealKey
+	queryLogKey
+	roleKey
+	claimsKey
+	commandsKey
+)
+
+// RequestIDFromContext returns the ID of the request being handled.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// UserFromContext returns the user that authenticated the request being
+// handled, or an empty string if authentication is disabled.
+func UserFromContext(ctx context.Context) string {
+	user, _ := ctx.Value(userKey).(string)
+	return user
+}
+
+// CommandFromContext returns the command of the request being handled, or an
+// empty string if it hasn't been decoded yet.
+func CommandFromContext(ctx context.Context) Command {
+	command, _ := ctx.Value(commandKey).(Command)
+	return command
+}
+
+// LogSampling limits how often the same error is logged: after Burst
+// identical messages within Window, the message is suppressed until the
+// window ends, at which point the number of suppressed messages is logged
+// along with the next occurrence.
+type LogSampling struct {
+	Window time.Duration
+	Burst  int
+}
+
+var _ Logger = &samplingLogger{}
+var _ ContextLogger = &samplingLogger{}
+
+type samplingLogger struct {
+	Logger
+	sampling LogSampling
+
+	mu      sync.Mutex
+	entries map[string]*sampleEntry
+}
+
+type sampleEntry struct {
+	start      time.Time
+	count      int
+	suppressed int
+}
+
+func newSamplingLogger(l Logger, sampling LogSampling) *samplingLogger {
+	return &samplingLogger{Logger: l, sampling: sampling, entries: make(map[string]*sampleEntry)}
+}
+
+// sample reports whether the message should be logged and adds the number of
+// messages suppressed since it was last logged to the args.
+func (l *samplingLogger) sample(msg string, args []interface{}) ([]interface{}, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	e, ok := l.entries[msg]
+	if !ok || now.Sub(e.start) >= l.sampling.Window {
+		// Forget the messages of past windows so that the map doesn't grow
+		// with every distinct message ever logged.
+		for m, e := range l.entries {
+			if now.Sub(e.start) >= l.sampling.Window && e.suppressed == 0 {
+				delete(l.entries, m)
+			}
+		}
+		suppressed := 0
+		if ok {
+			suppressed = e.suppressed
+		}
+		e = &sampleEntry{start: now}
+		l.entries[msg] = e
+		if suppressed > 0 {
+			args = append(args, "suppressed", suppressed)
+		}
+	}
+
+	e.count++
+	if e.count > l.sampling.Burst {
+		e.suppressed++
+		return nil, false
+	}
+	return args, true
+}
+
+func (l *samplingLogger) Error(msg string, args ...interface{}) {
+	if args, ok := l.sample(msg, args); ok {
+		l.Logger.Error(msg, args...)
+	}
+}
+
+func (l *samplingLogger) InfoContext(ctx context.Context, msg string, args ...any) {
+	logContext(ctx, l.Logger, levelInfo, msg, args)
+}
+
+func (l *samplingLogger) ErrorContext(ctx context.Context, msg string, args ...any) {
+	if args, ok := l.sample(msg, args); ok {
+		logContext(ctx, l.Logger, levelError, msg, args)
+	}
+}
+
+func (l *samplingLogger) DebugContext(ctx context.Context, msg string, args ...any) {
+	logContext(ctx, l.Logger, levelDebug, msg, args)
+}
+
+type logLevel int
+
+const (
+	levelInfo logLevel = iota
+	levelError
+	levelDebug
+)
+
+// logContext logs with the context if the logger supports it.
+func logContext(ctx context.Context, l Logger, level logLevel, msg string, args []interface{}) {
+	if cl, ok := l.(ContextLogger); ok {
+		switch level {
+		case levelInfo:
+			cl.InfoContext(ctx, msg, args...)
+		case levelError:
+			cl.ErrorContext(ctx, msg, args...)
+		case levelDebug:
+			cl.DebugContext(ctx, msg, args...)
+		}
+		return
+	}
+
+	switch level {
+	case levelInfo:
+		l.Info(msg, args...)
+	case levelError:
+		l.Error(msg, args...)
+	case levelDebug:
+		l.Debug(msg, args...)
+	}
+}
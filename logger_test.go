@@ -0,0 +1,109 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type logLine struct {
+	level     string
+	msg       string
+	requestID string
+	user      string
+	command   sqliteadmin.Command
+}
+
+// contextLogger records the lines logged with a context.
+type contextLogger struct {
+	mu    sync.Mutex
+	lines []logLine
+}
+
+func (l *contextLogger) Info(format string, args ...interface{})  {}
+func (l *contextLogger) Error(format string, args ...interface{}) {}
+func (l *contextLogger) Debug(format string, args ...interface{}) {}
+
+func (l *contextLogger) log(ctx context.Context, level, msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, logLine{
+		level:     level,
+		msg:       msg,
+		requestID: sqliteadmin.RequestIDFromContext(ctx),
+		user:      sqliteadmin.UserFromContext(ctx),
+		command:   sqliteadmin.CommandFromContext(ctx),
+	})
+}
+
+func (l *contextLogger) InfoContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, "info", msg)
+}
+
+func (l *contextLogger) ErrorContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, "error", msg)
+}
+
+func (l *contextLogger) DebugContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, "debug", msg)
+}
+
+func TestContextLogger(t *testing.T) {
+	logger := &contextLogger{}
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.Logger = logger
+	})
+	defer close()
+
+	res, err := http.DefaultClient.Do(makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{
+		Command: sqliteadmin.ListTables,
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	assert.Equal(t, []logLine{
+		{
+			level:     "info",
+			msg:       "Command: ListTables",
+			requestID: res.Header.Get(sqliteadmin.RequestIDHeader),
+			user:      "user",
+			command:   sqliteadmin.ListTables,
+		},
+	}, logger.lines)
+}
+
+func TestLogSampling(t *testing.T) {
+	logger := &contextLogger{}
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.Logger = logger
+		c.LogSampling = &sqliteadmin.LogSampling{Window: time.Hour, Burst: 2}
+	})
+	defer close()
+
+	for i := 0; i < 5; i++ {
+		res, err := http.DefaultClient.Do(makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{
+			Command: sqliteadmin.GetTable,
+			Params:  map[string]interface{}{"tableName": "missing"},
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	}
+
+	var errors, infos int
+	for _, line := range logger.lines {
+		switch line.level {
+		case "error":
+			errors++
+		case "info":
+			infos++
+		}
+	}
+	// Identical errors are sampled while other lines are all logged
+	assert.Equal(t, 2, errors)
+	assert.Equal(t, 5, infos)
+}
@@ -0,0 +1,160 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultWaitTimeout is how long WaitForChanges waits when no timeout is
+	// provided.
+	DefaultWaitTimeout = 20 * time.Second
+	// MaxWaitTimeout bounds the timeout of WaitForChanges, below the write
+	// timeout of the CLI server.
+	MaxWaitTimeout = 25 * time.Second
+)
+
+const (
+	// waitPollInterval is how often WaitForChanges checks the data version.
+	waitPollInterval = 100 * time.Millisecond
+	// versionBusyTimeout is how long reading the data version waits for a
+	// lock held by a write.
+	versionBusyTimeout = time.Second
+)
+
+// versionWatcher reads PRAGMA data_version on a connection dedicated to each
+// database. The data version only changes when other connections commit, and
+// differs between connections, which is why the same connection must be used
+// for a version to be comparable with the next ones.
+type versionWatcher struct {
+	mu    sync.Mutex
+	conns map[*sql.DB]*sql.Conn
+}
+
+func newVersionWatcher() *versionWatcher {
+	return &versionWatcher{conns: make(map[*sql.DB]*sql.Conn)}
+}
+
+func (v *versionWatcher) version(ctx context.Context, db *sql.DB) (int64, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	conn, ok := v.conns[db]
+	if !ok {
+		var err error
+		conn, err = db.Conn(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("error getting connection: %v", err)
+		}
+		// Reading the version waits for the commits of other connections
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("PRAGMA busy_timeout = %d", versionBusyTimeout.Milliseconds())); err != nil {
+			conn.Close()
+			return 0, fmt.Errorf("error setting busy timeout: %v", err)
+		}
+		v.conns[db] = conn
+	}
+
+	var version int64
+	if err := conn.QueryRowContext(ctx, "PRAGMA data_version").Scan(&version); err != nil {
+		// The next call gets a new connection, whose versions clients will
+		// see as a change.
+		conn.Close()
+		delete(v.conns, db)
+		return 0, fmt.Errorf("error reading data version: %v", err)
+	}
+	return version, nil
+}
+
+// forget releases the connection dedicated to db.
+func (v *versionWatcher) forget(db *sql.DB) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if conn, ok := v.conns[db]; ok {
+		conn.Close()
+		delete(v.conns, db)
+	}
+}
+
+// waitForChanges is a long-poll alternative to streaming changes: it responds
+// as soon as the database changes after the "version" token returned by a
+// previous call, or when the timeout elapses, so that the UI can refresh
+// without polling the tables themselves.
+func (a *Admin) waitForChanges(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getReadDB(w, params)
+	if !ok {
+		return
+	}
+
+	timeout := DefaultWaitTimeout
+	if params["timeout"] != nil {
+		seconds, ok := params["timeout"].(float64)
+		if !ok || seconds < 0 {
+			writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+			return
+		}
+		timeout = min(time.Duration(seconds*float64(time.Second)), MaxWaitTimeout)
+	}
+
+	var since int64
+	token, _ := params["version"].(string)
+	if token != "" {
+		var err error
+		since, err = strconv.ParseInt(token, 10, 64)
+		if err != nil {
+			writeError(w, apiErrBadRequest(ErrInvalidVersion.Error()))
+			return
+		}
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: WaitForChanges, version=%s, timeout=%s", token, timeout))
+
+	// The dedicated connection would starve every other request of a pool
+	// with a single connection.
+	if db.Stats().MaxOpenConnections == 1 {
+		writeError(w, apiErrBadRequest(ErrLongPollUnsupported.Error()))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(waitPollInterval)
+	defer ticker.Stop()
+
+	for {
+		version, err := a.versions.version(ctx, db)
+		if err != nil {
+			if ctx.Err() != nil {
+				// The timeout elapsed while waiting for the connection
+				json.NewEncoder(w).Encode(map[string]interface{}{"changed": false, "version": token})
+				return
+			}
+			a.logger.Error(fmt.Sprintf("Error reading data version: %v", err))
+			writeError(w, a.apiErr(err))
+			return
+		}
+
+		// Without a token, the current version is returned right away for
+		// the next call to wait on.
+		if token == "" || version != since {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"changed": token != "",
+				"version": strconv.FormatInt(version, 10),
+			})
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			json.NewEncoder(w).Encode(map[string]interface{}{"changed": false, "version": token})
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package sqliteadmin_test
+
+import (
+	"database/sql"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitForChanges(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "app.db"))
+	assert.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("CREATE TABLE t (id INTEGER PRIMARY KEY)")
+	assert.NoError(t, err)
+	ts.admin.AddDatabase("app", db)
+
+	wait := func(params map[string]interface{}) map[string]interface{} {
+		params["database"] = "app"
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: sqliteadmin.WaitForChanges, Params: params})
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		return readBody(t, res.Body)
+	}
+
+	body := wait(map[string]interface{}{})
+	assert.Equal(t, false, body["changed"])
+	version, _ := body["version"].(string)
+	assert.NotEmpty(t, version)
+
+	t.Run("Timeout", func(t *testing.T) {
+		start := time.Now()
+		body := wait(map[string]interface{}{"version": version, "timeout": 0.2})
+		assert.Equal(t, map[string]interface{}{"changed": false, "version": version}, body)
+		assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	})
+
+	t.Run("Change", func(t *testing.T) {
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			_, err := db.Exec("INSERT INTO t (id) VALUES (1)")
+			assert.NoError(t, err)
+		}()
+		body := wait(map[string]interface{}{"version": version, "timeout": 5.0})
+		assert.Equal(t, true, body["changed"])
+		assert.NotEqual(t, version, body["version"])
+	})
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Invalid Version",
+			params:         map[string]interface{}{"database": "app", "version": "abc"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid version",
+			},
+		},
+		{
+			name:           "Failure: Single Connection",
+			params:         map[string]interface{}{},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: waiting for changes requires a database with more than one connection",
+			},
+		},
+	}, sqliteadmin.WaitForChanges, t, ts.server)
+}
@@ -0,0 +1,320 @@
+package sqliteadmin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Values of the "fields" param of MergeRows which decide which row's value is
+// kept for a column.
+const (
+	mergeResolutionKeep  = "keep"
+	mergeResolutionMerge = "merge"
+)
+
+func (a *Admin) mergeRows(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return
+	}
+
+	table, ok := params["tableName"].(string)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrMissingTableName.Error()))
+		return
+	}
+
+	keepID, ok := params["keepId"]
+	if !ok || keepID == nil {
+		writeError(w, apiErrBadRequest(ErrInvalidOrMissingIds.Error()))
+		return
+	}
+	mergeID, ok := params["mergeId"]
+	if !ok || mergeID == nil {
+		writeError(w, apiErrBadRequest(ErrInvalidOrMissingIds.Error()))
+		return
+	}
+
+	fields := map[string]string{}
+	if rawFields, ok := params["fields"].(map[string]interface{}); ok {
+		for column, resolution := range rawFields {
+			r, ok := resolution.(string)
+			if !ok || (r != mergeResolutionKeep && r != mergeResolutionMerge) {
+				writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+				return
+			}
+			fields[column] = r
+		}
+	}
+
+	// Values are copied as they are stored, so they aren't normalized or
+	// encrypted again, but masked columns still require elevation
+	for column, resolution := range fields {
+		if p, ok := a.columnPolicy(table, column); ok && p.Mask && resolution == mergeResolutionMerge && !isElevated(ctx) {
+			writeError(w, apiErrForbidden(fmt.Sprintf("%s: %s", ErrMaskedColumn, column)))
+			return
+		}
+	}
+
+	var refs []foreignKeyRef
+	if rawRefs, ok := params["references"].([]interface{}); ok {
+		for _, rawRef := range rawRefs {
+			refMap, ok := rawRef.(map[string]interface{})
+			if !ok {
+				writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+				return
+			}
+			refTable, _ := refMap["tableName"].(string)
+			refColumn, _ := refMap["column"].(string)
+			if refTable == "" || refColumn == "" {
+				writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+				return
+			}
+			refs = append(refs, foreignKeyRef{Table: refTable, Column: refColumn})
+		}
+	}
+
+	for _, ref := range refs {
+		if !a.tableAllowed(ref.Table) {
+			writeError(w, apiErrTableNotFound())
+			return
+		}
+	}
+	// Merging a row into itself would delete it
+	if fmt.Sprint(keepID) == fmt.Sprint(mergeID) {
+		writeError(w, apiErrBadRequest(ErrMergeSameRow.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: MergeRows, table=%s, keepId=%v, mergeId=%v", table, keepID, mergeID))
+
+	tx, ok := a.beginMutation(ctx, w, db, table, params)
+	if !ok {
+		return
+	}
+	defer tx.Rollback()
+
+	merge, err := planMerge(ctx, tx, table, keepID, mergeID, fields, refs)
+	if errors.Is(err, ErrInvalidOrMissingIds) || errors.Is(err, ErrInvalidInput) {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error merging rows: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+
+	// Both rows must be in the scope, and so must the rows re-pointed
+	scope, err := a.rowScope(ctx, table)
+	if err != nil {
+		a.writeScopeError(w, err)
+		return
+	}
+	if ok, err := inScope(ctx, tx, table, merge.primaryKey, []interface{}{keepID, mergeID}, 2, scope); err != nil || !ok {
+		a.writeInScopeError(w, err)
+		return
+	}
+	for _, ref := range merge.refs {
+		refScope, err := a.rowScope(ctx, ref.Table)
+		if err != nil {
+			a.writeScopeError(w, err)
+			return
+		}
+		if ok, err := refsInScope(ctx, tx, ref, mergeID, refScope); err != nil || !ok {
+			a.writeInScopeError(w, err)
+			return
+		}
+	}
+
+	// The merged row is deleted and the kept one updated with the same
+	// checks and hooks as DeleteRows and UpdateRow
+	database, _ := params["database"].(string)
+	deleted := Mutation{Database: database, Table: table, PrimaryKey: merge.primaryKey, Keys: []interface{}{mergeID}, Old: []map[string]interface{}{merge.merged}}
+	updated := Mutation{Database: database, Table: table, PrimaryKey: merge.primaryKey, Keys: []interface{}{keepID}, Old: []map[string]interface{}{merge.kept}}
+	var findings []SecretFinding
+	if len(merge.values) > 0 {
+		findings, ok = a.checkSecrets(w, table, merge.values)
+		if !ok {
+			return
+		}
+		row := make(map[string]interface{}, len(merge.kept))
+		for k, v := range merge.kept {
+			row[k] = v
+		}
+		for k, v := range merge.values {
+			row[k] = v
+		}
+		updated.New = []map[string]interface{}{row}
+		if err := a.validateRows(table, updated.New); err != nil {
+			writeError(w, apiErrBadRequest(err.Error()))
+			return
+		}
+	}
+	if err := runBeforeHook(ctx, a.hooks.BeforeDelete, deleted); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	if updated.New != nil {
+		if err := runBeforeHook(ctx, a.hooks.BeforeUpdate, updated); err != nil {
+			writeError(w, apiErrBadRequest(err.Error()))
+			return
+		}
+	}
+
+	repointed, err := merge.apply(ctx, tx)
+	if err == nil && updated.New != nil {
+		updated.New, err = getRowsByPrimaryKey(ctx, tx, table, merge.primaryKey, updated.Keys)
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error merging rows: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	// The kept row can't be moved out of the scope either
+	if ok, err := inScope(ctx, tx, table, merge.primaryKey, updated.Keys, 1, scope); err != nil || !ok {
+		a.writeInScopeError(w, err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		a.logger.Error(fmt.Sprintf("Error merging rows: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Merged row %v into %v, re-pointed %d reference(s)", mergeID, keepID, repointed))
+	tx.afterCommit(ctx, func(ctx context.Context) {
+		a.afterMutation(ctx, a.hooks.AfterDelete, EventRowsDeleted, deleted)
+		if updated.New != nil {
+			a.afterMutation(ctx, a.hooks.AfterUpdate, EventRowsUpdated, updated)
+		}
+	})
+
+	json.NewEncoder(w).Encode(MergeRowsResponse{Status: "ok", ReferencesUpdated: repointed, SecretWarnings: findings})
+}
+
+// MergeRowsResponse is the response of MergeRows.
+type MergeRowsResponse struct {
+	Status            string          `json:"status"`
+	ReferencesUpdated int64           `json:"referencesUpdated"`
+	SecretWarnings    []SecretFinding `json:"secretWarnings,omitempty"`
+}
+
+// rowMerge is the merge of the row identified by mergeID into the row
+// identified by keepID.
+type rowMerge struct {
+	table           string
+	primaryKey      string
+	keepID, mergeID interface{}
+	refs            []foreignKeyRef
+	kept, merged    map[string]interface{}
+	// values are the columns of the kept row taking the value of the merged
+	// row.
+	values map[string]interface{}
+}
+
+// planMerge reads the rows to merge inside the transaction. Columns resolved
+// to "merge" in fields take the value of the merged row. When refs is empty
+// the references are discovered from the foreign keys of the database.
+func planMerge(ctx context.Context, tx queryer, table string, keepID, mergeID interface{}, fields map[string]string, refs []foreignKeyRef) (*rowMerge, error) {
+	primaryKey, err := getPrimaryKey(ctx, tx, table)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := getColumnNames(ctx, tx, table)
+	if err != nil {
+		return nil, err
+	}
+	for column := range fields {
+		if column == primaryKey {
+			return nil, fmt.Errorf("%w: the primary key %s can't be merged", ErrInvalidInput, column)
+		}
+		if !contains(columns, column) {
+			return nil, fmt.Errorf("%s: %w", column, ErrUnknownColumn)
+		}
+	}
+
+	if len(refs) == 0 {
+		refs, err = getReferencingColumns(ctx, tx, table, primaryKey)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		for _, ref := range refs {
+			refColumns, err := getColumnNames(ctx, tx, ref.Table)
+			if err != nil {
+				return nil, err
+			}
+			if !contains(refColumns, ref.Column) {
+				return nil, fmt.Errorf("%s.%s: %w", ref.Table, ref.Column, ErrUnknownColumn)
+			}
+		}
+	}
+
+	m := &rowMerge{table: table, primaryKey: primaryKey, keepID: keepID, mergeID: mergeID, refs: refs, values: map[string]interface{}{}}
+	rows, err := getRowsByPrimaryKey(ctx, tx, table, primaryKey, []interface{}{keepID, mergeID})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if fmt.Sprint(row[primaryKey]) == fmt.Sprint(keepID) {
+			m.kept = row
+		} else {
+			m.merged = row
+		}
+	}
+	if m.kept == nil || m.merged == nil {
+		return nil, fmt.Errorf("%w: rows %v and %v must exist", ErrInvalidOrMissingIds, keepID, mergeID)
+	}
+	for column, resolution := range fields {
+		if resolution == mergeResolutionMerge {
+			m.values[column] = m.merged[column]
+		}
+	}
+	return m, nil
+}
+
+// apply re-points the references to the merged row to the kept row, deletes
+// the merged row and then updates the kept one, returning the number of
+// references re-pointed.
+func (m *rowMerge) apply(ctx context.Context, tx queryer) (int64, error) {
+	var repointed int64
+	for _, ref := range m.refs {
+		query := fmt.Sprintf("UPDATE %s SET %s = ? WHERE %s = ?", quoteIdent(ref.Table), quoteIdent(ref.Column), quoteIdent(ref.Column))
+		result, err := tx.ExecContext(ctx, query, m.keepID, m.mergeID)
+		if err != nil {
+			return 0, fmt.Errorf("error re-pointing %s.%s: %v", ref.Table, ref.Column, err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		repointed += n
+	}
+
+	// Delete the merged row before updating the kept one so that UNIQUE
+	// columns taken from it don't conflict.
+	_, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s = ?", quoteIdent(m.table), quoteIdent(m.primaryKey)), m.mergeID)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting merged row: %v", err)
+	}
+
+	if len(m.values) > 0 {
+		var setClauses []string
+		var values []interface{}
+		for column, value := range m.values {
+			setClauses = append(setClauses, fmt.Sprintf("%s = ?", quoteIdent(column)))
+			values = append(values, value)
+		}
+		query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = ?", quoteIdent(m.table), strings.Join(setClauses, ", "), quoteIdent(m.primaryKey))
+		_, err = tx.ExecContext(ctx, query, append(values, m.keepID)...)
+		if err != nil {
+			return 0, fmt.Errorf("error updating kept row: %v", err)
+		}
+	}
+	return repointed, nil
+}
@@ -0,0 +1,174 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeRowsChecks(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	_, err := ts.db.Exec(`
+		CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users(id));
+		INSERT INTO orders (user_id) VALUES (1), (2), (2), (3);
+	`)
+	assert.NoError(t, err)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Same Row",
+			params:         map[string]interface{}{"tableName": "users", "keepId": 1, "mergeId": 1},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: " + sqliteadmin.ErrMergeSameRow.Error(),
+			},
+		},
+		{
+			name:           "Failure: Same Row Of Another Type",
+			params:         map[string]interface{}{"tableName": "users", "keepId": "1", "mergeId": 1},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: " + sqliteadmin.ErrMergeSameRow.Error(),
+			},
+		},
+		{
+			name:           "Failure: Unknown Column",
+			params:         map[string]interface{}{"tableName": "users", "keepId": 1, "mergeId": 2, "fields": map[string]interface{}{"nickname": "merge"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "COLUMN_NOT_FOUND",
+				"message":    "Bad request: unknown column",
+				"detail":     "nickname: unknown column",
+			},
+		},
+		{
+			name:           "Failure: Unknown Row",
+			params:         map[string]interface{}{"tableName": "users", "keepId": 1, "mergeId": 100},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid or missing ids: rows 1 and 100 must exist",
+			},
+		},
+	}, sqliteadmin.MergeRows, t, ts.server)
+
+	var n int
+	assert.NoError(t, ts.db.QueryRow("SELECT COUNT(*) FROM users").Scan(&n))
+	assert.Equal(t, 9, n)
+
+	// References to the merged row are re-pointed to the kept one, and the
+	// others left alone
+	runTestCases([]TestCase{
+		{
+			name:             "Success",
+			params:           map[string]interface{}{"tableName": "users", "keepId": 1, "mergeId": 2},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok", "referencesUpdated": float64(2)},
+		},
+	}, sqliteadmin.MergeRows, t, ts.server)
+
+	rows, err := ts.db.Query("SELECT user_id FROM orders ORDER BY id")
+	assert.NoError(t, err)
+	defer rows.Close()
+	var userIDs []int
+	for rows.Next() {
+		var id int
+		assert.NoError(t, rows.Scan(&id))
+		userIDs = append(userIDs, id)
+	}
+	assert.Equal(t, []int{1, 1, 1, 3}, userIDs)
+}
+
+func TestMergeRowsHooks(t *testing.T) {
+	var updates, deletes []sqliteadmin.Mutation
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.SecretScanner = sqliteadmin.NewSecretScanner()
+		c.SecretScanMode = sqliteadmin.SecretScanBlock
+		c.Hooks = sqliteadmin.Hooks{
+			BeforeDelete: func(ctx context.Context, m sqliteadmin.Mutation) error {
+				if m.Keys[0] == float64(3) {
+					return errors.New("row 3 must be kept")
+				}
+				return nil
+			},
+			AfterUpdate: func(ctx context.Context, m sqliteadmin.Mutation) {
+				updates = append(updates, m)
+			},
+			AfterDelete: func(ctx context.Context, m sqliteadmin.Mutation) {
+				deletes = append(deletes, m)
+			},
+		}
+	})
+	defer close()
+	ts.admin.RegisterValidator("users", func(row map[string]any) error {
+		if row["email"] == "bob@gmail.com" {
+			return errors.New("bob's email can't be reused")
+		}
+		return nil
+	})
+	_, err := ts.db.Exec("UPDATE users SET email = 'password=hunter22' WHERE id = 4")
+	assert.NoError(t, err)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Rejected By Validator",
+			params:         map[string]interface{}{"tableName": "users", "keepId": 1, "mergeId": 2, "fields": map[string]interface{}{"email": "merge"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid row: bob's email can't be reused",
+			},
+		},
+		{
+			name:           "Failure: Rejected By Hook",
+			params:         map[string]interface{}{"tableName": "users", "keepId": 1, "mergeId": 3},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: row 3 must be kept",
+			},
+		},
+		{
+			name:           "Failure: Secret Detected",
+			params:         map[string]interface{}{"tableName": "users", "keepId": 1, "mergeId": 4, "fields": map[string]interface{}{"email": "merge"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: value looks like a secret: email (password)",
+			},
+		},
+		{
+			name:             "Success",
+			params:           map[string]interface{}{"tableName": "users", "keepId": 1, "mergeId": 5, "fields": map[string]interface{}{"email": "merge"}},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok", "referencesUpdated": float64(0)},
+		},
+	}, sqliteadmin.MergeRows, t, ts.server)
+
+	var n int
+	assert.NoError(t, ts.db.QueryRow("SELECT COUNT(*) FROM users").Scan(&n))
+	assert.Equal(t, 8, n)
+
+	if assert.Len(t, deletes, 1) {
+		assert.Equal(t, "Eve", deletes[0].Old[0]["name"])
+	}
+	if assert.Len(t, updates, 1) {
+		assert.Equal(t, "alice@gmail.com", updates[0].Old[0]["email"])
+		assert.Equal(t, "eve@outlook.com", updates[0].New[0]["email"])
+	}
+}
@@ -0,0 +1,289 @@
+// Package migrate applies versioned SQL migrations to a SQLite database. It
+// is the runner behind the "sqliteadmin migrate" command, for applications
+// embedding the admin to manage their schema with the same files and
+// tracking table.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/joelseq/sqliteadmin-go/querybuilder"
+)
+
+// DefaultTable is the table recording the applied migrations.
+const DefaultTable = "_sqliteadmin_migrations"
+
+var (
+	ErrInvalidFileName  = errors.New("invalid migration file name")
+	ErrDuplicateVersion = errors.New("duplicate migration version")
+	ErrMissingUp        = errors.New("migration has no up file")
+	ErrMissingDown      = errors.New("migration has no down file")
+	ErrUnknownVersion   = errors.New("applied migration not found")
+)
+
+// fileName matches the files of the migrations, e.g. 0001_create_users.up.sql.
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is a version of the schema, read from the files
+// <version>_<name>.up.sql and <version>_<name>.down.sql.
+type Migration struct {
+	Version int64  `json:"version"`
+	Name    string `json:"name"`
+	Up      string `json:"-"`
+	Down    string `json:"-"`
+}
+
+// Status is a migration along with the time it was applied, if it was.
+type Status struct {
+	Migration
+	AppliedAt *time.Time `json:"appliedAt"`
+}
+
+type Config struct {
+	DB *sql.DB
+	// FS holds the migration files, e.g. an embed.FS or os.DirFS.
+	FS fs.FS
+	// Dir is the directory of FS the files are in, "." when empty.
+	Dir string
+	// Table records the applied migrations, DefaultTable when empty.
+	Table string
+}
+
+// Migrator applies the migrations of a directory. Each call runs in an
+// immediate transaction, so that concurrent migrators (e.g. several instances
+// of an application starting at once) wait for each other instead of
+// applying the same migrations twice. Migrations must therefore not contain
+// statements that can't run in a transaction, like VACUUM.
+type Migrator struct {
+	db         *sql.DB
+	table      string
+	migrations []Migration
+}
+
+func New(c Config) (*Migrator, error) {
+	dir := c.Dir
+	if dir == "" {
+		dir = "."
+	}
+	table := c.Table
+	if table == "" {
+		table = DefaultTable
+	}
+
+	migrations, err := readMigrations(c.FS, dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: c.DB, table: table, migrations: migrations}, nil
+}
+
+func readMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading migrations: %v", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".sql" {
+			continue
+		}
+		m := fileName.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFileName, e.Name())
+		}
+		version, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFileName, e.Name())
+		}
+		b, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", e.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = migration
+		} else if migration.Name != m[2] {
+			return nil, fmt.Errorf("%w: %d", ErrDuplicateVersion, version)
+		}
+		if m[3] == "up" {
+			migration.Up = string(b)
+		} else {
+			migration.Down = string(b)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("%w: %d_%s", ErrMissingUp, m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Up applies the pending migrations in order and returns them.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.locked(ctx, func(conn *sql.Conn, done map[int64]time.Time) error {
+		for _, migration := range m.migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			if _, err := conn.ExecContext(ctx, migration.Up); err != nil {
+				return fmt.Errorf("error applying %d_%s: %v", migration.Version, migration.Name, err)
+			}
+			_, err := conn.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (version, name, applied_at) VALUES (?, ?, ?)", querybuilder.Ident(m.table)),
+				migration.Version, migration.Name, time.Now().UTC().Format(time.RFC3339Nano))
+			if err != nil {
+				return fmt.Errorf("error recording %d_%s: %v", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations, most recent first, and
+// returns them.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.locked(ctx, func(conn *sql.Conn, done map[int64]time.Time) error {
+		versions := make([]int64, 0, len(done))
+		for v := range done {
+			versions = append(versions, v)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+		for _, version := range versions[:max(min(steps, len(versions)), 0)] {
+			migration, ok := m.find(version)
+			if !ok {
+				return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("%w: %d_%s", ErrMissingDown, migration.Version, migration.Name)
+			}
+			if _, err := conn.ExecContext(ctx, migration.Down); err != nil {
+				return fmt.Errorf("error reverting %d_%s: %v", migration.Version, migration.Name, err)
+			}
+			if _, err := conn.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE version = ?", querybuilder.Ident(m.table)), version); err != nil {
+				return fmt.Errorf("error recording %d_%s: %v", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status lists the migrations and when they were applied. Applied migrations
+// whose files are missing are listed with an empty name.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.locked(ctx, func(conn *sql.Conn, done map[int64]time.Time) error {
+		for _, migration := range m.migrations {
+			s := Status{Migration: migration}
+			if t, ok := done[migration.Version]; ok {
+				s.AppliedAt = &t
+			}
+			statuses = append(statuses, s)
+		}
+		for version, t := range done {
+			if _, ok := m.find(version); !ok {
+				statuses = append(statuses, Status{Migration: Migration{Version: version}, AppliedAt: &t})
+			}
+		}
+		return nil
+	})
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, err
+}
+
+func (m *Migrator) find(version int64) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// locked runs f in an immediate transaction with the applied migrations,
+// committing if it succeeds.
+func (m *Migrator) locked(ctx context.Context, f func(conn *sql.Conn, done map[int64]time.Time) error) (err error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting connection: %v", err)
+	}
+	defer conn.Close()
+
+	// BEGIN IMMEDIATE takes the write lock right away, which database/sql
+	// transactions can't do.
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			conn.ExecContext(context.Background(), "ROLLBACK")
+		}
+	}()
+
+	_, err = conn.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	)`, querybuilder.Ident(m.table)))
+	if err != nil {
+		return fmt.Errorf("error creating migrations table: %v", err)
+	}
+
+	done, err := appliedMigrations(ctx, conn, m.table)
+	if err != nil {
+		return err
+	}
+	if err = f(conn, done); err != nil {
+		return err
+	}
+	if _, err = conn.ExecContext(ctx, "COMMIT"); err != nil {
+		return fmt.Errorf("error committing: %v", err)
+	}
+	return nil
+}
+
+func appliedMigrations(ctx context.Context, conn *sql.Conn, table string) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf("SELECT version, applied_at FROM %s", querybuilder.Ident(table)))
+	if err != nil {
+		return nil, fmt.Errorf("error reading applied migrations: %v", err)
+	}
+	defer rows.Close()
+
+	done := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt string
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		t, _ := time.Parse(time.RFC3339Nano, appliedAt)
+		done[version] = t
+	}
+	return done, rows.Err()
+}
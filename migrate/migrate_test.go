@@ -0,0 +1,88 @@
+package migrate_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"testing/fstest"
+
+	"github.com/joelseq/sqliteadmin-go/migrate"
+	"github.com/stretchr/testify/assert"
+	_ "modernc.org/sqlite"
+)
+
+func TestMigrator(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	assert.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+	ctx := context.Background()
+
+	fsys := fstest.MapFS{
+		"migrations/0001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);")},
+		"migrations/0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		"migrations/0002_add_email.up.sql":      {Data: []byte("ALTER TABLE users ADD COLUMN email TEXT; CREATE INDEX users_email ON users (email);")},
+		"migrations/0002_add_email.down.sql":    {Data: []byte("DROP INDEX users_email; ALTER TABLE users DROP COLUMN email;")},
+		"migrations/README.md":                  {Data: []byte("not a migration")},
+	}
+	m, err := migrate.New(migrate.Config{DB: db, FS: fsys, Dir: "migrations"})
+	assert.NoError(t, err)
+
+	versions := func(migrations []migrate.Migration) []int64 {
+		var v []int64
+		for _, m := range migrations {
+			v = append(v, m.Version)
+		}
+		return v
+	}
+
+	applied, err := m.Up(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, versions(applied))
+	_, err = db.Exec("INSERT INTO users (name, email) VALUES ('Alice', 'alice@gmail.com')")
+	assert.NoError(t, err)
+
+	applied, err = m.Up(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+
+	statuses, err := m.Status(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, statuses, 2) {
+		assert.Equal(t, "add_email", statuses[1].Name)
+		assert.NotNil(t, statuses[1].AppliedAt)
+	}
+
+	reverted, err := m.Down(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2}, versions(reverted))
+	_, err = db.Exec("SELECT email FROM users")
+	assert.Error(t, err)
+
+	statuses, err = m.Status(ctx)
+	assert.NoError(t, err)
+	assert.NotNil(t, statuses[0].AppliedAt)
+	assert.Nil(t, statuses[1].AppliedAt)
+
+	t.Run("Failed migrations are rolled back", func(t *testing.T) {
+		fsys["migrations/0003_broken.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE orders (id INTEGER PRIMARY KEY); INSERT INTO missing VALUES (1);")}
+		m, err := migrate.New(migrate.Config{DB: db, FS: fsys, Dir: "migrations"})
+		assert.NoError(t, err)
+
+		_, err = m.Up(ctx)
+		assert.ErrorContains(t, err, "3_broken")
+		// Migration 2 was applied and rolled back along with 3
+		_, err = db.Exec("SELECT email FROM users")
+		assert.Error(t, err)
+		_, err = db.Exec("SELECT * FROM orders")
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid files", func(t *testing.T) {
+		_, err := migrate.New(migrate.Config{DB: db, FS: fstest.MapFS{"1.sql": {}}})
+		assert.ErrorIs(t, err, migrate.ErrInvalidFileName)
+
+		_, err = migrate.New(migrate.Config{DB: db, FS: fstest.MapFS{"0001_a.down.sql": {Data: []byte("SELECT 1")}}})
+		assert.ErrorIs(t, err, migrate.ErrMissingUp)
+	})
+}
@@ -0,0 +1,142 @@
+package sqliteadmin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// EventType identifies the kind of event a Notifier is notified about.
+type EventType string
+
+const (
+	// EventActionCompleted is sent after a custom action ran.
+	EventActionCompleted EventType = "action.completed"
+	// EventOperationCompleted is sent after a multi-step operation committed.
+	EventOperationCompleted EventType = "operation.completed"
+	// EventSecurityAlert is sent for security related events such as failed
+	// authentication attempts.
+	EventSecurityAlert EventType = "security.alert"
+)
+
+// notifyTimeout bounds how long a single notifier can take to deliver an
+// event.
+const notifyTimeout = 30 * time.Second
+
+// Event is sent to the Notifiers configured for its type.
+type Event struct {
+	Type    EventType              `json:"type"`
+	Time    time.Time              `json:"time"`
+	Message string                 `json:"message"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+// Notifier delivers events to an external system, e.g. email or a webhook.
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// notify delivers the event to the notifiers configured for its type in the
+// background so that requests aren't slowed down by slow sinks.
+func (a *Admin) notify(eventType EventType, message string, data map[string]interface{}) {
+	notifiers := append(a.webhookNotifiers(eventType), a.notifiers[eventType]...)
+	if len(notifiers) == 0 {
+		return
+	}
+
+	event := Event{Type: eventType, Time: time.Now(), Message: message, Data: data}
+	for _, n := range notifiers {
+		go func(n Notifier) {
+			ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+			defer cancel()
+			if err := n.Notify(ctx, event); err != nil {
+				a.logger.Error(fmt.Sprintf("Error sending %s notification: %v", event.Type, err))
+			}
+		}(n)
+	}
+}
+
+var _ Notifier = &WebhookNotifier{}
+
+// WebhookNotifier POSTs events as JSON to a URL.
+type WebhookNotifier struct {
+	URL     string
+	Headers map[string]string
+	// Client is used to send the requests, defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range n.Headers {
+		req.Header.Set(k, v)
+	}
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+	return nil
+}
+
+var _ Notifier = &SMTPNotifier{}
+
+// SMTPNotifier emails events through an SMTP server.
+type SMTPNotifier struct {
+	// Addr is the address of the SMTP server including the port, e.g.
+	// "smtp.example.com:587".
+	Addr string
+	Auth smtp.Auth
+	From string
+	To   []string
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, event Event) error {
+	var body strings.Builder
+	fmt.Fprintf(&body, "From: %s\r\n", n.From)
+	fmt.Fprintf(&body, "To: %s\r\n", strings.Join(n.To, ", "))
+	fmt.Fprintf(&body, "Subject: [sqliteadmin] %s\r\n", event.Type)
+	fmt.Fprintf(&body, "\r\n%s\r\n", event.Message)
+	if len(event.Data) > 0 {
+		data, err := json.MarshalIndent(event.Data, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&body, "\r\n%s\r\n", data)
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- smtp.SendMail(n.Addr, n.Auth, n.From, n.To, []byte(body.String()))
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
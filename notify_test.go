@@ -0,0 +1,123 @@
+package sqliteadmin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	events := make(chan sqliteadmin.Event, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event sqliteadmin.Event
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		assert.Equal(t, "secret", r.Header.Get("X-Token"))
+		events <- event
+	}))
+	defer webhook.Close()
+
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.Notifiers = map[sqliteadmin.EventType][]sqliteadmin.Notifier{
+			sqliteadmin.EventSecurityAlert: {
+				&sqliteadmin.WebhookNotifier{URL: webhook.URL, Headers: map[string]string{"X-Token": "secret"}},
+			},
+		}
+	})
+	defer close()
+
+	req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: sqliteadmin.Ping})
+	req.Header.Set("Authorization", "user:wrong")
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	select {
+	case event := <-events:
+		assert.Equal(t, sqliteadmin.EventSecurityAlert, event.Type)
+		assert.Equal(t, "Failed authentication attempt", event.Message)
+	case <-time.After(time.Second):
+		t.Fatal("expected a security alert notification")
+	}
+}
+
+func TestRuntimeWebhooks(t *testing.T) {
+	events := make(chan sqliteadmin.Event, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event sqliteadmin.Event
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		assert.Equal(t, "secret", r.Header.Get("X-Token"))
+		events <- event
+	}))
+	defer webhook.Close()
+
+	ts, close := setupTestServer(t)
+	defer close()
+
+	do := func(command sqliteadmin.Command, params map[string]interface{}) map[string]interface{} {
+		res, err := http.DefaultClient.Do(makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: command, Params: params}))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		return readBody(t, res.Body)
+	}
+
+	id := do(sqliteadmin.AddWebhook, map[string]interface{}{
+		"url":     webhook.URL,
+		"events":  []string{string(sqliteadmin.EventSecurityAlert)},
+		"headers": map[string]string{"X-Token": "secret"},
+	})["id"]
+	assert.NotEmpty(t, id)
+
+	assert.Equal(t, map[string]interface{}{
+		"webhooks": []interface{}{
+			map[string]interface{}{
+				"id":          id,
+				"url":         webhook.URL,
+				"events":      []interface{}{"security.alert"},
+				"headerNames": []interface{}{"X-Token"},
+			},
+		},
+	}, do(sqliteadmin.ListWebhooks, nil))
+
+	assert.Equal(t, map[string]interface{}{"delivered": true}, do(sqliteadmin.TestWebhook, map[string]interface{}{"id": id}))
+	assert.Equal(t, sqliteadmin.EventWebhookTest, (<-events).Type)
+
+	req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: sqliteadmin.Ping})
+	req.Header.Set("Authorization", "user:wrong")
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	select {
+	case event := <-events:
+		assert.Equal(t, sqliteadmin.EventSecurityAlert, event.Type)
+	case <-time.After(time.Second):
+		t.Fatal("expected a security alert notification")
+	}
+
+	// Webhooks are persisted in the database
+	admin := sqliteadmin.New(sqliteadmin.Config{DB: ts.db})
+	rec := httptest.NewRecorder()
+	admin.HandlePost(rec, makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: sqliteadmin.ListWebhooks}))
+	assert.Len(t, readBody(t, rec.Result().Body)["webhooks"], 1)
+
+	assert.Equal(t, map[string]interface{}{"status": "ok"}, do(sqliteadmin.RemoveWebhook, map[string]interface{}{"id": id}))
+	assert.Equal(t, map[string]interface{}{"webhooks": []interface{}{}}, do(sqliteadmin.ListWebhooks, nil))
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Unknown Id",
+			params:         map[string]interface{}{"id": id},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: unknown webhook id",
+			},
+		},
+	}, sqliteadmin.TestWebhook, t, ts.server)
+}
@@ -0,0 +1,388 @@
+package sqliteadmin
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultJWKSCacheTTL is how long the signing keys of the OIDC provider are
+// cached when OIDCConfig.JWKSCacheTTL is zero.
+const DefaultJWKSCacheTTL = time.Hour
+
+const (
+	// jwksRefreshInterval is how often the keys can be fetched again for
+	// tokens signed by an unknown key, e.g. right after the provider rotated
+	// its keys.
+	jwksRefreshInterval = 10 * time.Second
+	// tokenLeeway is the clock skew tolerated when checking the expiration
+	// of tokens.
+	tokenLeeway = time.Minute
+)
+
+// OIDCConfig authenticates requests with the bearer tokens issued by an
+// OpenID Connect provider, sent as "Authorization: Bearer <token>".
+type OIDCConfig struct {
+	// Issuer is the URL of the provider, which must match the iss claim of
+	// the tokens. The signing keys are found through its discovery document.
+	Issuer string
+	// Audience must be one of the aud claim of the tokens, usually the
+	// client ID of the admin UI.
+	Audience string
+	// UsernameClaim is the claim used as the user name, "sub" when empty,
+	// e.g. "email".
+	UsernameClaim string
+	// RoleClaim is the claim holding the roles or groups of the user,
+	// "roles" when empty. It can be a string or an array of strings.
+	RoleClaim string
+	// Roles maps the values of RoleClaim to roles, the one granting the most
+	// permissions winning. Other values are ignored, so a group named "admin"
+	// doesn't grant any role unless mapped. When Roles is nil, the values
+	// named after a role are used as is.
+	Roles map[string]Role
+	// DefaultRole is the role of the users without a known role, who can't
+	// run any command when it is empty.
+	DefaultRole Role
+	// JWKSCacheTTL is how long the signing keys are cached,
+	// DefaultJWKSCacheTTL when zero.
+	JWKSCacheTTL time.Duration
+	// HTTPClient fetches the discovery document and the keys,
+	// http.DefaultClient when nil.
+	HTTPClient *http.Client
+}
+
+// oidcVerifier verifies the tokens of an OIDC provider, caching its keys.
+type oidcVerifier struct {
+	config OIDCConfig
+	client *http.Client
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+}
+
+func newOIDCVerifier(c OIDCConfig) *oidcVerifier {
+	v := &oidcVerifier{config: c, client: c.HTTPClient, ttl: c.JWKSCacheTTL}
+	if v.client == nil {
+		v.client = http.DefaultClient
+	}
+	if v.ttl <= 0 {
+		v.ttl = DefaultJWKSCacheTTL
+	}
+	if v.config.UsernameClaim == "" {
+		v.config.UsernameClaim = "sub"
+	}
+	if v.config.RoleClaim == "" {
+		v.config.RoleClaim = "roles"
+	}
+	return v
+}
+
+// authenticate returns the user of a valid bearer token, along with its
+// claims.
+func (v *oidcVerifier) authenticate(ctx context.Context, token string) (User, map[string]interface{}, error) {
+	claims, err := v.verify(ctx, token)
+	if err != nil {
+		return User{}, nil, err
+	}
+	username, _ := claims[v.config.UsernameClaim].(string)
+	if username == "" {
+		return User{}, nil, fmt.Errorf("%w: missing %s claim", ErrInvalidToken, v.config.UsernameClaim)
+	}
+	return User{Username: username, Role: v.role(claims)}, claims, nil
+}
+
+// role returns the role granting the most permissions among the values of
+// the role claim.
+func (v *oidcVerifier) role(claims map[string]interface{}) Role {
+	var values []string
+	switch claim := claims[v.config.RoleClaim].(type) {
+	case string:
+		values = []string{claim}
+	case []interface{}:
+		for _, value := range claim {
+			if s, ok := value.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	role := v.config.DefaultRole
+	for _, value := range values {
+		r, ok := v.config.Roles[value]
+		if !ok && v.config.Roles == nil {
+			r = Role(value)
+		}
+		if len(r.categories()) > len(role.categories()) {
+			role = r
+		}
+	}
+	return role
+}
+
+// verify checks the signature, issuer, audience and lifetime of the token
+// and returns its claims.
+func (v *oidcVerifier) verify(ctx context.Context, token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeTokenPart(parts[0], &header); err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err := decodeTokenPart(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if iss, _ := claims["iss"].(string); iss != v.config.Issuer {
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, iss)
+	}
+	if !hasAudience(claims["aud"], v.config.Audience) {
+		return nil, fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+	now := time.Now()
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing exp claim", ErrInvalidToken)
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(tokenLeeway)) {
+		return nil, fmt.Errorf("%w: expired", ErrInvalidToken)
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(tokenLeeway).Before(time.Unix(int64(nbf), 0)) {
+		return nil, fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	}
+	return claims, nil
+}
+
+func decodeTokenPart(part string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+	return nil
+}
+
+func hasAudience(aud interface{}, audience string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// verifySignature verifies the signature of the RS* and ES* algorithms, the
+// ones OIDC providers sign tokens with. Symmetric algorithms and "none" are
+// rejected.
+func verifySignature(alg string, key crypto.PublicKey, signed, signature []byte) error {
+	var hash crypto.Hash
+	switch alg[min(len(alg), 2):] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, alg)
+	}
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			break
+		}
+		if rsa.VerifyPKCS1v15(key, hash, digest, signature) != nil {
+			return fmt.Errorf("%w: invalid signature", ErrInvalidToken)
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if !strings.HasPrefix(alg, "ES") || len(signature) != 2*size {
+			break
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return fmt.Errorf("%w: invalid signature", ErrInvalidToken)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: algorithm %q doesn't match the key", ErrInvalidToken, alg)
+}
+
+// key returns the signing key with the ID, fetching the keys of the provider
+// when they expired or the key is unknown.
+func (v *oidcVerifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	key, ok := v.keys[kid]
+	age := time.Since(v.fetched)
+	if ok && age < v.ttl {
+		return key, nil
+	}
+	// Unknown keys are only looked for every jwksRefreshInterval, so that
+	// tokens with made up key IDs can't flood the provider with requests
+	if !ok && !v.fetched.IsZero() && age < jwksRefreshInterval {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+	}
+
+	keys, err := v.fetchKeys(ctx)
+	if err != nil {
+		// The previous keys are still used while the provider is unreachable
+		if ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("error fetching the OIDC keys: %v", err)
+	}
+	v.keys, v.fetched = keys, time.Now()
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+}
+
+// fetchKeys fetches the JWKS of the provider, found in its discovery
+// document.
+func (v *oidcVerifier) fetchKeys(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	var discovery struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := v.getJSON(ctx, strings.TrimSuffix(v.config.Issuer, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, err
+	}
+	if discovery.JWKSURI == "" {
+		return nil, errors.New("no jwks_uri in the discovery document")
+	}
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := v.getJSON(ctx, discovery.JWKSURI, &jwks); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		// Keys of other types, like the ones for encryption, are skipped
+		if key, err := jwk.publicKey(); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+func (v *oidcVerifier) getJSON(ctx context.Context, url string, dst interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	res, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(dst)
+}
+
+// jsonWebKey is a public key of a JWKS.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	// N and E are the modulus and exponent of RSA keys.
+	N string `json:"n"`
+	E string `json:"e"`
+	// Crv, X and Y are the curve and coordinates of EC keys.
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("invalid EC key")
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
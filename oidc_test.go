@@ -0,0 +1,167 @@
+package sqliteadmin_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// testProvider is an OIDC provider serving its discovery document and keys.
+type testProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	// fetches counts the requests for the keys.
+	fetches atomic.Int32
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	p := &testProvider{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": p.server.URL, "jwks_uri": p.server.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		p.fetches.Add(1)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "k1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	p.server = httptest.NewServer(mux)
+	return p
+}
+
+// token returns a token signed by the provider with the claims, on top of
+// valid iss, aud and exp claims.
+func (p *testProvider) token(t *testing.T, kid string, claims map[string]interface{}) string {
+	all := map[string]interface{}{
+		"iss": p.server.URL,
+		"aud": "admin-ui",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range claims {
+		all[k] = v
+	}
+	encode := func(v interface{}) string {
+		b, err := json.Marshal(v)
+		assert.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	signed := encode(map[string]string{"alg": "RS256", "kid": kid}) + "." + encode(all)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, digest[:])
+	assert.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestOIDC(t *testing.T) {
+	provider := newTestProvider(t)
+	defer provider.server.Close()
+
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.OIDC = &sqliteadmin.OIDCConfig{
+			Issuer:        provider.server.URL,
+			Audience:      "admin-ui",
+			UsernameClaim: "email",
+			RoleClaim:     "groups",
+			Roles:         map[string]sqliteadmin.Role{"dba": sqliteadmin.RoleAdmin, "support": sqliteadmin.RoleViewer},
+		}
+		c.RowScopes = map[string]sqliteadmin.Condition{
+			"users": {
+				Cases: []sqliteadmin.Case{
+					sqliteadmin.Filter{Column: "id", Operator: sqliteadmin.OperatorEquals, Value: "$claims.uid"},
+				},
+				LogicalOperator: sqliteadmin.LogicalOperatorAnd,
+			},
+		}
+	})
+	defer close()
+
+	do := func(token string, command sqliteadmin.Command, params map[string]interface{}) (int, map[string]interface{}) {
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: command, Params: params})
+		req.Header.Set("Authorization", "Bearer "+token)
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res.StatusCode, readBody(t, res.Body)
+	}
+
+	token := provider.token(t, "k1", map[string]interface{}{
+		"email":  "alice@example.com",
+		"groups": []string{"support", "dba"},
+		"uid":    "2",
+	})
+	status, body := do(token, sqliteadmin.WhoAmI, nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "alice@example.com", body["user"])
+	assert.Equal(t, "admin", body["role"])
+
+	// The claims of the token are the ones of the principal
+	status, body = do(token, sqliteadmin.GetTable, map[string]interface{}{"tableName": "users"})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, body["rows"], 1)
+
+	token = provider.token(t, "k1", map[string]interface{}{"email": "bob@example.com", "groups": "support"})
+	status, _ = do(token, sqliteadmin.UpdateRow, map[string]interface{}{"tableName": "users", "row": map[string]interface{}{"id": 1, "name": "Alicia"}})
+	assert.Equal(t, http.StatusForbidden, status)
+
+	// Users without a known role can't run anything
+	token = provider.token(t, "k1", map[string]interface{}{"email": "eve@example.com"})
+	status, _ = do(token, sqliteadmin.WhoAmI, nil)
+	assert.Equal(t, http.StatusForbidden, status)
+
+	// Values missing from Roles don't grant the role they are named after
+	token = provider.token(t, "k1", map[string]interface{}{"email": "mallory@example.com", "groups": []string{"admin", "support"}})
+	status, body = do(token, sqliteadmin.WhoAmI, nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "viewer", body["role"])
+	token = provider.token(t, "k1", map[string]interface{}{"email": "mallory@example.com", "groups": "admin"})
+	status, _ = do(token, sqliteadmin.WhoAmI, nil)
+	assert.Equal(t, http.StatusForbidden, status)
+
+	for name, claims := range map[string]map[string]interface{}{
+		"expired":      {"email": "bob@example.com", "exp": time.Now().Add(-time.Hour).Unix()},
+		"audience":     {"email": "bob@example.com", "aud": "other"},
+		"issuer":       {"email": "bob@example.com", "iss": "https://evil.example.com"},
+		"missing user": {"groups": "dba"},
+	} {
+		status, _ = do(provider.token(t, "k1", claims), sqliteadmin.WhoAmI, nil)
+		assert.Equal(t, http.StatusUnauthorized, status, name)
+	}
+
+	// Tampered tokens and unknown keys are rejected
+	token = provider.token(t, "k1", map[string]interface{}{"email": "bob@example.com"})
+	status, _ = do(token[:len(token)-4]+"AAAA", sqliteadmin.WhoAmI, nil)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	status, _ = do(provider.token(t, "k2", map[string]interface{}{"email": "bob@example.com"}), sqliteadmin.WhoAmI, nil)
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	// The keys are cached, and only fetched again for unknown keys every few
+	// seconds
+	assert.Equal(t, int32(1), provider.fetches.Load())
+
+	// The static credentials still work alongside
+	req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: sqliteadmin.WhoAmI})
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
@@ -0,0 +1,287 @@
+package sqliteadmin
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// OperationTimeout is how long an unfinished operation is kept server-side
+// after its last interaction before it is discarded.
+const OperationTimeout = 30 * time.Minute
+
+// OperationStep is a single step of a multi-step Operation. Validate is called
+// with the input submitted for the step and can record whatever it needs for
+// the final commit in state. Returning an error rejects the input and keeps
+// the operation on the same step.
+type OperationStep struct {
+	Name     string
+	Validate func(ctx context.Context, db *sql.DB, state map[string]interface{}, input map[string]interface{}) error
+}
+
+// Operation is a multi-step admin workflow (e.g. merging two customer
+// accounts). Its steps are completed one at a time by the client, and once all
+// of them have been validated Commit is called inside a single transaction.
+type Operation struct {
+	Name        string
+	Description string
+	Steps       []OperationStep
+	Commit      func(ctx context.Context, tx *sql.Tx, state map[string]interface{}) (string, error)
+}
+
+type operationInfo struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Steps       []string `json:"steps"`
+}
+
+type operationSession struct {
+	mu        sync.Mutex
+	operation Operation
+	db        *sql.DB
+	step      int
+	state     map[string]interface{}
+	// updatedAt is guarded by Admin.mu so that sessions can be pruned without
+	// waiting on a step that is being validated.
+	updatedAt time.Time
+}
+
+// RegisterOperation registers a multi-step operation. Registering an operation
+// with the same name twice replaces the previous one.
+func (a *Admin) RegisterOperation(op Operation) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.operations[op.Name] = op
+}
+
+func (a *Admin) listOperations(w http.ResponseWriter) {
+	a.logger.Info("Command: ListOperations")
+
+	a.mu.RLock()
+	ops := make([]operationInfo, 0, len(a.operations))
+	for _, op := range a.operations {
+		steps := make([]string, len(op.Steps))
+		for i, step := range op.Steps {
+			steps[i] = step.Name
+		}
+		ops = append(ops, operationInfo{Name: op.Name, Description: op.Description, Steps: steps})
+	}
+	a.mu.RUnlock()
+
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].Name < ops[j].Name
+	})
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"operations": ops})
+}
+
+func (a *Admin) startOperation(w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return
+	}
+
+	name, ok := params["operation"].(string)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrMissingOperation.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: StartOperation, operation=%s", name))
+
+	a.mu.Lock()
+	op, ok := a.operations[name]
+	if !ok {
+		a.mu.Unlock()
+		writeError(w, apiErrBadRequest(ErrUnknownOperation.Error()))
+		return
+	}
+
+	id, err := newID()
+	if err != nil {
+		a.mu.Unlock()
+		a.logger.Error(fmt.Sprintf("Error generating operation id: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+
+	a.pruneOperationSessions()
+	session := &operationSession{
+		operation: op,
+		db:        db,
+		state:     make(map[string]interface{}),
+		updatedAt: time.Now(),
+	}
+	a.operationSessions[id] = session
+	a.mu.Unlock()
+
+	json.NewEncoder(w).Encode(operationStatus(id, session))
+}
+
+func (a *Admin) submitOperationStep(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	id, ok := params["operationId"].(string)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrMissingOperationID.Error()))
+		return
+	}
+
+	input, ok := params["input"].(map[string]interface{})
+	if !ok {
+		input = map[string]interface{}{}
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: SubmitOperationStep, operationId=%s", id))
+
+	session, ok := a.getOperationSession(id)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrUnknownOperationID.Error()))
+		return
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if session.step >= len(session.operation.Steps) {
+		writeError(w, apiErrBadRequest(ErrOperationStepsCompleted.Error()))
+		return
+	}
+
+	step := session.operation.Steps[session.step]
+	if step.Validate != nil {
+		if err := step.Validate(ctx, session.db, session.state, input); err != nil {
+			a.logger.Debug(fmt.Sprintf("Step %s of operation %s rejected: %v", step.Name, session.operation.Name, err))
+			writeError(w, apiErrBadRequest(err.Error()))
+			return
+		}
+	}
+	session.step++
+
+	a.mu.Lock()
+	session.updatedAt = time.Now()
+	a.mu.Unlock()
+
+	json.NewEncoder(w).Encode(operationStatus(id, session))
+}
+
+func (a *Admin) commitOperation(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	id, ok := params["operationId"].(string)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrMissingOperationID.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: CommitOperation, operationId=%s", id))
+
+	session, ok := a.getOperationSession(id)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrUnknownOperationID.Error()))
+		return
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if session.step < len(session.operation.Steps) {
+		writeError(w, apiErrBadRequest(ErrOperationStepsIncomplete.Error()))
+		return
+	}
+
+	a.mu.Lock()
+	delete(a.operationSessions, id)
+	a.mu.Unlock()
+
+	message, err := runOperationCommit(ctx, session)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error committing operation %s: %v", session.operation.Name, err))
+		writeError(w, apiErrSomethingWentWrong())
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: operation %s committed", session.operation.Name))
+	a.notify(EventOperationCompleted, fmt.Sprintf("Operation %s committed", session.operation.Name), map[string]interface{}{
+		"operation": session.operation.Name,
+		"message":   message,
+	})
+
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok", "message": message})
+}
+
+func (a *Admin) cancelOperation(w http.ResponseWriter, params map[string]interface{}) {
+	id, ok := params["operationId"].(string)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrMissingOperationID.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: CancelOperation, operationId=%s", id))
+
+	a.mu.Lock()
+	delete(a.operationSessions, id)
+	a.mu.Unlock()
+
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+func (a *Admin) getOperationSession(id string) (*operationSession, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.pruneOperationSessions()
+	session, ok := a.operationSessions[id]
+	return session, ok
+}
+
+// pruneOperationSessions discards the sessions that timed out. It must be
+// called with a.mu held.
+func (a *Admin) pruneOperationSessions() {
+	for id, session := range a.operationSessions {
+		if time.Since(session.updatedAt) > OperationTimeout {
+			delete(a.operationSessions, id)
+		}
+	}
+}
+
+func runOperationCommit(ctx context.Context, session *operationSession) (string, error) {
+	tx, err := session.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	message, err := session.operation.Commit(ctx, tx, session.state)
+	if err != nil {
+		return "", err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", fmt.Errorf("error committing transaction: %v", err)
+	}
+	return message, nil
+}
+
+func operationStatus(id string, session *operationSession) map[string]interface{} {
+	status := map[string]interface{}{
+		"operationId": id,
+		"completed":   session.step >= len(session.operation.Steps),
+	}
+	if session.step < len(session.operation.Steps) {
+		status["step"] = session.operation.Steps[session.step].Name
+	}
+	return status
+}
+
+// newID returns a random hex-encoded identifier.
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
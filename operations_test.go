@@ -0,0 +1,90 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperations(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	ts.admin.RegisterOperation(sqliteadmin.Operation{
+		Name:        "RenameUser",
+		Description: "Rename a user",
+		Steps: []sqliteadmin.OperationStep{
+			{
+				Name: "SelectUser",
+				Validate: func(ctx context.Context, db *sql.DB, state map[string]interface{}, input map[string]interface{}) error {
+					var name string
+					err := db.QueryRowContext(ctx, "SELECT name FROM users WHERE id = ?", input["id"]).Scan(&name)
+					if err != nil {
+						return errors.New("user not found")
+					}
+					state["id"] = input["id"]
+					return nil
+				},
+			},
+			{
+				Name: "ChooseName",
+				Validate: func(ctx context.Context, db *sql.DB, state map[string]interface{}, input map[string]interface{}) error {
+					name, ok := input["name"].(string)
+					if !ok || name == "" {
+						return errors.New("name is required")
+					}
+					state["name"] = name
+					return nil
+				},
+			},
+		},
+		Commit: func(ctx context.Context, tx *sql.Tx, state map[string]interface{}) (string, error) {
+			_, err := tx.ExecContext(ctx, "UPDATE users SET name = ? WHERE id = ?", state["name"], state["id"])
+			return "renamed", err
+		},
+	})
+
+	send := func(command sqliteadmin.Command, params map[string]interface{}) (int, map[string]interface{}) {
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: command, Params: params})
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res.StatusCode, readBody(t, res.Body)
+	}
+
+	status, result := send(sqliteadmin.StartOperation, map[string]interface{}{"operation": "RenameUser"})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "SelectUser", result["step"])
+	id := result["operationId"]
+
+	status, result = send(sqliteadmin.CommitOperation, map[string]interface{}{"operationId": id})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "Bad request: operation has incomplete steps", result["message"])
+
+	status, result = send(sqliteadmin.SubmitOperationStep, map[string]interface{}{"operationId": id, "input": map[string]interface{}{"id": 42}})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "Bad request: user not found", result["message"])
+
+	status, result = send(sqliteadmin.SubmitOperationStep, map[string]interface{}{"operationId": id, "input": map[string]interface{}{"id": 1}})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "ChooseName", result["step"])
+
+	status, result = send(sqliteadmin.SubmitOperationStep, map[string]interface{}{"operationId": id, "input": map[string]interface{}{"name": "Alicia"}})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, true, result["completed"])
+
+	status, result = send(sqliteadmin.CommitOperation, map[string]interface{}{"operationId": id})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "renamed", result["message"])
+
+	rows, err := getTableValues(ts.db, "users")
+	assert.NoError(t, err)
+	assert.Equal(t, "Alicia", rows[0]["name"])
+
+	status, _ = send(sqliteadmin.CommitOperation, map[string]interface{}{"operationId": id})
+	assert.Equal(t, http.StatusBadRequest, status)
+}
@@ -0,0 +1,248 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/joelseq/sqliteadmin-go/querybuilder"
+)
+
+// FindOrphansParams are the params of FindOrphans.
+type FindOrphansParams struct {
+	Database string `json:"database,omitempty" mapstructure:"database"`
+	// TableName restricts the check to the foreign keys of a table.
+	TableName string `json:"tableName,omitempty" mapstructure:"tableName"`
+	// Limit is the number of orphans to return, the default limit when nil.
+	Limit *int `json:"limit,omitempty" mapstructure:"limit"`
+}
+
+func (p *FindOrphansParams) validate() error {
+	if p.Limit != nil && *p.Limit < 0 {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+// Orphan is a row referencing a row that doesn't exist through a foreign key.
+type Orphan struct {
+	Table string `json:"tableName"`
+	// PrimaryKey is the primary key column of the table, or rowid for the
+	// tables without one, and Key its value for the row.
+	PrimaryKey string      `json:"primaryKey"`
+	Key        interface{} `json:"key"`
+	// Values are the values of the columns of the foreign key.
+	Values        map[string]interface{} `json:"values"`
+	ParentTable   string                 `json:"parentTable"`
+	ParentColumns []string               `json:"parentColumns"`
+}
+
+type FindOrphansResponse struct {
+	Orphans []Orphan `json:"orphans"`
+	// Total is the number of orphans, which can be more than returned.
+	Total int `json:"total"`
+}
+
+// findOrphans returns the rows violating foreign keys, found with PRAGMA
+// foreign_key_check whether or not foreign keys are enforced, with the
+// primary key of each row so that it can be fixed or deleted.
+func (a *Admin) findOrphans(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	var p FindOrphansParams
+	if err := a.decodeParams(params, &p); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	db, ok := a.getReadDB(w, params)
+	if !ok {
+		return
+	}
+	limit := a.defaultLimit
+	if p.Limit != nil {
+		limit = *p.Limit
+	}
+	if exceeds(limit, a.maxLimit) {
+		writeError(w, apiErrBadRequest(ErrLimitTooLarge.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: FindOrphans, table=%s, limit=%d", p.TableName, limit))
+
+	q := a.cached(db)
+	if p.TableName != "" {
+		exists, err := checkTableExists(ctx, q, p.TableName)
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Error checking table existence: %v", err))
+			writeError(w, a.apiErr(err))
+			return
+		}
+		if !exists {
+			writeError(w, apiErrTableNotFound())
+			return
+		}
+	}
+
+	res, err := a.queryOrphans(ctx, q, p.TableName, limit)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error finding orphans: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(res)
+}
+
+// violation is a row of PRAGMA foreign_key_check.
+type violation struct {
+	table  string
+	rowid  sql.NullInt64
+	parent string
+	fkid   int
+}
+
+func (a *Admin) queryOrphans(ctx context.Context, q queryer, table string, limit int) (*FindOrphansResponse, error) {
+	query := "PRAGMA foreign_key_check"
+	if table != "" {
+		query = fmt.Sprintf("PRAGMA foreign_key_check(%s)", quoteIdent(table))
+	}
+	rows, err := q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error checking foreign keys: %v", err)
+	}
+	var violations []violation
+	for rows.Next() {
+		var v violation
+		if err := rows.Scan(&v.table, &v.rowid, &v.parent, &v.fkid); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		if a.tableAllowed(v.table) {
+			violations = append(violations, v)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
+
+	// SQLite checks the tables in no particular order
+	sort.SliceStable(violations, func(i, j int) bool { return violations[i].table < violations[j].table })
+
+	res := &FindOrphansResponse{Orphans: []Orphan{}}
+	tables := map[string]*orphanTable{}
+	for _, v := range violations {
+		t, ok := tables[v.table]
+		if !ok {
+			t, err = a.getOrphanTable(ctx, q, v.table)
+			if err != nil {
+				return nil, err
+			}
+			tables[v.table] = t
+		}
+		// The rows of WITHOUT ROWID tables can't be identified, and those
+		// of tables whose scope can't be resolved are hidden
+		if t.scopeErr != nil || !v.rowid.Valid {
+			continue
+		}
+		ref, ok := t.references[v.fkid]
+		if !ok {
+			continue
+		}
+
+		// Once the limit is reached the orphans are only counted, which
+		// takes a query for the tables with a scope
+		if len(res.Orphans) == limit && t.scope == nil {
+			res.Total++
+			continue
+		}
+		columns := append([]string{t.primaryKey}, ref.From...)
+		query, args := querybuilder.Select(columns...).
+			From(v.table).
+			Where(querybuilder.Eq("rowid", v.rowid.Int64)).
+			Where(conditionExpr(t.scope)).
+			Build()
+		found, err := queryRows(ctx, q, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		// Rows out of the scope are left out as if they didn't exist
+		if len(found) == 0 {
+			continue
+		}
+		res.Total++
+		if len(res.Orphans) == limit {
+			continue
+		}
+
+		row := found[0]
+		key := row[t.primaryKey]
+		delete(row, t.primaryKey)
+		a.protectRows(ctx, v.table, found)
+		a.encodeRows(found)
+		res.Orphans = append(res.Orphans, Orphan{
+			Table:         v.table,
+			PrimaryKey:    t.primaryKey,
+			Key:           a.encodeValue(key),
+			Values:        row,
+			ParentTable:   v.parent,
+			ParentColumns: ref.To,
+		})
+	}
+	return res, nil
+}
+
+// orphanTable is what identifies the orphans of a table.
+type orphanTable struct {
+	primaryKey string
+	// references are the foreign keys of the table by id.
+	references map[int]reference
+	scope      *Condition
+	scopeErr   error
+}
+
+func (a *Admin) getOrphanTable(ctx context.Context, q queryer, table string) (*orphanTable, error) {
+	t := &orphanTable{references: map[int]reference{}}
+	var err error
+	t.primaryKey, err = getPrimaryKey(ctx, q, table)
+	if errors.Is(err, ErrNoPrimaryKey) {
+		t.primaryKey = "rowid"
+	} else if err != nil {
+		return nil, err
+	}
+
+	fks, err := getForeignKeys(ctx, q, table)
+	if err != nil {
+		return nil, err
+	}
+	parents := map[int]string{}
+	for _, fk := range fks {
+		ref := t.references[fk.ID]
+		ref.Table = table
+		ref.From = append(ref.From, fk.From)
+		ref.To = append(ref.To, fk.To)
+		t.references[fk.ID] = ref
+		parents[fk.ID] = fk.Table
+	}
+	// A foreign key without target columns references the primary key,
+	// which is unknown when the parent table is missing
+	for id, ref := range t.references {
+		if ref.To[0] != "" {
+			continue
+		}
+		ref.To = nil
+		if columns, err := getColumns(ctx, q, parents[id]); err == nil {
+			sort.Slice(columns, func(i, j int) bool { return columns[i].PK < columns[j].PK })
+			for _, c := range columns {
+				if c.PK > 0 {
+					ref.To = append(ref.To, c.Name)
+				}
+			}
+		}
+		t.references[id] = ref
+	}
+	t.scope, t.scopeErr = a.rowScope(ctx, table)
+	return t, nil
+}
@@ -0,0 +1,58 @@
+package sqliteadmin_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindOrphans(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	// Foreign keys aren't enforced by the test database, like by default
+	_, err := ts.db.Exec(`
+		CREATE TABLE authors (id INTEGER PRIMARY KEY, name TEXT);
+		CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT, author_id INTEGER REFERENCES authors);
+		CREATE TABLE notes (body TEXT, book_id INTEGER REFERENCES books (id));
+		INSERT INTO authors VALUES (1, 'a');
+		INSERT INTO books VALUES (1, 'x', 1), (2, 'y', 2), (3, 'z', 3), (4, 'w', NULL);
+		INSERT INTO notes VALUES ('n', 9);
+	`)
+	assert.NoError(t, err)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Success: All Tables",
+			params:         map[string]interface{}{},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"orphans": []interface{}{
+					map[string]interface{}{"tableName": "books", "primaryKey": "id", "key": float64(2), "values": map[string]interface{}{"author_id": float64(2)}, "parentTable": "authors", "parentColumns": []interface{}{"id"}},
+					map[string]interface{}{"tableName": "books", "primaryKey": "id", "key": float64(3), "values": map[string]interface{}{"author_id": float64(3)}, "parentTable": "authors", "parentColumns": []interface{}{"id"}},
+					map[string]interface{}{"tableName": "notes", "primaryKey": "rowid", "key": float64(1), "values": map[string]interface{}{"book_id": float64(9)}, "parentTable": "books", "parentColumns": []interface{}{"id"}},
+				},
+				"total": float64(3),
+			},
+		},
+		{
+			name:           "Success: One Table, Limited",
+			params:         map[string]interface{}{"tableName": "books", "limit": 1},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"orphans": []interface{}{
+					map[string]interface{}{"tableName": "books", "primaryKey": "id", "key": float64(2), "values": map[string]interface{}{"author_id": float64(2)}, "parentTable": "authors", "parentColumns": []interface{}{"id"}},
+				},
+				"total": float64(2),
+			},
+		},
+		{
+			name:             "Failure: Unknown Table",
+			params:           map[string]interface{}{"tableName": "missing"},
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{"statusCode": float64(http.StatusBadRequest), "code": "TABLE_NOT_FOUND", "message": "Bad request: unknown table"},
+		},
+	}, sqliteadmin.FindOrphans, t, ts.server)
+}
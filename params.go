@@ -0,0 +1,299 @@
+package sqliteadmin
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// params are the params of a command, which are validated once decoded.
+type params interface {
+	validate() error
+}
+
+// decodeParams decodes the params of a command into p and validates them.
+// Values are converted when possible, e.g. limits sent as strings.
+func (a *Admin) decodeParams(raw map[string]interface{}, p params) error {
+	// The errors of the hook are returned as is rather than wrapped by
+	// mapstructure
+	var invalid error
+	hook := func(from, to reflect.Type, data interface{}) (interface{}, error) {
+		switch to {
+		case reflect.TypeOf(Condition{}):
+			condition, ok := toCondition(data, a.logger)
+			if !ok {
+				invalid = ErrInvalidCondition
+				return nil, invalid
+			}
+			return *condition, nil
+		case reflect.TypeOf(time.Time{}):
+			s, ok := data.(string)
+			if !ok {
+				return data, nil
+			}
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				invalid = fmt.Errorf("%w: %v", ErrInvalidInput, err)
+				return nil, invalid
+			}
+			return t, nil
+		case reflect.TypeOf(PageVersion{}):
+			page, ok := toPageVersion(data)
+			if !ok {
+				invalid = ErrInvalidInput
+				return nil, invalid
+			}
+			return *page, nil
+		}
+		return data, nil
+	}
+	d, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       hook,
+		WeaklyTypedInput: true,
+		Result:           p,
+	})
+	if err != nil {
+		return err
+	}
+	if err := d.Decode(raw); err != nil {
+		if invalid != nil {
+			return invalid
+		}
+		a.logger.Debug(fmt.Sprintf("Error decoding params: %v", err))
+		return ErrInvalidInput
+	}
+	return p.validate()
+}
+
+// ListTablesParams are the params of ListTables.
+type ListTablesParams struct {
+	// Database is the name of the database, or empty for the default one.
+	Database string `json:"database,omitempty" mapstructure:"database"`
+	// Filter only lists the tables whose name contains it, ignoring case.
+	Filter string `json:"filter,omitempty" mapstructure:"filter"`
+	// Limit is the maximum number of tables, all of them if nil.
+	Limit  *int `json:"limit,omitempty" mapstructure:"limit"`
+	Offset int  `json:"offset,omitempty" mapstructure:"offset"`
+}
+
+func (p *ListTablesParams) validate() error {
+	if p.Offset < 0 || (p.Limit != nil && *p.Limit < 0) {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+type ListTablesResponse struct {
+	// Tables are the names of the tables, sorted.
+	Tables []string `json:"tables"`
+	// Total is the number of tables matching the filter, set when a limit
+	// is given.
+	Total *int `json:"total,omitempty"`
+	// VirtualTables maps the virtual tables among Tables to their module,
+	// e.g. fts5 or rtree.
+	VirtualTables map[string]string `json:"virtualTables,omitempty"`
+	// ShadowTables maps the shadow tables among Tables, which hold the data
+	// of a virtual table and can't be edited, to their virtual table.
+	ShadowTables map[string]string `json:"shadowTables,omitempty"`
+}
+
+// GetTableParams are the params of GetTable.
+type GetTableParams struct {
+	Database  string     `json:"database,omitempty" mapstructure:"database"`
+	TableName string     `json:"tableName" mapstructure:"tableName"`
+	Condition *Condition `json:"condition,omitempty" mapstructure:"condition"`
+	OrderBy   OrderBy    `json:"orderBy,omitempty" mapstructure:"orderBy"`
+	// Limit is the maximum number of rows, the default limit if nil.
+	Limit  *int `json:"limit,omitempty" mapstructure:"limit"`
+	Offset int  `json:"offset,omitempty" mapstructure:"offset"`
+	// Stream sends the rows as newline-delimited JSON, without a limit
+	// unless one is given.
+	Stream bool `json:"stream,omitempty" mapstructure:"stream"`
+	// Delta returns the version of the page, so that the next call can send
+	// it as Since to only get the rows that changed.
+	Delta       bool         `json:"delta,omitempty" mapstructure:"delta"`
+	Since       *PageVersion `json:"since,omitempty" mapstructure:"since"`
+	TimeZone    string       `json:"timeZone,omitempty" mapstructure:"timeZone"`
+	IncludeInfo bool         `json:"includeInfo,omitempty" mapstructure:"includeInfo"`
+	// Dates returns the values of the date columns normalized as ISO-8601,
+	// in the time zone, along with the stored values.
+	Dates bool `json:"dates,omitempty" mapstructure:"dates"`
+	// Sample returns this many random rows matching the condition instead
+	// of a page. It is clamped to the maximum limit like Limit.
+	Sample int `json:"sample,omitempty" mapstructure:"sample"`
+}
+
+func (p *GetTableParams) validate() error {
+	if p.TableName == "" {
+		return ErrMissingTableName
+	}
+	if p.OrderBy != "" && p.OrderBy != OrderByRelevance {
+		return ErrInvalidOrderBy
+	}
+	if (p.Since != nil && !p.Delta) || ((p.Delta || p.Dates) && p.Stream) {
+		return ErrInvalidInput
+	}
+	if p.Sample < 0 || (p.Sample > 0 && (p.Limit != nil || p.Offset != 0 || p.OrderBy != "" || p.Stream || p.Delta)) {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+type GetTableResponse struct {
+	Rows []map[string]interface{} `json:"rows"`
+	// Limit is set when the requested limit was clamped to the maximum limit.
+	Limit     int          `json:"limit,omitempty"`
+	Version   *PageVersion `json:"version,omitempty"`
+	TableInfo *TableInfo   `json:"tableInfo,omitempty"`
+	// Dates holds the normalized values of the date columns of each row.
+	Dates []map[string]DateValue `json:"dates,omitempty"`
+}
+
+// GetTableDeltaResponse is the response of GetTable when Since is set.
+type GetTableDeltaResponse struct {
+	Delta     *PageDelta `json:"delta"`
+	TableInfo *TableInfo `json:"tableInfo,omitempty"`
+}
+
+type TableInfo struct {
+	Columns         []ColumnInfo    `json:"columns"`
+	Count           int             `json:"count"`
+	IndexAdvisories []IndexAdvisory `json:"indexAdvisories"`
+	Triggers        []Trigger       `json:"triggers"`
+	// Strict is set for STRICT tables, whose values must match the type of
+	// their column.
+	Strict bool `json:"strict,omitempty"`
+	// Sequence is the AUTOINCREMENT sequence of the table, the largest rowid
+	// handed out so far, for the tables using AUTOINCREMENT.
+	Sequence *int64 `json:"sequence,omitempty"`
+	// Module is the module of virtual tables, and ShadowTables the tables
+	// holding their data.
+	Module       string   `json:"module,omitempty"`
+	ShadowTables []string `json:"shadowTables,omitempty"`
+	// ShadowOf is the virtual table of shadow tables, which can be queried
+	// but not edited.
+	ShadowOf string `json:"shadowOf,omitempty"`
+}
+
+// ColumnInfo is a column of a table as reported by PRAGMA table_xinfo.
+type ColumnInfo struct {
+	CID      int    `json:"cid"`
+	Name     string `json:"name"`
+	DataType string `json:"dataType"`
+	NotNull  int    `json:"notNull"`
+	// Default is the expression of the default value of the column, e.g.
+	// 'draft' or CURRENT_TIMESTAMP, if it has one.
+	Default string `json:"default,omitempty"`
+	// PK is the position of the column in the primary key, starting at 1,
+	// or 0 if it isn't part of it.
+	PK int `json:"pk"`
+	// Boolean is set for the columns declared as BOOLEAN or BOOL, whose
+	// values are returned as booleans.
+	Boolean bool `json:"boolean,omitempty"`
+	// Enum holds the values allowed by a CHECK constraint of the form
+	// col IN ('a', 'b', 'c').
+	Enum []string `json:"enum,omitempty"`
+	// Generated is GeneratedVirtual or GeneratedStored for generated
+	// columns.
+	Generated string `json:"generated,omitempty"`
+	// ReadOnly is set for the columns that can't be updated, which are
+	// left out of the updates.
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// Geometry is GeometryGeopoly or GeometrySpatiaLite for the columns
+	// holding geometries, which are returned as GeoJSON and can be set to
+	// GeoJSON.
+	Geometry string `json:"geometry,omitempty"`
+}
+
+// UpdateRowParams are the params of UpdateRow.
+type UpdateRowParams struct {
+	Database  string `json:"database,omitempty" mapstructure:"database"`
+	TableName string `json:"tableName" mapstructure:"tableName"`
+	// Row holds the primary key of the row and the values of the columns to
+	// update.
+	Row map[string]interface{} `json:"row" mapstructure:"row"`
+	// Columns, when set, are the only columns of Row that are updated, so
+	// that the other values sent along, e.g. the whole row as displayed, are
+	// left as they are. They must all be columns of the table and have a
+	// value in Row, possibly null.
+	Columns []string `json:"columns,omitempty" mapstructure:"columns"`
+	// Transaction is the ID of the transaction to run the update in, if any.
+	Transaction string `json:"transaction,omitempty" mapstructure:"transaction"`
+}
+
+func (p *UpdateRowParams) validate() error {
+	if p.TableName == "" {
+		return ErrMissingTableName
+	}
+	if p.Row == nil {
+		return ErrMissingRow
+	}
+	for _, c := range p.Columns {
+		if _, ok := p.Row[c]; !ok {
+			return fmt.Errorf("%w: %s", ErrMissingValue, c)
+		}
+	}
+	return nil
+}
+
+type UpdateRowResponse struct {
+	Status         string          `json:"status"`
+	SecretWarnings []SecretFinding `json:"secretWarnings,omitempty"`
+}
+
+// InsertRowParams are the params of InsertRow.
+type InsertRowParams struct {
+	Database  string `json:"database,omitempty" mapstructure:"database"`
+	TableName string `json:"tableName" mapstructure:"tableName"`
+	// Row holds the values of the columns to insert. The columns left out
+	// get their default value.
+	Row         map[string]interface{} `json:"row" mapstructure:"row"`
+	Transaction string                 `json:"transaction,omitempty" mapstructure:"transaction"`
+}
+
+func (p *InsertRowParams) validate() error {
+	if p.TableName == "" {
+		return ErrMissingTableName
+	}
+	if p.Row == nil {
+		return ErrMissingRow
+	}
+	return nil
+}
+
+type InsertRowResponse struct {
+	Status string `json:"status"`
+	// Row is the row as inserted, with its default values and rowid.
+	Row            map[string]interface{} `json:"row"`
+	SecretWarnings []SecretFinding        `json:"secretWarnings,omitempty"`
+}
+
+// DeleteRowsParams are the params of DeleteRows.
+type DeleteRowsParams struct {
+	Database  string `json:"database,omitempty" mapstructure:"database"`
+	TableName string `json:"tableName" mapstructure:"tableName"`
+	// IDs are the primary keys of the rows to delete.
+	IDs         []string `json:"ids" mapstructure:"ids"`
+	Transaction string   `json:"transaction,omitempty" mapstructure:"transaction"`
+	// PreviewCascade reports the rows of other tables the delete would
+	// delete, update or be blocked by, see DeleteRowsPreviewResponse,
+	// instead of deleting the rows.
+	PreviewCascade bool `json:"previewCascade,omitempty" mapstructure:"previewCascade"`
+}
+
+func (p *DeleteRowsParams) validate() error {
+	if p.TableName == "" {
+		return ErrMissingTableName
+	}
+	if p.IDs == nil {
+		return ErrInvalidOrMissingIds
+	}
+	return nil
+}
+
+type DeleteRowsResponse struct {
+	// RowsAffected is the number of deleted rows, as a string.
+	RowsAffected string `json:"rowsAffected"`
+}
@@ -0,0 +1,333 @@
+package sqliteadmin
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// RedactedValue replaces the values of redacted columns.
+const RedactedValue = "[REDACTED]"
+
+// Normalization rules of ColumnPolicy.Normalize.
+const (
+	NormalizeTrim  = "trim"
+	NormalizeLower = "lower"
+	NormalizeUpper = "upper"
+)
+
+// ColumnPolicy gathers the governance rules of a column. Redact, Mask, Encrypt
+// and Anonymize apply to the values returned by GetTable, PreviewTable and
+// GetColumnStats, Mask, Encrypt, Normalize and Enum to the values written by
+// InsertRow and UpdateRow.
+type ColumnPolicy struct {
+	// Redact replaces the values with RedactedValue.
+	Redact bool `json:"redact,omitempty"`
+	// Mask redacts the values like Redact and only lets elevated users, see
+	// Config.Elevated, update them.
+	Mask bool `json:"mask,omitempty"`
+	// Encrypt stores the values written encrypted with AES-GCM, using the
+	// keys of Config.KeyProvider. They are only decrypted for callers allowed
+	// to unseal them, see Config.CanUnseal, and shown as SealedValue to the
+	// others.
+	Encrypt bool `json:"encrypt,omitempty"`
+	// Anonymize replaces the values with a pseudonym which is the same for
+	// equal values, so that rows can still be compared.
+	Anonymize bool `json:"anonymize,omitempty"`
+	// Normalize lists the rules applied in order to the string values
+	// written, e.g. NormalizeTrim then NormalizeLower.
+	Normalize []string `json:"normalize,omitempty"`
+	// Enum lists the values allowed to be written, any if empty.
+	Enum []string `json:"enum,omitempty"`
+}
+
+// hides reports whether the values of the column are not shown as stored.
+func (p ColumnPolicy) hides() bool {
+	return p.Redact || p.Mask || p.Encrypt || p.Anonymize
+}
+
+// ColumnPolicies maps table names, matched ignoring case, to the policies of
+// their columns. The policies under the "*" table apply to the columns of every table, unless
+// the table has its own policy for the column.
+type ColumnPolicies map[string]map[string]ColumnPolicy
+
+// Validate returns an error if a policy uses an unknown normalization rule.
+func (p ColumnPolicies) Validate() error {
+	for table, columns := range p {
+		for column, policy := range columns {
+			for _, rule := range policy.Normalize {
+				if rule != NormalizeTrim && rule != NormalizeLower && rule != NormalizeUpper {
+					return fmt.Errorf("unknown normalization rule %q for %s.%s", rule, table, column)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// withColumns returns a copy of the policies where set is applied to the
+// policies of the columns matching the "table.column" patterns. The table may
+// be "*" for the column of every table.
+func (p ColumnPolicies) withColumns(patterns []string, set func(p *ColumnPolicy)) (ColumnPolicies, error) {
+	policies := make(ColumnPolicies, len(p))
+	for table, columns := range p {
+		policies[table] = make(map[string]ColumnPolicy, len(columns))
+		for column, policy := range columns {
+			policies[table][column] = policy
+		}
+	}
+	for _, pattern := range patterns {
+		table, column, ok := strings.Cut(pattern, ".")
+		if !ok || table == "" || column == "" {
+			return p, fmt.Errorf("invalid column %q, expected table.column", pattern)
+		}
+		if policies[table] == nil {
+			policies[table] = make(map[string]ColumnPolicy)
+		}
+		policy := policies[table][column]
+		set(&policy)
+		policies[table][column] = policy
+	}
+	return policies, nil
+}
+
+// folded returns a copy of the policies keyed by the lowercased table names,
+// merging the columns of a table named with different cases, since SQLite
+// ignores case in table names.
+func (p ColumnPolicies) folded() ColumnPolicies {
+	policies := make(ColumnPolicies, len(p))
+	for table, columns := range p {
+		t := strings.ToLower(table)
+		if policies[t] == nil {
+			policies[t] = make(map[string]ColumnPolicy, len(columns))
+		}
+		for column, policy := range columns {
+			policies[t][column] = policy
+		}
+	}
+	return policies
+}
+
+// newKey returns a random key used when Config.AnonymizationKey or
+// Config.ArtifactKey is not set, which doesn't survive restarts.
+func newKey() []byte {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return key
+}
+
+// columnPolicy returns the policy of the column, if any.
+func (a *Admin) columnPolicy(table, column string) (ColumnPolicy, bool) {
+	if p, ok := a.columnPolicies[strings.ToLower(table)][column]; ok {
+		return p, true
+	}
+	p, ok := a.columnPolicies["*"][column]
+	return p, ok
+}
+
+// tablePolicies returns the policies of the columns of the table, including
+// the ones of every table.
+func (a *Admin) tablePolicies(table string) map[string]ColumnPolicy {
+	policies := make(map[string]ColumnPolicy)
+	for column, p := range a.columnPolicies["*"] {
+		policies[column] = p
+	}
+	for column, p := range a.columnPolicies[strings.ToLower(table)] {
+		policies[column] = p
+	}
+	return policies
+}
+
+// isElevated reports whether the request was made by an elevated user.
+func isElevated(ctx context.Context) bool {
+	elevated, _ := ctx.Value(elevatedKey).(bool)
+	return elevated
+}
+
+func (a *Admin) anonymize(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	if b, ok := value.([]byte); ok {
+		value = string(b)
+	}
+	mac := hmac.New(sha256.New, a.anonymizationKey)
+	fmt.Fprint(mac, value)
+	return "anon_" + hex.EncodeToString(mac.Sum(nil))[:12]
+}
+
+// protectValue returns the value as shown to clients.
+func (a *Admin) protectValue(p ColumnPolicy, value interface{}) interface{} {
+	switch {
+	case p.Encrypt:
+		if value == nil {
+			return nil
+		}
+		return SealedValue
+	case p.Redact, p.Mask:
+		if value == nil {
+			return nil
+		}
+		return RedactedValue
+	case p.Anonymize:
+		return a.anonymize(value)
+	}
+	return value
+}
+
+// protectRows decodes the transformed columns and applies the Redact, Mask,
+// Encrypt and Anonymize policies to the rows of the table.
+func (a *Admin) protectRows(ctx context.Context, table string, rows []map[string]interface{}) {
+	a.decodeRows(ctx, table, rows)
+	policies := a.tablePolicies(table)
+	if len(policies) == 0 {
+		return
+	}
+	unseal := canUnseal(ctx)
+	for _, row := range rows {
+		for column, p := range policies {
+			v, ok := row[column]
+			if !ok || !p.hides() {
+				continue
+			}
+			if p.Encrypt && unseal && v != nil {
+				row[column] = a.unsealValue(table, column, v)
+				continue
+			}
+			row[column] = a.protectValue(p, v)
+		}
+	}
+}
+
+// hiddenColumn returns a column of the condition whose values are hidden, if
+// any. Filtering on such columns would reveal their values.
+func (a *Admin) hiddenColumn(table string, condition *Condition) (string, bool) {
+	for _, c := range condition.Cases {
+		switch c := c.(type) {
+		case Condition:
+			if column, ok := a.hiddenColumn(table, &c); ok {
+				return column, true
+			}
+		case Filter:
+			if p, ok := a.columnPolicy(table, c.Column); ok && p.hides() {
+				return c.Column, true
+			}
+			// Transformed values are stored encoded
+			if a.transformed(table, c.Column) {
+				return c.Column, true
+			}
+		}
+	}
+	return "", false
+}
+
+// applyWritePolicies normalizes the values of the row about to be written to
+// the table, checks them against the allowed values and encrypts or encodes
+// them. Redacted and sealed values sent back by clients are left unchanged.
+func (a *Admin) applyWritePolicies(ctx context.Context, table string, row map[string]interface{}) error {
+	for column, value := range row {
+		p, ok := a.columnPolicy(table, column)
+		if !ok {
+			continue
+		}
+		if ((p.Redact || p.Mask) && value == RedactedValue) || (p.Encrypt && value == SealedValue) {
+			delete(row, column)
+			continue
+		}
+		if p.Mask && !isElevated(ctx) {
+			return fmt.Errorf("%w: %s", ErrMaskedColumn, column)
+		}
+		s, isString := value.(string)
+		if isString {
+			for _, rule := range p.Normalize {
+				switch rule {
+				case NormalizeTrim:
+					s = strings.TrimSpace(s)
+				case NormalizeLower:
+					s = strings.ToLower(s)
+				case NormalizeUpper:
+					s = strings.ToUpper(s)
+				}
+			}
+			row[column] = s
+		}
+		if len(p.Enum) > 0 && value != nil && (!isString || !contains(p.Enum, s)) {
+			return fmt.Errorf("%w: %s must be one of %s", ErrValueNotAllowed, column, strings.Join(p.Enum, ", "))
+		}
+		if p.Encrypt && row[column] != nil {
+			encrypted, err := a.encryptValue(table, column, row[column])
+			if err != nil {
+				return err
+			}
+			row[column] = encrypted
+		}
+	}
+	return a.encodeRow(ctx, table, row)
+}
+
+func (a *Admin) getColumnPolicies(w http.ResponseWriter, params map[string]interface{}) {
+	table, _ := params["tableName"].(string)
+
+	a.logger.Info(fmt.Sprintf("Command: GetColumnPolicies, table=%s", table))
+
+	policies := ColumnPolicies{}
+	// Policies of every table are listed with the table filter too
+	for t, columns := range a.columnPolicies {
+		if table == "" || t == strings.ToLower(table) || t == "*" {
+			policies[t] = columns
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"policies": policies})
+}
+
+// protectStats applies the Redact and Anonymize policies to the stats of a
+// column.
+func (a *Admin) protectStats(table string, stats *ColumnStats) {
+	p, ok := a.columnPolicy(table, stats.Name)
+	if !ok || !p.hides() {
+		return
+	}
+	stats.Min = a.protectValue(p, stats.Min)
+	stats.Max = a.protectValue(p, stats.Max)
+	stats.AvgLength = nil
+	if p.Redact {
+		stats.TopValues = []ValueCount{}
+	}
+	for i := range stats.TopValues {
+		stats.TopValues[i].Value = a.protectValue(p, stats.TopValues[i].Value)
+	}
+}
+
+// protectPreview applies the Redact and Anonymize policies to the preview of
+// the table.
+func (a *Admin) protectPreview(ctx context.Context, table string, preview *TablePreview) {
+	a.protectRows(ctx, table, preview.Head)
+	a.protectRows(ctx, table, preview.Tail)
+	a.protectRows(ctx, table, preview.Sample)
+	for i, c := range preview.Columns {
+		if p, ok := a.columnPolicy(table, c.Name); ok && p.hides() {
+			preview.Columns[i].Min = a.protectValue(p, c.Min)
+			preview.Columns[i].Max = a.protectValue(p, c.Max)
+		}
+	}
+}
+
+// checkCondition writes an error and returns false if the condition filters on
+// a column whose values are hidden.
+func (a *Admin) checkCondition(w http.ResponseWriter, table string, condition *Condition) bool {
+	if condition == nil {
+		return true
+	}
+	if column, ok := a.hiddenColumn(table, condition); ok {
+		writeError(w, apiErrBadRequest(fmt.Sprintf("%s: %s", ErrHiddenColumn, column)))
+		return false
+	}
+	return true
+}
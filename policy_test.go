@@ -0,0 +1,238 @@
+package sqliteadmin_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumnPolicies(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.ColumnPolicies = sqliteadmin.ColumnPolicies{
+			"users": {
+				"email": {Redact: true},
+				"name":  {Normalize: []string{sqliteadmin.NormalizeTrim}, Enum: []string{"Alice", "Zoe"}},
+			},
+		}
+	})
+	defer close()
+
+	runTestCases([]TestCase{
+		{
+			name:           "Success",
+			params:         map[string]interface{}{"tableName": "users", "limit": 1},
+			expectedStatus: http.StatusOK,
+			expectedResponse: makeGetTableResponse([]responseRow{
+				{id: 1, name: "Alice", email: sqliteadmin.RedactedValue},
+			}),
+		},
+		{
+			name: "Failure: Filter On Redacted Column",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"condition": sqliteadmin.Condition{
+					Cases: []sqliteadmin.Case{
+						sqliteadmin.Filter{Column: "email", Operator: sqliteadmin.OperatorLike, Value: "a%"},
+					},
+					LogicalOperator: sqliteadmin.LogicalOperatorAnd,
+				},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: cannot filter on a redacted or anonymized column: email",
+			},
+		},
+	}, sqliteadmin.GetTable, t, ts.server)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Value Not Allowed",
+			params:         map[string]interface{}{"tableName": "users", "row": map[string]interface{}{"id": 1, "name": "Alicia"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: value not allowed: name must be one of Alice, Zoe",
+			},
+		},
+		{
+			name:             "Success: Normalized",
+			params:           map[string]interface{}{"tableName": "users", "row": map[string]interface{}{"id": 1, "name": "  Zoe "}},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok"},
+		},
+	}, sqliteadmin.UpdateRow, t, ts.server)
+
+	var name string
+	err := ts.db.QueryRow("SELECT name FROM users WHERE id = 1").Scan(&name)
+	assert.NoError(t, err)
+	assert.Equal(t, "Zoe", name)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Success",
+			params:         map[string]interface{}{"tableName": "users"},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"policies": map[string]interface{}{
+					"users": map[string]interface{}{
+						"email": map[string]interface{}{"redact": true},
+						"name": map[string]interface{}{
+							"normalize": []interface{}{"trim"},
+							"enum":      []interface{}{"Alice", "Zoe"},
+						},
+					},
+				},
+			},
+		},
+	}, sqliteadmin.GetColumnPolicies, t, ts.server)
+}
+
+func TestColumnPoliciesAnonymize(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.ColumnPolicies = sqliteadmin.ColumnPolicies{
+			"users": {"email": {Anonymize: true}},
+		}
+		c.AnonymizationKey = []byte("key")
+	})
+	defer close()
+
+	_, err := ts.db.Exec("UPDATE users SET email = 'shared@gmail.com' WHERE id IN (1, 2)")
+	assert.NoError(t, err)
+
+	req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{
+		Command: sqliteadmin.GetTable,
+		Params:  map[string]interface{}{"tableName": "users", "limit": 3},
+	})
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	rows := readBody(t, res.Body)["rows"].([]interface{})
+	emails := make([]string, len(rows))
+	for i, r := range rows {
+		emails[i] = r.(map[string]interface{})["email"].(string)
+		assert.True(t, strings.HasPrefix(emails[i], "anon_"))
+	}
+	// Equal values share a pseudonym
+	assert.Equal(t, emails[0], emails[1])
+	assert.NotEqual(t, emails[0], emails[2])
+}
+
+func TestMaskedColumns(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.MaskedColumns = []string{"*.email"}
+		c.Elevated = func(r *http.Request) bool {
+			return r.Header.Get("X-Role") == "admin"
+		}
+	})
+	defer close()
+
+	runTestCases([]TestCase{
+		{
+			name:           "Success",
+			params:         map[string]interface{}{"tableName": "users", "limit": 1},
+			expectedStatus: http.StatusOK,
+			expectedResponse: makeGetTableResponse([]responseRow{
+				{id: 1, name: "Alice", email: sqliteadmin.RedactedValue},
+			}),
+		},
+	}, sqliteadmin.GetTable, t, ts.server)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Masked Column",
+			params:         map[string]interface{}{"tableName": "users", "row": map[string]interface{}{"id": 1, "email": "alice@example.com"}},
+			expectedStatus: http.StatusForbidden,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusForbidden),
+				"code":       "FORBIDDEN",
+				"message":    "Forbidden: masked columns can only be updated by elevated users: email",
+			},
+		},
+		{
+			name: "Success: Redacted Value Sent Back",
+			params: map[string]interface{}{"tableName": "users", "row": map[string]interface{}{
+				"id": 1, "name": "Alicia", "email": sqliteadmin.RedactedValue,
+			}},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok"},
+		},
+	}, sqliteadmin.UpdateRow, t, ts.server)
+
+	req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{
+		Command: sqliteadmin.UpdateRow,
+		Params:  map[string]interface{}{"tableName": "users", "row": map[string]interface{}{"id": 2, "email": "bob@example.com"}},
+	})
+	req.Header.Set("X-Role", "admin")
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	rows, err := ts.db.Query("SELECT name, email FROM users WHERE id IN (1, 2) ORDER BY id")
+	assert.NoError(t, err)
+	defer rows.Close()
+	var got [][2]string
+	for rows.Next() {
+		var name, email string
+		assert.NoError(t, rows.Scan(&name, &email))
+		got = append(got, [2]string{name, email})
+	}
+	assert.Equal(t, [][2]string{{"Alicia", "alice@gmail.com"}, {"Bob", "bob@example.com"}}, got)
+}
+
+func TestMaskedColumnsIgnoreTableCase(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.ColumnPolicies = sqliteadmin.ColumnPolicies{"Users": {"email": {Mask: true}}}
+		c.Elevated = func(r *http.Request) bool {
+			return r.Header.Get("X-Role") == "admin"
+		}
+	})
+	defer close()
+
+	forbidden := map[string]interface{}{
+		"statusCode": float64(http.StatusForbidden),
+		"code":       "FORBIDDEN",
+		"message":    "Forbidden: masked columns can only be updated by elevated users: email",
+	}
+	runTestCases([]TestCase{
+		{
+			name:             "Failure: Table Named With Another Case",
+			params:           map[string]interface{}{"tableName": "USERS", "row": map[string]interface{}{"name": "Mallory", "email": "mallory@example.com"}},
+			expectedStatus:   http.StatusForbidden,
+			expectedResponse: forbidden,
+		},
+	}, sqliteadmin.InsertRow, t, ts.server)
+
+	// Merging copies the value of the masked column
+	runTestCases([]TestCase{
+		{
+			name:             "Failure: Masked Column",
+			params:           map[string]interface{}{"tableName": "users", "keepId": 1, "mergeId": 2, "fields": map[string]interface{}{"email": "merge"}},
+			expectedStatus:   http.StatusForbidden,
+			expectedResponse: forbidden,
+		},
+		{
+			name:             "Success: Masked Column Kept",
+			params:           map[string]interface{}{"tableName": "users", "keepId": 1, "mergeId": 2, "fields": map[string]interface{}{"email": "keep"}},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok", "referencesUpdated": float64(0)},
+		},
+	}, sqliteadmin.MergeRows, t, ts.server)
+
+	req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{
+		Command: sqliteadmin.MergeRows,
+		Params:  map[string]interface{}{"tableName": "users", "keepId": 1, "mergeId": 3, "fields": map[string]interface{}{"email": "merge"}},
+	})
+	req.Header.Set("X-Role", "admin")
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	var email string
+	assert.NoError(t, ts.db.QueryRow("SELECT email FROM users WHERE id = 1").Scan(&email))
+	assert.Equal(t, "charlie@gmail.com", email)
+}
@@ -0,0 +1,124 @@
+package sqliteadmin
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// PoolStats reports the state of the connection pools of the databases along
+// with the commands being handled, to diagnose commands holding on to
+// connections of a pool shared with the application.
+type PoolStats struct {
+	// Default and Read are the pools of Config.DB and Config.ReadDB, if set.
+	Default   *DBPoolStats           `json:"default,omitempty"`
+	Read      *DBPoolStats           `json:"read,omitempty"`
+	Databases map[string]DBPoolStats `json:"databases"`
+	// Transactions is the number of open transaction sessions, each of which
+	// holds a connection until it is committed, rolled back or expires.
+	Transactions int               `json:"transactions"`
+	Commands     []InFlightCommand `json:"commands"`
+}
+
+// DBPoolStats is the subset of sql.DBStats relevant to the admin, with the
+// durations in milliseconds.
+type DBPoolStats struct {
+	MaxOpenConnections int   `json:"maxOpenConnections"`
+	OpenConnections    int   `json:"openConnections"`
+	InUse              int   `json:"inUse"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"waitCount"`
+	WaitDurationMs     int64 `json:"waitDurationMs"`
+	MaxIdleClosed      int64 `json:"maxIdleClosed"`
+	MaxIdleTimeClosed  int64 `json:"maxIdleTimeClosed"`
+	MaxLifetimeClosed  int64 `json:"maxLifetimeClosed"`
+}
+
+// InFlightCommand is a command that is being handled.
+type InFlightCommand struct {
+	RequestID string    `json:"requestId"`
+	Command   Command   `json:"command"`
+	User      string    `json:"user,omitempty"`
+	Database  string    `json:"database,omitempty"`
+	Table     string    `json:"table,omitempty"`
+	Started   time.Time `json:"started"`
+	ElapsedMs int64     `json:"elapsedMs"`
+}
+
+func newDBPoolStats(db *sql.DB) DBPoolStats {
+	s := db.Stats()
+	return DBPoolStats{
+		MaxOpenConnections: s.MaxOpenConnections,
+		OpenConnections:    s.OpenConnections,
+		InUse:              s.InUse,
+		Idle:               s.Idle,
+		WaitCount:          s.WaitCount,
+		WaitDurationMs:     s.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      s.MaxIdleClosed,
+		MaxIdleTimeClosed:  s.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  s.MaxLifetimeClosed,
+	}
+}
+
+// PoolStats returns the current state of the connection pools and the
+// commands being handled, longest running first.
+func (a *Admin) PoolStats() PoolStats {
+	stats := PoolStats{Databases: make(map[string]DBPoolStats), Commands: []InFlightCommand{}}
+	if a.db != nil {
+		s := newDBPoolStats(a.db)
+		stats.Default = &s
+	}
+	if a.readDB != nil {
+		s := newDBPoolStats(a.readDB)
+		stats.Read = &s
+	}
+
+	now := time.Now()
+	a.mu.RLock()
+	for name, db := range a.dbs {
+		stats.Databases[name] = newDBPoolStats(db)
+	}
+	stats.Transactions = len(a.transactions)
+	for c := range a.inFlight {
+		command := *c
+		command.ElapsedMs = now.Sub(c.Started).Milliseconds()
+		stats.Commands = append(stats.Commands, command)
+	}
+	a.mu.RUnlock()
+
+	sort.Slice(stats.Commands, func(i, j int) bool {
+		return stats.Commands[i].Started.Before(stats.Commands[j].Started)
+	})
+	return stats
+}
+
+func (a *Admin) getPoolStats(w http.ResponseWriter) {
+	a.logger.Info("Command: GetPoolStats")
+	json.NewEncoder(w).Encode(a.PoolStats())
+}
+
+// trackCommand adds the command of the request to the in-flight commands
+// until the returned function is called.
+func (a *Admin) trackCommand(cr *CommandRequest) func() {
+	database, _ := cr.Params["database"].(string)
+	table, _ := cr.Params["tableName"].(string)
+	c := &InFlightCommand{
+		RequestID: a.request.id,
+		Command:   cr.Command,
+		User:      UserFromContext(a.request.ctx),
+		Database:  database,
+		Table:     table,
+		Started:   a.request.start,
+	}
+
+	a.mu.Lock()
+	a.inFlight[c] = struct{}{}
+	a.mu.Unlock()
+	return func() {
+		a.mu.Lock()
+		delete(a.inFlight, c)
+		a.mu.Unlock()
+	}
+}
@@ -0,0 +1,68 @@
+package sqliteadmin_test
+
+import (
+	"database/sql"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPoolStats(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "app.db"))
+	assert.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(4)
+	_, err = db.Exec("CREATE TABLE t (id INTEGER PRIMARY KEY)")
+	assert.NoError(t, err)
+	ts.admin.AddDatabase("app", db)
+
+	// A long poll holds on to a connection until its timeout
+	done := make(chan struct{}, 1)
+	go func() {
+		defer func() { done <- struct{}{} }()
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{
+			Command: sqliteadmin.WaitForChanges,
+			Params:  map[string]interface{}{"database": "app", "version": "1", "timeout": 1},
+		})
+		res, err := http.DefaultClient.Do(req)
+		if err == nil {
+			res.Body.Close()
+		}
+	}()
+
+	var commands []interface{}
+	var result map[string]interface{}
+	assert.Eventually(t, func() bool {
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: sqliteadmin.GetPoolStats})
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		result = readBody(t, res.Body)
+		commands, _ = result["commands"].([]interface{})
+		return len(commands) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	// Oldest first, including the GetPoolStats request itself
+	if assert.Len(t, commands, 2) {
+		wait := commands[0].(map[string]interface{})
+		assert.Equal(t, "WaitForChanges", wait["command"])
+		assert.Equal(t, "app", wait["database"])
+		assert.NotEmpty(t, wait["requestId"])
+		assert.Equal(t, "GetPoolStats", commands[1].(map[string]interface{})["command"])
+	}
+	def := result["default"].(map[string]interface{})
+	assert.Equal(t, float64(1), def["maxOpenConnections"])
+	app := result["databases"].(map[string]interface{})["app"].(map[string]interface{})
+	assert.Equal(t, float64(4), app["maxOpenConnections"])
+	assert.Equal(t, float64(1), app["inUse"])
+	assert.Equal(t, float64(0), result["transactions"])
+
+	<-done
+}
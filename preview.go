@@ -0,0 +1,163 @@
+package sqliteadmin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultPreviewRows is the number of rows returned in each part of a
+// PreviewTable response when the "rows" param is not provided.
+const DefaultPreviewRows = 5
+
+// ColumnSummary is a quick overview of the values of a column.
+type ColumnSummary struct {
+	Name      string      `json:"name"`
+	Type      string      `json:"type"`
+	NullCount int64       `json:"nullCount"`
+	Min       interface{} `json:"min"`
+	Max       interface{} `json:"max"`
+}
+
+// TablePreview gives a feel for a table without paginating through it: its
+// first and last rows, a random sample of rows and a summary of each column.
+type TablePreview struct {
+	Count   int64                    `json:"count"`
+	Columns []ColumnSummary          `json:"columns"`
+	Head    []map[string]interface{} `json:"head"`
+	Tail    []map[string]interface{} `json:"tail"`
+	Sample  []map[string]interface{} `json:"sample"`
+}
+
+func (a *Admin) previewTable(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getReadDB(w, params)
+	if !ok {
+		return
+	}
+
+	table, ok := params["tableName"].(string)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrMissingTableName.Error()))
+		return
+	}
+
+	n := DefaultPreviewRows
+	if params["rows"] != nil {
+		n, ok = convertNumber(params["rows"])
+		if !ok || n < 0 {
+			writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+			return
+		}
+	}
+	if exceeds(n, a.maxLimit) {
+		writeError(w, apiErrBadRequest(ErrLimitTooLarge.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: PreviewTable, table=%s, rows=%d", table, n))
+
+	scope, err := a.rowScope(ctx, table)
+	if err != nil {
+		a.writeScopeError(w, err)
+		return
+	}
+	preview, err := getTablePreview(ctx, a.cached(db), table, n, scope)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error previewing table: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+
+	a.protectPreview(ctx, table, preview)
+	a.encodeRows(preview.Head)
+	a.encodeRows(preview.Tail)
+	a.encodeRows(preview.Sample)
+	for i, c := range preview.Columns {
+		preview.Columns[i].Min, preview.Columns[i].Max = a.encodeValue(c.Min), a.encodeValue(c.Max)
+	}
+	a.recordRows(len(preview.Head) + len(preview.Tail) + len(preview.Sample))
+	json.NewEncoder(w).Encode(preview)
+}
+
+// getTablePreview previews the rows of the table in the scope, or all of them
+// when it is nil.
+func getTablePreview(ctx context.Context, q queryer, table string, n int, scope *Condition) (*TablePreview, error) {
+	columns, err := getColumns(ctx, q, table)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &TablePreview{}
+	preview.Count, preview.Columns, err = summarizeColumns(ctx, q, table, columns, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	where, args := scopeWhere(scope)
+	preview.Head, err = queryRows(ctx, q, fmt.Sprintf("SELECT * FROM %s%s LIMIT ?", quoteIdent(table), where), append(args, n)...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Skipping to the end works for WITHOUT ROWID tables as well and keeps the
+	// rows in the same order as the head.
+	tailOffset := max(preview.Count-int64(n), 0)
+	preview.Tail, err = queryRows(ctx, q, fmt.Sprintf("SELECT * FROM %s%s LIMIT ? OFFSET ?", quoteIdent(table), where), append(args, n, tailOffset)...)
+	if err != nil {
+		return nil, err
+	}
+
+	preview.Sample, err = queryRows(ctx, q, fmt.Sprintf("SELECT * FROM %s%s ORDER BY random() LIMIT ?", quoteIdent(table), where), append(args, n)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return preview, nil
+}
+
+// summarizeColumns computes the row count and the summary of every column in
+// a single scan of the table.
+func summarizeColumns(ctx context.Context, q queryer, table string, columns []column, scope *Condition) (int64, []ColumnSummary, error) {
+	exprs := []string{"count(*)"}
+	for _, c := range columns {
+		exprs = append(exprs, fmt.Sprintf("count(%s)", quoteIdent(c.Name)), fmt.Sprintf("min(%s)", quoteIdent(c.Name)), fmt.Sprintf("max(%s)", quoteIdent(c.Name)))
+	}
+	where, args := scopeWhere(scope)
+	query := fmt.Sprintf("SELECT %s FROM %s%s", strings.Join(exprs, ", "), quoteIdent(table), where)
+
+	var count int64
+	nonNull := make([]int64, len(columns))
+	summaries := make([]ColumnSummary, len(columns))
+	dest := []interface{}{&count}
+	for i, c := range columns {
+		summaries[i] = ColumnSummary{Name: c.Name, Type: c.DataType}
+		dest = append(dest, &nonNull[i], &summaries[i].Min, &summaries[i].Max)
+	}
+	if err := q.QueryRowContext(ctx, query, args...).Scan(dest...); err != nil {
+		return 0, nil, fmt.Errorf("error summarizing columns: %v", err)
+	}
+
+	for i := range summaries {
+		summaries[i].NullCount = count - nonNull[i]
+	}
+	return count, summaries, nil
+}
+
+func queryRows(ctx context.Context, q queryer, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying table: %v", err)
+	}
+	defer rows.Close()
+
+	result, err := scanRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		result = []map[string]interface{}{}
+	}
+	return result, nil
+}
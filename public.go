@@ -0,0 +1,275 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of the public data viewer, used when the corresponding Config
+// field is zero.
+const (
+	DefaultPublicMaxRows   = 100
+	DefaultPublicRateLimit = 60
+	DefaultPublicCacheTTL  = time.Minute
+)
+
+// PublicTable is a table exposed read-only without authentication by
+// HandlePublic.
+type PublicTable struct {
+	// Columns are the only columns returned, all of them when empty.
+	Columns []string `json:"columns"`
+	// MaxRows caps the rows returned by a request, DefaultPublicMaxRows when
+	// zero.
+	MaxRows int `json:"maxRows"`
+}
+
+// publicViewer holds the state of HandlePublic shared by all requests.
+type publicViewer struct {
+	tables    map[string]PublicTable
+	rateLimit int
+	cacheTTL  time.Duration
+
+	mu       sync.Mutex
+	requests map[string]*rateWindow
+	cache    map[string]cachedResponse
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+type cachedResponse struct {
+	body    []byte
+	expires time.Time
+}
+
+func newPublicViewer(c Config) *publicViewer {
+	if c.PublicCacheTTL == 0 {
+		c.PublicCacheTTL = DefaultPublicCacheTTL
+	}
+	return &publicViewer{
+		tables:    c.PublicTables,
+		rateLimit: limitOrDefault(c.PublicRateLimit, DefaultPublicRateLimit),
+		cacheTTL:  c.PublicCacheTTL,
+		requests:  make(map[string]*rateWindow),
+		cache:     make(map[string]cachedResponse),
+	}
+}
+
+// allow reports whether the client is under the rate limit, counting requests
+// per minute.
+func (p *publicViewer) allow(client string, now time.Time) bool {
+	if p.rateLimit < 0 {
+		return true
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	window, ok := p.requests[client]
+	if !ok || now.Sub(window.start) >= time.Minute {
+		// Windows of other clients are swept along to bound the map
+		for c, w := range p.requests {
+			if now.Sub(w.start) >= time.Minute {
+				delete(p.requests, c)
+			}
+		}
+		window = &rateWindow{start: now}
+		p.requests[client] = window
+	}
+	window.count++
+	return window.count <= p.rateLimit
+}
+
+func (p *publicViewer) cached(key string, now time.Time) ([]byte, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c, ok := p.cache[key]
+	if !ok || now.After(c.expires) {
+		return nil, false
+	}
+	return c.body, true
+}
+
+func (p *publicViewer) store(key string, body []byte, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for k, c := range p.cache {
+		if now.After(c.expires) {
+			delete(p.cache, k)
+		}
+	}
+	p.cache[key] = cachedResponse{body: body, expires: now.Add(p.cacheTTL)}
+}
+
+// HandlePublic serves the tables of Config.PublicTables read-only and without
+// authentication, so that open datasets can be published from the database.
+// It is meant to be mounted on its own GET route, separately from HandlePost:
+// without a "table" query param it lists the public tables, and otherwise it
+// returns the rows of the table, paginated with the "limit" and "offset"
+// query params. Responses are cached and requests are rate limited per client
+// address.
+func (a *Admin) HandlePublic(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	p := a.public
+	if len(p.tables) == 0 {
+		writeError(w, apiErrNotFound())
+		return
+	}
+
+	client, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		client = r.RemoteAddr
+	}
+	now := time.Now()
+	if !p.allow(client, now) {
+		w.Header().Set("Retry-After", "60")
+		writeError(w, apiErrTooManyRequests())
+		return
+	}
+
+	key := r.URL.Query().Encode()
+	body, ok := p.cached(key, now)
+	if !ok {
+		var apiErr *APIError
+		body, apiErr = a.publicResponse(r.Context(), r.URL.Query())
+		if apiErr != nil {
+			writeError(w, *apiErr)
+			return
+		}
+		p.store(key, body, now)
+	}
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(p.cacheTTL.Seconds())))
+	w.Write(body)
+}
+
+func (a *Admin) publicResponse(ctx context.Context, query url.Values) ([]byte, *APIError) {
+	db := a.publicDB()
+	if db == nil {
+		e := apiErrBadRequest(ErrMissingDatabase.Error())
+		return nil, &e
+	}
+
+	table := query.Get("table")
+	if table == "" {
+		a.logger.Info("Public: ListTables")
+		tables := make(map[string][]string, len(a.public.tables))
+		for name := range a.public.tables {
+			columns, err := a.publicColumns(ctx, name)
+			if err != nil {
+				a.logger.Error(fmt.Sprintf("Error getting columns: %v", err))
+				e := apiErrSomethingWentWrong()
+				return nil, &e
+			}
+			tables[name] = columns
+		}
+		body, _ := json.Marshal(map[string]interface{}{"tables": tables})
+		return body, nil
+	}
+
+	config, ok := a.public.tables[table]
+	if !ok {
+		// Private tables are indistinguishable from missing ones
+		e := apiErrNotFound()
+		return nil, &e
+	}
+	maxRows := limitOrDefault(config.MaxRows, DefaultPublicMaxRows)
+
+	limit := maxRows
+	offset := 0
+	if l := query.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 0 {
+			e := apiErrBadRequest(ErrInvalidInput.Error())
+			return nil, &e
+		}
+		limit = n
+	}
+	if exceeds(limit, maxRows) {
+		limit = maxRows
+	}
+	if o := query.Get("offset"); o != "" {
+		n, err := strconv.Atoi(o)
+		if err != nil || n < 0 {
+			e := apiErrBadRequest(ErrInvalidInput.Error())
+			return nil, &e
+		}
+		offset = n
+	}
+
+	a.logger.Info(fmt.Sprintf("Public: GetTable, table=%s, limit=%d, offset=%d", table, limit, offset))
+
+	// Public requests have no principal, so none of the rows of scoped
+	// tables are in scope.
+	if a.scoped(table) {
+		e := apiErrForbidden(ErrOutOfScope.Error())
+		return nil, &e
+	}
+
+	columns, err := a.publicColumns(ctx, table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error getting columns: %v", err))
+		e := apiErrSomethingWentWrong()
+		return nil, &e
+	}
+	if len(columns) == 0 {
+		e := apiErrNotFound()
+		return nil, &e
+	}
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = quoteIdent(c)
+	}
+
+	q := fmt.Sprintf("SELECT %s FROM %s LIMIT ? OFFSET ?", strings.Join(quoted, ", "), quoteIdent(table))
+	rows, err := queryRows(ctx, a.cached(db), q, limit, offset)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error querying table: %v", err))
+		e := apiErrSomethingWentWrong()
+		return nil, &e
+	}
+	a.protectRows(ctx, table, rows)
+	a.encodeRows(rows)
+
+	body, _ := json.Marshal(map[string]interface{}{"rows": rows, "limit": limit, "offset": offset})
+	return body, nil
+}
+
+// publicDB returns the database the public tables are read from, which is
+// always the default one.
+func (a *Admin) publicDB() *sql.DB {
+	if a.readDB != nil {
+		return a.readDB
+	}
+	return a.db
+}
+
+// publicColumns returns the columns of the public table that exist, in the
+// order of the table.
+func (a *Admin) publicColumns(ctx context.Context, table string) ([]string, error) {
+	names, err := getColumnNames(ctx, a.cached(a.publicDB()), table)
+	if err != nil {
+		return nil, err
+	}
+	allowed := a.public.tables[table].Columns
+	if len(allowed) == 0 {
+		return names, nil
+	}
+	var columns []string
+	for _, name := range names {
+		if contains(allowed, name) {
+			columns = append(columns, name)
+		}
+	}
+	return columns, nil
+}
@@ -0,0 +1,79 @@
+package sqliteadmin_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlePublic(t *testing.T) {
+	db := setupDB(t)
+	defer db.Close()
+
+	a := sqliteadmin.New(sqliteadmin.Config{
+		DB:       db,
+		Username: "user",
+		Password: "password",
+		PublicTables: map[string]sqliteadmin.PublicTable{
+			"users": {Columns: []string{"id", "name"}, MaxRows: 2},
+		},
+		PublicRateLimit: 5,
+	})
+	server := httptest.NewServer(http.HandlerFunc(a.HandlePublic))
+	defer server.Close()
+
+	get := func(query string) (int, map[string]interface{}) {
+		res, err := http.Get(server.URL + "?" + query)
+		assert.NoError(t, err)
+		return res.StatusCode, readBody(t, res.Body)
+	}
+
+	status, body := get("")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]interface{}{
+		"tables": map[string]interface{}{"users": []interface{}{"id", "name"}},
+	}, body)
+
+	status, body = get("table=users&limit=10")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]interface{}{
+		"rows": []interface{}{
+			map[string]interface{}{"id": float64(1), "name": "Alice"},
+			map[string]interface{}{"id": float64(2), "name": "Bob"},
+		},
+		"limit":  float64(2),
+		"offset": float64(0),
+	}, body)
+
+	// Responses are cached
+	_, err := db.Exec("UPDATE users SET name = 'Bobby' WHERE id = 2")
+	assert.NoError(t, err)
+	_, body = get("table=users&limit=10")
+	assert.Equal(t, "Bob", body["rows"].([]interface{})[1].(map[string]interface{})["name"])
+
+	status, body = get("table=users&limit=1&offset=1")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": float64(2), "name": "Bobby"}}, body["rows"])
+
+	status, _ = get("table=sqlite_master")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, body = get("table=users")
+	assert.Equal(t, http.StatusTooManyRequests, status)
+	assert.Equal(t, "Too many requests", body["message"])
+}
+
+func TestHandlePublicDisabled(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	server := httptest.NewServer(http.HandlerFunc(ts.admin.HandlePublic))
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "?table=users")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
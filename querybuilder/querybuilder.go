@@ -0,0 +1,396 @@
+// Package querybuilder generates the SQL of the queries run by the admin. Names
+// are always quoted as identifiers and values are always bound as args, so that
+// commands can be added without concatenating SQL by hand.
+//
+//	query, args := querybuilder.Select().
+//		From("users").
+//		Where(querybuilder.And(querybuilder.Eq("team", 1), querybuilder.IsNotNull("email"))).
+//		Page(20, 40).
+//		Build()
+package querybuilder
+
+import (
+	"sort"
+	"strings"
+)
+
+// Ident quotes a table, column or other name as an SQL identifier. Unlike %q,
+// which escapes double quotes with a backslash, it doubles them as SQL
+// expects, so that any name is safe to use in a query.
+func Ident(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// Idents quotes each of the names as an SQL identifier.
+func Idents(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = Ident(name)
+	}
+	return quoted
+}
+
+// Literal quotes a string as an SQL string literal.
+func Literal(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// Expr is a boolean SQL expression along with the args of its placeholders.
+// The zero Expr is empty and is left out of the queries.
+type Expr struct {
+	sql  string
+	args []any
+	// compound is set for expressions joining others with AND or OR, which
+	// are put in parentheses when nested.
+	compound bool
+}
+
+// Raw returns an expression of the SQL, which must quote its names itself.
+func Raw(sql string, args ...any) Expr {
+	return Expr{sql: sql, args: args}
+}
+
+// SQL returns the SQL of the expression and its args.
+func (e Expr) SQL() (string, []any) {
+	return e.sql, e.args
+}
+
+// IsEmpty reports whether the expression is empty.
+func (e Expr) IsEmpty() bool {
+	return e.sql == ""
+}
+
+func compare(column, op string, value any) Expr {
+	return Expr{sql: Ident(column) + " " + op + " ?", args: []any{value}}
+}
+
+// Eq returns the expression "column = value".
+func Eq(column string, value any) Expr { return compare(column, "=", value) }
+
+// Ne returns the expression "column != value".
+func Ne(column string, value any) Expr { return compare(column, "!=", value) }
+
+// Lt returns the expression "column < value".
+func Lt(column string, value any) Expr { return compare(column, "<", value) }
+
+// Le returns the expression "column <= value".
+func Le(column string, value any) Expr { return compare(column, "<=", value) }
+
+// Gt returns the expression "column > value".
+func Gt(column string, value any) Expr { return compare(column, ">", value) }
+
+// Ge returns the expression "column >= value".
+func Ge(column string, value any) Expr { return compare(column, ">=", value) }
+
+// Match returns a full-text search of the column, or of all the columns of the
+// table if the column is the name of the table.
+func Match(column string, value any) Expr { return compare(column, "MATCH", value) }
+
+// Contains returns the expression matching the values of the column that
+// contain the value, ignoring the case of ASCII characters.
+func Contains(column string, value any) Expr {
+	return Expr{sql: Ident(column) + " LIKE '%' || ? || '%'", args: []any{value}}
+}
+
+// IsNull returns the expression "column IS NULL".
+func IsNull(column string) Expr {
+	return Expr{sql: Ident(column) + " IS NULL"}
+}
+
+// IsNotNull returns the expression "column IS NOT NULL".
+func IsNotNull(column string) Expr {
+	return Expr{sql: Ident(column) + " IS NOT NULL"}
+}
+
+// In returns the expression "column IN (values...)". Without values, it never
+// matches.
+func In(column string, values ...any) Expr {
+	if len(values) == 0 {
+		return Expr{sql: "0"}
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+	return Expr{sql: Ident(column) + " IN (" + placeholders + ")", args: values}
+}
+
+// And returns the expression matching when all the expressions do. Empty
+// expressions are left out.
+func And(exprs ...Expr) Expr { return join("AND", exprs) }
+
+// Or returns the expression matching when one of the expressions does. Empty
+// expressions are left out.
+func Or(exprs ...Expr) Expr { return join("OR", exprs) }
+
+func join(op string, exprs []Expr) Expr {
+	var parts []string
+	var args []any
+	for _, e := range exprs {
+		if e.IsEmpty() {
+			continue
+		}
+		if e.compound {
+			parts = append(parts, "("+e.sql+")")
+		} else {
+			parts = append(parts, e.sql)
+		}
+		args = append(args, e.args...)
+	}
+	switch len(parts) {
+	case 0:
+		return Expr{}
+	case 1:
+		// A single expression keeps its own parentheses, if any
+		for _, e := range exprs {
+			if !e.IsEmpty() {
+				return e
+			}
+		}
+	}
+	return Expr{sql: strings.Join(parts, " "+op+" "), args: args, compound: true}
+}
+
+// where appends the WHERE clause of the expression to the query.
+func where(b *strings.Builder, args []any, e Expr) []any {
+	if e.IsEmpty() {
+		return args
+	}
+	b.WriteString(" WHERE ")
+	b.WriteString(e.sql)
+	return append(args, e.args...)
+}
+
+// SelectBuilder builds a SELECT query.
+type SelectBuilder struct {
+	columns []string
+	table   string
+	where   Expr
+	orderBy []Expr
+	limit   *int
+	offset  *int
+}
+
+// Select starts a query selecting the columns, or all of them if none are
+// given.
+func Select(columns ...string) *SelectBuilder {
+	return &SelectBuilder{columns: Idents(columns)}
+}
+
+// SelectRaw starts a query selecting SQL expressions, e.g. "count(*)", which
+// must quote their names themselves.
+func SelectRaw(exprs ...string) *SelectBuilder {
+	return &SelectBuilder{columns: exprs}
+}
+
+// From sets the table to select from.
+func (b *SelectBuilder) From(table string) *SelectBuilder {
+	b.table = table
+	return b
+}
+
+// Where filters the rows with the expression, along with the expressions of
+// previous calls.
+func (b *SelectBuilder) Where(e Expr) *SelectBuilder {
+	b.where = And(b.where, e)
+	return b
+}
+
+// OrderBy orders the rows by the SQL expression, after the expressions of
+// previous calls.
+func (b *SelectBuilder) OrderBy(expr string, args ...any) *SelectBuilder {
+	b.orderBy = append(b.orderBy, Raw(expr, args...))
+	return b
+}
+
+// Limit sets the maximum number of rows, negative limits meaning no limit.
+func (b *SelectBuilder) Limit(n int) *SelectBuilder {
+	b.limit = &n
+	return b
+}
+
+// Offset skips the first n rows.
+func (b *SelectBuilder) Offset(n int) *SelectBuilder {
+	b.offset = &n
+	return b
+}
+
+// Page sets both the limit and the offset. Since they are bound as args,
+// the query is the same for all the pages and its statement can be reused.
+func (b *SelectBuilder) Page(limit, offset int) *SelectBuilder {
+	return b.Limit(limit).Offset(offset)
+}
+
+// Build returns the query and its args.
+func (b *SelectBuilder) Build() (string, []any) {
+	var q strings.Builder
+	var args []any
+	q.WriteString("SELECT ")
+	if len(b.columns) == 0 {
+		q.WriteString("*")
+	} else {
+		q.WriteString(strings.Join(b.columns, ", "))
+	}
+	if b.table != "" {
+		q.WriteString(" FROM ")
+		q.WriteString(Ident(b.table))
+	}
+	args = where(&q, args, b.where)
+	for i, o := range b.orderBy {
+		if i == 0 {
+			q.WriteString(" ORDER BY ")
+		} else {
+			q.WriteString(", ")
+		}
+		q.WriteString(o.sql)
+		args = append(args, o.args...)
+	}
+	if b.limit != nil || b.offset != nil {
+		limit := -1
+		if b.limit != nil {
+			limit = *b.limit
+		}
+		q.WriteString(" LIMIT ?")
+		args = append(args, limit)
+		if b.offset != nil {
+			q.WriteString(" OFFSET ?")
+			args = append(args, *b.offset)
+		}
+	}
+	return q.String(), args
+}
+
+// UpdateBuilder builds an UPDATE query.
+type UpdateBuilder struct {
+	table  string
+	values map[string]any
+	where  Expr
+}
+
+// Update starts a query updating the rows of the table.
+func Update(table string) *UpdateBuilder {
+	return &UpdateBuilder{table: table, values: map[string]any{}}
+}
+
+// Set sets the column to the value.
+func (b *UpdateBuilder) Set(column string, value any) *UpdateBuilder {
+	b.values[column] = value
+	return b
+}
+
+// Where filters the rows to update with the expression, along with the
+// expressions of previous calls.
+func (b *UpdateBuilder) Where(e Expr) *UpdateBuilder {
+	b.where = And(b.where, e)
+	return b
+}
+
+// Build returns the query and its args. The columns are set in the order of
+// their names so that the same updates give the same query.
+func (b *UpdateBuilder) Build() (string, []any) {
+	var q strings.Builder
+	q.WriteString("UPDATE ")
+	q.WriteString(Ident(b.table))
+	q.WriteString(" SET ")
+	columns := sortedKeys(b.values)
+	args := make([]any, 0, len(columns))
+	for i, c := range columns {
+		if i > 0 {
+			q.WriteString(", ")
+		}
+		q.WriteString(Ident(c))
+		q.WriteString(" = ?")
+		args = append(args, b.values[c])
+	}
+	args = where(&q, args, b.where)
+	return q.String(), args
+}
+
+// DeleteBuilder builds a DELETE query.
+type DeleteBuilder struct {
+	table string
+	where Expr
+}
+
+// DeleteFrom starts a query deleting the rows of the table.
+func DeleteFrom(table string) *DeleteBuilder {
+	return &DeleteBuilder{table: table}
+}
+
+// Where filters the rows to delete with the expression, along with the
+// expressions of previous calls.
+func (b *DeleteBuilder) Where(e Expr) *DeleteBuilder {
+	b.where = And(b.where, e)
+	return b
+}
+
+// Build returns the query and its args.
+func (b *DeleteBuilder) Build() (string, []any) {
+	var q strings.Builder
+	q.WriteString("DELETE FROM ")
+	q.WriteString(Ident(b.table))
+	args := where(&q, nil, b.where)
+	return q.String(), args
+}
+
+// InsertBuilder builds an INSERT query.
+type InsertBuilder struct {
+	table     string
+	values    map[string]any
+	returning []string
+}
+
+// InsertInto starts a query inserting a row into the table.
+func InsertInto(table string) *InsertBuilder {
+	return &InsertBuilder{table: table, values: map[string]any{}}
+}
+
+// Value sets the value of the column of the row. The columns without a value
+// get their default value.
+func (b *InsertBuilder) Value(column string, value any) *InsertBuilder {
+	b.values[column] = value
+	return b
+}
+
+// Returning returns the columns of the inserted row, or all of them if none
+// are given.
+func (b *InsertBuilder) Returning(columns ...string) *InsertBuilder {
+	b.returning = Idents(columns)
+	if len(columns) == 0 {
+		b.returning = []string{"*"}
+	}
+	return b
+}
+
+// Build returns the query and its args. The columns are listed in the order
+// of their names so that the same inserts give the same query.
+func (b *InsertBuilder) Build() (string, []any) {
+	var q strings.Builder
+	q.WriteString("INSERT INTO ")
+	q.WriteString(Ident(b.table))
+	columns := sortedKeys(b.values)
+	args := make([]any, 0, len(columns))
+	if len(columns) == 0 {
+		q.WriteString(" DEFAULT VALUES")
+	} else {
+		q.WriteString(" (")
+		q.WriteString(strings.Join(Idents(columns), ", "))
+		q.WriteString(") VALUES (")
+		q.WriteString(strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", "))
+		q.WriteString(")")
+		for _, c := range columns {
+			args = append(args, b.values[c])
+		}
+	}
+	if len(b.returning) > 0 {
+		q.WriteString(" RETURNING ")
+		q.WriteString(strings.Join(b.returning, ", "))
+	}
+	return q.String(), args
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
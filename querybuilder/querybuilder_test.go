@@ -0,0 +1,145 @@
+package querybuilder_test
+
+import (
+	"testing"
+
+	qb "github.com/joelseq/sqliteadmin-go/querybuilder"
+	"github.com/stretchr/testify/assert"
+)
+
+type builder interface {
+	Build() (string, []any)
+}
+
+func TestBuilders(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder builder
+		query   string
+		args    []any
+	}{
+		{
+			name:    "Select All",
+			builder: qb.Select().From("users"),
+			query:   `SELECT * FROM "users"`,
+		},
+		{
+			name:    "Select Columns",
+			builder: qb.Select("id", "name").From("users"),
+			query:   `SELECT "id", "name" FROM "users"`,
+		},
+		{
+			name:    "Select Raw",
+			builder: qb.SelectRaw("COUNT(*)").From("users").Where(qb.IsNull("email")),
+			query:   `SELECT COUNT(*) FROM "users" WHERE "email" IS NULL`,
+		},
+		{
+			name:    "Page",
+			builder: qb.Select().From("users").Page(10, 20),
+			query:   `SELECT * FROM "users" LIMIT ? OFFSET ?`,
+			args:    []any{10, 20},
+		},
+		{
+			name:    "Offset Without Limit",
+			builder: qb.Select().From("users").Offset(5),
+			query:   `SELECT * FROM "users" LIMIT ? OFFSET ?`,
+			args:    []any{-1, 5},
+		},
+		{
+			name: "Where, Order and Page",
+			builder: qb.Select().From("users").
+				Where(qb.Contains("email", "@gmail.com")).
+				OrderBy(`length("email")`).
+				OrderBy(`instr("email", ?)`, "a").
+				Page(2, 4),
+			query: `SELECT * FROM "users" WHERE "email" LIKE '%' || ? || '%' ORDER BY length("email"), instr("email", ?) LIMIT ? OFFSET ?`,
+			args:  []any{"@gmail.com", "a", 2, 4},
+		},
+		{
+			name: "Nested Conditions",
+			builder: qb.Select().From("users").Where(qb.Or(
+				qb.Eq("id", 1),
+				qb.And(qb.Ge("age", 18), qb.Lt("age", 65)),
+				qb.And(),
+			)),
+			query: `SELECT * FROM "users" WHERE "id" = ? OR ("age" >= ? AND "age" < ?)`,
+			args:  []any{1, 18, 65},
+		},
+		{
+			name:    "Where Calls Are Combined",
+			builder: qb.Select().From("users").Where(qb.Or(qb.Ne("a", 1), qb.Le("b", 2))).Where(qb.Gt("c", 3)),
+			query:   `SELECT * FROM "users" WHERE ("a" != ? OR "b" <= ?) AND "c" > ?`,
+			args:    []any{1, 2, 3},
+		},
+		{
+			name:    "Empty Condition",
+			builder: qb.Select().From("users").Where(qb.And()).Where(qb.Expr{}),
+			query:   `SELECT * FROM "users"`,
+		},
+		{
+			name:    "Match",
+			builder: qb.Select().From("docs").Where(qb.Match("docs", "sqlite")),
+			query:   `SELECT * FROM "docs" WHERE "docs" MATCH ?`,
+			args:    []any{"sqlite"},
+		},
+		{
+			name:    "Raw",
+			builder: qb.Select().From("events").Where(qb.Raw("julianday(at) < julianday(?)", "now")),
+			query:   `SELECT * FROM "events" WHERE julianday(at) < julianday(?)`,
+			args:    []any{"now"},
+		},
+		{
+			name:    "Update",
+			builder: qb.Update("users").Set("name", "Zoe").Set("email", nil).Where(qb.Eq("id", 1)),
+			query:   `UPDATE "users" SET "email" = ?, "name" = ? WHERE "id" = ?`,
+			args:    []any{nil, "Zoe", 1},
+		},
+		{
+			name:    "Delete",
+			builder: qb.DeleteFrom("users").Where(qb.In("id", 1, 2, 3)),
+			query:   `DELETE FROM "users" WHERE "id" IN (?, ?, ?)`,
+			args:    []any{1, 2, 3},
+		},
+		{
+			name:    "Delete Without Values",
+			builder: qb.DeleteFrom("users").Where(qb.In("id")),
+			query:   `DELETE FROM "users" WHERE 0`,
+		},
+		{
+			name:    "Insert",
+			builder: qb.InsertInto("users").Value("name", "Zoe").Value("email", "zoe@example.com").Returning(),
+			query:   `INSERT INTO "users" ("email", "name") VALUES (?, ?) RETURNING *`,
+			args:    []any{"zoe@example.com", "Zoe"},
+		},
+		{
+			name:    "Insert Default Values",
+			builder: qb.InsertInto("users").Returning("id"),
+			query:   `INSERT INTO "users" DEFAULT VALUES RETURNING "id"`,
+		},
+		{
+			name:    "Hostile Names",
+			builder: qb.Select(`a"b`).From(`t"; DROP TABLE users; --`).Where(qb.Eq(`c\"d`, 1)),
+			query:   `SELECT "a""b" FROM "t""; DROP TABLE users; --" WHERE "c\""d" = ?`,
+			args:    []any{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args := tt.builder.Build()
+			assert.Equal(t, tt.query, query)
+			if tt.args == nil {
+				assert.Empty(t, args)
+			} else {
+				assert.Equal(t, tt.args, args)
+			}
+		})
+	}
+}
+
+func TestQuoting(t *testing.T) {
+	assert.Equal(t, `"users"`, qb.Ident("users"))
+	assert.Equal(t, `"say ""hi"""`, qb.Ident(`say "hi"`))
+	assert.Equal(t, []string{`"a"`, `"b"""`}, qb.Idents([]string{"a", `b"`}))
+	assert.Equal(t, `'it''s'`, qb.Literal("it's"))
+}
@@ -1,13 +1,15 @@
 package sqliteadmin
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
-	"strings"
 
+	"github.com/joelseq/sqliteadmin-go/querybuilder"
 	"github.com/mitchellh/mapstructure"
 )
 
@@ -17,12 +19,27 @@ func (a *Admin) ping(w http.ResponseWriter) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
 
-func (a *Admin) listTables(w http.ResponseWriter) {
+func (a *Admin) listTables(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	var p ListTablesParams
+	if err := a.decodeParams(params, &p); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	db, ok := a.getReadDB(w, params)
+	if !ok {
+		return
+	}
+
 	a.logger.Info("Command: ListTables")
-	rows, err := a.db.Query("SELECT name FROM sqlite_master WHERE type='table';")
+	where := querybuilder.Eq("type", "table")
+	if p.Filter != "" {
+		where = querybuilder.And(where, querybuilder.Raw("instr(lower(name), lower(?)) > 0", p.Filter))
+	}
+	query, args := querybuilder.Select("name").From("sqlite_master").Where(where).OrderBy("name").Build()
+	rows, err := a.cached(db).QueryContext(ctx, query, args...)
 	if err != nil {
 		a.logger.Error(fmt.Sprintf("Error listing tables: %v", err))
-		writeError(w, apiErrSomethingWentWrong())
+		writeError(w, a.apiErr(err))
 		return
 	}
 	defer rows.Close()
@@ -32,51 +49,94 @@ func (a *Admin) listTables(w http.ResponseWriter) {
 		var table string
 		if err := rows.Scan(&table); err != nil {
 			a.logger.Error(fmt.Sprintf("Error scanning rows: %v", err))
-			writeError(w, apiErrSomethingWentWrong())
+			writeError(w, a.apiErr(err))
 			return
 		}
+		if a.hideInternalTables && isInternalTable(table) {
+			continue
+		}
 		tables = append(tables, table)
 	}
+	// The tables are paged once the ones that aren't exposed are left out
+	tables = a.allowedTables(tables)
+
+	var response ListTablesResponse
+	if p.Limit != nil {
+		// The total is returned with pages so that clients can page through
+		// the tables
+		total := len(tables)
+		response.Total = &total
+		tables = tables[min(p.Offset, total):min(p.Offset+*p.Limit, total)]
+	} else {
+		tables = tables[min(p.Offset, len(tables)):]
+	}
+	response.Tables = tables
+
+	vt, err := getVirtualTables(ctx, a.cached(db))
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error listing virtual tables: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	for _, table := range tables {
+		if module, ok := vt.modules[table]; ok {
+			if response.VirtualTables == nil {
+				response.VirtualTables = map[string]string{}
+			}
+			response.VirtualTables[table] = module
+		}
+		if owner, ok := vt.shadows[table]; ok {
+			if response.ShadowTables == nil {
+				response.ShadowTables = map[string]string{}
+			}
+			response.ShadowTables[table] = owner
+		}
+	}
 
-	json.NewEncoder(w).Encode(map[string][]string{"tables": tables})
+	json.NewEncoder(w).Encode(response)
 }
 
-func (a *Admin) getTable(w http.ResponseWriter, params map[string]interface{}) {
-	// Parse table name
-	table, ok := params["tableName"].(string)
+func (a *Admin) getTable(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	var p GetTableParams
+	if err := a.decodeParams(params, &p); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	db, ok := a.getReadDB(w, params)
 	if !ok {
-		writeError(w, apiErrBadRequest(ErrMissingTableName.Error()))
 		return
 	}
+	table := p.TableName
 
-	// Parse limit
-	limit := DefaultLimit
-	if params["limit"] != nil {
-		// convert the limit parameter to an int
-		limit, ok = convertNumber(params["limit"])
-		if !ok {
-			limit = DefaultLimit
-		}
+	limit := a.defaultLimit
+	if p.Stream {
+		// Streams are meant for exporting whole tables
+		limit = -1
 	}
+	if p.Limit != nil {
+		limit = *p.Limit
+	}
+	if p.Sample > 0 {
+		limit = p.Sample
+	}
+	offset := p.Offset
 
-	// Parse offset
-	offset := DefaultOffset
-	if params["offset"] != nil {
-		// convert the offset parameter to an int
-		offset, ok = convertNumber(params["offset"])
-		if !ok {
-			offset = DefaultOffset
-		}
+	// A negative limit means no limit in SQLite. The limit is returned with
+	// the rows when clamped so that clients can page with it.
+	clamped := !p.Stream && a.maxLimit >= 0 && (limit < 0 || limit > a.maxLimit)
+	if clamped {
+		limit = a.maxLimit
 	}
 
-	a.logger.Info(fmt.Sprintf("Command: GetTable, table=%s, limit=%d, offset=%d", table, limit, offset))
+	a.logger.Info(fmt.Sprintf("Command: GetTable, table=%s, limit=%d, offset=%d, sample=%t", table, limit, offset, p.Sample > 0))
 
-	var condition *Condition
-	conditionParam, ok := params["condition"]
-	if ok {
-		condition, ok = toCondition(conditionParam, a.logger)
-		if !ok {
-			writeError(w, apiErrBadRequest("Invalid condition"))
+	condition := p.Condition
+	if condition != nil {
+		if exceeds(conditionDepth(condition), a.maxConditionDepth) {
+			writeError(w, apiErrBadRequest(ErrConditionTooDeep.Error()))
+			return
+		}
+		if !a.checkCondition(w, table, condition) {
 			return
 		}
 		a.logger.Debug(fmt.Sprintf("Condition provided: %v", condition))
@@ -84,95 +144,477 @@ func (a *Admin) getTable(w http.ResponseWriter, params map[string]interface{}) {
 		a.logger.Debug("No condition provided")
 	}
 
-	data, err := queryTable(a.db, table, condition, limit, offset, a.logger)
+	loc, err := a.getTimeZone(params)
+	if err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+
+	columnTypes, err := getColumnTypes(ctx, a.cached(db), table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error getting column types: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if loc != nil && condition != nil {
+		condition = localizeCondition(condition, columnTypes, loc)
+	}
+
+	scope, err := a.rowScope(ctx, table)
+	if err != nil {
+		a.writeScopeError(w, err)
+		return
+	}
+	if scope != nil {
+		condition = scopeCondition(scope, condition)
+	}
+
+	if p.Stream {
+		a.streamTable(ctx, w, db, table, condition, p.OrderBy, limit, offset, columnTypes, loc)
+		return
+	}
+
+	var data []map[string]interface{}
+	if p.Sample > 0 {
+		data, err = sampleTable(ctx, a.cached(db), table, condition, limit, a.logger)
+	} else {
+		data, err = queryTable(ctx, a.cached(db), table, condition, p.OrderBy, limit, offset, a.logger)
+	}
 	if err != nil {
 		a.logger.Error(fmt.Sprintf("Error querying table: %v", err))
-		writeError(w, apiErrSomethingWentWrong())
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if loc != nil {
+		localizeRows(data, columnTypes, loc)
+	}
+	convertBooleans(data, columnTypes)
+	if err := a.geoJSONRows(ctx, a.cached(db), table, data); err != nil {
+		a.logger.Error(fmt.Sprintf("Error converting geometries: %v", err))
+		writeError(w, a.apiErr(err))
 		return
 	}
-	response := map[string]interface{}{"rows": data}
+	a.protectRows(ctx, table, data)
+	response := GetTableResponse{Rows: data}
+	if clamped {
+		response.Limit = limit
+	}
+
+	if p.Delta {
+		response.Version, err = versionPage(ctx, a.cached(db), table, data)
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Error versioning page: %v", err))
+			writeError(w, a.apiErr(err))
+			return
+		}
+	}
+
+	if p.Dates {
+		response.Dates = normalizeDates(data, columnTypes, loc)
+	}
+
+	// The rows are encoded once versioned so that the versions don't depend
+	// on the encoding
+	a.encodeRows(data)
 
-	if params["includeInfo"] == true {
-		tableInfo, err := getTableInfo(a.db, table)
+	if p.IncludeInfo {
+		response.TableInfo, err = getTableInfo(ctx, a.cached(db), table)
 		if err != nil {
 			a.logger.Error(fmt.Sprintf("Error getting table info: %v", err))
-			writeError(w, apiErrSomethingWentWrong())
+			writeError(w, a.apiErr(err))
 			return
 		}
-		response["tableInfo"] = tableInfo
 	}
 	a.logger.Info(fmt.Sprintf("Fetched %d rows", len(data)))
+	a.recordRows(len(data))
 
+	if p.Since != nil {
+		json.NewEncoder(w).Encode(GetTableDeltaResponse{
+			Delta:     diffPage(p.Since, response.Version, data),
+			TableInfo: response.TableInfo,
+		})
+		return
+	}
 	json.NewEncoder(w).Encode(response)
 }
 
-func (a *Admin) deleteRows(w http.ResponseWriter, params map[string]interface{}) {
-	table, ok := params["tableName"].(string)
+func (a *Admin) deleteRows(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	var p DeleteRowsParams
+	if err := a.decodeParams(params, &p); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	db, ok := a.getDB(w, params)
 	if !ok {
-		writeError(w, apiErrBadRequest(ErrMissingTableName.Error()))
 		return
 	}
+	table := p.TableName
 
-	ids, ok := convertToStrSlice(params["ids"])
-	if !ok {
-		writeError(w, apiErrBadRequest(ErrInvalidOrMissingIds.Error()))
+	ids := make([]any, len(p.IDs))
+	for i, id := range p.IDs {
+		ids[i] = id
+	}
+	if exceeds(len(ids), a.maxDeleteIDs) {
+		writeError(w, apiErrBadRequest(ErrTooManyIds.Error()))
 		return
 	}
 
-	a.logger.Info(fmt.Sprintf("Command: DeleteRows, table=%s, ids=%v", table, ids))
+	a.logger.Info(fmt.Sprintf("Command: DeleteRows, table=%s, ids=%v, previewCascade=%t", table, ids, p.PreviewCascade))
 
-	exists, err := checkTableExists(a.db, table)
+	exists, err := checkTableExists(ctx, a.cached(db), table)
 	if err != nil {
 		a.logger.Error(fmt.Sprintf("Error checking table existence: %v", err))
-		writeError(w, apiErrSomethingWentWrong())
+		writeError(w, a.apiErr(err))
 		return
 	}
 	if !exists {
 		a.logger.Error(fmt.Sprintf("Error table does not exist: %s", table))
-		writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+		writeError(w, apiErrTableNotFound())
+		return
+	}
+
+	tx, ok := a.beginMutation(ctx, w, db, table, params)
+	if !ok {
+		return
+	}
+	defer tx.Rollback()
+
+	primaryKey, err := getPrimaryKey(ctx, tx, table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error deleting rows from table: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	// Rows out of scope are left alone as if they didn't exist
+	scope, err := a.rowScope(ctx, table)
+	if err != nil {
+		a.writeScopeError(w, err)
+		return
+	}
+	if scope != nil {
+		ids, err = keysInScope(ctx, tx, table, primaryKey, ids, scope)
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Error deleting rows from table: %v", err))
+			writeError(w, a.apiErr(err))
+			return
+		}
+	}
+	if p.PreviewCascade {
+		preview, err := previewCascade(ctx, tx, table, primaryKey, ids)
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Error previewing delete: %v", err))
+			writeError(w, a.apiErr(err))
+			return
+		}
+		json.NewEncoder(w).Encode(preview)
+		return
+	}
+	m := Mutation{Database: p.Database, Table: table, PrimaryKey: primaryKey, Keys: ids}
+	m.Old, err = getRowsByPrimaryKey(ctx, tx, table, primaryKey, ids)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error deleting rows from table: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if err := runBeforeHook(ctx, a.hooks.BeforeDelete, m); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
 		return
 	}
 
-	rowsAffected, err := batchDelete(a.db, table, ids)
+	rowsAffected, err := batchDelete(ctx, tx, table, ids)
+	if err == nil {
+		err = tx.Commit()
+	}
 	if err != nil {
 		a.logger.Error(fmt.Sprintf("Error deleting rows from table: %v", err))
-		writeError(w, apiErrSomethingWentWrong())
+		writeError(w, a.apiErr(err))
 		return
 	}
 	a.logger.Info(fmt.Sprintf("Deleted %d row(s)", rowsAffected))
+	tx.afterCommit(ctx, func(ctx context.Context) {
+		a.afterMutation(ctx, a.hooks.AfterDelete, EventRowsDeleted, m)
+	})
 
-	json.NewEncoder(w).Encode(map[string]string{"rowsAffected": fmt.Sprintf("%d", rowsAffected)})
+	json.NewEncoder(w).Encode(DeleteRowsResponse{RowsAffected: fmt.Sprintf("%d", rowsAffected)})
 }
 
-func (a *Admin) updateRow(w http.ResponseWriter, params map[string]interface{}) {
-	table, ok := params["tableName"].(string)
+func (a *Admin) updateRow(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	var p UpdateRowParams
+	if err := a.decodeParams(params, &p); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	db, ok := a.getDB(w, params)
 	if !ok {
-		writeError(w, apiErrBadRequest(ErrMissingTableName.Error()))
 		return
 	}
 
-	row, ok := params["row"].(map[string]interface{})
+	a.logger.Info(fmt.Sprintf("Command: UpdateRow, table=%s, row=%v, columns=%v", p.TableName, p.Row, p.Columns))
+
+	if p.Columns != nil {
+		row, err := partialRow(ctx, a.cached(db), p)
+		if err != nil {
+			writeError(w, a.apiErr(err))
+			return
+		}
+		p.Row = row
+	}
+	a.updateRowValues(ctx, w, db, params, p)
+}
+
+// partialRow returns the primary key and the values of p.Columns of p.Row,
+// rejecting the columns that aren't columns of the table.
+func partialRow(ctx context.Context, q queryer, p UpdateRowParams) (map[string]interface{}, error) {
+	names, err := getColumnNames(ctx, q, p.TableName)
+	if err == nil {
+		err = checkColumns(p.Columns, names)
+	}
+	if err != nil {
+		return nil, err
+	}
+	primaryKey, err := getPrimaryKey(ctx, q, p.TableName)
+	if err != nil {
+		return nil, err
+	}
+	row := map[string]interface{}{primaryKey: p.Row[primaryKey]}
+	for _, c := range p.Columns {
+		row[c] = p.Row[c]
+	}
+	return row, nil
+}
+
+// updateRowValues updates the columns of p.Row, running the same checks,
+// policies and hooks for UpdateRow and UpdateCell.
+func (a *Admin) updateRowValues(ctx context.Context, w http.ResponseWriter, db *sql.DB, params map[string]interface{}, p UpdateRowParams) {
+	table, row := p.TableName, p.Row
+
+	columnTypes, err := getColumnTypes(ctx, a.cached(db), table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error getting column types: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	geometries, err := getGeometryColumns(ctx, a.cached(db), table)
+	if err == nil {
+		err = decodeGeometries(ctx, a.cached(db), geometries, row)
+	}
+	if errors.Is(err, ErrInvalidGeometry) {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error converting geometries: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	storeBooleans(row, columnTypes)
+
+	strict, err := isStrictTable(ctx, a.cached(db), table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error checking if table is strict: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if strict {
+		if err := checkStrictTypes(row, columnTypes); err != nil {
+			writeError(w, apiErrBadRequest(err.Error()))
+			return
+		}
+	}
+
+	enums, err := getEnumValues(ctx, a.cached(db), table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error getting enum values: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if err := checkEnums(row, enums); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+
+	if err := a.applyWritePolicies(ctx, table, row); err != nil {
+		if errors.Is(err, ErrMaskedColumn) {
+			writeError(w, apiErrForbidden(err.Error()))
+		} else {
+			writeError(w, apiErrBadRequest(err.Error()))
+		}
+		return
+	}
+
+	findings, ok := a.checkSecrets(w, table, row)
 	if !ok {
-		writeError(w, apiErrBadRequest(ErrMissingRow.Error()))
 		return
 	}
 
-	a.logger.Info(fmt.Sprintf("Command: UpdateRow, table=%s, row=%v", table, row))
+	tx, ok := a.beginMutation(ctx, w, db, table, params)
+	if !ok {
+		return
+	}
+	defer tx.Rollback()
 
-	err := editRow(a.db, table, row)
+	primaryKey, err := getPrimaryKey(ctx, tx, table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error editing row: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	m := Mutation{Database: p.Database, Table: table, PrimaryKey: primaryKey, Keys: []interface{}{row[primaryKey]}}
+	m.Old, err = getRowsByPrimaryKey(ctx, tx, table, primaryKey, m.Keys)
 	if err != nil {
 		a.logger.Error(fmt.Sprintf("Error editing row: %v", err))
-		writeError(w, apiErrSomethingWentWrong())
+		writeError(w, a.apiErr(err))
+		return
+	}
+	scope, err := a.rowScope(ctx, table)
+	if err != nil {
+		a.writeScopeError(w, err)
+		return
+	}
+	if ok, err := inScope(ctx, tx, table, primaryKey, m.Keys, len(m.Old), scope); err != nil || !ok {
+		a.writeInScopeError(w, err)
+		return
+	}
+	// The Before hook is given the row as it will be once updated
+	m.New = []map[string]interface{}{}
+	for _, old := range m.Old {
+		updated := make(map[string]interface{}, len(old))
+		for k, v := range old {
+			updated[k] = v
+		}
+		for k, v := range row {
+			updated[k] = v
+		}
+		m.New = append(m.New, updated)
+	}
+	if err := a.validateRows(table, m.New); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	if err := runBeforeHook(ctx, a.hooks.BeforeUpdate, m); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+
+	err = editRow(ctx, tx, table, row)
+	if err == nil {
+		m.New, err = getRowsByPrimaryKey(ctx, tx, table, primaryKey, m.Keys)
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error editing row: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	// Rows can't be moved out of the scope either
+	if ok, err := inScope(ctx, tx, table, primaryKey, m.Keys, len(m.New), scope); err != nil || !ok {
+		a.writeInScopeError(w, err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		a.logger.Error(fmt.Sprintf("Error editing row: %v", err))
+		writeError(w, a.apiErr(err))
 		return
 	}
 	a.logger.Info("Row updated")
+	tx.afterCommit(ctx, func(ctx context.Context) {
+		a.afterMutation(ctx, a.hooks.AfterUpdate, EventRowsUpdated, m)
+	})
 
-	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	json.NewEncoder(w).Encode(UpdateRowResponse{Status: "ok", SecretWarnings: findings})
+}
+
+func (a *Admin) insertRow(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	var p InsertRowParams
+	if err := a.decodeParams(params, &p); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return
+	}
+	table, row := p.TableName, p.Row
+
+	t, err := getInsertTable(ctx, a.cached(db), table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error getting columns: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if err := a.prepareInsert(ctx, a.cached(db), t, row); err != nil {
+		writeError(w, a.rowError(err))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: InsertRow, table=%s, row=%v", table, row))
+
+	findings, ok := a.checkSecrets(w, table, row)
+	if !ok {
+		return
+	}
+
+	tx, ok := a.beginMutation(ctx, w, db, table, params)
+	if !ok {
+		return
+	}
+	defer tx.Rollback()
+
+	primaryKey, err := getPrimaryKey(ctx, tx, table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error inserting row: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	// The row is returned as stored, with the values of the columns left out
+	inserted, err := insertRow(ctx, tx, "INSERT", table, row)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error inserting row: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	m := Mutation{Database: p.Database, Table: table, PrimaryKey: primaryKey, Keys: []interface{}{inserted[0][primaryKey]}, New: inserted}
+	scope, err := a.rowScope(ctx, table)
+	if err != nil {
+		a.writeScopeError(w, err)
+		return
+	}
+	if ok, err := inScope(ctx, tx, table, primaryKey, m.Keys, 1, scope); err != nil || !ok {
+		a.writeInScopeError(w, err)
+		return
+	}
+	if err := a.validateRows(table, m.New); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	if err := runBeforeHook(ctx, a.hooks.BeforeInsert, m); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		a.logger.Error(fmt.Sprintf("Error inserting row: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.logger.Info("Row inserted")
+	tx.afterCommit(ctx, func(ctx context.Context) {
+		a.afterMutation(ctx, a.hooks.AfterInsert, EventRowsInserted, m)
+	})
+
+	// The row is copied so that the hooks are given the values as stored
+	returned := make(map[string]interface{}, len(inserted[0]))
+	for k, v := range inserted[0] {
+		returned[k] = v
+	}
+	rows := []map[string]interface{}{returned}
+	convertBooleans(rows, t.columnTypes)
+	a.protectRows(ctx, table, rows)
+	a.encodeRows(rows)
+	json.NewEncoder(w).Encode(InsertRowResponse{Status: "ok", Row: returned, SecretWarnings: findings})
 }
 
-func checkTableExists(db *sql.DB, tableName string) (bool, error) {
+func checkTableExists(ctx context.Context, q queryer, tableName string) (bool, error) {
 	var exists int
-	err := db.QueryRow(`
+	err := q.QueryRowContext(ctx, `
 				SELECT COUNT(*) FROM sqlite_master 
 				WHERE type='table' AND name=?`, tableName).Scan(&exists)
 	if err != nil {
@@ -181,56 +623,79 @@ func checkTableExists(db *sql.DB, tableName string) (bool, error) {
 	return exists > 0, nil
 }
 
-func queryTable(db *sql.DB, tableName string, condition *Condition, limit int, offset int, logger Logger) ([]map[string]interface{}, error) {
+func queryTable(ctx context.Context, q queryer, tableName string, condition *Condition, orderBy OrderBy, limit int, offset int, logger Logger) ([]map[string]interface{}, error) {
+	rows, err := openTableRows(ctx, q, tableName, condition, orderBy, limit, offset, logger)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRows(rows)
+}
+
+// openTableRows runs the query for a page of the table. The caller must close
+// the returned rows.
+func openTableRows(ctx context.Context, q queryer, tableName string, condition *Condition, orderBy OrderBy, limit int, offset int, logger Logger) (*sql.Rows, error) {
 	// First, verify the table exists to prevent SQL injection
-	exists, err := checkTableExists(db, tableName)
+	exists, err := checkTableExists(ctx, q, tableName)
 	if err != nil {
 		return nil, fmt.Errorf("error checking table existence: %v", err)
 	}
 	if !exists {
-		return nil, fmt.Errorf("table %s does not exist", tableName)
+		return nil, fmt.Errorf("%s: %w", tableName, ErrUnknownTable)
 	}
 
-	// Query to get column names
-	rows, err := db.Query(fmt.Sprintf("SELECT * FROM %q LIMIT 0", tableName))
-	if err != nil {
-		return nil, fmt.Errorf("error getting columns: %v", err)
-	}
-	columns, err := rows.Columns()
-	rows.Close()
-	if err != nil {
-		return nil, fmt.Errorf("error reading columns: %v", err)
+	if err := checkConditionColumns(ctx, q, tableName, condition); err != nil {
+		return nil, err
 	}
 
-	var query string
-
-	var args []interface{}
+	b := querybuilder.Select().From(tableName)
 	if condition != nil && len(condition.Cases) > 0 {
-		// Build the query
-		query = fmt.Sprintf("SELECT * FROM %s WHERE ", tableName)
-
-		// Generate the conditions for the where clause
-		var conditionQuery string
-		conditionQuery, args = getCondition(condition)
+		where := conditionExpr(condition)
+		conditionQuery, conditionArgs := where.SQL()
 		logger.Debug(fmt.Sprintf("ConditionQuery: %s", conditionQuery))
-		logger.Debug(fmt.Sprintf("Args: %v", args))
-		query += conditionQuery
-		query += fmt.Sprintf(" LIMIT %d", limit)
-	} else {
-		query = fmt.Sprintf("SELECT * FROM %q LIMIT %d OFFSET %d", tableName, limit, offset)
+		logger.Debug(fmt.Sprintf("Args: %v", conditionArgs))
+		b.Where(where)
+
+		if orderBy == OrderByRelevance {
+			orderQuery, orderArgs := getRelevanceOrder(tableName, condition)
+			if orderQuery != "" {
+				b.OrderBy(orderQuery, orderArgs...)
+			}
+		}
 	}
+	query, args := b.Page(limit, offset).Build()
 
 	logger.Info(fmt.Sprintf("About to perform query: `%s`", query))
 
 	// Now perform the actual query
-	rows, err = db.Query(query, args...)
+	rows, err := q.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("error querying table: %v", err)
 	}
-	defer rows.Close()
+	return rows, nil
+}
 
-	// Prepare the result slice
+// scanRows reads all the rows into maps of column name to value.
+func scanRows(rows *sql.Rows) ([]map[string]interface{}, error) {
 	var result []map[string]interface{}
+	err := forEachRow(rows, func(row map[string]interface{}) error {
+		result = append(result, row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// forEachRow calls fn with each row as a map of column name to value, without
+// holding more than one row in memory.
+func forEachRow(rows *sql.Rows, fn func(row map[string]interface{}) error) error {
+	columns, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("error reading columns: %v", err)
+	}
 
 	// Prepare value holders
 	values := make([]interface{}, len(columns))
@@ -243,7 +708,7 @@ func queryTable(db *sql.DB, tableName string, condition *Condition, limit int, o
 	for rows.Next() {
 		err = rows.Scan(scanArgs...)
 		if err != nil {
-			return nil, fmt.Errorf("error scanning row: %v", err)
+			return fmt.Errorf("error scanning row: %v", err)
 		}
 
 		// Create a map for this row
@@ -257,83 +722,81 @@ func queryTable(db *sql.DB, tableName string, condition *Condition, limit int, o
 				row[col] = v
 			}
 		}
-		result = append(result, row)
+		if err = fn(row); err != nil {
+			return err
+		}
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error reading rows: %v", err)
+		return fmt.Errorf("error reading rows: %v", err)
 	}
 
-	return result, nil
+	return nil
 }
 
-func getCondition(condition *Condition) (string, []interface{}) {
-	var clause string
-	var args []interface{}
-
-	for i, c := range condition.Cases {
-		if i > 0 {
-			clause += fmt.Sprintf(" %s ", condition.LogicalOperator)
-		}
-		switch c.ConditionCaseType() {
-		case "condition":
-			condition := c.(Condition)
-			subClause, subArgs := getCondition(&condition)
-			clause += "(" + subClause + ")"
-			args = append(args, subArgs...)
-		case "filter":
-			filter := c.(Filter)
-			clause += getClause(filter)
-			args = append(args, filter.Value)
+// conditionExpr returns the expression of the condition and its
+// sub-conditions for the WHERE clause of a query.
+func conditionExpr(condition *Condition) querybuilder.Expr {
+	if condition == nil {
+		return querybuilder.Expr{}
+	}
+	exprs := make([]querybuilder.Expr, 0, len(condition.Cases))
+	for _, c := range condition.Cases {
+		switch c := c.(type) {
+		case Condition:
+			exprs = append(exprs, conditionExpr(&c))
+		case Filter:
+			exprs = append(exprs, filterExpr(c))
 		}
 	}
-	return clause, args
+	if condition.LogicalOperator == LogicalOperatorOr {
+		return querybuilder.Or(exprs...)
+	}
+	return querybuilder.And(exprs...)
 }
 
-func getClause(filter Filter) string {
+func filterExpr(filter Filter) querybuilder.Expr {
 	switch filter.Operator {
 	case OperatorEquals:
-		return fmt.Sprintf("%s = ?", filter.Column)
+		return querybuilder.Eq(filter.Column, filter.Value)
 	case OperatorLike:
-		return fmt.Sprintf("%s LIKE '%%' || ? || '%%'", filter.Column)
+		return querybuilder.Contains(filter.Column, filter.Value)
 	case OperatorNotEquals:
-		return fmt.Sprintf("%s != ?", filter.Column)
+		return querybuilder.Ne(filter.Column, filter.Value)
 	case OperatorLessThan:
-		return fmt.Sprintf("%s < ?", filter.Column)
+		return querybuilder.Lt(filter.Column, filter.Value)
 	case OperatorLessThanOrEquals:
-		return fmt.Sprintf("%s <= ?", filter.Column)
+		return querybuilder.Le(filter.Column, filter.Value)
 	case OperatorGreaterThan:
-		return fmt.Sprintf("%s > ?", filter.Column)
+		return querybuilder.Gt(filter.Column, filter.Value)
 	case OperatorGreaterThanOrEquals:
-		return fmt.Sprintf("%s >= ?", filter.Column)
+		return querybuilder.Ge(filter.Column, filter.Value)
 	case OperatorIsNull:
-		return fmt.Sprintf("%s IS NULL", filter.Column)
+		return querybuilder.IsNull(filter.Column)
 	case OperatorIsNotNull:
-		return fmt.Sprintf("%s IS NOT NULL", filter.Column)
+		return querybuilder.IsNotNull(filter.Column)
+	case OperatorMatch:
+		return querybuilder.Match(filter.Column, filter.Value)
 	default:
-		return ""
+		return querybuilder.Expr{}
 	}
 }
 
-func batchDelete(db *sql.DB, tableName string, ids []any) (int64, error) {
+func batchDelete(ctx context.Context, q queryer, tableName string, ids []any) (int64, error) {
 	// Handle empty case
 	if len(ids) == 0 {
 		return 0, nil
 	}
 
 	// Get the primary key of the table
-	tableInfo, err := getTableInfo(db, tableName)
+	columns, err := getTableColumns(ctx, q, tableName)
 	if err != nil {
-		return 0, fmt.Errorf("error getting primary key for delete: %v", err)
-	}
-	columns, ok := tableInfo["columns"].([]map[string]interface{})
-	if !ok {
-		return 0, fmt.Errorf("error getting primary key for delete")
+		return 0, fmt.Errorf("error getting primary key for delete: %w", err)
 	}
 	var primaryKey string
 	for _, column := range columns {
-		if column["pk"].(int) == 1 {
-			primaryKey = column["name"].(string)
+		if column.PK == 1 {
+			primaryKey = column.Name
 			break
 		}
 	}
@@ -342,22 +805,10 @@ func batchDelete(db *sql.DB, tableName string, ids []any) (int64, error) {
 		return 0, fmt.Errorf("table %s does not have a primary key", tableName)
 	}
 
-	// Create the placeholders for the query (?,?,?)
-	placeholders := make([]string, len(ids))
-	for i := range ids {
-		placeholders[i] = "?"
-	}
-
-	// Build the query
-	query := fmt.Sprintf(
-		"DELETE FROM %s WHERE %s IN (%s)",
-		tableName,
-		primaryKey,
-		strings.Join(placeholders, ","),
-	)
+	query, args := querybuilder.DeleteFrom(tableName).Where(querybuilder.In(primaryKey, ids...)).Build()
 
 	// Execute the delete
-	result, err := db.Exec(query, ids...)
+	result, err := q.ExecContext(ctx, query, args...)
 	if err != nil {
 		return 0, fmt.Errorf("batch delete failed: %v", err)
 	}
@@ -366,78 +817,129 @@ func batchDelete(db *sql.DB, tableName string, ids []any) (int64, error) {
 	return result.RowsAffected()
 }
 
-func getTableInfo(db *sql.DB, tableName string) (map[string]interface{}, error) {
+func getTableInfo(ctx context.Context, q queryer, tableName string) (*TableInfo, error) {
+	columns, err := getTableColumns(ctx, q, tableName)
+	if err != nil {
+		return nil, err
+	}
+	info := &TableInfo{Columns: columns}
+
+	geometries, err := getGeometryColumns(ctx, q, tableName)
+	if err != nil {
+		return nil, err
+	}
+	for i, column := range info.Columns {
+		info.Columns[i].Geometry = geometries[column.Name].kind
+	}
+
+	vt, err := getVirtualTables(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	info.Module = vt.modules[tableName]
+	info.ShadowTables = vt.shadowTablesOf(tableName)
+	info.ShadowOf = vt.shadows[tableName]
+
+	info.Strict, err = isStrictTable(ctx, q, tableName)
+	if err != nil {
+		return nil, err
+	}
+	info.Sequence, err = getSequence(ctx, q, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the number of rows
+	err = q.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", quoteIdent(tableName))).Scan(&info.Count)
+	if err != nil {
+		return nil, fmt.Errorf("error getting row count: %v", err)
+	}
+
+	info.IndexAdvisories, err = getIndexAdvisories(ctx, q, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("error getting index advisories: %v", err)
+	}
+
+	info.Triggers, err = getTriggers(ctx, q, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// readTableColumns reads the columns of a table from its schema, see
+// getTableColumns.
+func readTableColumns(ctx context.Context, q queryer, tableName string) ([]ColumnInfo, error) {
 	// First, verify the table exists to prevent SQL injection
-	exists, err := checkTableExists(db, tableName)
+	exists, err := checkTableExists(ctx, q, tableName)
 	if err != nil {
 		return nil, fmt.Errorf("error checking table existence: %v", err)
 	}
 	if !exists {
-		return nil, fmt.Errorf("table %s does not exist", tableName)
+		return nil, fmt.Errorf("%s: %w", tableName, ErrUnknownTable)
 	}
 
-	// Query to get column names
-	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%q)", tableName))
+	// Query to get column names, including the generated columns that
+	// PRAGMA table_info leaves out
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("PRAGMA table_xinfo(%s)", quoteIdent(tableName)))
 	if err != nil {
 		return nil, fmt.Errorf("error getting columns: %v", err)
 	}
 	defer rows.Close()
 
-	// Prepare the result slice
-	var result []map[string]interface{}
+	var columns []ColumnInfo
 
 	// Iterate through rows
 	for rows.Next() {
-		var cid int
-		var name string
-		var dataType string
-		var notNull int
-		var defaultValue interface{}
-		var pk int
-		if err = rows.Scan(&cid, &name, &dataType, &notNull, &defaultValue, &pk); err != nil {
+		var column ColumnInfo
+		var defaultValue sql.NullString
+		var hidden int
+		if err = rows.Scan(&column.CID, &column.Name, &column.DataType, &column.NotNull, &defaultValue, &column.PK, &hidden); err != nil {
 			return nil, fmt.Errorf("error scanning row: %v", err)
 		}
-
-		// Create a map for this row
-		row := map[string]interface{}{
-			"cid":      cid,
-			"name":     name,
-			"dataType": dataType,
-			"notNull":  notNull,
-			"pk":       pk,
+		// The hidden columns of virtual tables aren't part of their rows
+		if hidden == 1 {
+			continue
 		}
-		result = append(result, row)
+		column.Default = defaultValue.String
+		column.Generated = generatedKind(hidden)
+		column.ReadOnly = column.Generated != ""
+		column.Boolean = isBooleanType(column.DataType)
+		columns = append(columns, column)
 	}
 
 	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("error reading rows: %v", err)
 	}
 
-	// Get the number of rows
-	var count int
-	err = db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %q", tableName)).Scan(&count)
+	enums, err := getEnumValues(ctx, q, tableName)
 	if err != nil {
-		return nil, fmt.Errorf("error getting row count: %v", err)
+		return nil, err
+	}
+	for i, column := range columns {
+		columns[i].Enum = enums[column.Name]
 	}
 
-	return map[string]interface{}{"columns": result, "count": count}, nil
+	return columns, nil
 }
 
-func editRow(db *sql.DB, tableName string, row map[string]interface{}) error {
+func editRow(ctx context.Context, q queryer, tableName string, row map[string]interface{}) error {
 	// Get the primary key of the table
-	tableInfo, err := getTableInfo(db, tableName)
+	columns, err := getTableColumns(ctx, q, tableName)
 	if err != nil {
-		return fmt.Errorf("error getting primary key for edit: %v", err)
-	}
-	columns, ok := tableInfo["columns"].([]map[string]interface{})
-	if !ok {
-		return fmt.Errorf("error getting primary key for edit")
+		return fmt.Errorf("error getting primary key for edit: %w", err)
 	}
 	var primaryKey string
-	for _, column := range columns {
-		if column["pk"].(int) == 1 {
-			primaryKey = column["name"].(string)
-			break
+	var generated []string
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name
+		if column.Generated != "" {
+			generated = append(generated, column.Name)
+		}
+		if primaryKey == "" && column.PK == 1 {
+			primaryKey = names[i]
 		}
 	}
 
@@ -449,38 +951,27 @@ func editRow(db *sql.DB, tableName string, row map[string]interface{}) error {
 		return fmt.Errorf("row does not contain primary key")
 	}
 
-	nonPKColumns := make(map[string]interface{})
+	update := querybuilder.Update(tableName).Where(querybuilder.Eq(primaryKey, row[primaryKey]))
+	updated := 0
 	for k, v := range row {
-		if k != primaryKey {
-			nonPKColumns[k] = v
+		if !contains(names, k) {
+			return fmt.Errorf("%s: %w", k, ErrUnknownColumn)
+		}
+		// Generated columns are sent back with the rest of the row but
+		// can't be set
+		if k != primaryKey && !contains(generated, k) {
+			update.Set(k, v)
+			updated++
 		}
 	}
-
-	// Create the placeholders for the query (?,?,?)
-	// We exclude the primary key from the placeholders
-	placeholders := make([]string, len(row)-1)
-	values := make([]interface{}, len(row)-1)
-	i := 0
-	for k, v := range nonPKColumns {
-		// Add the column name to the placeholder string
-		placeholders[i] = fmt.Sprintf("%s = ?", k)
-		values[i] = v
-		i++
+	if updated == 0 {
+		// Nothing to update, e.g. only redacted values were sent back
+		return nil
 	}
 
-	// Build the query
-	query := fmt.Sprintf(
-		"UPDATE %s SET %s WHERE %s = ?",
-		tableName,
-		strings.Join(placeholders, ","),
-		primaryKey,
-	)
-
-	// Add the primary key value to the end of the values slice
-	values = append(values, row[primaryKey])
-
 	// Execute the update
-	_, err = db.Exec(query, values...)
+	query, args := update.Build()
+	_, err = q.ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("edit row failed: %v", err)
 	}
@@ -489,6 +980,7 @@ func editRow(db *sql.DB, tableName string, row map[string]interface{}) error {
 }
 
 func writeError(w http.ResponseWriter, err APIError) {
+	err.RequestID = w.Header().Get(RequestIDHeader)
 	w.WriteHeader(err.StatusCode)
 	json.NewEncoder(w).Encode(err)
 }
@@ -550,13 +1042,25 @@ func toCondition(val interface{}, logger Logger) (*Condition, bool) {
 					logger.Error(fmt.Sprintf("Error decoding filter: %v", err))
 					return nil, false
 				}
+				if filterExpr(filter).IsEmpty() {
+					logger.Debug(fmt.Sprintf("Unknown operator: %s", filter.Operator))
+					return nil, false
+				}
 				condition.Cases = append(condition.Cases, filter)
 			}
 		}
 	}
 
 	if valMap["logicalOperator"] != nil {
-		condition.LogicalOperator = LogicalOperator(valMap["logicalOperator"].(string))
+		op, _ := valMap["logicalOperator"].(string)
+		condition.LogicalOperator = LogicalOperator(op)
+		// Cases are joined with AND by default
+		switch condition.LogicalOperator {
+		case "", LogicalOperatorAnd, LogicalOperatorOr:
+		default:
+			logger.Debug(fmt.Sprintf("Unknown logical operator: %s", op))
+			return nil, false
+		}
 	}
 
 	return &condition, true
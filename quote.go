@@ -0,0 +1,88 @@
+package sqliteadmin
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/joelseq/sqliteadmin-go/querybuilder"
+)
+
+// quoteIdent quotes a table, column or other name as an SQL identifier.
+func quoteIdent(name string) string {
+	return querybuilder.Ident(name)
+}
+
+// quoteLiteral quotes a string as an SQL string literal.
+func quoteLiteral(s string) string {
+	return querybuilder.Literal(s)
+}
+
+// rowidColumns are the names of the rowid of tables that don't have a column
+// with one of these names.
+var rowidColumns = []string{"rowid", "oid", "_rowid_"}
+
+// tableColumns returns the names of the columns of the table, including
+// hidden and generated columns, e.g. the column of a full-text search table
+// named after the table, and the names of the rowid.
+func tableColumns(ctx context.Context, q queryer, table string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, "SELECT name FROM pragma_table_xinfo(?)", table)
+	if err != nil {
+		return nil, fmt.Errorf("error getting columns: %v", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%s: %w", table, ErrUnknownTable)
+	}
+	return append(names, rowidColumns...), nil
+}
+
+// conditionColumns returns the columns filtered on by the condition and its
+// sub-conditions.
+func conditionColumns(condition *Condition) []string {
+	var columns []string
+	for _, c := range condition.Cases {
+		switch c := c.(type) {
+		case Condition:
+			columns = append(columns, conditionColumns(&c)...)
+		case Filter:
+			columns = append(columns, c.Column)
+		}
+	}
+	return columns
+}
+
+// checkColumns returns an error wrapping ErrUnknownColumn if one of the
+// columns isn't one of the known columns.
+func checkColumns(columns, known []string) error {
+	for _, c := range columns {
+		if !contains(known, c) {
+			return fmt.Errorf("%s: %w", c, ErrUnknownColumn)
+		}
+	}
+	return nil
+}
+
+// checkConditionColumns returns an error wrapping ErrUnknownColumn if the
+// condition filters on a column that isn't a column of the table.
+func checkConditionColumns(ctx context.Context, q queryer, table string, condition *Condition) error {
+	if condition == nil || len(condition.Cases) == 0 {
+		return nil
+	}
+	known, err := tableColumns(ctx, q, table)
+	if err != nil {
+		return err
+	}
+	return checkColumns(conditionColumns(condition), known)
+}
@@ -0,0 +1,98 @@
+package sqliteadmin_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostileIdentifiers(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	const table = `odd" table; DROP TABLE users; --`
+	_, err := ts.db.Exec(`
+		CREATE TABLE "odd"" table; DROP TABLE users; --" (
+			"i""d" INTEGER PRIMARY KEY,
+			"select" TEXT,
+			"x""y" TEXT
+		);
+		INSERT INTO "odd"" table; DROP TABLE users; --" VALUES (1, 'one', 'x'), (2, 'two', 'y'), (3, 'three', 'z');
+	`)
+	assert.NoError(t, err)
+
+	send := func(command sqliteadmin.Command, params map[string]interface{}) (int, map[string]interface{}) {
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: command, Params: params})
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res.StatusCode, readBody(t, res.Body)
+	}
+	filter := func(column string, value interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"cases": []interface{}{
+				map[string]interface{}{"column": column, "operator": "eq", "value": value},
+			},
+		}
+	}
+
+	t.Run("Query", func(t *testing.T) {
+		status, body := send(sqliteadmin.GetTable, map[string]interface{}{"tableName": table, "condition": filter(`x"y`, "y")})
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{`i"d`: float64(2), "select": "two", `x"y`: "y"},
+		}, body["rows"])
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		status, _ := send(sqliteadmin.UpdateRow, map[string]interface{}{
+			"tableName": table,
+			"row":       map[string]interface{}{`i"d`: 1, "select": "uno"},
+		})
+		assert.Equal(t, http.StatusOK, status)
+
+		var value string
+		assert.NoError(t, ts.db.QueryRow(`SELECT "select" FROM "odd"" table; DROP TABLE users; --" WHERE "i""d" = 1`).Scan(&value))
+		assert.Equal(t, "uno", value)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		status, body := send(sqliteadmin.DeleteRows, map[string]interface{}{"tableName": table, "ids": []interface{}{"3"}})
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "1", body["rowsAffected"])
+	})
+
+	t.Run("Failure: Injected Column", func(t *testing.T) {
+		status, body := send(sqliteadmin.GetTable, map[string]interface{}{"tableName": "users", "condition": filter("1 = 1 OR name", "x")})
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Equal(t, "COLUMN_NOT_FOUND", body["code"])
+		assert.Equal(t, "1 = 1 OR name: unknown column", body["detail"])
+
+		status, body = send(sqliteadmin.UpdateRow, map[string]interface{}{
+			"tableName": "users",
+			"row":       map[string]interface{}{"id": 1, "name = 'x', email": "y"},
+		})
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Equal(t, "COLUMN_NOT_FOUND", body["code"])
+	})
+
+	t.Run("Failure: Injected Operators", func(t *testing.T) {
+		condition := filter("id", "1")
+		condition["logicalOperator"] = "OR 1 = 1 OR"
+		status, body := send(sqliteadmin.GetTable, map[string]interface{}{"tableName": "users", "condition": condition})
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Equal(t, "Bad request: Invalid condition", body["message"])
+
+		condition = filter("id", "1")
+		condition["cases"].([]interface{})[0].(map[string]interface{})["operator"] = "= 1 OR 1 ="
+		status, body = send(sqliteadmin.GetTable, map[string]interface{}{"tableName": "users", "condition": condition})
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Equal(t, "Bad request: Invalid condition", body["message"])
+	})
+
+	// The users table is still there
+	var count int
+	assert.NoError(t, ts.db.QueryRow("SELECT count(*) FROM users").Scan(&count))
+	assert.Equal(t, 9, count)
+}
@@ -0,0 +1,209 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// rebuildTable changes the definition of a table following the generalized
+// ALTER TABLE procedure from https://www.sqlite.org/lang_altertable.html: a
+// new table is created from the CREATE TABLE statement returned by transform,
+// the rows are copied over, the old table is dropped and the new one renamed,
+// and the indexes and triggers of the table are recreated. Everything happens
+// in a single transaction on a dedicated connection, with foreign keys
+// enforcement disabled until the foreign keys have been checked.
+func rebuildTable(ctx context.Context, db *sql.DB, table string, transform func(createSQL string) (string, error)) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting connection: %v", err)
+	}
+	defer conn.Close()
+
+	var foreignKeys, legacyAlterTable bool
+	if err := conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys); err != nil {
+		return fmt.Errorf("error reading foreign_keys: %v", err)
+	}
+	if err := conn.QueryRowContext(ctx, "PRAGMA legacy_alter_table").Scan(&legacyAlterTable); err != nil {
+		return fmt.Errorf("error reading legacy_alter_table: %v", err)
+	}
+	// The pragmas can't be changed inside a transaction. legacy_alter_table
+	// keeps the rename from rewriting (or failing on) views and triggers that
+	// reference the table while it doesn't exist.
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF; PRAGMA legacy_alter_table = ON"); err != nil {
+		return fmt.Errorf("error disabling foreign keys: %v", err)
+	}
+	defer conn.ExecContext(context.Background(), fmt.Sprintf("PRAGMA foreign_keys = %t; PRAGMA legacy_alter_table = %t", foreignKeys, legacyAlterTable))
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var createSQL string
+	err = tx.QueryRowContext(ctx, "SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&createSQL)
+	if err != nil {
+		return fmt.Errorf("error reading table definition: %v", err)
+	}
+
+	newSQL, err := transform(createSQL)
+	if err != nil {
+		return err
+	}
+	start := strings.Index(newSQL, "(")
+	if start < 0 {
+		return fmt.Errorf("invalid table definition")
+	}
+	newTable := "_sqliteadmin_new_" + table
+	newSQL = fmt.Sprintf("CREATE TABLE %s %s", quoteIdent(newTable), newSQL[start:])
+
+	// Indexes and triggers are dropped along with the table
+	schema, err := tx.QueryContext(ctx, "SELECT sql FROM sqlite_master WHERE type IN ('index', 'trigger') AND tbl_name = ? AND sql IS NOT NULL", table)
+	if err != nil {
+		return fmt.Errorf("error reading indexes and triggers: %v", err)
+	}
+	var recreate []string
+	for schema.Next() {
+		var s string
+		if err := schema.Scan(&s); err != nil {
+			schema.Close()
+			return fmt.Errorf("error scanning row: %v", err)
+		}
+		recreate = append(recreate, s)
+	}
+	schema.Close()
+
+	// Copying the rows with their ids would otherwise reset the AUTOINCREMENT
+	// sequence to the largest remaining id.
+	var seq sql.NullInt64
+	if exists, _ := checkTableExists(ctx, tx, "sqlite_sequence"); exists {
+		tx.QueryRowContext(ctx, "SELECT seq FROM sqlite_sequence WHERE name = ?", table).Scan(&seq)
+	}
+
+	var count int64
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", quoteIdent(table))).Scan(&count); err != nil {
+		return fmt.Errorf("error counting rows: %v", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, newSQL); err != nil {
+		return fmt.Errorf("error creating new table: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s SELECT * FROM %s", quoteIdent(newTable), quoteIdent(table))); err != nil {
+		return fmt.Errorf("error copying rows: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("DROP TABLE %s", quoteIdent(table))); err != nil {
+		return fmt.Errorf("error dropping table: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quoteIdent(newTable), quoteIdent(table))); err != nil {
+		return fmt.Errorf("error renaming table: %v", err)
+	}
+	for _, s := range recreate {
+		if _, err := tx.ExecContext(ctx, s); err != nil {
+			return fmt.Errorf("error recreating %s: %v", s, err)
+		}
+	}
+	if seq.Valid {
+		if _, err := tx.ExecContext(ctx, "UPDATE sqlite_sequence SET seq = max(seq, ?) WHERE name = ?", seq.Int64, table); err != nil {
+			return fmt.Errorf("error restoring sequence: %v", err)
+		}
+	}
+
+	var newCount int64
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", quoteIdent(table))).Scan(&newCount); err != nil {
+		return fmt.Errorf("error counting rows: %v", err)
+	}
+	if newCount != count {
+		return fmt.Errorf("rebuilt table has %d rows instead of %d", newCount, count)
+	}
+
+	if foreignKeys {
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf("PRAGMA foreign_key_check(%s)", quoteIdent(table)))
+		if err != nil {
+			return fmt.Errorf("error checking foreign keys: %v", err)
+		}
+		violation := rows.Next()
+		rows.Close()
+		if violation {
+			return fmt.Errorf("rebuilt table violates foreign key constraints")
+		}
+	}
+
+	return tx.Commit()
+}
+
+// sqlScanner walks over SQL text, keeping track of string literals, quoted
+// identifiers and comments so that only the syntax outside of them is
+// inspected.
+type sqlScanner struct {
+	s     string
+	i     int
+	depth int
+}
+
+// next advances to the next character outside of literals and comments and
+// returns it, or 0 at the end of the text.
+func (sc *sqlScanner) next() byte {
+	for sc.i < len(sc.s) {
+		c := sc.s[sc.i]
+		switch {
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			end := c
+			if c == '[' {
+				end = ']'
+			}
+			sc.i++
+			for sc.i < len(sc.s) {
+				if sc.s[sc.i] == end {
+					// Quotes are escaped by doubling them
+					if end != ']' && sc.i+1 < len(sc.s) && sc.s[sc.i+1] == end {
+						sc.i += 2
+						continue
+					}
+					break
+				}
+				sc.i++
+			}
+			sc.i++
+		case c == '-' && strings.HasPrefix(sc.s[sc.i:], "--"):
+			if nl := strings.IndexByte(sc.s[sc.i:], '\n'); nl >= 0 {
+				sc.i += nl + 1
+			} else {
+				sc.i = len(sc.s)
+			}
+		case c == '/' && strings.HasPrefix(sc.s[sc.i:], "/*"):
+			if end := strings.Index(sc.s[sc.i+2:], "*/"); end >= 0 {
+				sc.i += end + 4
+			} else {
+				sc.i = len(sc.s)
+			}
+		default:
+			sc.i++
+			switch c {
+			case '(':
+				sc.depth++
+			case ')':
+				sc.depth--
+			}
+			return c
+		}
+	}
+	return 0
+}
+
+// isSQLExpression reports whether s can safely be embedded as an expression
+// in a statement: its parentheses are balanced and it doesn't end the
+// statement.
+func isSQLExpression(s string) bool {
+	if strings.TrimSpace(s) == "" {
+		return false
+	}
+	sc := &sqlScanner{s: s}
+	for c := sc.next(); c != 0; c = sc.next() {
+		if c == ';' || sc.depth < 0 {
+			return false
+		}
+	}
+	return sc.depth == 0
+}
@@ -0,0 +1,52 @@
+package sqliteadmin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// getRelevanceOrder returns the ORDER BY expression (and its args) that puts
+// the best matches of the search filters of the condition first. Full-text
+// searches are ranked with bm25, while LIKE searches prefer values where the
+// term appears earlier and shorter values, i.e. closer matches. It returns an
+// empty string if the condition has no search filters.
+func getRelevanceOrder(table string, condition *Condition) (string, []interface{}) {
+	filters := searchFilters(condition)
+	if len(filters) == 0 {
+		return "", nil
+	}
+
+	for _, f := range filters {
+		if f.Operator == OperatorMatch {
+			return fmt.Sprintf("bm25(%s)", quoteIdent(table)), nil
+		}
+	}
+
+	var terms []string
+	var args []interface{}
+	for _, f := range filters {
+		terms = append(terms, fmt.Sprintf("nullif(instr(lower(%s), lower(?)), 0) NULLS LAST", quoteIdent(f.Column)))
+		args = append(args, f.Value)
+	}
+	for _, f := range filters {
+		terms = append(terms, fmt.Sprintf("length(%s)", quoteIdent(f.Column)))
+	}
+	return strings.Join(terms, ", "), args
+}
+
+// searchFilters returns the LIKE and MATCH filters of the condition and its
+// sub-conditions.
+func searchFilters(condition *Condition) []Filter {
+	var filters []Filter
+	for _, c := range condition.Cases {
+		switch c := c.(type) {
+		case Condition:
+			filters = append(filters, searchFilters(&c)...)
+		case Filter:
+			if c.Operator == OperatorLike || c.Operator == OperatorMatch {
+				filters = append(filters, c)
+			}
+		}
+	}
+	return filters
+}
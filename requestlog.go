@@ -0,0 +1,115 @@
+package sqliteadmin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// RequestIDHeader is the response header containing the ID assigned to the
+// request. The same ID is included in the log lines of the request and in
+// error responses so that they can be correlated.
+const RequestIDHeader = "X-Request-Id"
+
+type requestInfo struct {
+	id     string
+	start  time.Time
+	rows   int
+	status *statusRecorder
+	// ctx carries the request ID, user and command for the logger.
+	ctx context.Context
+}
+
+// startRequest assigns an ID to the request and returns a copy of the Admin
+// whose logger includes it in every log line, along with the ResponseWriter
+// and request to use for the rest of the request.
+func (a *Admin) startRequest(w http.ResponseWriter, r *http.Request) (*Admin, http.ResponseWriter, *http.Request) {
+	id, err := newID()
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error generating request id: %v", err))
+	}
+	w.Header().Set(RequestIDHeader, id)
+
+	status := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	req := *a
+	req.request = &requestInfo{
+		id:     id,
+		start:  time.Now(),
+		status: status,
+		ctx:    context.WithValue(r.Context(), requestIDKey, id),
+	}
+	req.logger = &requestLogger{Logger: a.logger, request: req.request}
+	return &req, status, r.WithContext(req.request.ctx)
+}
+
+// withRequestValue adds a value to the context of the request, for the
+// logger and the handlers.
+func (a *Admin) withRequestValue(r *http.Request, key contextKey, value interface{}) *http.Request {
+	a.request.ctx = context.WithValue(a.request.ctx, key, value)
+	return r.WithContext(a.request.ctx)
+}
+
+// recordRows records the number of rows returned by the command for the
+// request log.
+func (a *Admin) recordRows(n int) {
+	if a.request != nil {
+		a.request.rows = n
+	}
+}
+
+// logRequest logs the summary of the request once it has been handled.
+func (a *Admin) logRequest(cr *CommandRequest) {
+	outcome := "ok"
+	if a.request.status.status >= http.StatusBadRequest {
+		outcome = "error"
+	}
+	table, _ := cr.Params["tableName"].(string)
+	a.logger.Info("Request",
+		"command", cr.Command,
+		"table", table,
+		"duration", time.Since(a.request.start),
+		"rows", a.request.rows,
+		"status", a.request.status.status,
+		"outcome", outcome,
+	)
+}
+
+var _ Logger = &requestLogger{}
+
+// requestLogger adds the request ID to every log line as a key/value pair,
+// following the conventions of log/slog, and passes the context of the
+// request to loggers implementing ContextLogger.
+type requestLogger struct {
+	Logger
+	request *requestInfo
+}
+
+func (l *requestLogger) Info(format string, args ...interface{}) {
+	logContext(l.request.ctx, l.Logger, levelInfo, format, append(args, "requestId", l.request.id))
+}
+
+func (l *requestLogger) Error(format string, args ...interface{}) {
+	logContext(l.request.ctx, l.Logger, levelError, format, append(args, "requestId", l.request.id))
+}
+
+func (l *requestLogger) Debug(format string, args ...interface{}) {
+	logContext(l.request.ctx, l.Logger, levelDebug, format, append(args, "requestId", l.request.id))
+}
+
+// statusRecorder records the status code of the response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,218 @@
+package sqliteadmin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// Role is the role of a user, which determines the categories of commands
+// the user can run.
+type Role string
+
+const (
+	// RoleAdmin can run every command.
+	RoleAdmin Role = "admin"
+	// RoleEditor can read and edit rows, but not change the schema or the
+	// configuration of the Admin.
+	RoleEditor Role = "editor"
+	// RoleViewer can only read.
+	RoleViewer Role = "viewer"
+)
+
+// User is an account authenticated with the Authorization header
+// "username:password", like Config.Username and Config.Password.
+type User struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Role     Role   `json:"role"`
+}
+
+// CommandCategory groups the commands by the role required to run them.
+type CommandCategory string
+
+const (
+	// CategoryRead commands only read data.
+	CategoryRead CommandCategory = "read"
+	// CategoryWrite commands edit rows.
+	CategoryWrite CommandCategory = "write"
+	// CategoryAdmin commands change the schema, the settings stored in the
+	// database or the files of the server, or reach other servers.
+	CategoryAdmin CommandCategory = "admin"
+)
+
+// commandCategories are the categories of the commands which aren't read
+// only, other than the mutating commands which are writes.
+var commandCategories = map[Command]CommandCategory{
+	BeginTransaction:     CategoryWrite,
+	RollbackTransaction:  CategoryWrite,
+	StartOperation:       CategoryWrite,
+	SubmitOperationStep:  CategoryWrite,
+	CancelOperation:      CategoryWrite,
+	TrackChanges:         CategoryAdmin,
+	ListWebhooks:         CategoryAdmin,
+	AddWebhook:           CategoryAdmin,
+	RemoveWebhook:        CategoryAdmin,
+	TestWebhook:          CategoryAdmin,
+	AddCheckConstraint:   CategoryAdmin,
+	DropCheckConstraint:  CategoryAdmin,
+	CreateTrigger:        CategoryAdmin,
+	DropTrigger:          CategoryAdmin,
+	CreateFlagsTable:     CategoryAdmin,
+	RotateEncryptionKeys: CategoryAdmin,
+	SeedFixtures:         CategoryAdmin,
+	SeedTable:            CategoryAdmin,
+	AnonymizeTable:       CategoryAdmin,
+	TruncateTable:        CategoryAdmin,
+	ResetSequence:        CategoryAdmin,
+	IncrementalVacuum:    CategoryAdmin,
+	CloneDatabase:        CategoryAdmin,
+	CreateAPIKey:         CategoryAdmin,
+	ListAPIKeys:          CategoryAdmin,
+	RevokeAPIKey:         CategoryAdmin,
+}
+
+func commandCategory(command Command) CommandCategory {
+	if category, ok := commandCategories[command]; ok {
+		return category
+	}
+	if mutatingCommands[command] {
+		return CategoryWrite
+	}
+	return CategoryRead
+}
+
+// categories returns the categories of commands the role can run.
+func (r Role) categories() []CommandCategory {
+	switch r {
+	case RoleAdmin:
+		return []CommandCategory{CategoryRead, CategoryWrite, CategoryAdmin}
+	case RoleEditor:
+		return []CommandCategory{CategoryRead, CategoryWrite}
+	case RoleViewer:
+		return []CommandCategory{CategoryRead}
+	}
+	return []CommandCategory{}
+}
+
+func (r Role) allows(command Command) bool {
+	return slices.Contains(r.categories(), commandCategory(command))
+}
+
+// includes reports whether the role can run every command of the other role.
+// Unknown roles only include themselves.
+func (r Role) includes(other Role) bool {
+	if other == "" || r == other {
+		return true
+	}
+	required := other.categories()
+	if len(required) == 0 {
+		return false
+	}
+	for _, category := range required {
+		if !slices.Contains(r.categories(), category) {
+			return false
+		}
+	}
+	return true
+}
+
+// RoleFromContext returns the role of the user that authenticated the request
+// being handled, which is RoleAdmin when authentication is disabled.
+func RoleFromContext(ctx context.Context) Role {
+	if role, ok := ctx.Value(roleKey).(Role); ok {
+		return role
+	}
+	return RoleAdmin
+}
+
+// newUsers returns the users of the config by username, including the one
+// of Username and Password who is an admin, or nil if authentication is
+// disabled. Invalid users are reported but still require authentication:
+// users without credentials are left out and the others can't run commands
+// their role doesn't allow.
+func newUsers(c Config) (map[string]User, error) {
+	if (c.Username == "" || c.Password == "") && len(c.Users) == 0 {
+		return nil, nil
+	}
+	users := make(map[string]User)
+	if c.Username != "" && c.Password != "" {
+		users[c.Username] = User{Username: c.Username, Password: c.Password, Role: RoleAdmin}
+	}
+	var errs []error
+	for _, u := range c.Users {
+		if u.Username == "" || u.Password == "" {
+			errs = append(errs, fmt.Errorf("users need a username and a password"))
+			continue
+		}
+		if _, ok := users[u.Username]; ok {
+			errs = append(errs, fmt.Errorf("duplicate user %s", u.Username))
+			continue
+		}
+		switch u.Role {
+		case RoleAdmin, RoleEditor, RoleViewer:
+		default:
+			errs = append(errs, fmt.Errorf("invalid role %q of %s", u.Role, u.Username))
+		}
+		users[u.Username] = u
+	}
+	return users, errors.Join(errs...)
+}
+
+// authEnabled reports whether requests must be authenticated.
+func (a *Admin) authEnabled() bool {
+	return a.users != nil || a.oidc != nil
+}
+
+// checkRole writes an error and returns false if the role of the request,
+// or its API key, doesn't allow running the command.
+func (a *Admin) checkRole(w http.ResponseWriter, r *http.Request, command Command) bool {
+	role := RoleFromContext(r.Context())
+	if !role.allows(command) {
+		a.logger.Info(fmt.Sprintf("Audit: %s denied %s as %s", UserFromContext(r.Context()), command, role))
+		writeError(w, apiErrForbidden(fmt.Sprintf("%s: %s can't run %s", ErrRoleNotAllowed, role, command)))
+		return false
+	}
+	commands, _ := r.Context().Value(commandsKey).([]Command)
+	if commands != nil && !slices.Contains(commands, command) {
+		a.logger.Info(fmt.Sprintf("Audit: %s denied %s", UserFromContext(r.Context()), command))
+		writeError(w, apiErrForbidden(fmt.Sprintf("%s: %s", ErrAPIKeyNotAllowed, command)))
+		return false
+	}
+	return true
+}
+
+// WhoAmIResponse is the response of WhoAmI.
+type WhoAmIResponse struct {
+	// User is empty when authentication is disabled.
+	User string `json:"user,omitempty"`
+	Role Role   `json:"role"`
+	// Permissions are the categories of commands the user can run.
+	Permissions []CommandCategory `json:"permissions"`
+	// Commands restrict API keys to some commands of their role.
+	Commands []Command `json:"commands,omitempty"`
+	// Elevated and CanUnseal report whether the user can update masked
+	// columns and see the values of encrypted ones.
+	Elevated  bool `json:"elevated"`
+	CanUnseal bool `json:"canUnseal"`
+}
+
+// whoAmI returns the identity and permissions of the caller, for the UI to
+// hide what they can't do.
+func (a *Admin) whoAmI(ctx context.Context, w http.ResponseWriter) {
+	user := UserFromContext(ctx)
+	a.logger.Info(fmt.Sprintf("Command: WhoAmI, user=%s", user))
+
+	id := identityFromContext(ctx)
+	json.NewEncoder(w).Encode(WhoAmIResponse{
+		User:        user,
+		Role:        id.role,
+		Permissions: id.role.categories(),
+		Commands:    id.commands,
+		Elevated:    isElevated(ctx),
+		CanUnseal:   canUnseal(ctx),
+	})
+}
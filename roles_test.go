@@ -0,0 +1,99 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoles(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.Users = []sqliteadmin.User{
+			{Username: "ed", Password: "secret", Role: sqliteadmin.RoleEditor},
+			{Username: "vi", Password: "secret", Role: sqliteadmin.RoleViewer},
+			{Username: "typo", Password: "secret", Role: "owner"},
+		}
+	})
+	defer close()
+
+	do := func(credentials string, command sqliteadmin.Command, params map[string]interface{}) (int, map[string]interface{}) {
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: command, Params: params})
+		req.Header.Set("Authorization", credentials)
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res.StatusCode, readBody(t, res.Body)
+	}
+	update := map[string]interface{}{"tableName": "users", "row": map[string]interface{}{"id": 1, "name": "Alicia"}}
+	trigger := map[string]interface{}{"tableName": "users"}
+
+	status, body := do("vi:secret", sqliteadmin.WhoAmI, nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]interface{}{
+		"user":        "vi",
+		"role":        "viewer",
+		"permissions": []interface{}{"read"},
+		"elevated":    false,
+		"canUnseal":   false,
+	}, body)
+
+	status, _ = do("vi:secret", sqliteadmin.GetTable, map[string]interface{}{"tableName": "users"})
+	assert.Equal(t, http.StatusOK, status)
+	status, body = do("vi:secret", sqliteadmin.UpdateRow, update)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, "Forbidden: command not allowed for the role: viewer can't run UpdateRow", body["message"])
+
+	status, _ = do("ed:secret", sqliteadmin.UpdateRow, update)
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = do("ed:secret", sqliteadmin.CreateTrigger, trigger)
+	assert.Equal(t, http.StatusForbidden, status)
+
+	// Username and Password are the credentials of an admin
+	status, body = do("user:password", sqliteadmin.WhoAmI, nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []interface{}{"read", "write", "admin"}, body["permissions"])
+
+	status, _ = do("vi:wrong", sqliteadmin.WhoAmI, nil)
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	// Users with an invalid role can't run anything
+	status, _ = do("typo:secret", sqliteadmin.GetTable, map[string]interface{}{"tableName": "users"})
+	assert.Equal(t, http.StatusForbidden, status)
+}
+
+func TestRolesSessions(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.Sessions = true
+		c.Users = []sqliteadmin.User{{Username: "vi", Password: "secret", Role: sqliteadmin.RoleViewer}}
+	})
+	defer close()
+
+	req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: sqliteadmin.Login})
+	req.Header.Set("Authorization", "vi:secret")
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	token := readBody(t, res.Body)["csrfToken"].(string)
+
+	req = makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: sqliteadmin.DeleteRows, Params: map[string]interface{}{"tableName": "users", "ids": []string{"1"}}})
+	req.Header.Del("Authorization")
+	req.Header.Set(sqliteadmin.CSRFHeader, token)
+	for _, c := range res.Cookies() {
+		req.AddCookie(c)
+	}
+	res, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+}
+
+func TestWhoAmI(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	res, err := ts.admin.WhoAmI(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, sqliteadmin.RoleAdmin, res.Role)
+	assert.True(t, res.Elevated)
+	assert.True(t, res.CanUnseal)
+}
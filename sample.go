@@ -0,0 +1,92 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	mathrand "math/rand"
+
+	"github.com/joelseq/sqliteadmin-go/querybuilder"
+)
+
+// sampleScanRows is the size of the rowid range above which the rows of a
+// table are sampled by rowid rather than by sorting the whole table.
+const sampleScanRows = 100000
+
+// sampleTable returns n random rows of the table matching the condition.
+// ORDER BY random() reads and sorts every row, so the rows of large tables
+// without a condition are instead looked up from random rowids between the
+// smallest and the largest. The rows following a gap in the rowids are more
+// likely to be picked that way, which is good enough to explore the data.
+func sampleTable(ctx context.Context, q queryer, table string, condition *Condition, n int, logger Logger) ([]map[string]interface{}, error) {
+	exists, err := checkTableExists(ctx, q, table)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("%s: %w", table, ErrUnknownTable)
+	}
+	if err := checkConditionColumns(ctx, q, table, condition); err != nil {
+		return nil, err
+	}
+
+	if condition == nil || len(condition.Cases) == 0 {
+		rowids, err := sampleRowids(ctx, q, table, n)
+		if err != nil {
+			return nil, err
+		}
+		if rowids != nil {
+			query, args := querybuilder.Select().From(table).Where(querybuilder.In("rowid", rowids...)).Build()
+			logger.Info(fmt.Sprintf("About to perform query: `%s`", query))
+			return queryRows(ctx, q, query, args...)
+		}
+	}
+
+	query, args := querybuilder.Select().
+		From(table).
+		Where(conditionExpr(condition)).
+		OrderBy("random()").
+		Limit(n).
+		Build()
+	logger.Info(fmt.Sprintf("About to perform query: `%s`", query))
+	return queryRows(ctx, q, query, args...)
+}
+
+// sampleRowids returns up to n distinct rowids of random rows of the table,
+// or nil if the table is too small to be worth it or has no rowid.
+func sampleRowids(ctx context.Context, q queryer, table string, n int) ([]interface{}, error) {
+	withoutRowid, err := isWithoutRowidTable(ctx, q, table)
+	if err != nil {
+		return nil, err
+	}
+	if withoutRowid {
+		return nil, nil
+	}
+
+	var lo, hi sql.NullInt64
+	query := fmt.Sprintf("SELECT min(rowid), max(rowid) FROM %s", quoteIdent(table))
+	if err := q.QueryRowContext(ctx, query).Scan(&lo, &hi); err != nil {
+		return nil, fmt.Errorf("error reading rowid range: %v", err)
+	}
+	if !lo.Valid || hi.Int64-lo.Int64 < sampleScanRows {
+		return nil, nil
+	}
+
+	next := fmt.Sprintf("SELECT rowid FROM %s WHERE rowid >= ? ORDER BY rowid LIMIT 1", quoteIdent(table))
+	seen := map[int64]bool{}
+	rowids := []interface{}{}
+	// Picking rows already sampled gets likelier as the sample grows, which
+	// is bounded by the number of attempts
+	for attempts := 0; len(rowids) < n && attempts < 3*n; attempts++ {
+		var rowid int64
+		err := q.QueryRowContext(ctx, next, lo.Int64+mathrand.Int63n(hi.Int64-lo.Int64+1)).Scan(&rowid)
+		if err != nil {
+			return nil, fmt.Errorf("error sampling rowid: %v", err)
+		}
+		if !seen[rowid] {
+			seen[rowid] = true
+			rowids = append(rowids, rowid)
+		}
+	}
+	return rowids, nil
+}
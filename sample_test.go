@@ -0,0 +1,74 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTableSample(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.MaxLimit = 5
+	})
+	defer close()
+
+	// The rowids span more than the range sorted by random()
+	_, err := ts.db.Exec(`
+		CREATE TABLE events (id INTEGER PRIMARY KEY, name TEXT);
+		WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 50)
+		INSERT INTO events SELECT i * 5000, 'event ' || i FROM n;
+	`)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	distinct := func(rows []map[string]interface{}) int {
+		ids := map[interface{}]bool{}
+		for _, row := range rows {
+			ids[row["id"]] = true
+		}
+		return len(ids)
+	}
+
+	t.Run("Small table", func(t *testing.T) {
+		res, err := ts.admin.GetTable(ctx, sqliteadmin.GetTableParams{TableName: "users", Sample: 3})
+		assert.NoError(t, err)
+		assert.Len(t, res.Rows, 3)
+		assert.Equal(t, 3, distinct(res.Rows))
+	})
+
+	t.Run("Large table", func(t *testing.T) {
+		res, err := ts.admin.GetTable(ctx, sqliteadmin.GetTableParams{TableName: "events", Sample: 4})
+		assert.NoError(t, err)
+		assert.Len(t, res.Rows, 4)
+		assert.Equal(t, 4, distinct(res.Rows))
+		for _, row := range res.Rows {
+			assert.Contains(t, row["name"], "event ")
+		}
+	})
+
+	t.Run("Condition", func(t *testing.T) {
+		res, err := ts.admin.GetTable(ctx, sqliteadmin.GetTableParams{
+			TableName: "events",
+			Sample:    3,
+			Condition: &sqliteadmin.Condition{Cases: []sqliteadmin.Case{
+				sqliteadmin.Filter{Column: "id", Operator: sqliteadmin.OperatorLessThan, Value: "15000"},
+			}},
+		})
+		assert.NoError(t, err)
+		assert.Len(t, res.Rows, 2)
+	})
+
+	t.Run("Clamped", func(t *testing.T) {
+		res, err := ts.admin.GetTable(ctx, sqliteadmin.GetTableParams{TableName: "events", Sample: 10})
+		assert.NoError(t, err)
+		assert.Len(t, res.Rows, 5)
+		assert.Equal(t, 5, res.Limit)
+	})
+
+	t.Run("With an offset", func(t *testing.T) {
+		_, err := ts.admin.GetTable(ctx, sqliteadmin.GetTableParams{TableName: "events", Sample: 3, Offset: 10})
+		assert.ErrorContains(t, err, "Bad request: invalid input")
+	})
+}
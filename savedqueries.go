@@ -0,0 +1,330 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// savedQueriesTable is where the default store persists saved queries, in the
+// default database.
+const savedQueriesTable = "_sqliteadmin_saved_queries"
+
+// SavedQuery is a named GetTable query shared between the users of the admin.
+type SavedQuery struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Database is the name of the database queried, the default one if empty.
+	Database  string                 `json:"database,omitempty"`
+	TableName string                 `json:"tableName"`
+	Condition map[string]interface{} `json:"condition,omitempty"`
+	OrderBy   OrderBy                `json:"orderBy,omitempty"`
+	Limit     int                    `json:"limit,omitempty"`
+	CreatedAt time.Time              `json:"createdAt"`
+}
+
+// SavedQueryStore persists saved queries. By default they are stored in a
+// table of the default database, set Config.SavedQueryStore to keep them
+// elsewhere.
+type SavedQueryStore interface {
+	ListSavedQueries(ctx context.Context) ([]SavedQuery, error)
+	// GetSavedQuery returns ErrUnknownSavedQuery if there is no query with
+	// the name.
+	GetSavedQuery(ctx context.Context, name string) (*SavedQuery, error)
+	// CreateSavedQuery returns ErrSavedQueryExists if there is already a
+	// query with the same name.
+	CreateSavedQuery(ctx context.Context, q SavedQuery) error
+	// DeleteSavedQuery returns ErrUnknownSavedQuery if there is no query with
+	// the name.
+	DeleteSavedQuery(ctx context.Context, name string) error
+}
+
+// dbSavedQueryStore stores saved queries in a table of the database.
+type dbSavedQueryStore struct {
+	db *sql.DB
+}
+
+func (s *dbSavedQueryStore) ListSavedQueries(ctx context.Context) ([]SavedQuery, error) {
+	exists, err := checkTableExists(ctx, s.db, savedQueriesTable)
+	if err != nil || !exists {
+		return []SavedQuery{}, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf("SELECT name, query FROM %s ORDER BY name", quoteIdent(savedQueriesTable)))
+	if err != nil {
+		return nil, fmt.Errorf("error querying saved queries: %v", err)
+	}
+	defer rows.Close()
+
+	queries := []SavedQuery{}
+	for rows.Next() {
+		var name, query string
+		if err := rows.Scan(&name, &query); err != nil {
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		var q SavedQuery
+		if err := json.Unmarshal([]byte(query), &q); err != nil {
+			return nil, fmt.Errorf("error decoding saved query %s: %v", name, err)
+		}
+		queries = append(queries, q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
+	return queries, nil
+}
+
+func (s *dbSavedQueryStore) GetSavedQuery(ctx context.Context, name string) (*SavedQuery, error) {
+	exists, err := checkTableExists(ctx, s.db, savedQueriesTable)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrUnknownSavedQuery
+	}
+
+	var query string
+	err = s.db.QueryRowContext(ctx, fmt.Sprintf("SELECT query FROM %s WHERE name = ?", quoteIdent(savedQueriesTable)), name).Scan(&query)
+	if err == sql.ErrNoRows {
+		return nil, ErrUnknownSavedQuery
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying saved query: %v", err)
+	}
+	var q SavedQuery
+	if err := json.Unmarshal([]byte(query), &q); err != nil {
+		return nil, fmt.Errorf("error decoding saved query %s: %v", name, err)
+	}
+	return &q, nil
+}
+
+func (s *dbSavedQueryStore) CreateSavedQuery(ctx context.Context, q SavedQuery) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		name TEXT PRIMARY KEY,
+		query TEXT NOT NULL
+	)`, quoteIdent(savedQueriesTable)))
+	if err != nil {
+		return fmt.Errorf("error creating saved queries table: %v", err)
+	}
+
+	b, err := json.Marshal(q)
+	if err != nil {
+		return err
+	}
+	res, err := s.db.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (name, query) VALUES (?, ?) ON CONFLICT DO NOTHING", quoteIdent(savedQueriesTable)), q.Name, string(b))
+	if err != nil {
+		return fmt.Errorf("error inserting saved query: %v", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrSavedQueryExists
+	}
+	return err
+}
+
+func (s *dbSavedQueryStore) DeleteSavedQuery(ctx context.Context, name string) error {
+	exists, err := checkTableExists(ctx, s.db, savedQueriesTable)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrUnknownSavedQuery
+	}
+
+	res, err := s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE name = ?", quoteIdent(savedQueriesTable)), name)
+	if err != nil {
+		return fmt.Errorf("error deleting saved query: %v", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrUnknownSavedQuery
+	}
+	return err
+}
+
+// getSavedQueryStore returns the store of saved queries, writing an error if
+// there is none.
+func (a *Admin) getSavedQueryStore(w http.ResponseWriter) (SavedQueryStore, bool) {
+	if a.savedQueries != nil {
+		return a.savedQueries, true
+	}
+	if a.db == nil {
+		writeError(w, apiErrBadRequest(ErrMissingDatabase.Error()))
+		return nil, false
+	}
+	return &dbSavedQueryStore{db: a.db}, true
+}
+
+func (a *Admin) listSavedQueries(ctx context.Context, w http.ResponseWriter) {
+	store, ok := a.getSavedQueryStore(w)
+	if !ok {
+		return
+	}
+
+	a.logger.Info("Command: ListSavedQueries")
+
+	queries, err := store.ListSavedQueries(ctx)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error listing saved queries: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+
+	allowed := []SavedQuery{}
+	for _, q := range queries {
+		if a.tableAllowed(q.TableName) {
+			allowed = append(allowed, q)
+		}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"queries": allowed})
+}
+
+func (a *Admin) createSavedQuery(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	store, ok := a.getSavedQueryStore(w)
+	if !ok {
+		return
+	}
+
+	q := SavedQuery{CreatedAt: time.Now().UTC()}
+	q.Name, _ = params["name"].(string)
+	if strings.TrimSpace(q.Name) == "" {
+		writeError(w, apiErrBadRequest(ErrMissingSavedQueryName.Error()))
+		return
+	}
+	q.TableName, ok = params["tableName"].(string)
+	if !ok || q.TableName == "" {
+		writeError(w, apiErrBadRequest(ErrMissingTableName.Error()))
+		return
+	}
+	q.Description, _ = params["description"].(string)
+	q.Database, _ = params["database"].(string)
+
+	// The query is validated now rather than when it is run
+	if params["condition"] != nil {
+		condition, ok := toCondition(params["condition"], a.logger)
+		if !ok {
+			writeError(w, apiErrBadRequest("Invalid condition"))
+			return
+		}
+		if exceeds(conditionDepth(condition), a.maxConditionDepth) {
+			writeError(w, apiErrBadRequest(ErrConditionTooDeep.Error()))
+			return
+		}
+		q.Condition, _ = params["condition"].(map[string]interface{})
+	}
+	if o, ok := params["orderBy"].(string); ok {
+		q.OrderBy = OrderBy(o)
+		if q.OrderBy != OrderByRelevance {
+			writeError(w, apiErrBadRequest(ErrInvalidOrderBy.Error()))
+			return
+		}
+	}
+	if params["limit"] != nil {
+		q.Limit, ok = convertNumber(params["limit"])
+		if !ok || q.Limit < 0 || exceeds(q.Limit, a.maxLimit) {
+			writeError(w, apiErrBadRequest(ErrLimitTooLarge.Error()))
+			return
+		}
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: CreateSavedQuery, name=%s, table=%s", q.Name, q.TableName))
+
+	err := store.CreateSavedQuery(ctx, q)
+	if err == ErrSavedQueryExists {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error saving query: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: saved query %s", q.Name))
+
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// runSavedQuery runs the saved query as a GetTable command. The params of the
+// request, e.g. offset or includeInfo, are added to the saved ones.
+func (a *Admin) runSavedQuery(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	store, ok := a.getSavedQueryStore(w)
+	if !ok {
+		return
+	}
+
+	name, ok := params["name"].(string)
+	if !ok || name == "" {
+		writeError(w, apiErrBadRequest(ErrMissingSavedQueryName.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: RunSavedQuery, name=%s", name))
+
+	q, err := store.GetSavedQuery(ctx, name)
+	if err == ErrUnknownSavedQuery {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error getting saved query: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+
+	tableParams := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		if k != "name" {
+			tableParams[k] = v
+		}
+	}
+	tableParams["tableName"] = q.TableName
+	if q.Database != "" {
+		tableParams["database"] = q.Database
+	}
+	if q.Condition != nil {
+		tableParams["condition"] = q.Condition
+	}
+	if q.OrderBy != "" {
+		tableParams["orderBy"] = string(q.OrderBy)
+	}
+	if q.Limit > 0 {
+		tableParams["limit"] = float64(q.Limit)
+	}
+
+	// The table may no longer be exposed since the query was saved
+	if !a.checkTableParams(w, tableParams) {
+		return
+	}
+	a.getTable(ctx, w, tableParams)
+}
+
+func (a *Admin) deleteSavedQuery(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	store, ok := a.getSavedQueryStore(w)
+	if !ok {
+		return
+	}
+
+	name, ok := params["name"].(string)
+	if !ok || name == "" {
+		writeError(w, apiErrBadRequest(ErrMissingSavedQueryName.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: DeleteSavedQuery, name=%s", name))
+
+	err := store.DeleteSavedQuery(ctx, name)
+	if err == ErrUnknownSavedQuery {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error deleting saved query: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: deleted saved query %s", name))
+
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
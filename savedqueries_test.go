@@ -0,0 +1,119 @@
+package sqliteadmin_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSavedQueries(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	condition := map[string]interface{}{
+		"cases": []interface{}{
+			map[string]interface{}{"column": "name", "operator": "like", "value": "%e%"},
+		},
+		"logicalOperator": "and",
+	}
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Missing Name",
+			params:         map[string]interface{}{"tableName": "users"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: missing saved query name",
+			},
+		},
+		{
+			name:           "Failure: Invalid Condition",
+			params:         map[string]interface{}{"name": "bad", "tableName": "users", "condition": "name = 1"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: Invalid condition",
+			},
+		},
+		{
+			name: "Success",
+			params: map[string]interface{}{
+				"name":        "names with e",
+				"description": "Users with an e in their name",
+				"tableName":   "users",
+				"condition":   condition,
+				"limit":       2,
+			},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok"},
+		},
+		{
+			name:           "Failure: Already Exists",
+			params:         map[string]interface{}{"name": "names with e", "tableName": "users"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: saved query already exists",
+			},
+		},
+	}, sqliteadmin.CreateSavedQuery, t, ts.server)
+
+	req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: sqliteadmin.ListSavedQueries})
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	queries := readBody(t, res.Body)["queries"].([]interface{})
+	assert.Len(t, queries, 1)
+	saved := queries[0].(map[string]interface{})
+	assert.Equal(t, "names with e", saved["name"])
+	assert.Equal(t, "Users with an e in their name", saved["description"])
+	assert.Equal(t, condition, saved["condition"])
+	assert.Equal(t, float64(2), saved["limit"])
+
+	runTestCases([]TestCase{
+		{
+			name:           "Success",
+			params:         map[string]interface{}{"name": "names with e"},
+			expectedStatus: http.StatusOK,
+			expectedResponse: makeGetTableResponse([]responseRow{
+				{id: 1, name: "Alice", email: "alice@gmail.com"},
+				{id: 3, name: "Charlie", email: "charlie@gmail.com"},
+			}),
+		},
+		{
+			name:           "Failure: Unknown Query",
+			params:         map[string]interface{}{"name": "unknown"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: unknown saved query",
+			},
+		},
+	}, sqliteadmin.RunSavedQuery, t, ts.server)
+
+	runTestCases([]TestCase{
+		{
+			name:             "Success",
+			params:           map[string]interface{}{"name": "names with e"},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok"},
+		},
+		{
+			name:           "Failure: Unknown Query",
+			params:         map[string]interface{}{"name": "names with e"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: unknown saved query",
+			},
+		},
+	}, sqliteadmin.DeleteSavedQuery, t, ts.server)
+}
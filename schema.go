@@ -0,0 +1,220 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// queryer is implemented by *sql.DB, *sql.Tx and *sql.Conn so that helpers can
+// be used both inside and outside of transactions.
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// foreignKeyRef is a column of another table referencing a table.
+type foreignKeyRef struct {
+	Table  string `json:"tableName" mapstructure:"tableName"`
+	Column string `json:"column" mapstructure:"column"`
+}
+
+// column is a column of a table as reported by PRAGMA table_info.
+type column struct {
+	CID          int
+	Name         string
+	DataType     string
+	NotNull      bool
+	DefaultValue interface{}
+	PK           int
+}
+
+// getColumns returns the columns of the table. It returns an error if the
+// table does not exist.
+func getColumns(ctx context.Context, q queryer, table string) ([]column, error) {
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", quoteIdent(table)))
+	if err != nil {
+		return nil, fmt.Errorf("error getting columns: %v", err)
+	}
+	defer rows.Close()
+
+	var columns []column
+	for rows.Next() {
+		var c column
+		if err := rows.Scan(&c.CID, &c.Name, &c.DataType, &c.NotNull, &c.DefaultValue, &c.PK); err != nil {
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		columns = append(columns, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
+
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("%s: %w", table, ErrUnknownTable)
+	}
+	return columns, nil
+}
+
+// getPrimaryKey returns the name of the primary key column of the table.
+func getPrimaryKey(ctx context.Context, q queryer, table string) (string, error) {
+	columns, err := getColumns(ctx, q, table)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range columns {
+		if c.PK == 1 {
+			return c.Name, nil
+		}
+	}
+	return "", fmt.Errorf("%s: %w", table, ErrNoPrimaryKey)
+}
+
+// getColumnNames returns the names of the columns of the table.
+func getColumnNames(ctx context.Context, q queryer, table string) ([]string, error) {
+	columns, err := getColumns(ctx, q, table)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+	}
+	return names, nil
+}
+
+// getColumnTypes returns the declared type of each column of the table.
+func getColumnTypes(ctx context.Context, q queryer, table string) (map[string]string, error) {
+	columns, err := getColumns(ctx, q, table)
+	if err != nil {
+		return nil, err
+	}
+	types := make(map[string]string, len(columns))
+	for _, c := range columns {
+		types[c.Name] = c.DataType
+	}
+	return types, nil
+}
+
+// getReferencingColumns returns the columns of all tables that have a foreign
+// key referencing the given column of table.
+func getReferencingColumns(ctx context.Context, q queryer, table, column string) ([]foreignKeyRef, error) {
+	tables, err := getTableNames(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []foreignKeyRef
+	for _, t := range tables {
+		fks, err := getForeignKeys(ctx, q, t)
+		if err != nil {
+			return nil, err
+		}
+		for _, fk := range fks {
+			// A foreign key without a target column references the primary key.
+			if fk.Table == table && (fk.To == column || fk.To == "") {
+				refs = append(refs, foreignKeyRef{Table: t, Column: fk.From})
+			}
+		}
+	}
+	return refs, nil
+}
+
+type foreignKey struct {
+	// ID groups the columns of composite foreign keys
+	ID    int
+	Table string
+	From  string
+	To    string
+	// OnDelete is the ON DELETE action, e.g. CASCADE or NO ACTION.
+	OnDelete string
+}
+
+// getForeignKeys returns the foreign keys declared on the table.
+func getForeignKeys(ctx context.Context, q queryer, table string) ([]foreignKey, error) {
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("PRAGMA foreign_key_list(%s)", quoteIdent(table)))
+	if err != nil {
+		return nil, fmt.Errorf("error getting foreign keys: %v", err)
+	}
+	defer rows.Close()
+
+	var fks []foreignKey
+	for rows.Next() {
+		var id, seq int
+		var refTable, from string
+		var to sql.NullString
+		var onUpdate, onDelete, match string
+		if err := rows.Scan(&id, &seq, &refTable, &from, &to, &onUpdate, &onDelete, &match); err != nil {
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		fks = append(fks, foreignKey{ID: id, Table: refTable, From: from, To: to.String, OnDelete: onDelete})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
+	return fks, nil
+}
+
+// isWithoutRowidTable reports whether the table is a WITHOUT ROWID table.
+func isWithoutRowidTable(ctx context.Context, q queryer, table string) (bool, error) {
+	var withoutRowid bool
+	err := q.QueryRowContext(ctx, "SELECT wr FROM pragma_table_list WHERE schema = 'main' AND name = ?", table).Scan(&withoutRowid)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error checking if table has a rowid: %v", err)
+	}
+	return withoutRowid, nil
+}
+
+// getTableNames returns the names of all the tables in the database.
+func getTableNames(ctx context.Context, q queryer) ([]string, error) {
+	rows, err := q.QueryContext(ctx, "SELECT name FROM sqlite_master WHERE type='table'")
+	if err != nil {
+		return nil, fmt.Errorf("error listing tables: %v", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		tables = append(tables, table)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
+	return tables, nil
+}
+
+// getRowByPrimaryKey returns the row of the table with the given primary key
+// value, or an error if it doesn't exist.
+func getRowByPrimaryKey(ctx context.Context, q queryer, table, primaryKey string, id interface{}) (map[string]interface{}, error) {
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("SELECT * FROM %s WHERE %s = ?", quoteIdent(table), quoteIdent(primaryKey)), id)
+	if err != nil {
+		return nil, fmt.Errorf("error querying row: %v", err)
+	}
+	defer rows.Close()
+
+	result, err := scanRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("row %v does not exist in table %s", id, table)
+	}
+	return result[0], nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
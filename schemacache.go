@@ -0,0 +1,155 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultSchemaCacheTTL is how long the columns of a table are cached when
+// Config.SchemaCacheTTL is zero.
+const DefaultSchemaCacheTTL = time.Minute
+
+type schemaKey struct {
+	db    *sql.DB
+	table string
+}
+
+type schemaEntry struct {
+	columns []ColumnInfo
+	// version is the schema_version of the database the columns were read
+	// at, which SQLite increments on every schema change.
+	version int64
+	loaded  time.Time
+}
+
+// schemaCache caches the columns of tables, which updates and deletes read to
+// find the primary key, so that they take one PRAGMA schema_version query
+// rather than reading and parsing the schema of the table every time.
+type schemaCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[schemaKey]schemaEntry
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+func newSchemaCache(ttl time.Duration) *schemaCache {
+	return &schemaCache{
+		ttl:     ttl,
+		entries: make(map[schemaKey]schemaEntry),
+	}
+}
+
+// columns returns the columns of the table of db, read with q which must be
+// a connection of db.
+func (c *schemaCache) columns(ctx context.Context, db *sql.DB, q queryer, table string) ([]ColumnInfo, error) {
+	var version int64
+	if err := q.QueryRowContext(ctx, "PRAGMA schema_version").Scan(&version); err != nil {
+		return nil, fmt.Errorf("error reading schema version: %v", err)
+	}
+	key := schemaKey{db: db, table: table}
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && entry.version == version && time.Since(entry.loaded) < c.ttl {
+		c.hits.Add(1)
+		return slices.Clone(entry.columns), nil
+	}
+	c.misses.Add(1)
+
+	columns, err := readTableColumns(ctx, q, table)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = schemaEntry{columns: columns, version: version, loaded: time.Now()}
+	// Expired entries are dropped along the way so that the tables which
+	// aren't used anymore don't pile up
+	for key, entry := range c.entries {
+		if time.Since(entry.loaded) >= c.ttl {
+			delete(c.entries, key)
+		}
+	}
+	return slices.Clone(columns), nil
+}
+
+// forget removes the entries of db, or of every database if db is nil.
+func (c *schemaCache) forget(db *sql.DB) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.entries {
+		if db == nil || key.db == db {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// SchemaCacheStats reports how effective the schema cache is.
+type SchemaCacheStats struct {
+	Size    int     `json:"size"`
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hitRate"`
+}
+
+func (c *schemaCache) stats() SchemaCacheStats {
+	if c == nil {
+		return SchemaCacheStats{}
+	}
+	c.mu.Lock()
+	size := len(c.entries)
+	c.mu.Unlock()
+
+	stats := SchemaCacheStats{
+		Size:   size,
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+// schemaCommands change the schema of tables. The version check would catch
+// their changes too, but not the ones rolled back after being cached within
+// a transaction, whose version may be reused by the next change.
+var schemaCommands = map[Command]bool{
+	AddCheckConstraint:  true,
+	DropCheckConstraint: true,
+	CreateFlagsTable:    true,
+	TruncateTable:       true,
+	CommitTransaction:   true,
+	RollbackTransaction: true,
+}
+
+// schemaCached is implemented by the queryers reading the columns of tables
+// through the schema cache.
+type schemaCached interface {
+	schemaCache() (*schemaCache, *sql.DB)
+}
+
+// getTableColumns returns the columns of a table, from the schema cache if q
+// has one.
+func getTableColumns(ctx context.Context, q queryer, table string) ([]ColumnInfo, error) {
+	if s, ok := q.(schemaCached); ok {
+		if cache, db := s.schemaCache(); cache != nil {
+			return cache.columns(ctx, db, q, table)
+		}
+	}
+	return readTableColumns(ctx, q, table)
+}
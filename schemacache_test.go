@@ -0,0 +1,58 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaCache(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+	ctx := context.Background()
+
+	update := func(row map[string]interface{}) error {
+		_, err := ts.admin.UpdateRow(ctx, sqliteadmin.UpdateRowParams{TableName: "users", Row: row})
+		return err
+	}
+
+	assert.NoError(t, update(map[string]interface{}{"id": "1", "name": "Alicia"}))
+	assert.NoError(t, update(map[string]interface{}{"id": "2", "name": "Bobby"}))
+	stats := ts.admin.Stats().SchemaCache
+	assert.Equal(t, 1, stats.Size)
+	assert.Equal(t, int64(1), stats.Misses)
+	assert.Equal(t, int64(1), stats.Hits)
+
+	// Changes to the schema made outside of the Admin are picked up
+	_, err := ts.db.Exec("ALTER TABLE users ADD COLUMN age INTEGER")
+	assert.NoError(t, err)
+	assert.NoError(t, update(map[string]interface{}{"id": "1", "age": 30}))
+	assert.Equal(t, int64(2), ts.admin.Stats().SchemaCache.Misses)
+
+	deleted, err := ts.admin.DeleteRows(ctx, sqliteadmin.DeleteRowsParams{TableName: "users", IDs: []string{"9"}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	assert.Equal(t, int64(2), ts.admin.Stats().SchemaCache.Hits)
+
+	users, err := getTableValues(ts.db, "users")
+	assert.NoError(t, err)
+	assert.Len(t, users, 8)
+	assert.Equal(t, "Alicia", users[0]["name"])
+	assert.Equal(t, int64(30), users[0]["age"])
+}
+
+func TestSchemaCacheDisabled(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.DisableSchemaCache = true
+	})
+	defer close()
+
+	_, err := ts.admin.UpdateRow(context.Background(), sqliteadmin.UpdateRowParams{
+		TableName: "users",
+		Row:       map[string]interface{}{"id": "1", "name": "Alicia"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, sqliteadmin.SchemaCacheStats{}, ts.admin.Stats().SchemaCache)
+}
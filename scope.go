@@ -0,0 +1,195 @@
+package sqliteadmin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/joelseq/sqliteadmin-go/querybuilder"
+)
+
+// Principal is the authenticated user making a request.
+type Principal struct {
+	User   string
+	Claims map[string]interface{}
+}
+
+// PrincipalFromContext returns the principal of the request being handled, or
+// nil if there is none.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey).(*Principal)
+	return p
+}
+
+// Placeholders of the filter values of row scopes, replaced by the user or a
+// claim of the principal making the request.
+const (
+	scopeUser        = "$user"
+	scopeClaimPrefix = "$claims."
+)
+
+// principal returns the principal of the request, from Config.Principal or
+// else the user that authenticated, with the claims of their OIDC token.
+func (a *Admin) principal(r *http.Request) *Principal {
+	if a.principalFunc != nil {
+		return a.principalFunc(r)
+	}
+	if user := UserFromContext(r.Context()); user != "" {
+		claims, _ := r.Context().Value(claimsKey).(map[string]interface{})
+		return &Principal{User: user, Claims: claims}
+	}
+	return nil
+}
+
+// rowScope returns the scope of the table for the principal of the request,
+// or nil if the table isn't scoped.
+func (a *Admin) rowScope(ctx context.Context, table string) (*Condition, error) {
+	scope, ok := a.rowScopes[strings.ToLower(table)]
+	if !ok {
+		return nil, nil
+	}
+	p := PrincipalFromContext(ctx)
+	if p == nil {
+		return nil, fmt.Errorf("%w: no principal", ErrOutOfScope)
+	}
+	return resolveScope(scope, p)
+}
+
+// scoped reports whether the rows of the table are scoped.
+func (a *Admin) scoped(table string) bool {
+	_, ok := a.rowScopes[strings.ToLower(table)]
+	return ok
+}
+
+// resolveScope returns a copy of the scope where the placeholders are
+// replaced by the values of the principal.
+func resolveScope(scope Condition, p *Principal) (*Condition, error) {
+	resolved := &Condition{LogicalOperator: scope.LogicalOperator}
+	for _, c := range scope.Cases {
+		switch c := c.(type) {
+		case Condition:
+			sub, err := resolveScope(c, p)
+			if err != nil {
+				return nil, err
+			}
+			resolved.Cases = append(resolved.Cases, *sub)
+		case Filter:
+			switch {
+			case c.Value == scopeUser:
+				c.Value = p.User
+			case strings.HasPrefix(c.Value, scopeClaimPrefix):
+				name := strings.TrimPrefix(c.Value, scopeClaimPrefix)
+				claim, ok := p.Claims[name]
+				if !ok || claim == nil {
+					return nil, fmt.Errorf("%w: missing claim %s", ErrOutOfScope, name)
+				}
+				c.Value = fmt.Sprint(claim)
+			}
+			resolved.Cases = append(resolved.Cases, c)
+		}
+	}
+	return resolved, nil
+}
+
+// scopeWhere returns the WHERE clause restricting a query to the scope, which
+// is empty when there is none.
+func scopeWhere(scope *Condition) (string, []interface{}) {
+	where, args := conditionExpr(scope).SQL()
+	if where == "" {
+		return "", nil
+	}
+	return " WHERE " + where, args
+}
+
+// scopeCondition returns the condition restricted to the scope.
+func scopeCondition(scope, condition *Condition) *Condition {
+	if condition == nil || len(condition.Cases) == 0 {
+		return scope
+	}
+	return &Condition{
+		Cases:           []Case{*scope, *condition},
+		LogicalOperator: LogicalOperatorAnd,
+	}
+}
+
+// keysInScope returns the primary keys of the rows with the given keys that
+// are in the scope.
+func keysInScope(ctx context.Context, q queryer, table, primaryKey string, keys []interface{}, scope *Condition) ([]interface{}, error) {
+	if len(keys) == 0 {
+		return []interface{}{}, nil
+	}
+	query, args := querybuilder.Select(primaryKey).
+		From(table).
+		Where(querybuilder.In(primaryKey, keys...)).
+		Where(conditionExpr(scope)).
+		Build()
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error checking scope: %v", err)
+	}
+	defer rows.Close()
+
+	inScope := []interface{}{}
+	for rows.Next() {
+		var key interface{}
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		inScope = append(inScope, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
+	return inScope, nil
+}
+
+// inScope reports whether the n rows with the given keys are all in the scope,
+// which is always the case when there is none.
+func inScope(ctx context.Context, q queryer, table, primaryKey string, keys []interface{}, n int, scope *Condition) (bool, error) {
+	if scope == nil {
+		return true, nil
+	}
+	inScope, err := keysInScope(ctx, q, table, primaryKey, keys, scope)
+	if err != nil {
+		return false, err
+	}
+	return len(inScope) == n, nil
+}
+
+// refsInScope reports whether the rows of ref referencing the key are all in
+// the scope, which is always the case when there is none.
+func refsInScope(ctx context.Context, q queryer, ref foreignKeyRef, key interface{}, scope *Condition) (bool, error) {
+	if scope == nil {
+		return true, nil
+	}
+	// Rows for which the scope is NULL are out of it as well
+	where, whereArgs := conditionExpr(scope).SQL()
+	query, args := querybuilder.SelectRaw("count(*)").
+		From(ref.Table).
+		Where(querybuilder.Eq(ref.Column, key)).
+		Where(querybuilder.Raw("NOT ifnull(("+where+"), 0)", whereArgs...)).
+		Build()
+	var outOfScope int
+	if err := q.QueryRowContext(ctx, query, args...).Scan(&outOfScope); err != nil {
+		return false, fmt.Errorf("error checking scope: %v", err)
+	}
+	return outOfScope == 0, nil
+}
+
+// writeInScopeError writes the error of inScope, or that the rows are out of
+// scope if err is nil.
+func (a *Admin) writeInScopeError(w http.ResponseWriter, err error) {
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error checking scope: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	writeError(w, apiErrForbidden(ErrOutOfScope.Error()))
+}
+
+// writeScopeError writes the error of rowScope.
+func (a *Admin) writeScopeError(w http.ResponseWriter, err error) {
+	a.logger.Info(fmt.Sprintf("Scope: %v", err))
+	writeError(w, apiErrForbidden(ErrOutOfScope.Error()))
+}
@@ -0,0 +1,174 @@
+package sqliteadmin_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRowScopes(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.RowScopes = map[string]sqliteadmin.Condition{
+			"users": {
+				Cases: []sqliteadmin.Case{
+					sqliteadmin.Filter{Column: "tenant", Operator: sqliteadmin.OperatorEquals, Value: "$claims.tenant"},
+				},
+				LogicalOperator: sqliteadmin.LogicalOperatorAnd,
+			},
+		}
+		c.Principal = func(r *http.Request) *sqliteadmin.Principal {
+			p := &sqliteadmin.Principal{User: "admin", Claims: map[string]interface{}{}}
+			if tenant := r.Header.Get("X-Tenant"); tenant != "" {
+				p.Claims["tenant"] = tenant
+			}
+			return p
+		}
+	})
+	defer close()
+
+	_, err := ts.db.Exec(`
+    ALTER TABLE users ADD COLUMN tenant INTEGER;
+    UPDATE users SET tenant = CASE WHEN id <= 3 THEN 1 ELSE 2 END;
+  `)
+	assert.NoError(t, err)
+
+	do := func(tenant string, command sqliteadmin.Command, params map[string]interface{}) (int, map[string]interface{}) {
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: command, Params: params})
+		if tenant != "" {
+			req.Header.Set("X-Tenant", tenant)
+		}
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res.StatusCode, readBody(t, res.Body)
+	}
+	names := func(body map[string]interface{}) []string {
+		var names []string
+		for _, r := range body["rows"].([]interface{}) {
+			names = append(names, r.(map[string]interface{})["name"].(string))
+		}
+		return names
+	}
+
+	status, body := do("", sqliteadmin.GetTable, map[string]interface{}{"tableName": "users"})
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, "Forbidden: rows are out of scope", body["message"])
+
+	status, body = do("1", sqliteadmin.GetTable, map[string]interface{}{"tableName": "users"})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []string{"Alice", "Bob", "Charlie"}, names(body))
+
+	// The scope is combined with the condition of the request
+	status, body = do("1", sqliteadmin.GetTable, map[string]interface{}{
+		"tableName": "users",
+		"condition": sqliteadmin.Condition{
+			Cases: []sqliteadmin.Case{
+				sqliteadmin.Filter{Column: "name", Operator: sqliteadmin.OperatorEquals, Value: "Alice"},
+				sqliteadmin.Filter{Column: "name", Operator: sqliteadmin.OperatorEquals, Value: "David"},
+			},
+			LogicalOperator: sqliteadmin.LogicalOperatorOr,
+		},
+	})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []string{"Alice"}, names(body))
+
+	status, _ = do("1", sqliteadmin.UpdateRow, map[string]interface{}{"tableName": "users", "row": map[string]interface{}{"id": 4, "name": "Mallory"}})
+	assert.Equal(t, http.StatusForbidden, status)
+	status, _ = do("1", sqliteadmin.UpdateRow, map[string]interface{}{"tableName": "users", "row": map[string]interface{}{"id": 1, "tenant": 2}})
+	assert.Equal(t, http.StatusForbidden, status)
+	status, _ = do("1", sqliteadmin.UpdateRow, map[string]interface{}{"tableName": "users", "row": map[string]interface{}{"id": 1, "name": "Alicia"}})
+	assert.Equal(t, http.StatusOK, status)
+
+	status, body = do("1", sqliteadmin.DeleteRows, map[string]interface{}{"tableName": "users", "ids": []interface{}{"2", "4"}})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "1", body["rowsAffected"])
+
+	rows, err := ts.db.Query("SELECT name, tenant FROM users WHERE id IN (1, 2, 4) ORDER BY id")
+	assert.NoError(t, err)
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var name string
+		var tenant int
+		assert.NoError(t, rows.Scan(&name, &tenant))
+		got = append(got, name)
+	}
+	assert.Equal(t, []string{"Alicia", "David"}, got)
+}
+
+func TestRowScopesAllCommands(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		scope := func(column string) sqliteadmin.Condition {
+			return sqliteadmin.Condition{
+				Cases: []sqliteadmin.Case{
+					sqliteadmin.Filter{Column: column, Operator: sqliteadmin.OperatorEquals, Value: "$claims.tenant"},
+				},
+				LogicalOperator: sqliteadmin.LogicalOperatorAnd,
+			}
+		}
+		// Tables are matched ignoring case
+		c.RowScopes = map[string]sqliteadmin.Condition{"Users": scope("tenant"), "ORDERS": scope("tenant")}
+		c.Principal = func(r *http.Request) *sqliteadmin.Principal {
+			return &sqliteadmin.Principal{User: "admin", Claims: map[string]interface{}{"tenant": "1"}}
+		}
+	})
+	defer close()
+
+	_, err := ts.db.Exec(`
+		ALTER TABLE users ADD COLUMN tenant INTEGER;
+		UPDATE users SET tenant = CASE WHEN id <= 3 THEN 1 ELSE 2 END;
+		CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users(id), tenant INTEGER);
+		INSERT INTO orders (user_id, tenant) VALUES (3, 1), (2, 2);
+	`)
+	assert.NoError(t, err)
+
+	do := func(command sqliteadmin.Command, params map[string]interface{}) (int, map[string]interface{}) {
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: command, Params: params})
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res.StatusCode, readBody(t, res.Body)
+	}
+
+	status, body := do(sqliteadmin.PreviewTable, map[string]interface{}{"tableName": "users"})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, float64(3), body["count"])
+	for _, part := range []string{"head", "tail", "sample"} {
+		for _, row := range body[part].([]interface{}) {
+			assert.Equal(t, float64(1), row.(map[string]interface{})["tenant"], part)
+		}
+	}
+
+	status, body = do(sqliteadmin.GetColumnStats, map[string]interface{}{"tableName": "users", "column": "tenant"})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, float64(3), body["count"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"value": float64(1), "count": float64(3)}}, body["topValues"])
+
+	status, _ = do(sqliteadmin.InsertRow, map[string]interface{}{"tableName": "USERS", "row": map[string]interface{}{"name": "Mallory", "tenant": 2}})
+	assert.Equal(t, http.StatusForbidden, status)
+
+	// Rows can only be merged when both of them and the rows referencing
+	// the merged one are in scope
+	status, _ = do(sqliteadmin.MergeRows, map[string]interface{}{"tableName": "users", "keepId": 1, "mergeId": 5})
+	assert.Equal(t, http.StatusForbidden, status)
+	status, _ = do(sqliteadmin.MergeRows, map[string]interface{}{"tableName": "users", "keepId": 1, "mergeId": 2})
+	assert.Equal(t, http.StatusForbidden, status)
+	status, _ = do(sqliteadmin.MergeRows, map[string]interface{}{"tableName": "users", "keepId": 1, "mergeId": 3, "fields": map[string]interface{}{"tenant": "keep"}})
+	assert.Equal(t, http.StatusOK, status)
+
+	// Commands working on whole tables are denied
+	for command, params := range map[sqliteadmin.Command]map[string]interface{}{
+		sqliteadmin.DiffTable:       {"tableName": "users", "sourceRows": []interface{}{}},
+		sqliteadmin.Subscribe:       {"tableName": "users"},
+		sqliteadmin.GetChangesSince: {"tableName": "users"},
+	} {
+		status, _ = do(command, params)
+		assert.Equal(t, http.StatusForbidden, status, command)
+	}
+
+	var users, orders int
+	assert.NoError(t, ts.db.QueryRow("SELECT COUNT(*) FROM users").Scan(&users))
+	assert.NoError(t, ts.db.QueryRow("SELECT COUNT(*) FROM orders WHERE user_id = 1").Scan(&orders))
+	assert.Equal(t, 8, users)
+	assert.Equal(t, 1, orders)
+}
@@ -0,0 +1,132 @@
+package sqliteadmin
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SecretScanMode decides what happens to writes containing values that look
+// like secrets.
+type SecretScanMode string
+
+const (
+	// SecretScanWarn logs the findings and returns them with the response.
+	SecretScanWarn SecretScanMode = "warn"
+	// SecretScanBlock rejects the write with a 400.
+	SecretScanBlock SecretScanMode = "block"
+)
+
+// SecretScanner inspects values written by InsertRow, UpdateRow and DiffTable
+// and returns the names of the rules matched by the value, if any.
+type SecretScanner interface {
+	ScanValue(table, column, value string) []string
+}
+
+// SecretRule is a named pattern of a PatternScanner.
+type SecretRule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// DefaultSecretRules match common API keys, tokens, private keys and
+// password assignments.
+var DefaultSecretRules = []SecretRule{
+	{Name: "aws access key", Pattern: regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{Name: "github token", Pattern: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{Name: "slack token", Pattern: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{Name: "stripe secret key", Pattern: regexp.MustCompile(`\b[rs]k_live_[A-Za-z0-9]{20,}`)},
+	{Name: "private key", Pattern: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+	{Name: "jwt", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{Name: "password", Pattern: regexp.MustCompile(`(?i)\b(password|passwd|pwd|secret|api[_-]?key)\s*[:=]\s*\S{6,}`)},
+}
+
+// PatternScanner is a SecretScanner matching values against regular
+// expressions.
+type PatternScanner struct {
+	Rules []SecretRule
+}
+
+// NewSecretScanner returns a PatternScanner with the DefaultSecretRules.
+func NewSecretScanner() *PatternScanner {
+	return &PatternScanner{Rules: DefaultSecretRules}
+}
+
+func (s *PatternScanner) ScanValue(table, column, value string) []string {
+	var matched []string
+	for _, rule := range s.Rules {
+		if rule.Pattern.MatchString(value) {
+			matched = append(matched, rule.Name)
+		}
+	}
+	return matched
+}
+
+// SecretFinding is a value that looks like a secret.
+type SecretFinding struct {
+	Column string `json:"column"`
+	Rule   string `json:"rule"`
+}
+
+// scanSecrets returns the values of the rows that look like secrets, ordered by
+// column.
+func (a *Admin) scanSecrets(table string, rows ...map[string]interface{}) []SecretFinding {
+	if a.secretScanner == nil {
+		return nil
+	}
+
+	seen := make(map[SecretFinding]bool)
+	var findings []SecretFinding
+	for _, row := range rows {
+		for column, value := range row {
+			s, ok := value.(string)
+			if !ok {
+				continue
+			}
+			for _, rule := range a.secretScanner.ScanValue(table, column, s) {
+				f := SecretFinding{Column: column, Rule: rule}
+				if !seen[f] {
+					seen[f] = true
+					findings = append(findings, f)
+				}
+			}
+		}
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Column != findings[j].Column {
+			return findings[i].Column < findings[j].Column
+		}
+		return findings[i].Rule < findings[j].Rule
+	})
+	return findings
+}
+
+// checkSecrets scans the rows about to be written to the table. It writes an
+// error and returns false if the write must be blocked, otherwise it returns
+// the findings to warn the client about.
+func (a *Admin) checkSecrets(w http.ResponseWriter, table string, rows ...map[string]interface{}) ([]SecretFinding, bool) {
+	findings := a.scanSecrets(table, rows...)
+	if len(findings) == 0 {
+		return nil, true
+	}
+
+	described := make([]string, len(findings))
+	for i, f := range findings {
+		described[i] = fmt.Sprintf("%s (%s)", f.Column, f.Rule)
+	}
+	message := fmt.Sprintf("possible secrets written to %s: %s", table, strings.Join(described, ", "))
+	a.logger.Info(fmt.Sprintf("Secret scan: %s", message))
+	a.notify(EventSecurityAlert, message, map[string]interface{}{
+		"table":    table,
+		"findings": findings,
+		"blocked":  a.secretScanMode == SecretScanBlock,
+	})
+
+	if a.secretScanMode == SecretScanBlock {
+		writeError(w, apiErrBadRequest(fmt.Sprintf("%s: %s", ErrSecretDetected, strings.Join(described, ", "))))
+		return nil, false
+	}
+	return findings, true
+}
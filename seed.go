@@ -0,0 +1,546 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	mathrand "math/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/joelseq/sqliteadmin-go/querybuilder"
+)
+
+// SeedTableParams are the params of SeedTable.
+type SeedTableParams struct {
+	Database  string `json:"database,omitempty" mapstructure:"database"`
+	TableName string `json:"tableName" mapstructure:"tableName"`
+	// Rows is the number of rows to generate.
+	Rows int `json:"rows" mapstructure:"rows"`
+	// Seed makes the generated values reproducible, they are random when
+	// nil.
+	Seed *int64 `json:"seed,omitempty" mapstructure:"seed"`
+}
+
+func (p *SeedTableParams) validate() error {
+	if p.TableName == "" {
+		return ErrMissingTableName
+	}
+	if p.Rows <= 0 {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+// seedTable inserts rows of fake data into a table for demos and local
+// development, with values guessed from the names and types of the columns,
+// e.g. emails for "email" columns and timestamps for "created_at". Unique
+// columns get distinct values and foreign keys reference existing rows of
+// their table, which must be seeded first. Like SeedFixtures, the rows don't
+// go through the column policies, validators or hooks, so it is only
+// available in development mode.
+func (a *Admin) seedTable(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	if !a.development {
+		writeError(w, apiErrForbidden(ErrSeedingDisabled.Error()))
+		return
+	}
+	var p SeedTableParams
+	if err := a.decodeParams(params, &p); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	if exceeds(p.Rows, a.maxLimit) {
+		writeError(w, apiErrBadRequest(ErrLimitTooLarge.Error()))
+		return
+	}
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return
+	}
+	table := p.TableName
+
+	a.logger.Info(fmt.Sprintf("Command: SeedTable, table=%s, rows=%d", table, p.Rows))
+
+	exists, err := checkTableExists(ctx, a.cached(db), table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error checking table existence: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if !exists || isInternalTable(table) {
+		writeError(w, apiErrTableNotFound())
+		return
+	}
+	if !a.checkNotShadowTable(ctx, w, a.cached(db), table) {
+		return
+	}
+	// Seeded rows can't be made to match the scope
+	if a.scoped(table) {
+		writeError(w, apiErrForbidden(ErrOutOfScope.Error()))
+		return
+	}
+
+	seed := time.Now().UnixNano()
+	if p.Seed != nil {
+		seed = *p.Seed
+	}
+	err = a.seed(ctx, db, table, p.Rows, seed)
+	if errors.Is(err, ErrNoParentRows) {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error seeding table: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: seeded %s with %d row(s)", table, p.Rows))
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "rowsInserted": p.Rows})
+}
+
+// seed inserts n generated rows into the table in a transaction.
+func (a *Admin) seed(ctx context.Context, db *sql.DB, table string, n int, seed int64) error {
+	tx, err := a.beginImmediateTx(ctx, db)
+	if err != nil {
+		return readOnlyError(fmt.Errorf("error starting transaction: %v", err))
+	}
+	defer tx.Rollback()
+
+	s, err := newSeeder(ctx, tx, table, mathrand.New(mathrand.NewSource(seed)))
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		row, err := s.row()
+		if err != nil {
+			return err
+		}
+		if _, err := insertRow(ctx, tx, "INSERT", table, row); err != nil {
+			return readOnlyError(err)
+		}
+	}
+	return readOnlyError(tx.Commit())
+}
+
+// seedColumn is a column of a table being seeded.
+type seedColumn struct {
+	name     string
+	dataType string
+	notNull  bool
+	unique   bool
+	enum     []string
+	// taken are the values of unique columns already used, as formatted by
+	// fmt.Sprint.
+	taken map[string]bool
+}
+
+// seedReference is a foreign key of a table being seeded, whose columns are
+// given the values of a random row of the referenced table.
+type seedReference struct {
+	table   string
+	columns []string
+	// parents are the values of the referenced columns of the rows of the
+	// referenced table.
+	parents  [][]interface{}
+	nullable bool
+	// unique references use each parent once.
+	unique bool
+}
+
+type seeder struct {
+	rand       *mathrand.Rand
+	now        time.Time
+	columns    []*seedColumn
+	references []*seedReference
+}
+
+func newSeeder(ctx context.Context, q queryer, table string, r *mathrand.Rand) (*seeder, error) {
+	s := &seeder{rand: r, now: time.Now()}
+
+	columns, err := getColumns(ctx, q, table)
+	if err != nil {
+		return nil, err
+	}
+	generated, err := getGeneratedColumns(ctx, q, table)
+	if err != nil {
+		return nil, err
+	}
+	enums, err := getEnumValues(ctx, q, table)
+	if err != nil {
+		return nil, err
+	}
+	unique, err := getUniqueColumns(ctx, q, table)
+	if err != nil {
+		return nil, err
+	}
+
+	pkColumns := 0
+	for _, c := range columns {
+		if c.PK > 0 {
+			pkColumns++
+		}
+	}
+	byName := map[string]*seedColumn{}
+	for _, c := range columns {
+		// The rowid is assigned by SQLite
+		if c.PK == 1 && pkColumns == 1 && strings.EqualFold(c.DataType, "INTEGER") {
+			continue
+		}
+		if _, ok := generated[c.Name]; ok {
+			continue
+		}
+		sc := &seedColumn{name: c.Name, dataType: strings.ToUpper(c.DataType), notNull: c.NotNull, unique: unique[c.Name], enum: enums[c.Name]}
+		if sc.unique {
+			sc.taken, err = distinctValues(ctx, q, table, c.Name)
+			if err != nil {
+				return nil, err
+			}
+		}
+		s.columns = append(s.columns, sc)
+		byName[c.Name] = sc
+	}
+
+	fks, err := getForeignKeys(ctx, q, table)
+	if err != nil {
+		return nil, err
+	}
+	grouped := map[int]*seedReference{}
+	targets := map[int][]string{}
+	var ids []int
+	for _, fk := range fks {
+		ref, ok := grouped[fk.ID]
+		if !ok {
+			ref = &seedReference{table: fk.Table, nullable: true, unique: true}
+			grouped[fk.ID] = ref
+			ids = append(ids, fk.ID)
+		}
+		ref.columns = append(ref.columns, fk.From)
+		targets[fk.ID] = append(targets[fk.ID], fk.To)
+	}
+	for _, id := range ids {
+		ref := grouped[id]
+		for _, c := range ref.columns {
+			sc, ok := byName[c]
+			if !ok {
+				continue
+			}
+			ref.nullable = ref.nullable && !sc.notNull
+			ref.unique = ref.unique && sc.unique
+		}
+		to := targets[id]
+		// References without target columns are to the primary key
+		if to[0] == "" {
+			to, err = getPrimaryKeyColumns(ctx, q, ref.table)
+			if err != nil {
+				return nil, err
+			}
+		}
+		notNull := make([]querybuilder.Expr, len(to))
+		for i, c := range to {
+			notNull[i] = querybuilder.IsNotNull(c)
+		}
+		query, args := querybuilder.Select(to...).From(ref.table).Where(querybuilder.And(notNull...)).Build()
+		rows, err := q.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("error reading referenced rows: %v", err)
+		}
+		for rows.Next() {
+			values := make([]interface{}, len(to))
+			pointers := make([]interface{}, len(to))
+			for i := range values {
+				pointers[i] = &values[i]
+			}
+			if err := rows.Scan(pointers...); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("error scanning row: %v", err)
+			}
+			ref.parents = append(ref.parents, values)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error reading rows: %v", err)
+		}
+		if len(ref.parents) == 0 && !ref.nullable {
+			return nil, fmt.Errorf("%w: %s", ErrNoParentRows, ref.table)
+		}
+		s.references = append(s.references, ref)
+	}
+	return s, nil
+}
+
+// row generates the values of a row.
+func (s *seeder) row() (map[string]interface{}, error) {
+	row := make(map[string]interface{}, len(s.columns))
+	first, last := pick(s.rand, firstNames), pick(s.rand, lastNames)
+	for _, c := range s.columns {
+		row[c.name] = s.uniqueValue(c, s.value(c, first, last))
+	}
+	for _, ref := range s.references {
+		var values []interface{}
+		if len(ref.parents) > 0 {
+			i := s.rand.Intn(len(ref.parents))
+			values = ref.parents[i]
+			if ref.unique {
+				ref.parents = append(ref.parents[:i], ref.parents[i+1:]...)
+			}
+		} else if !ref.nullable {
+			return nil, fmt.Errorf("%w: %s", ErrNoParentRows, ref.table)
+		}
+		for i, c := range ref.columns {
+			if values == nil {
+				row[c] = nil
+			} else {
+				row[c] = values[i]
+			}
+		}
+	}
+	return row, nil
+}
+
+// value generates a value for the column, first guessing what the column
+// holds from its name and then falling back to its type. first and last are
+// the name of the person the row is about, if any, so that the names and
+// emails of a row match.
+func (s *seeder) value(c *seedColumn, first, last string) interface{} {
+	if len(c.enum) > 0 {
+		return pick(s.rand, c.enum)
+	}
+	name := strings.ToLower(c.name)
+	affinity := typeAffinity(c.dataType)
+	switch {
+	case strings.Contains(c.dataType, "BOOL") || strings.HasPrefix(name, "is_") || strings.HasPrefix(name, "has_"):
+		return s.rand.Intn(2)
+	case strings.Contains(c.dataType, "DATE") || strings.Contains(c.dataType, "TIME") ||
+		strings.HasSuffix(name, "_at") || strings.Contains(name, "date"):
+		t := s.now.Add(-time.Duration(s.rand.Int63n(int64(365 * 24 * time.Hour)))).UTC().Truncate(time.Second)
+		switch {
+		case affinity == affinityInteger:
+			return t.Unix()
+		case c.dataType == "DATE" || (strings.Contains(name, "date") && !strings.Contains(c.dataType, "TIME")):
+			return t.Format(time.DateOnly)
+		}
+		return t.Format(time.DateTime)
+	case affinity == affinityInteger:
+		switch {
+		case strings.Contains(name, "age"):
+			return 18 + s.rand.Intn(72)
+		case strings.Contains(name, "year"):
+			return 1970 + s.rand.Intn(s.now.Year()-1969)
+		case strings.Contains(name, "count") || strings.Contains(name, "quantity") || strings.Contains(name, "qty"):
+			return s.rand.Intn(100)
+		}
+		return 1 + s.rand.Intn(1000)
+	case affinity == affinityReal || affinity == affinityNumeric:
+		return float64(s.rand.Intn(100000)) / 100
+	case affinity == affinityBlob:
+		b := make([]byte, 16)
+		s.rand.Read(b)
+		return b
+	}
+
+	switch {
+	case strings.Contains(name, "email"):
+		return strings.ToLower(first + "." + last + "@example.com")
+	case strings.Contains(name, "first") || strings.Contains(name, "given"):
+		return first
+	case strings.Contains(name, "last") || strings.Contains(name, "surname") || strings.Contains(name, "family"):
+		return last
+	case strings.Contains(name, "user") || strings.Contains(name, "login") || strings.Contains(name, "handle"):
+		return strings.ToLower(first) + fmt.Sprint(s.rand.Intn(1000))
+	case strings.Contains(name, "name") || strings.Contains(name, "author"):
+		return first + " " + last
+	case strings.Contains(name, "phone") || strings.Contains(name, "mobile"):
+		return fmt.Sprintf("+1-555-%03d-%04d", s.rand.Intn(1000), s.rand.Intn(10000))
+	case strings.Contains(name, "url") || strings.Contains(name, "website") || strings.Contains(name, "link"):
+		return "https://example.com/" + pick(s.rand, words)
+	case strings.Contains(name, "city"):
+		return pick(s.rand, cities)
+	case strings.Contains(name, "country"):
+		return pick(s.rand, countries)
+	case strings.Contains(name, "address") || strings.Contains(name, "street"):
+		return fmt.Sprintf("%d %s Street", 1+s.rand.Intn(999), capitalize(pick(s.rand, words)))
+	case strings.Contains(name, "uuid") || strings.Contains(name, "guid"):
+		return s.uuid()
+	case strings.Contains(name, "color") || strings.Contains(name, "colour"):
+		return pick(s.rand, colors)
+	case strings.Contains(name, "title") || strings.Contains(name, "subject") || strings.Contains(name, "label"):
+		return capitalize(s.words(2 + s.rand.Intn(3)))
+	case strings.Contains(name, "description") || strings.Contains(name, "body") || strings.Contains(name, "content") ||
+		strings.Contains(name, "text") || strings.Contains(name, "bio") || strings.Contains(name, "comment") ||
+		strings.Contains(name, "note") || strings.Contains(name, "summary"):
+		return capitalize(s.words(8+s.rand.Intn(8))) + "."
+	}
+	return s.words(1 + s.rand.Intn(2))
+}
+
+// uniqueValue returns the value, or a variation of it if the column is unique
+// and it was already used.
+func (s *seeder) uniqueValue(c *seedColumn, v interface{}) interface{} {
+	if !c.unique {
+		return v
+	}
+	candidate := v
+	for n := 2; c.taken[fmt.Sprint(candidate)]; n++ {
+		switch v := v.(type) {
+		case int:
+			candidate = v + n - 1
+		case int64:
+			candidate = v + int64(n) - 1
+		case float64:
+			candidate = v + float64(n-1)
+		case []byte:
+			b := make([]byte, len(v))
+			s.rand.Read(b)
+			candidate = b
+		case string:
+			// Emails stay valid
+			if local, domain, ok := strings.Cut(v, "@"); ok {
+				candidate = fmt.Sprintf("%s%d@%s", local, n, domain)
+			} else {
+				candidate = fmt.Sprintf("%s %d", v, n)
+			}
+		}
+	}
+	c.taken[fmt.Sprint(candidate)] = true
+	return candidate
+}
+
+func (s *seeder) words(n int) string {
+	w := make([]string, n)
+	for i := range w {
+		w[i] = pick(s.rand, words)
+	}
+	return strings.Join(w, " ")
+}
+
+func (s *seeder) uuid() string {
+	b := make([]byte, 16)
+	s.rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	h := hex.EncodeToString(b)
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+// Type affinities of columns, see https://www.sqlite.org/datatype3.html.
+const (
+	affinityInteger = "INTEGER"
+	affinityText    = "TEXT"
+	affinityBlob    = "BLOB"
+	affinityReal    = "REAL"
+	affinityNumeric = "NUMERIC"
+)
+
+// typeAffinity returns the affinity of a declared type following the rules of
+// SQLite, except that columns without a type are treated as text rather than
+// blobs.
+func typeAffinity(dataType string) string {
+	t := strings.ToUpper(dataType)
+	switch {
+	case strings.Contains(t, "INT"):
+		return affinityInteger
+	case t == "" || strings.Contains(t, "CHAR") || strings.Contains(t, "CLOB") || strings.Contains(t, "TEXT"):
+		return affinityText
+	case strings.Contains(t, "BLOB"):
+		return affinityBlob
+	case strings.Contains(t, "REAL") || strings.Contains(t, "FLOA") || strings.Contains(t, "DOUB"):
+		return affinityReal
+	}
+	return affinityNumeric
+}
+
+// getUniqueColumns returns the columns of the unique indexes of the table,
+// including the index of a primary key that isn't the rowid. The columns of
+// multi-column indexes are all considered unique, which keeps their
+// combination unique.
+func getUniqueColumns(ctx context.Context, q queryer, table string) (map[string]bool, error) {
+	rows, err := q.QueryContext(ctx, fmt.Sprintf(`SELECT i.name FROM pragma_index_list(%s) AS l, pragma_index_info(l.name) AS i WHERE l."unique" AND i.name IS NOT NULL`, quoteLiteral(table)))
+	if err != nil {
+		return nil, fmt.Errorf("error listing unique indexes: %v", err)
+	}
+	defer rows.Close()
+
+	unique := map[string]bool{}
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		unique[column] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
+	return unique, nil
+}
+
+// getPrimaryKeyColumns returns the columns of the primary key of the table in
+// order, or rowid if it doesn't declare one.
+func getPrimaryKeyColumns(ctx context.Context, q queryer, table string) ([]string, error) {
+	columns, err := getColumns(ctx, q, table)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i].PK < columns[j].PK })
+	var pk []string
+	for _, c := range columns {
+		if c.PK > 0 {
+			pk = append(pk, c.Name)
+		}
+	}
+	if len(pk) == 0 {
+		pk = []string{"rowid"}
+	}
+	return pk, nil
+}
+
+// distinctValues returns the values of the column, as formatted by fmt.Sprint.
+func distinctValues(ctx context.Context, q queryer, table, column string) (map[string]bool, error) {
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("SELECT DISTINCT %s FROM %s WHERE %s IS NOT NULL", quoteIdent(column), quoteIdent(table), quoteIdent(column)))
+	if err != nil {
+		return nil, fmt.Errorf("error reading values: %v", err)
+	}
+	defer rows.Close()
+
+	values := map[string]bool{}
+	for rows.Next() {
+		var v interface{}
+		if err := rows.Scan(&v); err != nil {
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		values[fmt.Sprint(v)] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
+	return values, nil
+}
+
+func pick(r *mathrand.Rand, values []string) string {
+	return values[r.Intn(len(values))]
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+var (
+	firstNames = []string{"Alice", "Bob", "Carol", "David", "Emma", "Frank", "Grace", "Henry", "Isabel", "Jack", "Kate", "Liam", "Maria", "Noah", "Olivia", "Paul", "Quinn", "Rosa", "Sam", "Tara", "Umar", "Vera", "Will", "Yuki", "Zoe"}
+	lastNames  = []string{"Anderson", "Brown", "Chen", "Davis", "Evans", "Fischer", "Garcia", "Hughes", "Ito", "Johnson", "Kim", "Lopez", "Martin", "Nguyen", "Okafor", "Patel", "Quinn", "Rossi", "Smith", "Taylor", "Usman", "Varga", "Wilson", "Young", "Zhang"}
+	cities     = []string{"Amsterdam", "Berlin", "Cairo", "Denver", "Edinburgh", "Florence", "Geneva", "Helsinki", "Istanbul", "Jakarta", "Kyoto", "Lisbon", "Montreal", "Nairobi", "Oslo", "Porto", "Quito", "Rome", "Seoul", "Toronto"}
+	countries  = []string{"Argentina", "Brazil", "Canada", "Denmark", "Egypt", "France", "Germany", "India", "Japan", "Kenya", "Mexico", "Norway", "Portugal", "Spain", "Sweden", "United Kingdom", "United States", "Vietnam"}
+	colors     = []string{"red", "orange", "yellow", "green", "blue", "indigo", "violet", "black", "white", "gray"}
+	words      = []string{"alpha", "amber", "anchor", "apple", "arch", "autumn", "beacon", "birch", "bloom", "breeze", "cedar", "cloud", "comet", "coral", "delta", "dune", "ember", "falcon", "fern", "forest", "glacier", "harbor", "harvest", "horizon", "island", "jade", "lagoon", "lantern", "maple", "meadow", "mist", "nova", "ocean", "orbit", "pebble", "pine", "prairie", "quartz", "raven", "river", "sage", "shadow", "summit", "thunder", "tide", "valley", "willow", "zephyr"}
+)
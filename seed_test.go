@@ -0,0 +1,131 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeedTable(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.Development = true
+	})
+	defer close()
+
+	_, err := ts.db.Exec(`
+		CREATE TABLE authors (
+			id INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			email TEXT NOT NULL UNIQUE,
+			created_at DATETIME
+		);
+		CREATE TABLE books (
+			id INTEGER PRIMARY KEY,
+			title TEXT NOT NULL,
+			status TEXT NOT NULL CHECK (status IN ('draft', 'published')),
+			author_id INTEGER NOT NULL REFERENCES authors(id),
+			price REAL
+		);
+	`)
+	assert.NoError(t, err)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: No rows to reference",
+			params:         map[string]interface{}{"tableName": "books", "rows": 5},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: no rows to reference in table: authors",
+			},
+		},
+		{
+			name:             "Success: Seed the referenced table",
+			params:           map[string]interface{}{"tableName": "authors", "rows": 50},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok", "rowsInserted": float64(50)},
+		},
+		{
+			name:             "Success: Seed the referencing table",
+			params:           map[string]interface{}{"tableName": "books", "rows": 20},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok", "rowsInserted": float64(20)},
+		},
+		{
+			name:           "Failure: Missing rows",
+			params:         map[string]interface{}{"tableName": "books"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid input",
+			},
+		},
+	}, sqliteadmin.SeedTable, t, ts.server)
+
+	authors, err := getTableValues(ts.db, "authors")
+	assert.NoError(t, err)
+	assert.Len(t, authors, 50)
+	emails := map[interface{}]bool{}
+	for _, a := range authors {
+		emails[a["email"]] = true
+		assert.Contains(t, a["email"], "@example.com")
+		assert.NotEmpty(t, a["name"])
+		assert.NotNil(t, a["created_at"])
+	}
+	assert.Len(t, emails, 50)
+
+	var orphans, invalid int
+	assert.NoError(t, ts.db.QueryRow(`SELECT count(*) FROM books WHERE author_id NOT IN (SELECT id FROM authors)`).Scan(&orphans))
+	assert.Equal(t, 0, orphans)
+	assert.NoError(t, ts.db.QueryRow(`SELECT count(*) FROM books WHERE status NOT IN ('draft', 'published')`).Scan(&invalid))
+	assert.Equal(t, 0, invalid)
+}
+
+func TestSeedTableReproducible(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.Development = true
+	})
+	defer close()
+
+	_, err := ts.db.Exec(`
+		CREATE TABLE first (id INTEGER PRIMARY KEY, name TEXT, age INTEGER);
+		CREATE TABLE second (id INTEGER PRIMARY KEY, name TEXT, age INTEGER);
+	`)
+	assert.NoError(t, err)
+
+	seed := int64(42)
+	for _, table := range []string{"first", "second"} {
+		n, err := ts.admin.SeedTable(context.Background(), sqliteadmin.SeedTableParams{TableName: table, Rows: 10, Seed: &seed})
+		assert.NoError(t, err)
+		assert.Equal(t, 10, n)
+	}
+	first, err := getTableValues(ts.db, "first")
+	assert.NoError(t, err)
+	second, err := getTableValues(ts.db, "second")
+	assert.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Contains(t, first[0]["name"], " ")
+}
+
+func TestSeedTableDisabled(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Not in Development Mode",
+			params:         map[string]interface{}{"tableName": "users", "rows": 5},
+			expectedStatus: http.StatusForbidden,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusForbidden),
+				"code":       "FORBIDDEN",
+				"message":    "Forbidden: tables can only be seeded in development mode",
+			},
+		},
+	}, sqliteadmin.SeedTable, t, ts.server)
+}
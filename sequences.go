@@ -0,0 +1,142 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+var autoincrement = regexp.MustCompile(`(?i)\bAUTOINCREMENT\b`)
+
+// getSequence returns the AUTOINCREMENT sequence of the table, the largest
+// rowid it handed out, or nil if the table doesn't use AUTOINCREMENT. The
+// sequence is 0 until a row is inserted.
+func getSequence(ctx context.Context, q queryer, table string) (*int64, error) {
+	var createSQL sql.NullString
+	err := q.QueryRowContext(ctx, "SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&createSQL)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error reading table schema: %v", err)
+	}
+	if !autoincrement.MatchString(createSQL.String) {
+		return nil, nil
+	}
+
+	// sqlite_sequence is created along with the first AUTOINCREMENT table
+	var sequence int64
+	err = q.QueryRowContext(ctx, "SELECT seq FROM sqlite_sequence WHERE name = ?", table).Scan(&sequence)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error reading sequence: %v", err)
+	}
+	return &sequence, nil
+}
+
+// ResetSequenceParams are the params of ResetSequence.
+type ResetSequenceParams struct {
+	Database  string `json:"database,omitempty" mapstructure:"database"`
+	TableName string `json:"tableName" mapstructure:"tableName"`
+	// Sequence is the new value of the sequence, so that the next row gets
+	// the rowid following it. It defaults to the largest rowid of the table.
+	Sequence *int64 `json:"sequence,omitempty" mapstructure:"sequence"`
+}
+
+func (p *ResetSequenceParams) validate() error {
+	if p.TableName == "" {
+		return ErrMissingTableName
+	}
+	if p.Sequence != nil && *p.Sequence < 0 {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+// resetSequence sets the AUTOINCREMENT sequence of a table, e.g. after bulk
+// deletes, so that the next rows get predictable IDs. The sequence can't be
+// set below the largest rowid of the table, which SQLite would skip anyway,
+// and like SeedFixtures it is only available in development mode.
+func (a *Admin) resetSequence(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	if !a.development {
+		writeError(w, apiErrForbidden(ErrSequencesDisabled.Error()))
+		return
+	}
+	var p ResetSequenceParams
+	if err := a.decodeParams(params, &p); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return
+	}
+	table := p.TableName
+
+	a.logger.Info(fmt.Sprintf("Command: ResetSequence, table=%s", table))
+
+	exists, err := checkTableExists(ctx, a.cached(db), table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error checking table existence: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if !exists || isInternalTable(table) {
+		writeError(w, apiErrTableNotFound())
+		return
+	}
+
+	sequence, err := a.setSequence(ctx, db, table, p.Sequence)
+	if err != nil {
+		if errors.Is(err, ErrNoSequence) || errors.Is(err, ErrSequenceTooLow) {
+			writeError(w, apiErrBadRequest(err.Error()))
+			return
+		}
+		a.logger.Error(fmt.Sprintf("Error resetting sequence: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: reset the sequence of %s to %d", table, sequence))
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "sequence": sequence})
+}
+
+// setSequence sets the sequence of the table in a transaction, to the largest
+// rowid of the table when sequence is nil, and returns its new value.
+func (a *Admin) setSequence(ctx context.Context, db *sql.DB, table string, sequence *int64) (int64, error) {
+	tx, err := a.beginImmediateTx(ctx, db)
+	if err != nil {
+		return 0, readOnlyError(fmt.Errorf("error starting transaction: %v", err))
+	}
+	defer tx.Rollback()
+
+	current, err := getSequence(ctx, tx, table)
+	if err != nil {
+		return 0, err
+	}
+	if current == nil {
+		return 0, fmt.Errorf("%s: %w", table, ErrNoSequence)
+	}
+	var maxRowID int64
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT coalesce(max(rowid), 0) FROM %s", quoteIdent(table))).Scan(&maxRowID); err != nil {
+		return 0, fmt.Errorf("error reading largest rowid: %v", err)
+	}
+	value := maxRowID
+	if sequence != nil {
+		if *sequence < maxRowID {
+			return 0, fmt.Errorf("%w (%d)", ErrSequenceTooLow, maxRowID)
+		}
+		value = *sequence
+	}
+
+	if err := resetTableSequence(ctx, tx, table); err != nil {
+		return 0, readOnlyError(err)
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO sqlite_sequence (name, seq) VALUES (?, ?)", table, value); err != nil {
+		return 0, readOnlyError(fmt.Errorf("error setting sequence: %v", err))
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, readOnlyError(err)
+	}
+	return value, nil
+}
@@ -0,0 +1,90 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResetSequence(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.Development = true
+	})
+	defer close()
+
+	_, err := ts.db.Exec(`
+		CREATE TABLE posts (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT);
+		INSERT INTO posts (title) VALUES ('First'), ('Second'), ('Third');
+		DELETE FROM posts WHERE id = 3;
+	`)
+	assert.NoError(t, err)
+
+	res, err := ts.admin.GetTable(context.Background(), sqliteadmin.GetTableParams{TableName: "posts", IncludeInfo: true})
+	assert.NoError(t, err)
+	if assert.NotNil(t, res.TableInfo.Sequence) {
+		assert.Equal(t, int64(3), *res.TableInfo.Sequence)
+	}
+	res, err = ts.admin.GetTable(context.Background(), sqliteadmin.GetTableParams{TableName: "users", IncludeInfo: true})
+	assert.NoError(t, err)
+	assert.Nil(t, res.TableInfo.Sequence)
+
+	runTestCases([]TestCase{
+		{
+			name:             "Success: Reset to Largest Rowid",
+			params:           map[string]interface{}{"tableName": "posts"},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok", "sequence": float64(2)},
+		},
+		{
+			name:             "Success: Set Sequence",
+			params:           map[string]interface{}{"tableName": "posts", "sequence": 100},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok", "sequence": float64(100)},
+		},
+		{
+			name:           "Failure: Below Largest Rowid",
+			params:         map[string]interface{}{"tableName": "posts", "sequence": 1},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: sequence cannot be lower than the largest rowid of the table (2)",
+			},
+		},
+		{
+			name:           "Failure: No AUTOINCREMENT",
+			params:         map[string]interface{}{"tableName": "users"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: users: table does not use AUTOINCREMENT",
+			},
+		},
+	}, sqliteadmin.ResetSequence, t, ts.server)
+
+	var id int64
+	assert.NoError(t, ts.db.QueryRow("INSERT INTO posts (title) VALUES ('Next') RETURNING id").Scan(&id))
+	assert.Equal(t, int64(101), id)
+}
+
+func TestResetSequenceDisabled(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Not in Development Mode",
+			params:         map[string]interface{}{"tableName": "users"},
+			expectedStatus: http.StatusForbidden,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusForbidden),
+				"code":       "FORBIDDEN",
+				"message":    "Forbidden: sequences can only be reset in development mode",
+			},
+		},
+	}, sqliteadmin.ResetSequence, t, ts.server)
+}
@@ -0,0 +1,186 @@
+package sqliteadmin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultSessionTTL is how long a session lasts when Config.SessionTTL is not
+// set.
+const DefaultSessionTTL = 12 * time.Hour
+
+const (
+	// SessionCookie is the name of the cookie holding the session ID.
+	SessionCookie = "sqliteadmin_session"
+	// CSRFHeader must be sent with the CSRF token of the session by requests
+	// authenticated with the session cookie.
+	CSRFHeader = "X-CSRF-Token"
+)
+
+type session struct {
+	identity
+	csrfToken string
+	expires   time.Time
+}
+
+// identity is who authenticated a request.
+type identity struct {
+	user   string
+	role   Role
+	claims map[string]interface{}
+	// commands restrict API keys to some of the commands of their role.
+	commands []Command
+}
+
+// identityFromContext returns the identity of the request being handled.
+func identityFromContext(ctx context.Context) identity {
+	claims, _ := ctx.Value(claimsKey).(map[string]interface{})
+	commands, _ := ctx.Value(commandsKey).([]Command)
+	return identity{user: UserFromContext(ctx), role: RoleFromContext(ctx), claims: claims, commands: commands}
+}
+
+// authenticate returns who made the request, authenticated either by the
+// Authorization header with the credentials of a user, an API key or an OIDC
+// bearer token, or by a session cookie with its CSRF token.
+func (a *Admin) authenticate(r *http.Request) (identity, bool) {
+	authorization := r.Header.Get("Authorization")
+	for _, u := range a.users {
+		if authorization == u.Username+":"+u.Password {
+			return identity{user: u.Username, role: u.Role}, true
+		}
+	}
+	if token, ok := strings.CutPrefix(authorization, "Bearer "); ok {
+		var id identity
+		var err error
+		switch {
+		case strings.HasPrefix(token, APIKeyPrefix):
+			id, err = a.authenticateAPIKey(r.Context(), token)
+		case a.oidc != nil:
+			var user User
+			user, id.claims, err = a.oidc.authenticate(r.Context(), token)
+			id.user, id.role = user.Username, user.Role
+		default:
+			err = ErrInvalidToken
+		}
+		if err != nil {
+			a.logger.Info(fmt.Sprintf("Rejected bearer token: %v", err))
+			return identity{}, false
+		}
+		return id, true
+	}
+	if !a.sessionsEnabled {
+		return identity{}, false
+	}
+
+	cookie, err := r.Cookie(SessionCookie)
+	if err != nil {
+		return identity{}, false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s, ok := a.sessions[cookie.Value]
+	if !ok {
+		return identity{}, false
+	}
+	if time.Now().After(s.expires) {
+		delete(a.sessions, cookie.Value)
+		return identity{}, false
+	}
+	// Cookies are sent by the browser with cross-site requests too, which is
+	// why the token, only readable by the UI, must also be sent.
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get(CSRFHeader)), []byte(s.csrfToken)) != 1 {
+		return identity{}, false
+	}
+	return s.identity, true
+}
+
+func isSecureRequest(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
+
+// login starts a session for the authenticated user, so that the UI doesn't
+// need to keep the credentials. The session ID is set as an HttpOnly cookie
+// and the CSRF token to send with each request is returned.
+func (a *Admin) login(w http.ResponseWriter, r *http.Request) {
+	if !a.sessionsEnabled {
+		writeError(w, apiErrBadRequest(ErrSessionsDisabled.Error()))
+		return
+	}
+
+	user := UserFromContext(r.Context())
+	a.logger.Info(fmt.Sprintf("Command: Login, user=%s", user))
+
+	// Sessions would outlive the revocation or the expiry of the bearer token
+	if strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		writeError(w, apiErrForbidden(ErrBearerSession.Error()))
+		return
+	}
+
+	id, err := newID()
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error generating session id: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	token, err := newID()
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error generating CSRF token: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+
+	now := time.Now()
+	expires := now.Add(a.sessionTTL)
+	a.mu.Lock()
+	// Expired sessions are removed when new ones are started
+	for id, s := range a.sessions {
+		if now.After(s.expires) {
+			delete(a.sessions, id)
+		}
+	}
+	a.sessions[id] = &session{identity: identityFromContext(r.Context()), csrfToken: token, expires: expires}
+	a.mu.Unlock()
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    id,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteStrictMode,
+	})
+	a.logger.Info(fmt.Sprintf("Audit: started session for %s", user))
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"csrfToken": token,
+		"expiresAt": expires.UTC(),
+	})
+}
+
+// logout ends the session of the request, if any.
+func (a *Admin) logout(w http.ResponseWriter, r *http.Request) {
+	a.logger.Info("Command: Logout")
+
+	if cookie, err := r.Cookie(SessionCookie); err == nil {
+		a.mu.Lock()
+		delete(a.sessions, cookie.Value)
+		a.mu.Unlock()
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
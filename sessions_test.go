@@ -0,0 +1,89 @@
+package sqliteadmin_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessions(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.Sessions = true
+	})
+	defer close()
+
+	res, err := http.DefaultClient.Do(makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: sqliteadmin.Login}))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	body := readBody(t, res.Body)
+	token, _ := body["csrfToken"].(string)
+	assert.NotEmpty(t, token)
+
+	var cookie *http.Cookie
+	for _, c := range res.Cookies() {
+		if c.Name == sqliteadmin.SessionCookie {
+			cookie = c
+		}
+	}
+	if !assert.NotNil(t, cookie) {
+		return
+	}
+	assert.True(t, cookie.HttpOnly)
+	assert.Equal(t, http.SameSiteStrictMode, cookie.SameSite)
+
+	sessionRequest := func(command sqliteadmin.Command, token string) *http.Response {
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: command})
+		req.Header.Del("Authorization")
+		req.AddCookie(cookie)
+		if token != "" {
+			req.Header.Set(sqliteadmin.CSRFHeader, token)
+		}
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res
+	}
+
+	t.Run("Cookie with CSRF token", func(t *testing.T) {
+		res := sessionRequest(sqliteadmin.Ping, token)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("Cookie without CSRF token", func(t *testing.T) {
+		res := sessionRequest(sqliteadmin.Ping, "")
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+
+	t.Run("Cookie with wrong CSRF token", func(t *testing.T) {
+		res := sessionRequest(sqliteadmin.Ping, "wrong")
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+
+	t.Run("Logout", func(t *testing.T) {
+		res := sessionRequest(sqliteadmin.Logout, token)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		res = sessionRequest(sqliteadmin.Ping, token)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
+
+func TestSessionsDisabled(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	cases := []TestCase{
+		{
+			name:           "Failure: Sessions Disabled",
+			params:         map[string]interface{}{},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: sessions are disabled",
+			},
+		},
+	}
+	runTestCases(cases, sqliteadmin.Login, t, ts.server)
+}
@@ -0,0 +1,55 @@
+package sqliteadmin
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// queryLog collects the SQL run by a command for the slow command log.
+type queryLog struct {
+	mu      sync.Mutex
+	queries []string
+}
+
+// recordQuery adds the query to the log of the request, if it has one.
+func recordQuery(ctx context.Context, query string) {
+	l, ok := ctx.Value(queryLogKey).(*queryLog)
+	if !ok {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.queries = append(l.queries, query)
+}
+
+// withQueryTimeout applies the query timeout to the context of the request.
+// Long polls are left alone since they are bounded by their own timeout.
+func (a *Admin) withQueryTimeout(r *http.Request, command Command) (*http.Request, context.CancelFunc) {
+	if a.queryTimeout <= 0 || command == WaitForChanges {
+		return r, func() {}
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), a.queryTimeout)
+	return r.WithContext(ctx), cancel
+}
+
+// logSlowCommand logs the command along with the SQL it ran if it took
+// longer than the slow command threshold.
+func (a *Admin) logSlowCommand(cr *CommandRequest, l *queryLog) {
+	duration := time.Since(a.request.start)
+	if duration < a.slowThreshold {
+		return
+	}
+	l.mu.Lock()
+	queries := append([]string(nil), l.queries...)
+	l.mu.Unlock()
+
+	table, _ := cr.Params["tableName"].(string)
+	a.logger.Info("Slow command",
+		"command", cr.Command,
+		"table", table,
+		"duration", duration,
+		"queries", queries,
+	)
+}
@@ -0,0 +1,81 @@
+package sqliteadmin_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlowQueryLog(t *testing.T) {
+	var logs bytes.Buffer
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.Logger = slog.New(slog.NewJSONHandler(&logs, nil))
+		c.SlowQueryThreshold = time.Nanosecond
+	})
+	defer close()
+
+	body := sqliteadmin.CommandRequest{
+		Command: sqliteadmin.GetTable,
+		Params:  map[string]interface{}{"tableName": "users", "limit": 3},
+	}
+	res, err := http.DefaultClient.Do(makeRequest(t, ts.server.URL, body))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	res.Body.Close()
+
+	var slow map[string]interface{}
+	dec := json.NewDecoder(&logs)
+	for dec.More() {
+		var line map[string]interface{}
+		assert.NoError(t, dec.Decode(&line))
+		if line["msg"] == "Slow command" {
+			slow = line
+		}
+	}
+
+	assert.NotNil(t, slow)
+	assert.Equal(t, "GetTable", slow["command"])
+	assert.Equal(t, "users", slow["table"])
+	assert.NotZero(t, slow["duration"])
+	queries, _ := slow["queries"].([]interface{})
+	assert.Contains(t, queries, `SELECT * FROM "users" LIMIT ? OFFSET ?`)
+}
+
+func TestQueryTimeout(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		// Expires before the first query
+		c.QueryTimeout = time.Nanosecond
+	})
+	defer close()
+
+	res, err := http.DefaultClient.Do(makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{
+		Command: sqliteadmin.GetTable,
+		Params:  map[string]interface{}{"tableName": "users"},
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	res.Body.Close()
+
+	// Long polls are bounded by their own timeout
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "app.db"))
+	assert.NoError(t, err)
+	defer db.Close()
+	ts.admin.AddDatabase("app", db)
+
+	res, err = http.DefaultClient.Do(makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{
+		Command: sqliteadmin.WaitForChanges,
+		Params:  map[string]interface{}{"database": "app", "timeout": 0.1},
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	body := readBody(t, res.Body)
+	assert.NotEmpty(t, body["version"])
+}
@@ -0,0 +1,362 @@
+package sqliteadmin
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultArchiveTable is the name of the table of SQLite archives, as created
+// by "sqlite3 -A".
+const DefaultArchiveTable = "sqlar"
+
+// defaultArchiveFileMode is the mode of uploaded files: a regular file
+// readable by everyone.
+const defaultArchiveFileMode = 0100644
+
+var archiveColumns = []string{"name", "mode", "mtime", "sz", "data"}
+
+// ArchiveFile is a file stored in an SQLite archive table.
+type ArchiveFile struct {
+	Name string `json:"name"`
+	Mode int64  `json:"mode"`
+	// MTime is the modification time in seconds since the Unix epoch.
+	MTime int64 `json:"mtime"`
+	// Size is the uncompressed size and CompressedSize the size stored.
+	Size           int64 `json:"size"`
+	CompressedSize int64 `json:"compressedSize"`
+}
+
+// isArchiveTable reports whether the table has the columns of an SQLite
+// archive.
+func isArchiveTable(ctx context.Context, q queryer, table string) (bool, error) {
+	exists, err := checkTableExists(ctx, q, table)
+	if err != nil || !exists {
+		return false, err
+	}
+	columns, err := getColumnNames(ctx, q, table)
+	if err != nil {
+		return false, err
+	}
+	return strings.Join(columns, ",") == strings.Join(archiveColumns, ","), nil
+}
+
+// getArchiveTable returns the archive table of the params, writing an error
+// if it isn't one.
+func (a *Admin) getArchiveTable(ctx context.Context, w http.ResponseWriter, q queryer, params map[string]interface{}) (string, bool) {
+	table := DefaultArchiveTable
+	if t, ok := params["tableName"].(string); ok && t != "" {
+		table = t
+	}
+	// The default table isn't among the params checked with the others
+	if !a.tableAllowed(table) {
+		a.logger.Info(fmt.Sprintf("Table not exposed: %s", table))
+		writeError(w, apiErrTableNotFound())
+		return "", false
+	}
+	// The files can't be filtered by the scope
+	if a.scoped(table) {
+		writeError(w, apiErrForbidden(ErrOutOfScope.Error()))
+		return "", false
+	}
+	ok, err := isArchiveTable(ctx, q, table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error checking archive table: %v", err))
+		writeError(w, a.apiErr(err))
+		return "", false
+	}
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrNotArchive.Error()))
+		return "", false
+	}
+	return table, true
+}
+
+func (a *Admin) listArchives(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getReadDB(w, params)
+	if !ok {
+		return
+	}
+
+	a.logger.Info("Command: ListArchives")
+
+	tables, err := getTableNames(ctx, a.cached(db))
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error listing tables: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	archives := []string{}
+	for _, t := range a.allowedTables(tables) {
+		if a.scoped(t) {
+			continue
+		}
+		ok, err := isArchiveTable(ctx, a.cached(db), t)
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Error checking archive table: %v", err))
+			writeError(w, a.apiErr(err))
+			return
+		}
+		if ok {
+			archives = append(archives, t)
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"archives": archives})
+}
+
+func (a *Admin) listArchiveFiles(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getReadDB(w, params)
+	if !ok {
+		return
+	}
+	table, ok := a.getArchiveTable(ctx, w, a.cached(db), params)
+	if !ok {
+		return
+	}
+	prefix, _ := params["prefix"].(string)
+
+	a.logger.Info(fmt.Sprintf("Command: ListArchiveFiles, table=%s, prefix=%s", table, prefix))
+
+	rows, err := a.cached(db).QueryContext(ctx, fmt.Sprintf(
+		"SELECT name, mode, mtime, sz, length(data) FROM %s WHERE substr(name, 1, length(?)) = ? ORDER BY name", quoteIdent(table)), prefix, prefix)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error listing archive files: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	defer rows.Close()
+
+	files := []ArchiveFile{}
+	for rows.Next() {
+		var f ArchiveFile
+		var compressed sql.NullInt64
+		if err := rows.Scan(&f.Name, &f.Mode, &f.MTime, &f.Size, &compressed); err != nil {
+			a.logger.Error(fmt.Sprintf("Error scanning rows: %v", err))
+			writeError(w, a.apiErr(err))
+			return
+		}
+		f.CompressedSize = compressed.Int64
+		files = append(files, f)
+	}
+	if err := rows.Err(); err != nil {
+		a.logger.Error(fmt.Sprintf("Error reading rows: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.recordRows(len(files))
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"files": files})
+}
+
+// decompressArchiveData returns the content of a file. As in the sqlar format,
+// data is zlib compressed unless it is as large as the file.
+func decompressArchiveData(data []byte, size int64) ([]byte, error) {
+	if int64(len(data)) >= size {
+		return data, nil
+	}
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	// The size bounds the output in case the data doesn't match it
+	content, err := io.ReadAll(io.LimitReader(r, size+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) != size {
+		return nil, fmt.Errorf("decompressed %d bytes, expected %d", len(content), size)
+	}
+	return content, nil
+}
+
+// compressArchiveData compresses the content of a file, keeping it as is if
+// compression doesn't make it smaller.
+func compressArchiveData(content []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(content); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	if buf.Len() >= len(content) {
+		return content, nil
+	}
+	return buf.Bytes(), nil
+}
+
+func (a *Admin) downloadArchiveFile(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getReadDB(w, params)
+	if !ok {
+		return
+	}
+	table, ok := a.getArchiveTable(ctx, w, a.cached(db), params)
+	if !ok {
+		return
+	}
+	name, ok := params["name"].(string)
+	if !ok || name == "" {
+		writeError(w, apiErrBadRequest(ErrMissingFileName.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: DownloadArchiveFile, table=%s, name=%s", table, name))
+
+	var f ArchiveFile
+	var data []byte
+	err := a.cached(db).QueryRowContext(ctx, fmt.Sprintf("SELECT name, mode, mtime, sz, data FROM %s WHERE name = ?", quoteIdent(table)), name).
+		Scan(&f.Name, &f.Mode, &f.MTime, &f.Size, &data)
+	if err == sql.ErrNoRows {
+		writeError(w, apiErrBadRequest(ErrUnknownFile.Error()))
+		return
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error reading archive file: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	f.CompressedSize = int64(len(data))
+
+	// Directories and symlinks have no data to decompress
+	content := data
+	if data != nil && f.Size >= 0 {
+		content, err = decompressArchiveData(data, f.Size)
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Error decompressing archive file: %v", err))
+			writeError(w, a.apiErr(err))
+			return
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"file": f,
+		"data": base64.StdEncoding.EncodeToString(content),
+	})
+}
+
+// uploadArchiveFile adds or replaces a file of the archive. The table is
+// created if it doesn't exist.
+func (a *Admin) uploadArchiveFile(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return
+	}
+
+	table := DefaultArchiveTable
+	if t, ok := params["tableName"].(string); ok && t != "" {
+		table = t
+	}
+	name, ok := params["name"].(string)
+	if !ok || name == "" {
+		writeError(w, apiErrBadRequest(ErrMissingFileName.Error()))
+		return
+	}
+	encoded, ok := params["data"].(string)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+		return
+	}
+	content, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+		return
+	}
+	mode := int64(defaultArchiveFileMode)
+	if params["mode"] != nil {
+		m, ok := convertNumber(params["mode"])
+		if !ok || m < 0 {
+			writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+			return
+		}
+		mode = int64(m)
+	}
+	mtime := time.Now().Unix()
+	if params["mtime"] != nil {
+		t, ok := convertNumber(params["mtime"])
+		if !ok {
+			writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+			return
+		}
+		mtime = int64(t)
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: UploadArchiveFile, table=%s, name=%s, size=%d", table, name, len(content)))
+
+	_, err = db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		name TEXT PRIMARY KEY,
+		mode INT,
+		mtime INT,
+		sz INT,
+		data BLOB
+	)`, quoteIdent(table)))
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error creating archive table: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if _, ok := a.getArchiveTable(ctx, w, db, map[string]interface{}{"tableName": table}); !ok {
+		return
+	}
+
+	data, err := compressArchiveData(content)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error compressing archive file: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	_, err = db.ExecContext(ctx, fmt.Sprintf("REPLACE INTO %s (name, mode, mtime, sz, data) VALUES (?, ?, ?, ?, ?)", quoteIdent(table)),
+		name, mode, mtime, len(content), data)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error writing archive file: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: uploaded %s to archive %s", name, table))
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"file": ArchiveFile{Name: name, Mode: mode, MTime: mtime, Size: int64(len(content)), CompressedSize: int64(len(data))},
+	})
+}
+
+func (a *Admin) deleteArchiveFile(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return
+	}
+	table, ok := a.getArchiveTable(ctx, w, db, params)
+	if !ok {
+		return
+	}
+	name, ok := params["name"].(string)
+	if !ok || name == "" {
+		writeError(w, apiErrBadRequest(ErrMissingFileName.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: DeleteArchiveFile, table=%s, name=%s", table, name))
+
+	res, err := db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE name = ?", quoteIdent(table)), name)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error deleting archive file: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		writeError(w, apiErrBadRequest(ErrUnknownFile.Error()))
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: deleted %s from archive %s", name, table))
+
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
@@ -0,0 +1,193 @@
+package sqliteadmin_test
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchives(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Not An Archive",
+			params:         map[string]interface{}{"tableName": "users"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: table is not an SQLite archive",
+			},
+		},
+	}, sqliteadmin.ListArchiveFiles, t, ts.server)
+
+	readme := strings.Repeat("hello sqlar\n", 100)
+	runTestCases([]TestCase{
+		{
+			name: "Success: Compressed",
+			params: map[string]interface{}{
+				"name":  "docs/README.md",
+				"data":  base64.StdEncoding.EncodeToString([]byte(readme)),
+				"mtime": 1700000000,
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"file": map[string]interface{}{
+					"name": "docs/README.md", "mode": float64(0100644), "mtime": float64(1700000000),
+					"size": float64(len(readme)), "compressedSize": float64(31),
+				},
+			},
+		},
+		{
+			name: "Success: Stored",
+			params: map[string]interface{}{
+				"name":  "logo.bin",
+				"data":  base64.StdEncoding.EncodeToString([]byte{1, 2, 3}),
+				"mode":  0100600,
+				"mtime": 1700000000,
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"file": map[string]interface{}{
+					"name": "logo.bin", "mode": float64(0100600), "mtime": float64(1700000000),
+					"size": float64(3), "compressedSize": float64(3),
+				},
+			},
+		},
+	}, sqliteadmin.UploadArchiveFile, t, ts.server)
+
+	// The data is stored in the sqlar format so that other tools can read it
+	var size, stored int
+	err := ts.db.QueryRow("SELECT sz, length(data) FROM sqlar WHERE name = 'docs/README.md'").Scan(&size, &stored)
+	assert.NoError(t, err)
+	assert.Equal(t, len(readme), size)
+	assert.Less(t, stored, size)
+
+	runTestCases([]TestCase{
+		{
+			name:             "Success",
+			params:           map[string]interface{}{},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"archives": []interface{}{"sqlar"}},
+		},
+	}, sqliteadmin.ListArchives, t, ts.server)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Success: Prefix",
+			params:         map[string]interface{}{"prefix": "docs/"},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"files": []interface{}{
+					map[string]interface{}{
+						"name": "docs/README.md", "mode": float64(0100644), "mtime": float64(1700000000),
+						"size": float64(len(readme)), "compressedSize": float64(31),
+					},
+				},
+			},
+		},
+	}, sqliteadmin.ListArchiveFiles, t, ts.server)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Success",
+			params:         map[string]interface{}{"name": "docs/README.md"},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"file": map[string]interface{}{
+					"name": "docs/README.md", "mode": float64(0100644), "mtime": float64(1700000000),
+					"size": float64(len(readme)), "compressedSize": float64(31),
+				},
+				"data": base64.StdEncoding.EncodeToString([]byte(readme)),
+			},
+		},
+		{
+			name:           "Failure: Unknown File",
+			params:         map[string]interface{}{"name": "missing"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: unknown file",
+			},
+		},
+	}, sqliteadmin.DownloadArchiveFile, t, ts.server)
+
+	runTestCases([]TestCase{
+		{
+			name:             "Success",
+			params:           map[string]interface{}{"name": "logo.bin"},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok"},
+		},
+		{
+			name:           "Failure: Unknown File",
+			params:         map[string]interface{}{"name": "logo.bin"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: unknown file",
+			},
+		},
+	}, sqliteadmin.DeleteArchiveFile, t, ts.server)
+}
+
+func TestArchivesHiddenTables(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.ExcludeTables = []string{"sqlar"}
+		c.RowScopes = map[string]sqliteadmin.Condition{
+			"backups": {Cases: []sqliteadmin.Case{sqliteadmin.Filter{Column: "name", Operator: sqliteadmin.OperatorEquals, Value: "$user"}}},
+		}
+	})
+	defer close()
+
+	for _, table := range []string{"sqlar", "backups", "docs"} {
+		_, err := ts.db.Exec("CREATE TABLE " + table + " (name TEXT PRIMARY KEY, mode INT, mtime INT, sz INT, data BLOB)")
+		assert.NoError(t, err)
+	}
+
+	runTestCases([]TestCase{
+		{
+			name:             "Success",
+			params:           map[string]interface{}{},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"archives": []interface{}{"docs"}},
+		},
+	}, sqliteadmin.ListArchives, t, ts.server)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Excluded Default Table",
+			params:         map[string]interface{}{},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "TABLE_NOT_FOUND",
+				"message":    "Bad request: unknown table",
+			},
+		},
+		{
+			name:           "Failure: Scoped Table",
+			params:         map[string]interface{}{"tableName": "backups"},
+			expectedStatus: http.StatusForbidden,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusForbidden),
+				"code":       "FORBIDDEN",
+				"message":    "Forbidden: " + sqliteadmin.ErrOutOfScope.Error(),
+			},
+		},
+		{
+			name:             "Success",
+			params:           map[string]interface{}{"tableName": "docs"},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"files": []interface{}{}},
+		},
+	}, sqliteadmin.ListArchiveFiles, t, ts.server)
+}
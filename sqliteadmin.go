@@ -5,16 +5,106 @@
 package sqliteadmin
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
 	"net/http"
+	"sync"
+	"time"
 )
 
 type Admin struct {
-	db       *sql.DB
-	username string
-	password string
-	logger   Logger
+	db     *sql.DB
+	readDB *sql.DB
+	// users are the accounts allowed to authenticate by username, nil when
+	// there are none, and oidc verifies bearer tokens when configured.
+	users  map[string]User
+	oidc   *oidcVerifier
+	logger Logger
+
+	notifiers map[EventType][]Notifier
+	stmts     *stmtCache
+	schemas   *schemaCache
+	versions  *versionWatcher
+	timeZone  *time.Location
+	compress  bool
+
+	largeIntegers IntegerEncoding
+	timeFormat    string
+
+	hideInternalTables bool
+	includeTables      []string
+	excludeTables      []string
+
+	maxBodySize       int64
+	maxDeleteIDs      int
+	maxLimit          int
+	defaultLimit      int
+	maxConditionDepth int
+
+	logRequests   bool
+	queryTimeout  time.Duration
+	busyTimeout   time.Duration
+	writes        *writeQueue
+	showDetails   bool
+	slowThreshold time.Duration
+	viewURL       string
+	savedQueries  SavedQueryStore
+
+	secretScanner  SecretScanner
+	secretScanMode SecretScanMode
+	hooks          Hooks
+
+	columnPolicies   ColumnPolicies
+	anonymizationKey []byte
+	elevated         func(r *http.Request) bool
+
+	rowScopes     map[string]Condition
+	principalFunc func(r *http.Request) *Principal
+
+	keyProvider KeyProvider
+	canUnseal   func(r *http.Request) bool
+
+	sessionsEnabled bool
+	sessionTTL      time.Duration
+
+	webSocketOrigins []string
+	public           *publicViewer
+
+	development bool
+	fixtures    fs.FS
+
+	artifactKey []byte
+	artifactTTL time.Duration
+	// request is only set on the per-request copies made by HandlePost.
+	request *requestInfo
+
+	// The state below is shared with the per-request copies, which is why
+	// the maps are created up front and the mutex is a pointer.
+	mu      *sync.RWMutex
+	dbs     map[string]*sql.DB
+	actions map[string]map[string]Action
+
+	operations        map[string]Operation
+	operationSessions map[string]*operationSession
+	subscriptions     map[string]*subscription
+	webhooks          map[string]Webhook
+	validators        map[string][]Validator
+	transforms        map[string]map[string]ColumnTransform
+	ttlColumns        map[string]string
+	ttlDeleted        map[string]int64
+	ttlLastSweep      *time.Time
+	sessions          map[string]*session
+	snapshots         map[string]*tableSnapshot
+	transactions      map[string]*transactionSession
+	truncations       map[string]truncation
+	artifacts         map[string]artifact
+	jobs              map[string]*Job
+	inFlight          map[*InFlightCommand]struct{}
 }
 
 type Command string
@@ -61,14 +151,95 @@ const (
 	OperatorGreaterThanOrEquals Operator = "gte"
 	OperatorIsNull              Operator = "null"
 	OperatorIsNotNull           Operator = "notnull"
+	// OperatorMatch runs a full-text search on FTS tables
+	OperatorMatch Operator = "match"
 )
 
+type OrderBy string
+
 const (
-	Ping       Command = "Ping"
-	ListTables Command = "ListTables"
-	GetTable   Command = "GetTable"
-	DeleteRows Command = "DeleteRows"
-	UpdateRow  Command = "UpdateRow"
+	// OrderByRelevance puts the best matches of the search filters first
+	OrderByRelevance OrderBy = "relevance"
+)
+
+const (
+	Ping                 Command = "Ping"
+	ListTables           Command = "ListTables"
+	GetTable             Command = "GetTable"
+	DeleteRows           Command = "DeleteRows"
+	UpdateRow            Command = "UpdateRow"
+	InsertRow            Command = "InsertRow"
+	MergeRows            Command = "MergeRows"
+	ListActions          Command = "ListActions"
+	RunAction            Command = "RunAction"
+	ListDatabases        Command = "ListDatabases"
+	GetStats             Command = "GetStats"
+	ListOperations       Command = "ListOperations"
+	StartOperation       Command = "StartOperation"
+	SubmitOperationStep  Command = "SubmitOperationStep"
+	CommitOperation      Command = "CommitOperation"
+	CancelOperation      Command = "CancelOperation"
+	PreviewTable         Command = "PreviewTable"
+	Subscribe            Command = "Subscribe"
+	ListSubscriptions    Command = "ListSubscriptions"
+	Unsubscribe          Command = "Unsubscribe"
+	TrackChanges         Command = "TrackChanges"
+	GetChangesSince      Command = "GetChangesSince"
+	GetColumnStats       Command = "GetColumnStats"
+	ListWebhooks         Command = "ListWebhooks"
+	AddWebhook           Command = "AddWebhook"
+	RemoveWebhook        Command = "RemoveWebhook"
+	TestWebhook          Command = "TestWebhook"
+	DiffTable            Command = "DiffTable"
+	AddCheckConstraint   Command = "AddCheckConstraint"
+	DropCheckConstraint  Command = "DropCheckConstraint"
+	ListTriggers         Command = "ListTriggers"
+	CreateTrigger        Command = "CreateTrigger"
+	DropTrigger          Command = "DropTrigger"
+	GetFlag              Command = "GetFlag"
+	SetFlag              Command = "SetFlag"
+	CreateFlagsTable     Command = "CreateFlagsTable"
+	ListSavedQueries     Command = "ListSavedQueries"
+	CreateSavedQuery     Command = "CreateSavedQuery"
+	RunSavedQuery        Command = "RunSavedQuery"
+	DeleteSavedQuery     Command = "DeleteSavedQuery"
+	GetColumnPolicies    Command = "GetColumnPolicies"
+	ListArchives         Command = "ListArchives"
+	ListArchiveFiles     Command = "ListArchiveFiles"
+	DownloadArchiveFile  Command = "DownloadArchiveFile"
+	UploadArchiveFile    Command = "UploadArchiveFile"
+	DeleteArchiveFile    Command = "DeleteArchiveFile"
+	RotateEncryptionKeys Command = "RotateEncryptionKeys"
+	Login                Command = "Login"
+	Logout               Command = "Logout"
+	WaitForChanges       Command = "WaitForChanges"
+	GetChanges           Command = "GetChanges"
+	BeginTransaction     Command = "BeginTransaction"
+	CommitTransaction    Command = "CommitTransaction"
+	RollbackTransaction  Command = "RollbackTransaction"
+	CopyRows             Command = "CopyRows"
+	JoinTables           Command = "JoinTables"
+	SeedFixtures         Command = "SeedFixtures"
+	TruncateTable        Command = "TruncateTable"
+	GetPoolStats         Command = "GetPoolStats"
+	ResetSequence        Command = "ResetSequence"
+	FindDuplicates       Command = "FindDuplicates"
+	DeleteDuplicates     Command = "DeleteDuplicates"
+	FindOrphans          Command = "FindOrphans"
+	GetDatabaseStats     Command = "GetDatabaseStats"
+	IncrementalVacuum    Command = "IncrementalVacuum"
+	UpdateCell           Command = "UpdateCell"
+	InsertRows           Command = "InsertRows"
+	SeedTable            Command = "SeedTable"
+	AnonymizeTable       Command = "AnonymizeTable"
+	CloneDatabase        Command = "CloneDatabase"
+	GetJob               Command = "GetJob"
+	GetTableWindow       Command = "GetTableWindow"
+	ListExtensions       Command = "ListExtensions"
+	WhoAmI               Command = "WhoAmI"
+	CreateAPIKey         Command = "CreateAPIKey"
+	ListAPIKeys          Command = "ListAPIKeys"
+	RevokeAPIKey         Command = "RevokeAPIKey"
 )
 
 const pathPrefixPlaceholder = "%%__path_prefix__%%"
@@ -92,25 +263,372 @@ const (
 )
 
 type Config struct {
-	DB       *sql.DB
+	DB *sql.DB
+	// Username and Password are the credentials of an admin. Requests are
+	// only authenticated when they or Users are set.
 	Username string
 	Password string
-	Logger   Logger
+	// Users are the accounts allowed to authenticate, each with a role
+	// restricting the commands they can run.
+	Users []User
+	// OIDC authenticates the users of an OpenID Connect provider with their
+	// bearer tokens, in addition to Users. The claims of the tokens are
+	// those of the principal for RowScopes.
+	OIDC   *OIDCConfig
+	Logger Logger
+	// Connector is used to open the database when DB is not set. This allows
+	// connecting to databases that are not local files, e.g. a libSQL/Turso
+	// database through the connector from
+	// github.com/tursodatabase/libsql-client-go/libsql.
+	Connector driver.Connector
+	// ReadDB is an optional read-only connection pool to the same database as
+	// DB (e.g. opened with mode=ro or the query_only pragma). When set, it is
+	// used for every command that only reads data, which reduces contention
+	// with writers and makes accidental writes from read paths impossible.
+	ReadDB *sql.DB
+	// Notifiers are notified about the events of each type, e.g. failed
+	// authentication attempts for EventSecurityAlert.
+	Notifiers map[EventType][]Notifier
+	// TimeZone is used to display timestamp columns and to interpret date
+	// filters without an offset. It can be overridden per request with the
+	// "timeZone" param. When unset timestamps are returned as stored.
+	TimeZone *time.Location
+	// LargeIntegers is how the integers of rows beyond MaxSafeInteger, which
+	// JavaScript clients would round, are encoded. Defaults to
+	// IntegersAsNumbers.
+	LargeIntegers IntegerEncoding
+	// TimeFormat is the layout, as for time.Time.Format, of the timestamps of
+	// rows. Defaults to time.RFC3339Nano.
+	TimeFormat string
+	// HideInternalTables leaves the tables of SQLite (sqlite_*) and of the
+	// admin itself (_sqliteadmin_*) out of ListTables.
+	HideInternalTables bool
+	// IncludeTables are the only tables exposed when set, and ExcludeTables
+	// are never exposed, given as names or globs as for path.Match (e.g.
+	// "tenant_*"), matched ignoring case. Other tables are left out of
+	// ListTables and reported as unknown to the commands naming them.
+	IncludeTables []string
+	ExcludeTables []string
+	// Databases are additional named databases that clients can select with
+	// the "database" param. More can be added later with AddDatabase.
+	Databases map[string]*sql.DB
+	// Compress enables gzip/deflate compression of the responses for clients
+	// that send a matching Accept-Encoding header.
+	Compress bool
+	// MaxBodySize is the maximum size in bytes of a request body, larger
+	// requests are rejected with a 413. Defaults to DefaultMaxBodySize.
+	MaxBodySize int64
+	// MaxDeleteIDs is the maximum number of ids accepted by DeleteRows.
+	// Defaults to DefaultMaxDeleteIDs.
+	MaxDeleteIDs int
+	// MaxLimit is the maximum number of rows returned by GetTable, except for
+	// streamed responses, larger limits being clamped to it. Other commands
+	// reject larger limits. Defaults to DefaultMaxLimit.
+	MaxLimit int
+	// DefaultLimit is the number of rows returned when a command is sent
+	// without a limit. Defaults to the DefaultLimit constant.
+	DefaultLimit int
+	// MaxConditionDepth is the maximum nesting of sub-conditions accepted by
+	// GetTable. Defaults to DefaultMaxConditionDepth.
+	//
+	// Setting any of these limits to a negative value disables it.
+	MaxConditionDepth int
+	// LogRequests logs a summary of every command once it has been handled:
+	// the command, table, duration, number of rows returned and outcome, as
+	// structured key/value attributes (e.g. for a *slog.Logger).
+	LogRequests bool
+	// LogSampling limits how often identical errors are logged, e.g. during
+	// an incident where every request fails the same way. Disabled when nil.
+	LogSampling *LogSampling
+	// QueryTimeout bounds how long the queries of a command can run, except
+	// for WaitForChanges which has its own timeout. Disabled when zero.
+	QueryTimeout time.Duration
+	// BusyTimeout is how long mutations wait for the locks held by other
+	// connections, e.g. while the application is writing, before failing
+	// with a 409. Defaults to DefaultBusyTimeout, a negative value disables
+	// waiting.
+	BusyTimeout time.Duration
+	// WriteQueueTimeout is how long a mutating command waits for the ones
+	// of other requests, which are run one at a time, before failing with a
+	// 409. Defaults to DefaultWriteQueueTimeout, a negative value waits until
+	// the request is canceled.
+	WriteQueueTimeout time.Duration
+	// ShowErrorDetails includes the message of database errors in the error
+	// responses, which can disclose the schema to clients, e.g. to debug
+	// them during development. Only the error code is included by default.
+	ShowErrorDetails bool
+	// SlowQueryThreshold logs the commands taking longer than it, along
+	// with their duration and the SQL they ran through the statement cache.
+	// Disabled when zero.
+	SlowQueryThreshold time.Duration
+	// ViewURL is the URL of the UI, used to link to the matching rows in
+	// subscription notifications.
+	ViewURL string
+	// TTLColumns maps tables of the default database to the column holding
+	// the expiration time of their rows, see SetTTLColumn. Expired rows are
+	// deleted by SweepExpired or RunTTLSweeper.
+	TTLColumns map[string]string
+	// SavedQueryStore persists the queries saved with CreateSavedQuery. When
+	// unset they are stored in a table of the default database.
+	SavedQueryStore SavedQueryStore
+	// SecretScanner inspects the values written by UpdateRow, MergeRows and
+	// DiffTable for things like API keys and passwords, e.g.
+	// NewSecretScanner(). What happens to matching writes is decided by
+	// SecretScanMode, which defaults to SecretScanWarn. Scanning is disabled
+	// when nil.
+	SecretScanner  SecretScanner
+	SecretScanMode SecretScanMode
+	// Hooks are called before and after rows are updated or deleted.
+	Hooks Hooks
+	// ColumnPolicies are the redaction, anonymization, normalization and
+	// allowed values rules of columns, see ColumnPolicy. They are listed by
+	// the GetColumnPolicies command.
+	ColumnPolicies ColumnPolicies
+	// AnonymizationKey keys the pseudonyms of anonymized columns. When unset
+	// a random key is used, so pseudonyms change when the process restarts.
+	AnonymizationKey []byte
+	// MaskedColumns are "table.column" patterns of sensitive columns, e.g.
+	// "users.password_hash" or "*.ssn" for the column of every table. They
+	// are added to ColumnPolicies with Mask set.
+	MaskedColumns []string
+	// Elevated reports whether the user making the request has an elevated
+	// role, which is required to update masked columns.
+	Elevated func(r *http.Request) bool
+	// RowScopes restrict the rows of tables, keyed by table name ignoring
+	// case, that the commands reading or writing rows can access to the
+	// ones matching a condition. Filter values can refer to the principal making the request with
+	// "$user" or "$claims.<name>", e.g. a "tenant_id" filter with the value
+	// "$claims.tenant". Requests without a principal or the claim are denied.
+	RowScopes map[string]Condition
+	// Principal returns the principal making the request, whose claims are
+	// used by RowScopes. Defaults to the authenticated user without claims.
+	Principal func(r *http.Request) *Principal
+	// EncryptedColumns are "table.column" patterns of columns whose values
+	// are encrypted, added to ColumnPolicies with Encrypt set. The keys are
+	// provided by KeyProvider.
+	EncryptedColumns []string
+	KeyProvider      KeyProvider
+	// CanUnseal reports whether the user making the request may see the
+	// decrypted values of encrypted columns and rotate their keys.
+	CanUnseal func(r *http.Request) bool
+	// Sessions lets cookie based UIs exchange the credentials for a session
+	// with the Login command. The session cookie is only accepted along with
+	// the CSRF token returned by Login in the X-CSRF-Token header. Sessions
+	// last SessionTTL, which defaults to DefaultSessionTTL.
+	Sessions   bool
+	SessionTTL time.Duration
+	// WebSocketOrigins are the origins, other than the one of the handler,
+	// allowed to open WebSocket connections with HandleWebSocket. Patterns
+	// like "*.example.com" are supported.
+	WebSocketOrigins []string
+	// PublicTables are the tables HandlePublic exposes read-only to anyone,
+	// keyed by table name. Nothing is exposed when it is empty. Column
+	// policies still apply to the rows.
+	PublicTables map[string]PublicTable
+	// PublicRateLimit is the number of requests per minute a client address
+	// can make to HandlePublic, DefaultPublicRateLimit when zero. A negative
+	// value disables the limit.
+	PublicRateLimit int
+	// PublicCacheTTL is how long the responses of HandlePublic are cached,
+	// DefaultPublicCacheTTL when zero.
+	PublicCacheTTL time.Duration
+	// Development enables the commands meant for development and staging
+	// instances only, like SeedFixtures, SeedTable, AnonymizeTable and
+	// ResetSequence. It must not be set in production.
+	Development bool
+	// Fixtures holds the fixture sets loaded by SeedFixtures, each in a JSON
+	// file named after the set (e.g. "demo.json"), see FixtureTable. They can
+	// be embedded or read from a directory with os.DirFS.
+	Fixtures fs.FS
+	// ArtifactKey signs the download URLs of the files published with
+	// PublishArtifact. When unset a random key is used, so the URLs stop
+	// working when the process restarts. ArtifactTTL is how long the URLs
+	// stay valid, DefaultArtifactTTL when zero.
+	ArtifactKey []byte
+	ArtifactTTL time.Duration
+	// SchemaCacheTTL is how long the columns of tables are cached for the
+	// commands updating and deleting rows, DefaultSchemaCacheTTL when zero.
+	// The cached columns are read again as soon as the schema_version of
+	// the database changes, or after the commands changing the schema.
+	// DisableSchemaCache reads them on every command instead.
+	SchemaCacheTTL     time.Duration
+	DisableSchemaCache bool
 }
 
 // Returns a *Admin which has a HandlePost method that can be used to handle
 // requests from https://sqliteadmin.dev.
 func New(c Config) *Admin {
 	h := &Admin{
-		db:       c.DB,
-		username: c.Username,
-		password: c.Password,
-		logger:   c.Logger,
+		db:     c.DB,
+		readDB: c.ReadDB,
+		logger: c.Logger,
+
+		notifiers: c.Notifiers,
+		stmts:     newStmtCache(),
+		versions:  newVersionWatcher(),
+		timeZone:  c.TimeZone,
+		compress:  c.Compress,
+
+		largeIntegers: c.LargeIntegers,
+		timeFormat:    c.TimeFormat,
+
+		hideInternalTables: c.HideInternalTables,
+		includeTables:      c.IncludeTables,
+		excludeTables:      c.ExcludeTables,
+
+		maxBodySize:       limitOrDefault(c.MaxBodySize, DefaultMaxBodySize),
+		maxDeleteIDs:      limitOrDefault(c.MaxDeleteIDs, DefaultMaxDeleteIDs),
+		maxLimit:          limitOrDefault(c.MaxLimit, DefaultMaxLimit),
+		defaultLimit:      limitOrDefault(c.DefaultLimit, DefaultLimit),
+		maxConditionDepth: limitOrDefault(c.MaxConditionDepth, DefaultMaxConditionDepth),
+
+		logRequests:   c.LogRequests,
+		queryTimeout:  c.QueryTimeout,
+		busyTimeout:   c.BusyTimeout,
+		writes:        newWriteQueue(c.WriteQueueTimeout),
+		showDetails:   c.ShowErrorDetails,
+		slowThreshold: c.SlowQueryThreshold,
+		viewURL:       c.ViewURL,
+
+		savedQueries: c.SavedQueryStore,
+
+		secretScanner:  c.SecretScanner,
+		secretScanMode: c.SecretScanMode,
+		hooks:          c.Hooks,
+
+		columnPolicies:   c.ColumnPolicies,
+		anonymizationKey: c.AnonymizationKey,
+		elevated:         c.Elevated,
+
+		rowScopes:     foldTableKeys(c.RowScopes),
+		principalFunc: c.Principal,
+
+		keyProvider: c.KeyProvider,
+		canUnseal:   c.CanUnseal,
+
+		sessionsEnabled: c.Sessions,
+		sessionTTL:      c.SessionTTL,
+
+		webSocketOrigins: c.WebSocketOrigins,
+		public:           newPublicViewer(c),
+
+		development: c.Development,
+		fixtures:    c.Fixtures,
+
+		artifactKey: c.ArtifactKey,
+		artifactTTL: c.ArtifactTTL,
+
+		mu:                &sync.RWMutex{},
+		dbs:               make(map[string]*sql.DB),
+		actions:           make(map[string]map[string]Action),
+		operations:        make(map[string]Operation),
+		operationSessions: make(map[string]*operationSession),
+		subscriptions:     make(map[string]*subscription),
+		webhooks:          make(map[string]Webhook),
+		validators:        make(map[string][]Validator),
+		transforms:        make(map[string]map[string]ColumnTransform),
+		ttlColumns:        make(map[string]string),
+		sessions:          make(map[string]*session),
+		snapshots:         make(map[string]*tableSnapshot),
+		transactions:      make(map[string]*transactionSession),
+		truncations:       make(map[string]truncation),
+		artifacts:         make(map[string]artifact),
+		jobs:              make(map[string]*Job),
+		inFlight:          make(map[*InFlightCommand]struct{}),
+		ttlDeleted:        make(map[string]int64),
 	}
 
 	if h.logger == nil {
 		h.logger = &defaultLogger{}
 	}
+	if h.busyTimeout == 0 {
+		h.busyTimeout = DefaultBusyTimeout
+	}
+	// Requests without a limit must not be rejected for exceeding MaxLimit
+	if h.maxLimit >= 0 && (h.defaultLimit < 0 || h.defaultLimit > h.maxLimit) {
+		h.defaultLimit = h.maxLimit
+	}
+	if len(c.Hooks.Webhooks) > 0 {
+		// Copied so that the map of the Config is left untouched
+		notifiers := make(map[EventType][]Notifier, len(h.notifiers)+3)
+		for eventType, n := range h.notifiers {
+			notifiers[eventType] = append([]Notifier(nil), n...)
+		}
+		for _, url := range c.Hooks.Webhooks {
+			n := &WebhookNotifier{URL: url}
+			notifiers[EventRowsInserted] = append(notifiers[EventRowsInserted], n)
+			notifiers[EventRowsUpdated] = append(notifiers[EventRowsUpdated], n)
+			notifiers[EventRowsDeleted] = append(notifiers[EventRowsDeleted], n)
+		}
+		h.notifiers = notifiers
+	}
+	if h.sessionTTL <= 0 {
+		h.sessionTTL = DefaultSessionTTL
+	}
+	if len(h.anonymizationKey) == 0 {
+		h.anonymizationKey = newKey()
+	}
+	if len(h.artifactKey) == 0 {
+		h.artifactKey = newKey()
+	}
+	if h.artifactTTL <= 0 {
+		h.artifactTTL = DefaultArtifactTTL
+	}
+	if !c.DisableSchemaCache {
+		ttl := c.SchemaCacheTTL
+		if ttl <= 0 {
+			ttl = DefaultSchemaCacheTTL
+		}
+		h.schemas = newSchemaCache(ttl)
+	}
+	if len(c.MaskedColumns) > 0 {
+		policies, err := h.columnPolicies.withColumns(c.MaskedColumns, func(p *ColumnPolicy) { p.Mask = true })
+		if err != nil {
+			h.logger.Error(fmt.Sprintf("Invalid masked columns: %v", err))
+		}
+		h.columnPolicies = policies
+	}
+	if len(c.EncryptedColumns) > 0 {
+		policies, err := h.columnPolicies.withColumns(c.EncryptedColumns, func(p *ColumnPolicy) { p.Encrypt = true })
+		if err != nil {
+			h.logger.Error(fmt.Sprintf("Invalid encrypted columns: %v", err))
+		}
+		h.columnPolicies = policies
+	}
+	users, err := newUsers(c)
+	if err != nil {
+		h.logger.Error(fmt.Sprintf("Invalid users: %v", err))
+	}
+	h.users = users
+	if c.OIDC != nil {
+		h.oidc = newOIDCVerifier(*c.OIDC)
+	}
+	h.columnPolicies = h.columnPolicies.folded()
+	if err := h.columnPolicies.Validate(); err != nil {
+		h.logger.Error(fmt.Sprintf("Invalid column policies: %v", err))
+	}
+	if h.secretScanMode == "" {
+		h.secretScanMode = SecretScanWarn
+	}
+	if c.LogSampling != nil {
+		h.logger = newSamplingLogger(h.logger, *c.LogSampling)
+	}
+
+	if h.db == nil && c.Connector != nil {
+		h.db = sql.OpenDB(c.Connector)
+	}
+
+	for table, column := range c.TTLColumns {
+		h.SetTTLColumn(table, column)
+	}
+
+	if err := h.loadWebhooks(context.Background()); err != nil {
+		h.logger.Error(fmt.Sprintf("Error loading webhooks: %v", err))
+	}
+
+	for name, db := range c.Databases {
+		h.AddDatabase(name, db)
+	}
 
 	return h
 }
@@ -125,37 +643,326 @@ type CommandRequest struct {
 func (a *Admin) HandlePost(w http.ResponseWriter, r *http.Request) {
 	// Check for auth header that contains username and password
 	w.Header().Set("Content-Type", "application/json")
-	if a.username != "" && a.password != "" {
-		authHeader := r.Header.Get("Authorization")
-		if a.username+":"+a.password != authHeader {
+	if a.compress {
+		var done func()
+		w, done = compressResponse(w, r)
+		defer done()
+	}
+	a, w, r = a.startRequest(w, r)
+
+	var cr CommandRequest
+	if a.logRequests {
+		defer a.logRequest(&cr)
+	}
+
+	if a.authEnabled() {
+		id, ok := a.authenticate(r)
+		if !ok {
+			a.notify(EventSecurityAlert, "Failed authentication attempt", map[string]interface{}{
+				"remoteAddr": r.RemoteAddr,
+			})
 			writeError(w, apiErrUnauthorized())
 			return
 		}
+		r = a.withRequestValue(r, userKey, id.user)
+		r = a.withRequestValue(r, roleKey, id.role)
+		if id.claims != nil {
+			r = a.withRequestValue(r, claimsKey, id.claims)
+		}
+		if id.commands != nil {
+			r = a.withRequestValue(r, commandsKey, id.commands)
+		}
+	}
+	if a.elevated != nil && a.elevated(r) {
+		r = a.withRequestValue(r, elevatedKey, true)
+	}
+	if a.canUnseal != nil && a.canUnseal(r) {
+		r = a.withRequestValue(r, unsealKey, true)
+	}
+	if p := a.principal(r); p != nil {
+		r = a.withRequestValue(r, principalKey, p)
+	}
+
+	if a.maxBodySize >= 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, a.maxBodySize)
 	}
 
-	var cr CommandRequest
 	err := json.NewDecoder(r.Body).Decode(&cr)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		writeError(w, apiErrRequestTooLarge())
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid Request Body"})
 		return
 	}
+	a.handleCommand(w, r, &cr)
+}
+
+// handleCommand runs the command of an authenticated request.
+func (a *Admin) handleCommand(w http.ResponseWriter, r *http.Request, cr *CommandRequest) {
+	r = a.withRequestValue(r, commandKey, cr.Command)
+	if !a.checkRole(w, r, cr.Command) {
+		return
+	}
+	defer a.trackCommand(cr)()
+	if a.slowThreshold > 0 {
+		l := &queryLog{}
+		r = a.withRequestValue(r, queryLogKey, l)
+		defer a.logSlowCommand(cr, l)
+	}
+	r, cancel := a.withQueryTimeout(r, cr.Command)
+	defer cancel()
+
+	if !a.checkTableParams(w, cr.Params) {
+		return
+	}
+	if mutatingCommands[cr.Command] {
+		release, err := a.writes.acquire(r.Context())
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Error waiting for other writes: %v", err))
+			writeError(w, a.apiErr(err))
+			return
+		}
+		defer release()
+	}
+	if schemaCommands[cr.Command] {
+		defer a.schemas.forget(nil)
+	}
 
 	switch cr.Command {
 	case Ping:
 		a.ping(w)
 		return
+	case WhoAmI:
+		a.whoAmI(r.Context(), w)
+		return
+	case CreateAPIKey:
+		a.createAPIKey(r.Context(), w, cr.Params)
+		return
+	case ListAPIKeys:
+		a.listAPIKeys(r.Context(), w)
+		return
+	case RevokeAPIKey:
+		a.revokeAPIKey(r.Context(), w, cr.Params)
+		return
+	case ListDatabases:
+		a.listDatabases(w)
+		return
+	case GetStats:
+		a.getStats(w)
+		return
+	case GetPoolStats:
+		a.getPoolStats(w)
+		return
 	case ListTables:
-		a.listTables(w)
+		a.listTables(r.Context(), w, cr.Params)
 		return
 	case GetTable:
-		a.getTable(w, cr.Params)
+		a.getTable(r.Context(), w, cr.Params)
+		return
+	case PreviewTable:
+		a.previewTable(r.Context(), w, cr.Params)
 		return
 	case DeleteRows:
-		a.deleteRows(w, cr.Params)
+		a.deleteRows(r.Context(), w, cr.Params)
 		return
 	case UpdateRow:
-		a.updateRow(w, cr.Params)
+		a.updateRow(r.Context(), w, cr.Params)
+		return
+	case UpdateCell:
+		a.updateCell(r.Context(), w, cr.Params)
+		return
+	case InsertRow:
+		a.insertRow(r.Context(), w, cr.Params)
+		return
+	case InsertRows:
+		a.insertRows(r.Context(), w, cr.Params)
+		return
+	case SeedTable:
+		a.seedTable(r.Context(), w, cr.Params)
+		return
+	case AnonymizeTable:
+		a.anonymizeTable(r.Context(), w, cr.Params)
+		return
+	case CloneDatabase:
+		a.cloneDatabase(r.Context(), w, cr.Params)
+		return
+	case GetJob:
+		a.getJob(w, cr.Params)
+		return
+	case GetTableWindow:
+		a.getTableWindow(r.Context(), w, cr.Params)
+		return
+	case MergeRows:
+		a.mergeRows(r.Context(), w, cr.Params)
+		return
+	case ListActions:
+		a.listActions(r.Context(), w, cr.Params)
+		return
+	case RunAction:
+		a.runAction(r.Context(), w, cr.Params)
+		return
+	case ListOperations:
+		a.listOperations(w)
+		return
+	case StartOperation:
+		a.startOperation(w, cr.Params)
+		return
+	case SubmitOperationStep:
+		a.submitOperationStep(r.Context(), w, cr.Params)
+		return
+	case CommitOperation:
+		a.commitOperation(r.Context(), w, cr.Params)
+		return
+	case CancelOperation:
+		a.cancelOperation(w, cr.Params)
+		return
+	case Subscribe:
+		a.subscribe(r.Context(), w, cr.Params)
+		return
+	case ListSubscriptions:
+		a.listSubscriptions(w)
+		return
+	case Unsubscribe:
+		a.unsubscribe(w, cr.Params)
+		return
+	case TrackChanges:
+		a.trackChanges(r.Context(), w, cr.Params)
+		return
+	case GetChangesSince:
+		a.getChangesSince(r.Context(), w, cr.Params)
+		return
+	case GetColumnStats:
+		a.getColumnStats(r.Context(), w, cr.Params)
+		return
+	case ListWebhooks:
+		a.listWebhooks(w)
+		return
+	case AddWebhook:
+		a.addWebhook(r.Context(), w, cr.Params)
+		return
+	case RemoveWebhook:
+		a.removeWebhook(r.Context(), w, cr.Params)
+		return
+	case TestWebhook:
+		a.testWebhook(r.Context(), w, cr.Params)
+		return
+	case DiffTable:
+		a.diffTable(r.Context(), w, cr.Params)
+		return
+	case AddCheckConstraint:
+		a.addCheckConstraint(r.Context(), w, cr.Params)
+		return
+	case DropCheckConstraint:
+		a.dropCheckConstraint(r.Context(), w, cr.Params)
+		return
+	case ListTriggers:
+		a.listTriggers(r.Context(), w, cr.Params)
+		return
+	case ListExtensions:
+		a.listExtensions(r.Context(), w, cr.Params)
+		return
+	case CreateTrigger:
+		a.createTrigger(r.Context(), w, cr.Params)
+		return
+	case DropTrigger:
+		a.dropTrigger(r.Context(), w, cr.Params)
+		return
+	case GetFlag:
+		a.getFlag(r.Context(), w, cr.Params)
+		return
+	case SetFlag:
+		a.setFlag(r.Context(), w, cr.Params)
+		return
+	case CreateFlagsTable:
+		a.createFlagsTable(r.Context(), w, cr.Params)
+		return
+	case ListSavedQueries:
+		a.listSavedQueries(r.Context(), w)
+		return
+	case CreateSavedQuery:
+		a.createSavedQuery(r.Context(), w, cr.Params)
+		return
+	case RunSavedQuery:
+		a.runSavedQuery(r.Context(), w, cr.Params)
+		return
+	case DeleteSavedQuery:
+		a.deleteSavedQuery(r.Context(), w, cr.Params)
+		return
+	case GetColumnPolicies:
+		a.getColumnPolicies(w, cr.Params)
+		return
+	case ListArchives:
+		a.listArchives(r.Context(), w, cr.Params)
+		return
+	case ListArchiveFiles:
+		a.listArchiveFiles(r.Context(), w, cr.Params)
+		return
+	case DownloadArchiveFile:
+		a.downloadArchiveFile(r.Context(), w, cr.Params)
+		return
+	case UploadArchiveFile:
+		a.uploadArchiveFile(r.Context(), w, cr.Params)
+		return
+	case DeleteArchiveFile:
+		a.deleteArchiveFile(r.Context(), w, cr.Params)
+		return
+	case RotateEncryptionKeys:
+		a.rotateEncryptionKeys(r.Context(), w, cr.Params)
+		return
+	case Login:
+		a.login(w, r)
+		return
+	case Logout:
+		a.logout(w, r)
+		return
+	case WaitForChanges:
+		a.waitForChanges(r.Context(), w, cr.Params)
+		return
+	case GetChanges:
+		a.getChanges(r.Context(), w, cr.Params)
+		return
+	case BeginTransaction:
+		a.beginTransaction(r.Context(), w, cr.Params)
+		return
+	case CommitTransaction:
+		a.endTransaction(r.Context(), w, cr.Params, true)
+		return
+	case RollbackTransaction:
+		a.endTransaction(r.Context(), w, cr.Params, false)
+		return
+	case CopyRows:
+		a.copyRows(r.Context(), w, cr.Params)
+		return
+	case JoinTables:
+		a.joinTables(r.Context(), w, cr.Params)
+		return
+	case SeedFixtures:
+		a.seedFixtures(r.Context(), w, cr.Params)
+		return
+	case TruncateTable:
+		a.truncateTable(r.Context(), w, cr.Params)
+		return
+	case ResetSequence:
+		a.resetSequence(r.Context(), w, cr.Params)
+		return
+	case FindDuplicates:
+		a.findDuplicates(r.Context(), w, cr.Params)
+		return
+	case DeleteDuplicates:
+		a.deleteDuplicates(r.Context(), w, cr.Params)
+		return
+	case FindOrphans:
+		a.findOrphans(r.Context(), w, cr.Params)
+		return
+	case GetDatabaseStats:
+		a.getDatabaseStats(r.Context(), w, cr.Params)
+		return
+	case IncrementalVacuum:
+		a.incrementalVacuum(r.Context(), w, cr.Params)
 		return
 	default:
 		http.Error(w, "Invalid command", http.StatusBadRequest)
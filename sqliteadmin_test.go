@@ -2,9 +2,14 @@ package sqliteadmin_test
 
 import (
 	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -50,6 +55,61 @@ func TestListTables(t *testing.T) {
 	assert.Equal(t, []interface{}{"users"}, result["tables"])
 }
 
+func TestListTablesFilter(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.HideInternalTables = true
+	})
+	defer close()
+
+	_, err := ts.db.Exec(`
+    CREATE TABLE tenant_b (id INTEGER PRIMARY KEY AUTOINCREMENT);
+    CREATE TABLE tenant_a (id INTEGER);
+    CREATE TABLE tenantc (id INTEGER);
+    CREATE TABLE _sqliteadmin_internal (id INTEGER);
+  `)
+	assert.NoError(t, err)
+
+	cases := []TestCase{
+		{
+			name:           "Success: Internal Tables Hidden",
+			params:         map[string]interface{}{},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"tables": []interface{}{"tenant_a", "tenant_b", "tenantc", "users"},
+			},
+		},
+		{
+			name:           "Success: Filter",
+			params:         map[string]interface{}{"filter": "TENANT_"},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"tables": []interface{}{"tenant_a", "tenant_b"},
+			},
+		},
+		{
+			name:           "Success: Page",
+			params:         map[string]interface{}{"filter": "tenant", "limit": 2, "offset": 1},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"tables": []interface{}{"tenant_b", "tenantc"},
+				"total":  float64(3),
+			},
+		},
+		{
+			name:           "Failure: Negative Offset",
+			params:         map[string]interface{}{"offset": -1},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid input",
+			},
+		},
+	}
+
+	runTestCases(cases, sqliteadmin.ListTables, t, ts.server)
+}
+
 type TestCase struct {
 	name             string
 	params           map[string]interface{}
@@ -70,6 +130,7 @@ func TestDeleteRows(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedResponse: map[string]interface{}{
 				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
 				"message":    "Bad request: missing table name",
 			},
 		},
@@ -81,6 +142,7 @@ func TestDeleteRows(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedResponse: map[string]interface{}{
 				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
 				"message":    "Bad request: invalid or missing ids",
 			},
 		},
@@ -93,7 +155,8 @@ func TestDeleteRows(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedResponse: map[string]interface{}{
 				"statusCode": float64(http.StatusBadRequest),
-				"message":    "Bad request: invalid input",
+				"code":       "TABLE_NOT_FOUND",
+				"message":    "Bad request: unknown table",
 			},
 		},
 		{
@@ -134,6 +197,7 @@ func TestUpdateRow(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedResponse: map[string]interface{}{
 				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
 				"message":    "Bad request: missing table name",
 			},
 		},
@@ -147,10 +211,12 @@ func TestUpdateRow(t *testing.T) {
 					"email": "alice-updated@gmail.com",
 				},
 			},
-			expectedStatus: http.StatusInternalServerError,
+			expectedStatus: http.StatusBadRequest,
 			expectedResponse: map[string]interface{}{
-				"statusCode": float64(http.StatusInternalServerError),
-				"message":    "Something went wrong",
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "TABLE_NOT_FOUND",
+				"message":    "Bad request: unknown table",
+				"detail":     "invalid: unknown table",
 			},
 		},
 		{
@@ -162,10 +228,12 @@ func TestUpdateRow(t *testing.T) {
 					"email": "alice-updated@gmail.com",
 				},
 			},
-			expectedStatus: http.StatusInternalServerError,
+			expectedStatus: http.StatusBadRequest,
 			expectedResponse: map[string]interface{}{
-				"statusCode": float64(http.StatusInternalServerError),
-				"message":    "Something went wrong",
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "TABLE_NOT_FOUND",
+				"message":    "Bad request: unknown table",
+				"detail":     "invalid: unknown table",
 			},
 		},
 		{
@@ -193,6 +261,383 @@ func TestUpdateRow(t *testing.T) {
 	assert.Equal(t, "alice-updated@gmail.com", rows[0]["email"])
 }
 
+func TestUpdateRowColumns(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	cases := []TestCase{
+		{
+			name: "Failure: Unknown column",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"row":       map[string]interface{}{"id": 1, "nickname": "Al"},
+				"columns":   []string{"nickname"},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "COLUMN_NOT_FOUND",
+				"message":    "Bad request: unknown column",
+				"detail":     "nickname: unknown column",
+			},
+		},
+		{
+			name: "Failure: Column without a value",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"row":       map[string]interface{}{"id": 1, "name": "Al"},
+				"columns":   []string{"name", "email"},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: missing value of column: email",
+			},
+		},
+		{
+			name: "Success: Only the listed columns are updated",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"row":       map[string]interface{}{"id": 1, "name": "Al", "email": "stale@gmail.com"},
+				"columns":   []string{"name"},
+			},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok"},
+		},
+		{
+			name: "Success: Null value",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"row":       map[string]interface{}{"id": 2, "name": "Bob", "email": nil},
+				"columns":   []string{"email"},
+			},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok"},
+		},
+	}
+
+	runTestCases(cases, sqliteadmin.UpdateRow, t, ts.server)
+
+	rows, err := getTableValues(ts.db, "users")
+	assert.NoError(t, err)
+	assert.Equal(t, "Al", rows[0]["name"])
+	assert.Equal(t, "alice@gmail.com", rows[0]["email"])
+	assert.Nil(t, rows[1]["email"])
+}
+
+func TestGetStats(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	for i := 0; i < 3; i++ {
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{
+			Command: sqliteadmin.GetTable,
+			Params:  map[string]interface{}{"tableName": "users", "limit": 2, "offset": i * 2},
+		})
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	}
+
+	req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: sqliteadmin.GetStats})
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	result := readBody(t, res.Body)
+	cache := result["statementCache"].(map[string]interface{})
+	// The first page prepares the statements and the following pages reuse them
+	assert.Equal(t, float64(4), cache["misses"])
+	assert.Equal(t, float64(8), cache["hits"])
+}
+
+func TestTTL(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.TTLColumns = map[string]string{"sessions": "expires_at"}
+	})
+	defer close()
+
+	_, err := ts.db.Exec(`
+    CREATE TABLE sessions (id INTEGER PRIMARY KEY, expires_at);
+    INSERT INTO sessions (expires_at) VALUES
+      (unixepoch() - 60),
+      (unixepoch() + 60),
+      (datetime('now', '-1 minute')),
+      (datetime('now', '+1 minute')),
+      (NULL);
+  `)
+	assert.NoError(t, err)
+
+	deleted := ts.admin.SweepExpired(context.Background())
+	assert.Equal(t, map[string]int64{"sessions": 2}, deleted)
+
+	var remaining int
+	assert.NoError(t, ts.db.QueryRow("SELECT COUNT(*) FROM sessions").Scan(&remaining))
+	assert.Equal(t, 3, remaining)
+
+	req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: sqliteadmin.GetStats})
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	ttl := readBody(t, res.Body)["ttl"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"sessions": "expires_at"}, ttl["columns"])
+	assert.Equal(t, map[string]interface{}{"sessions": float64(2)}, ttl["deleted"])
+	assert.NotNil(t, ttl["lastSweep"])
+}
+
+func TestGetTableRelevance(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	_, err := ts.db.Exec(`
+    CREATE VIRTUAL TABLE notes USING fts5(body);
+    INSERT INTO notes (body) VALUES
+      ('sqlite is a database'),
+      ('sqlite sqlite sqlite'),
+      ('postgres is a database');
+  `)
+	assert.NoError(t, err)
+
+	likeCondition := sqliteadmin.Condition{
+		Cases: []sqliteadmin.Case{
+			sqliteadmin.Filter{Column: "name", Operator: sqliteadmin.OperatorLike, Value: "i"},
+		},
+	}
+	matchCondition := sqliteadmin.Condition{
+		Cases: []sqliteadmin.Case{
+			sqliteadmin.Filter{Column: "notes", Operator: sqliteadmin.OperatorMatch, Value: "sqlite"},
+		},
+	}
+
+	cases := []TestCase{
+		{
+			name: "Failure: Invalid Order By",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"orderBy":   "invalid",
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid orderBy",
+			},
+		},
+		{
+			name: "Success: LIKE ordered by match position",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"condition": likeCondition,
+				"orderBy":   "relevance",
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponse: makeGetTableResponse([]responseRow{
+				{id: 9, name: "Ivy", email: nil},
+				{id: 1, name: "Alice", email: "alice@gmail.com"},
+				{id: 4, name: "David", email: "david@gmail.com"},
+				{id: 3, name: "Charlie", email: "charlie@gmail.com"},
+			}),
+		},
+		{
+			name: "Success: MATCH ordered by bm25",
+			params: map[string]interface{}{
+				"tableName": "notes",
+				"condition": matchCondition,
+				"orderBy":   "relevance",
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"rows": []interface{}{
+					map[string]interface{}{"body": "sqlite sqlite sqlite"},
+					map[string]interface{}{"body": "sqlite is a database"},
+				},
+			},
+		},
+	}
+
+	runTestCases(cases, sqliteadmin.GetTable, t, ts.server)
+}
+
+func TestGetTableStream(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{
+		Command: sqliteadmin.GetTable,
+		Params:  map[string]interface{}{"tableName": "users", "stream": true},
+	})
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/x-ndjson", res.Header.Get("Content-Type"))
+
+	dec := json.NewDecoder(res.Body)
+	var rows []map[string]interface{}
+	for dec.More() {
+		var row map[string]interface{}
+		assert.NoError(t, dec.Decode(&row))
+		rows = append(rows, row)
+	}
+	assert.Equal(t, len(testValues), len(rows))
+	assert.Equal(t, "Alice", rows[0]["name"])
+	assert.Equal(t, "Ivy", rows[len(rows)-1]["name"])
+}
+
+func TestGetTableTimeZone(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	_, err := ts.db.Exec(`
+    CREATE TABLE events (
+      id INTEGER PRIMARY KEY,
+      day DATE,
+      at DATETIME
+    );
+    INSERT INTO events (day, at) VALUES ('2024-01-01', '2024-01-01 12:00:00'), ('2024-01-02', '2024-01-02 12:00:00');
+  `)
+	assert.NoError(t, err)
+
+	cases := []TestCase{
+		{
+			name: "Failure: Invalid Time Zone",
+			params: map[string]interface{}{
+				"tableName": "events",
+				"timeZone":  "Nowhere/Invalid",
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid time zone Nowhere/Invalid: unknown time zone Nowhere/Invalid",
+			},
+		},
+		{
+			name: "Success: Timestamps in Time Zone",
+			params: map[string]interface{}{
+				"tableName": "events",
+				"timeZone":  "America/New_York",
+				"condition": sqliteadmin.Condition{
+					Cases: []sqliteadmin.Case{
+						sqliteadmin.Filter{
+							Column:   "at",
+							Operator: sqliteadmin.OperatorGreaterThanOrEquals,
+							Value:    "2024-01-02 07:00:00",
+						},
+					},
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"rows": []interface{}{
+					map[string]interface{}{
+						"id":  float64(2),
+						"day": "2024-01-02T00:00:00Z",
+						"at":  "2024-01-02T07:00:00-05:00",
+					},
+				},
+			},
+		},
+	}
+
+	runTestCases(cases, sqliteadmin.GetTable, t, ts.server)
+}
+
+func TestGetTableIndexAdvisories(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	_, err := ts.db.Exec(`
+    CREATE TABLE orders (
+      id INTEGER PRIMARY KEY,
+      user_id INTEGER REFERENCES users(id),
+      reviewer_id INTEGER REFERENCES users(id)
+    );
+    CREATE INDEX idx_orders_reviewer ON orders (reviewer_id);
+  `)
+	assert.NoError(t, err)
+
+	req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{
+		Command: sqliteadmin.GetTable,
+		Params:  map[string]interface{}{"tableName": "orders", "includeInfo": true},
+	})
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	result := readBody(t, res.Body)
+	tableInfo := result["tableInfo"].(map[string]interface{})
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"columns":         []interface{}{"user_id"},
+			"referencedTable": "users",
+			"sql":             `CREATE INDEX IF NOT EXISTS "idx_orders_user_id" ON "orders" ("user_id")`,
+		},
+	}, tableInfo["indexAdvisories"])
+}
+
+func TestMergeRows(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	_, err := ts.db.Exec(`
+    CREATE TABLE orders (
+      id INTEGER PRIMARY KEY,
+      user_id INTEGER REFERENCES users(id)
+    );
+    INSERT INTO orders (user_id) VALUES (1), (2), (2);
+  `)
+	assert.NoError(t, err)
+
+	cases := []TestCase{
+		{
+			name: "Failure: Invalid Column",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"keepId":    1,
+				"mergeId":   2,
+				"fields":    map[string]interface{}{"invalid": "merge"},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "COLUMN_NOT_FOUND",
+				"message":    "Bad request: unknown column",
+				"detail":     "invalid: unknown column",
+			},
+		},
+		{
+			name: "Success: Merge Rows",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"keepId":    1,
+				"mergeId":   2,
+				"fields":    map[string]interface{}{"email": "merge"},
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"status":            "ok",
+				"referencesUpdated": float64(2),
+			},
+		},
+	}
+
+	runTestCases(cases, sqliteadmin.MergeRows, t, ts.server)
+
+	rows, err := getTableValues(ts.db, "users")
+	assert.NoError(t, err)
+	assert.Equal(t, 8, len(rows))
+	assert.Equal(t, "Alice", rows[0]["name"])
+	assert.Equal(t, "bob@gmail.com", rows[0]["email"])
+
+	orders, err := getTableValues(ts.db, "orders")
+	assert.NoError(t, err)
+	for _, order := range orders {
+		assert.EqualValues(t, 1, order["user_id"])
+	}
+}
+
 func TestGetTable(t *testing.T) {
 	ts, close := setupTestServer(t)
 	defer close()
@@ -206,6 +651,7 @@ func TestGetTable(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedResponse: map[string]interface{}{
 				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
 				"message":    "Bad request: missing table name",
 			},
 		},
@@ -215,10 +661,42 @@ func TestGetTable(t *testing.T) {
 				"tableName": "invalid",
 				"limit":     10,
 			},
-			expectedStatus: http.StatusInternalServerError,
+			expectedStatus: http.StatusBadRequest,
 			expectedResponse: map[string]interface{}{
-				"statusCode": float64(http.StatusInternalServerError),
-				"message":    "Something went wrong",
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "TABLE_NOT_FOUND",
+				"message":    "Bad request: unknown table",
+				"detail":     "invalid: unknown table",
+			},
+		},
+		{
+			name: "Failure: Invalid Limit",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"limit":     "ten",
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid input",
+			},
+		},
+		{
+			name: "Success: Get Table with limit as string",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"limit":     "1",
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"rows": []interface{}{
+					map[string]interface{}{
+						"id":    float64(1),
+						"name":  "Alice",
+						"email": "alice@gmail.com",
+					},
+				},
 			},
 		},
 		{
@@ -277,6 +755,28 @@ func TestGetTable(t *testing.T) {
 				{id: 8, name: "Henry", email: "henry@gmail.com"},
 			}),
 		),
+		{
+			name: "Success: Get Table with condition, limit and offset",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"condition": sqliteadmin.Condition{
+					Cases: []sqliteadmin.Case{
+						sqliteadmin.Filter{
+							Column:   "email",
+							Operator: sqliteadmin.OperatorLike,
+							Value:    "@gmail.com",
+						},
+					},
+				},
+				"limit":  2,
+				"offset": 2,
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponse: makeGetTableResponse([]responseRow{
+				{id: 3, name: "Charlie", email: "charlie@gmail.com"},
+				{id: 4, name: "David", email: "david@gmail.com"},
+			}),
+		},
 		makeGetTableCondition("Success: Get Table with not equals condition",
 			sqliteadmin.Condition{
 				Cases: []sqliteadmin.Case{
@@ -398,6 +898,81 @@ func TestGetTable(t *testing.T) {
 	runTestCases(cases, sqliteadmin.GetTable, t, ts.server)
 }
 
+func TestActions(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	ts.admin.RegisterAction("users", sqliteadmin.Action{
+		Name:        "ClearEmail",
+		Description: "Remove the email address of the selected users",
+		Handler: func(ctx context.Context, db *sql.DB, ids []string) (string, error) {
+			for _, id := range ids {
+				if _, err := db.ExecContext(ctx, "UPDATE users SET email = NULL WHERE id = ?", id); err != nil {
+					return "", err
+				}
+			}
+			return fmt.Sprintf("Cleared %d email(s)", len(ids)), nil
+		},
+	})
+
+	listCases := []TestCase{
+		{
+			name: "Success: List Actions",
+			params: map[string]interface{}{
+				"tableName": "users",
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"actions": []interface{}{
+					map[string]interface{}{
+						"name":        "ClearEmail",
+						"description": "Remove the email address of the selected users",
+					},
+				},
+			},
+		},
+	}
+
+	runTestCases(listCases, sqliteadmin.ListActions, t, ts.server)
+
+	runCases := []TestCase{
+		{
+			name: "Failure: Unknown Action",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"action":    "Unknown",
+				"ids":       []string{"1"},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: unknown action",
+			},
+		},
+		{
+			name: "Success: Run Action",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"action":    "ClearEmail",
+				"ids":       []string{"1", "2"},
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"status":  "ok",
+				"message": "Cleared 2 email(s)",
+			},
+		},
+	}
+
+	runTestCases(runCases, sqliteadmin.RunAction, t, ts.server)
+
+	rows, err := getTableValues(ts.db, "users")
+	assert.NoError(t, err)
+	assert.Nil(t, rows[0]["email"])
+	assert.Nil(t, rows[1]["email"])
+}
+
 func runTestCases(testCases []TestCase, command sqliteadmin.Command, t *testing.T, srv *httptest.Server) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -413,6 +988,11 @@ func runTestCases(testCases []TestCase, command sqliteadmin.Command, t *testing.
 			assert.Equal(t, tc.expectedStatus, res.StatusCode)
 
 			result := readBody(t, res.Body)
+			if requestID, ok := result["requestId"]; ok {
+				// Error responses include the ID of the request
+				assert.Equal(t, res.Header.Get(sqliteadmin.RequestIDHeader), requestID)
+				delete(result, "requestId")
+			}
 			assert.EqualValues(t, tc.expectedResponse, result)
 		})
 	}
@@ -464,18 +1044,316 @@ var testValues = [][]string{
 	{"Ivy"},
 }
 
+func TestPreviewTable(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	body := sqliteadmin.CommandRequest{
+		Command: sqliteadmin.PreviewTable,
+		Params:  map[string]interface{}{"tableName": "users", "rows": 2},
+	}
+	res, err := ts.server.Client().Do(makeRequest(t, ts.server.URL, body))
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	result := readBody(t, res.Body)
+	assert.Equal(t, float64(9), result["count"])
+	assert.Equal(t, makeGetTableResponse([]responseRow{
+		{id: 1, name: "Alice", email: "alice@gmail.com"},
+		{id: 2, name: "Bob", email: "bob@gmail.com"},
+	})["rows"], result["head"])
+	assert.Equal(t, makeGetTableResponse([]responseRow{
+		{id: 8, name: "Henry", email: "henry@gmail.com"},
+		{id: 9, name: "Ivy", email: nil},
+	})["rows"], result["tail"])
+	assert.Len(t, result["sample"], 2)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "id", "type": "INTEGER", "nullCount": float64(0), "min": float64(1), "max": float64(9)},
+		map[string]interface{}{"name": "name", "type": "TEXT", "nullCount": float64(0), "min": "Alice", "max": "Ivy"},
+		map[string]interface{}{"name": "email", "type": "TEXT", "nullCount": float64(1), "min": "alice@gmail.com", "max": "henry@gmail.com"},
+	}, result["columns"])
+
+	cases := []TestCase{
+		{
+			name:           "Failure: Missing Table Name",
+			params:         map[string]interface{}{},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: missing table name",
+			},
+		},
+		{
+			name:           "Failure: Invalid Rows",
+			params:         map[string]interface{}{"tableName": "users", "rows": -1},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid input",
+			},
+		},
+	}
+	runTestCases(cases, sqliteadmin.PreviewTable, t, ts.server)
+}
+
+func TestGetColumnStats(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	cases := []TestCase{
+		{
+			name:           "Failure: Missing Column",
+			params:         map[string]interface{}{"tableName": "users"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: missing column",
+			},
+		},
+		{
+			name:           "Failure: Unknown Column",
+			params:         map[string]interface{}{"tableName": "users", "column": "unknown"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid input",
+			},
+		},
+		{
+			name:           "Success",
+			params:         map[string]interface{}{"tableName": "users", "column": "email", "top": 2},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"name":          "email",
+				"type":          "TEXT",
+				"count":         float64(9),
+				"nullCount":     float64(1),
+				"distinctCount": float64(8),
+				"min":           "alice@gmail.com",
+				"max":           "henry@gmail.com",
+				"avgLength":     float64(15),
+				"topValues": []interface{}{
+					map[string]interface{}{"value": "alice@gmail.com", "count": float64(1)},
+					map[string]interface{}{"value": "bob@gmail.com", "count": float64(1)},
+				},
+			},
+		},
+	}
+
+	runTestCases(cases, sqliteadmin.GetColumnStats, t, ts.server)
+}
+
+func TestRequestLimits(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.MaxBodySize = 512
+		c.MaxDeleteIDs = 2
+		c.MaxLimit = 5
+		c.MaxConditionDepth = 2
+	})
+	defer close()
+
+	nested := sqliteadmin.Condition{
+		Cases: []sqliteadmin.Case{
+			sqliteadmin.Condition{
+				Cases: []sqliteadmin.Case{
+					sqliteadmin.Condition{
+						Cases: []sqliteadmin.Case{
+							sqliteadmin.Filter{Column: "name", Operator: sqliteadmin.OperatorEquals, Value: "Alice"},
+						},
+						LogicalOperator: sqliteadmin.LogicalOperatorAnd,
+					},
+				},
+				LogicalOperator: sqliteadmin.LogicalOperatorAnd,
+			},
+		},
+		LogicalOperator: sqliteadmin.LogicalOperatorAnd,
+	}
+
+	getTableCases := []TestCase{
+		{
+			name:           "Failure: Condition Too Deep",
+			params:         map[string]interface{}{"tableName": "users", "limit": 5, "condition": nested},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: condition nested too deeply",
+			},
+		},
+	}
+	runTestCases(getTableCases, sqliteadmin.GetTable, t, ts.server)
+
+	for name, params := range map[string]map[string]interface{}{
+		"Success: Default Limit":   {"tableName": "users"},
+		"Success: Limit Too Large": {"tableName": "users", "limit": 6},
+		"Success: Unlimited":       {"tableName": "users", "limit": -1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: sqliteadmin.GetTable, Params: params})
+			res, err := http.DefaultClient.Do(req)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+
+			// Limits over the maximum are clamped to it
+			result := readBody(t, res.Body)
+			assert.Len(t, result["rows"], 5)
+			if params["limit"] != nil {
+				assert.Equal(t, float64(5), result["limit"])
+			} else {
+				assert.NotContains(t, result, "limit")
+			}
+		})
+	}
+
+	deleteRowsCases := []TestCase{
+		{
+			name:           "Failure: Too Many Ids",
+			params:         map[string]interface{}{"tableName": "users", "ids": []string{"1", "2", "3"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: too many ids",
+			},
+		},
+	}
+	runTestCases(deleteRowsCases, sqliteadmin.DeleteRows, t, ts.server)
+
+	t.Run("Failure: Body Too Large", func(t *testing.T) {
+		ids := make([]string, 200)
+		for i := range ids {
+			ids[i] = fmt.Sprintf("%d", i)
+		}
+		body := sqliteadmin.CommandRequest{
+			Command: sqliteadmin.DeleteRows,
+			Params:  map[string]interface{}{"tableName": "users", "ids": ids},
+		}
+		res, err := ts.server.Client().Do(makeRequest(t, ts.server.URL, body))
+		assert.NoError(t, err)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+		assert.Equal(t, map[string]interface{}{
+			"statusCode": float64(http.StatusRequestEntityTooLarge),
+			"code":       "REQUEST_TOO_LARGE",
+			"message":    "Request body too large",
+			"requestId":  res.Header.Get(sqliteadmin.RequestIDHeader),
+		}, readBody(t, res.Body))
+	})
+}
+
+func TestRequestLogging(t *testing.T) {
+	var logs bytes.Buffer
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.Logger = slog.New(slog.NewJSONHandler(&logs, nil))
+		c.LogRequests = true
+	})
+	defer close()
+
+	body := sqliteadmin.CommandRequest{
+		Command: sqliteadmin.GetTable,
+		Params:  map[string]interface{}{"tableName": "users", "limit": 3},
+	}
+	res, err := ts.server.Client().Do(makeRequest(t, ts.server.URL, body))
+	assert.NoError(t, err)
+	res.Body.Close()
+
+	requestID := res.Header.Get(sqliteadmin.RequestIDHeader)
+	assert.NotEmpty(t, requestID)
+
+	var summary map[string]interface{}
+	dec := json.NewDecoder(&logs)
+	for dec.More() {
+		var line map[string]interface{}
+		assert.NoError(t, dec.Decode(&line))
+		// Every line of the request includes its ID
+		assert.Equal(t, requestID, line["requestId"])
+		if line["msg"] == "Request" {
+			summary = line
+		}
+	}
+
+	assert.NotNil(t, summary)
+	assert.Equal(t, "GetTable", summary["command"])
+	assert.Equal(t, "users", summary["table"])
+	assert.Equal(t, float64(3), summary["rows"])
+	assert.Equal(t, float64(http.StatusOK), summary["status"])
+	assert.Equal(t, "ok", summary["outcome"])
+	assert.Contains(t, summary, "duration")
+}
+
+func TestCompression(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.Compress = true
+	})
+	defer close()
+
+	body := sqliteadmin.CommandRequest{
+		Command: sqliteadmin.Ping,
+	}
+
+	readers := map[string]func(r io.Reader) (io.Reader, error){
+		"gzip": func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		"deflate": func(r io.Reader) (io.Reader, error) { return flate.NewReader(r), nil },
+	}
+
+	for encoding, newReader := range readers {
+		t.Run(encoding, func(t *testing.T) {
+			req := makeRequest(t, ts.server.URL, body)
+			req.Header.Set("Accept-Encoding", encoding)
+			res, err := ts.server.Client().Do(req)
+			assert.NoError(t, err)
+			defer res.Body.Close()
+
+			assert.Equal(t, encoding, res.Header.Get("Content-Encoding"))
+			r, err := newReader(res.Body)
+			assert.NoError(t, err)
+			var decoded map[string]interface{}
+			assert.NoError(t, json.NewDecoder(r).Decode(&decoded))
+			assert.Equal(t, map[string]interface{}{"status": "ok"}, decoded)
+		})
+	}
+
+	t.Run("identity", func(t *testing.T) {
+		req := makeRequest(t, ts.server.URL, body)
+		req.Header.Set("Accept-Encoding", "identity")
+		res, err := ts.server.Client().Do(req)
+		assert.NoError(t, err)
+		defer res.Body.Close()
+
+		assert.Equal(t, "", res.Header.Get("Content-Encoding"))
+		assert.Equal(t, map[string]interface{}{"status": "ok"}, readBody(t, res.Body))
+	})
+}
+
 type TestServer struct {
 	server *httptest.Server
 	db     *sql.DB
+	admin  *sqliteadmin.Admin
 }
 
 func setupTestServer(t *testing.T) (*TestServer, func()) {
+	return setupTestServerWithConfig(t, nil)
+}
+
+// setupTestServerWithConfig allows tests to customize the Config before the
+// Admin is created.
+func setupTestServerWithConfig(t *testing.T, configure func(c *sqliteadmin.Config)) (*TestServer, func()) {
 	db := setupDB(t)
 
 	c := sqliteadmin.Config{
-		DB:       db,
-		Username: "user",
-		Password: "password",
+		DB:               db,
+		Username:         "user",
+		Password:         "password",
+		ShowErrorDetails: true,
+	}
+	if configure != nil {
+		configure(&c)
 	}
 
 	a := sqliteadmin.New(c)
@@ -491,6 +1369,7 @@ func setupTestServer(t *testing.T) (*TestServer, func()) {
 	return &TestServer{
 			server: srv,
 			db:     db,
+			admin:  a,
 		}, func() {
 			srv.Close()
 			db.Close()
@@ -521,6 +1400,8 @@ func readBody(t *testing.T, body io.ReadCloser) map[string]interface{} {
 func setupDB(t *testing.T) *sql.DB {
 	db, err := sql.Open("sqlite", ":memory:")
 	assert.NoError(t, err)
+	// Every connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
 
 	err = seedData(db)
 	assert.NoError(t, err)
@@ -0,0 +1,29 @@
+package sqliteadmin
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Stats are runtime metrics about the Admin.
+type Stats struct {
+	StatementCache StatementCacheStats `json:"statementCache"`
+	SchemaCache    SchemaCacheStats    `json:"schemaCache"`
+	TTL            TTLStats            `json:"ttl"`
+	WriteQueue     WriteQueueStats     `json:"writeQueue"`
+}
+
+// Stats returns the current runtime metrics.
+func (a *Admin) Stats() Stats {
+	return Stats{
+		StatementCache: a.stmts.stats(),
+		SchemaCache:    a.schemas.stats(),
+		TTL:            a.ttlStats(),
+		WriteQueue:     a.writes.stats(),
+	}
+}
+
+func (a *Admin) getStats(w http.ResponseWriter) {
+	a.logger.Info("Command: GetStats")
+	json.NewEncoder(w).Encode(a.Stats())
+}
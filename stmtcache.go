@@ -0,0 +1,161 @@
+package sqliteadmin
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+)
+
+// stmtCacheSize is the maximum number of prepared statements kept per Admin.
+const stmtCacheSize = 256
+
+type stmtKey struct {
+	db    *sql.DB
+	query string
+}
+
+type stmtEntry struct {
+	key  stmtKey
+	stmt *sql.Stmt
+}
+
+// stmtCache is an LRU cache of prepared statements so that hot paths (e.g.
+// paginating over the same table) don't re-parse the same SQL on every
+// request.
+type stmtCache struct {
+	mu      sync.Mutex
+	entries map[stmtKey]*list.Element
+	lru     *list.List
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+func newStmtCache() *stmtCache {
+	return &stmtCache{
+		entries: make(map[stmtKey]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+func (c *stmtCache) prepare(ctx context.Context, db *sql.DB, query string) (*sql.Stmt, error) {
+	key := stmtKey{db: db, query: query}
+
+	c.mu.Lock()
+	if el, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(el)
+		c.mu.Unlock()
+		c.hits.Add(1)
+		return el.Value.(*stmtEntry).stmt, nil
+	}
+	c.mu.Unlock()
+	c.misses.Add(1)
+
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Another request may have prepared the same statement in the meantime.
+	if el, ok := c.entries[key]; ok {
+		stmt.Close()
+		c.lru.MoveToFront(el)
+		return el.Value.(*stmtEntry).stmt, nil
+	}
+
+	c.entries[key] = c.lru.PushFront(&stmtEntry{key: key, stmt: stmt})
+	for c.lru.Len() > stmtCacheSize {
+		c.remove(c.lru.Back())
+	}
+	return stmt, nil
+}
+
+// forget closes and removes all the statements prepared for db.
+func (c *stmtCache) forget(db *sql.DB) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, el := range c.entries {
+		if key.db == db {
+			c.remove(el)
+		}
+	}
+}
+
+// remove must be called with c.mu held.
+func (c *stmtCache) remove(el *list.Element) {
+	entry := c.lru.Remove(el).(*stmtEntry)
+	delete(c.entries, entry.key)
+	entry.stmt.Close()
+}
+
+// StatementCacheStats reports how effective the prepared statement cache is.
+type StatementCacheStats struct {
+	Size    int     `json:"size"`
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hitRate"`
+}
+
+func (c *stmtCache) stats() StatementCacheStats {
+	c.mu.Lock()
+	size := c.lru.Len()
+	c.mu.Unlock()
+
+	stats := StatementCacheStats{
+		Size:   size,
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+// cachedDB is a queryer that runs queries through prepared statements from
+// the cache. Exec is not cached since mutations are rarely on a hot path.
+type cachedDB struct {
+	*sql.DB
+	cache   *stmtCache
+	schemas *schemaCache
+}
+
+func (c cachedDB) schemaCache() (*schemaCache, *sql.DB) {
+	return c.schemas, c.DB
+}
+
+func (c cachedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	recordQuery(ctx, query)
+	return c.DB.ExecContext(ctx, query, args...)
+}
+
+func (c cachedDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	recordQuery(ctx, query)
+	stmt, err := c.cache.prepare(ctx, c.DB, query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.QueryContext(ctx, args...)
+}
+
+func (c cachedDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	recordQuery(ctx, query)
+	stmt, err := c.cache.prepare(ctx, c.DB, query)
+	if err != nil {
+		// Let database/sql report the error through the returned *sql.Row.
+		return c.DB.QueryRowContext(ctx, query, args...)
+	}
+	return stmt.QueryRowContext(ctx, args...)
+}
+
+// cached returns a queryer for db that reuses prepared statements and the
+// cached columns of tables.
+func (a *Admin) cached(db *sql.DB) queryer {
+	return cachedDB{DB: db, cache: a.stmts, schemas: a.schemas}
+}
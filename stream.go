@@ -0,0 +1,59 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// streamFlushRows is the number of rows written between two flushes of a
+// streamed response.
+const streamFlushRows = 100
+
+// streamTable writes the rows of the table as newline delimited JSON as they
+// are scanned instead of building the whole result in memory. Since the status
+// has already been sent when an error happens mid-stream, it is reported as a
+// final {"error": ...} line.
+func (a *Admin) streamTable(ctx context.Context, w http.ResponseWriter, db *sql.DB, table string, condition *Condition, orderBy OrderBy, limit, offset int, columnTypes map[string]string, loc *time.Location) {
+	rows, err := openTableRows(ctx, a.cached(db), table, condition, orderBy, limit, offset, a.logger)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error querying table: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	defer rows.Close()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+
+	count := 0
+	err = forEachRow(rows, func(row map[string]interface{}) error {
+		if loc != nil {
+			localizeRows([]map[string]interface{}{row}, columnTypes, loc)
+		}
+		convertBooleans([]map[string]interface{}{row}, columnTypes)
+		a.protectRows(ctx, table, []map[string]interface{}{row})
+		a.encodeRows([]map[string]interface{}{row})
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+		count++
+		if flusher != nil && count%streamFlushRows == 0 {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error streaming table: %v", err))
+		enc.Encode(map[string]string{"error": apiErrSomethingWentWrong().Message})
+	}
+	if flusher != nil {
+		flusher.Flush()
+	}
+	a.logger.Info(fmt.Sprintf("Streamed %d rows", count))
+	a.recordRows(count)
+}
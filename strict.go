@@ -0,0 +1,115 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Kinds of generated columns, as reported by ColumnInfo.Generated.
+const (
+	GeneratedVirtual = "VIRTUAL"
+	GeneratedStored  = "STORED"
+)
+
+// generatedKind returns the kind of generated column of a column given the
+// hidden value reported by PRAGMA table_xinfo, or "" for other columns.
+func generatedKind(hidden int) string {
+	switch hidden {
+	case 2:
+		return GeneratedVirtual
+	case 3:
+		return GeneratedStored
+	}
+	return ""
+}
+
+// getGeneratedColumns returns the kind of each generated column of the table.
+// PRAGMA table_info leaves generated columns out.
+func getGeneratedColumns(ctx context.Context, q queryer, table string) (map[string]string, error) {
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("PRAGMA table_xinfo(%s)", quoteIdent(table)))
+	if err != nil {
+		return nil, fmt.Errorf("error getting columns: %v", err)
+	}
+	defer rows.Close()
+
+	generated := map[string]string{}
+	for rows.Next() {
+		var c column
+		var hidden int
+		if err := rows.Scan(&c.CID, &c.Name, &c.DataType, &c.NotNull, &c.DefaultValue, &c.PK, &hidden); err != nil {
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		if kind := generatedKind(hidden); kind != "" {
+			generated[c.Name] = kind
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
+	return generated, nil
+}
+
+// isStrictTable reports whether the table was created as a STRICT table.
+func isStrictTable(ctx context.Context, q queryer, table string) (bool, error) {
+	var strict bool
+	err := q.QueryRowContext(ctx, "SELECT strict FROM pragma_table_list WHERE schema = 'main' AND name = ?", table).Scan(&strict)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error checking if table is strict: %v", err)
+	}
+	return strict, nil
+}
+
+// checkStrictTypes returns an error if a value of the row can't be stored in
+// its column of a STRICT table. Like SQLite, it accepts the values that
+// convert to the type of the column without loss, e.g. "12" for an INTEGER.
+func checkStrictTypes(row map[string]interface{}, types map[string]string) error {
+	for column, value := range row {
+		dataType, ok := types[column]
+		if !ok || value == nil || strictValueFits(strings.ToUpper(dataType), value) {
+			continue
+		}
+		return fmt.Errorf("%w: %s is %s in a STRICT table, got %v", ErrStrictType, column, strings.ToUpper(dataType), value)
+	}
+	return nil
+}
+
+func strictValueFits(dataType string, value interface{}) bool {
+	switch dataType {
+	case "INT", "INTEGER":
+		switch v := value.(type) {
+		case int, int64, bool:
+			return true
+		case float64:
+			return v == math.Trunc(v) && math.Abs(v) <= math.MaxInt64
+		case string:
+			_, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			return err == nil
+		}
+	case "REAL":
+		switch v := value.(type) {
+		case int, int64, float64:
+			return true
+		case string:
+			_, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			return err == nil
+		}
+	case "TEXT":
+		switch value.(type) {
+		case string, int, int64, float64:
+			return true
+		}
+	case "BLOB":
+		_, ok := value.([]byte)
+		return ok
+	case "ANY":
+		return true
+	}
+	return false
+}
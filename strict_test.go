@@ -0,0 +1,109 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneratedColumnsAndStrictTables(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	_, err := ts.db.Exec(`
+		CREATE TABLE items (
+			id INTEGER PRIMARY KEY,
+			price REAL,
+			quantity INT,
+			name TEXT,
+			total REAL GENERATED ALWAYS AS (price * quantity) STORED,
+			label TEXT AS (upper(name))
+		) STRICT;
+		INSERT INTO items (id, price, quantity, name) VALUES (1, 2.5, 2, 'pen');
+	`)
+	assert.NoError(t, err)
+
+	res, err := ts.admin.GetTable(context.Background(), sqliteadmin.GetTableParams{TableName: "items", IncludeInfo: true})
+	assert.NoError(t, err)
+	assert.True(t, res.TableInfo.Strict)
+	generated := map[string]string{}
+	for _, c := range res.TableInfo.Columns {
+		if c.ReadOnly {
+			generated[c.Name] = c.Generated
+		}
+	}
+	assert.Equal(t, map[string]string{"total": sqliteadmin.GeneratedStored, "label": sqliteadmin.GeneratedVirtual}, generated)
+
+	runTestCases([]TestCase{
+		{
+			name: "Success: Generated Columns Sent Back",
+			params: map[string]interface{}{
+				"tableName": "items",
+				"row":       map[string]interface{}{"id": 1, "quantity": "4", "total": 5, "label": "PEN"},
+			},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok"},
+		},
+		{
+			name: "Failure: Text in Integer Column",
+			params: map[string]interface{}{
+				"tableName": "items",
+				"row":       map[string]interface{}{"id": 1, "quantity": "many"},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: value does not match the type of the column: quantity is INT in a STRICT table, got many",
+			},
+		},
+		{
+			name: "Failure: Fraction in Integer Column",
+			params: map[string]interface{}{
+				"tableName": "items",
+				"row":       map[string]interface{}{"id": 1, "quantity": 1.5},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: value does not match the type of the column: quantity is INT in a STRICT table, got 1.5",
+			},
+		},
+	}, sqliteadmin.UpdateRow, t, ts.server)
+
+	var total float64
+	assert.NoError(t, ts.db.QueryRow("SELECT total FROM items WHERE id = 1").Scan(&total))
+	assert.Equal(t, 10.0, total)
+
+	runTestCases([]TestCase{
+		{
+			name: "Success: Generated Columns Left Out",
+			params: map[string]interface{}{
+				"tableName": "items",
+				"row":       map[string]interface{}{"id": 2, "price": 1, "quantity": 3, "name": "cap", "total": 0},
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"status": "ok",
+				"row":    map[string]interface{}{"id": float64(2), "price": float64(1), "quantity": float64(3), "name": "cap", "total": float64(3), "label": "CAP"},
+			},
+		},
+		{
+			name: "Failure: Text in Real Column",
+			params: map[string]interface{}{
+				"tableName": "items",
+				"row":       map[string]interface{}{"id": 3, "price": "cheap"},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: value does not match the type of the column: price is REAL in a STRICT table, got cheap",
+			},
+		},
+	}, sqliteadmin.InsertRow, t, ts.server)
+}
@@ -0,0 +1,257 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/joelseq/sqliteadmin-go/querybuilder"
+)
+
+// EventSubscriptionMatched is sent when rows start matching the condition of
+// a subscription.
+const EventSubscriptionMatched EventType = "subscription.matched"
+
+// DefaultSubscriptionInterval is how often a subscription is evaluated when no
+// interval is provided.
+const DefaultSubscriptionInterval = time.Minute
+
+// subscriptionTick is how often RunSubscriptions checks for subscriptions that
+// are due, which is also the smallest supported interval.
+const subscriptionTick = time.Second
+
+// Subscription watches a table for rows matching a condition.
+type Subscription struct {
+	ID        string     `json:"id"`
+	Database  string     `json:"database,omitempty"`
+	TableName string     `json:"tableName"`
+	Condition *Condition `json:"condition"`
+	// Interval is the number of seconds between two evaluations.
+	Interval int `json:"interval"`
+	// Matches is the number of matching rows at the last evaluation.
+	Matches int64 `json:"matches"`
+}
+
+type subscription struct {
+	Subscription
+	db        *sql.DB
+	checkedAt time.Time
+}
+
+func (a *Admin) subscribe(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getReadDB(w, params)
+	if !ok {
+		return
+	}
+
+	table, ok := params["tableName"].(string)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrMissingTableName.Error()))
+		return
+	}
+
+	// Subscriptions are evaluated without a principal, so none of the rows
+	// of scoped tables are in scope
+	if a.scoped(table) {
+		writeError(w, apiErrForbidden(ErrOutOfScope.Error()))
+		return
+	}
+
+	condition, ok := toCondition(params["condition"], a.logger)
+	if !ok || len(condition.Cases) == 0 {
+		writeError(w, apiErrBadRequest("Invalid condition"))
+		return
+	}
+	if exceeds(conditionDepth(condition), a.maxConditionDepth) {
+		writeError(w, apiErrBadRequest(ErrConditionTooDeep.Error()))
+		return
+	}
+	if !a.checkCondition(w, table, condition) {
+		return
+	}
+
+	interval := int(DefaultSubscriptionInterval / time.Second)
+	if params["interval"] != nil {
+		interval, ok = convertNumber(params["interval"])
+		if !ok || interval < int(subscriptionTick/time.Second) {
+			writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+			return
+		}
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: Subscribe, table=%s, interval=%d", table, interval))
+
+	// Evaluate the condition once so that invalid subscriptions are rejected
+	// up front and only rows matching from now on are notified about.
+	matches, err := countMatches(ctx, a.cached(db), table, condition)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error evaluating subscription: %v", err))
+		writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+		return
+	}
+
+	id, err := newID()
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error generating subscription id: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+
+	database, _ := params["database"].(string)
+	a.mu.Lock()
+	a.subscriptions[id] = &subscription{
+		Subscription: Subscription{
+			ID:        id,
+			Database:  database,
+			TableName: table,
+			Condition: condition,
+			Interval:  interval,
+			Matches:   matches,
+		},
+		db:        db,
+		checkedAt: time.Now(),
+	}
+	a.mu.Unlock()
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"id": id, "matches": matches})
+}
+
+func (a *Admin) listSubscriptions(w http.ResponseWriter) {
+	a.logger.Info("Command: ListSubscriptions")
+
+	a.mu.RLock()
+	subscriptions := make([]Subscription, 0, len(a.subscriptions))
+	for _, s := range a.subscriptions {
+		subscriptions = append(subscriptions, s.Subscription)
+	}
+	a.mu.RUnlock()
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].ID < subscriptions[j].ID
+	})
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"subscriptions": subscriptions})
+}
+
+func (a *Admin) unsubscribe(w http.ResponseWriter, params map[string]interface{}) {
+	id, ok := params["id"].(string)
+	if !ok || id == "" {
+		writeError(w, apiErrBadRequest(ErrMissingSubscriptionID.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: Unsubscribe, id=%s", id))
+
+	a.mu.Lock()
+	_, ok = a.subscriptions[id]
+	delete(a.subscriptions, id)
+	a.mu.Unlock()
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrUnknownSubscriptionID.Error()))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// RunSubscriptions evaluates the subscriptions at their interval until ctx is
+// done, sending an EventSubscriptionMatched notification whenever rows start
+// matching the condition of a subscription that previously had no matches.
+func (a *Admin) RunSubscriptions(ctx context.Context) {
+	ticker := time.NewTicker(subscriptionTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, s := range a.dueSubscriptions(now) {
+				a.evaluateSubscription(ctx, s)
+			}
+		}
+	}
+}
+
+func (a *Admin) dueSubscriptions(now time.Time) []*subscription {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var due []*subscription
+	for _, s := range a.subscriptions {
+		if now.Sub(s.checkedAt) >= time.Duration(s.Interval)*time.Second {
+			s.checkedAt = now
+			due = append(due, s)
+		}
+	}
+	return due
+}
+
+func (a *Admin) evaluateSubscription(ctx context.Context, s *subscription) {
+	matches, err := countMatches(ctx, a.cached(s.db), s.TableName, s.Condition)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error evaluating subscription %s: %v", s.ID, err))
+		return
+	}
+
+	a.mu.Lock()
+	previous := s.Matches
+	s.Matches = matches
+	a.mu.Unlock()
+
+	if previous > 0 || matches == 0 {
+		return
+	}
+
+	data := map[string]interface{}{
+		"subscriptionId": s.ID,
+		"tableName":      s.TableName,
+		"matches":        matches,
+	}
+	if link := a.viewLink(s.Subscription); link != "" {
+		data["link"] = link
+	}
+	a.notify(EventSubscriptionMatched, fmt.Sprintf("%d row(s) of %s match subscription %s", matches, s.TableName, s.ID), data)
+}
+
+// viewLink returns a link to the UI showing the rows matching the
+// subscription, or an empty string if no ViewURL is configured.
+func (a *Admin) viewLink(s Subscription) string {
+	if a.viewURL == "" {
+		return ""
+	}
+	condition, err := json.Marshal(s.Condition)
+	if err != nil {
+		return ""
+	}
+	query := url.Values{"table": {s.TableName}, "condition": {string(condition)}}
+	if s.Database != "" {
+		query.Set("database", s.Database)
+	}
+	return a.viewURL + "?" + query.Encode()
+}
+
+func countMatches(ctx context.Context, q queryer, table string, condition *Condition) (int64, error) {
+	exists, err := checkTableExists(ctx, q, table)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, fmt.Errorf("%s: %w", table, ErrUnknownTable)
+	}
+	if err := checkConditionColumns(ctx, q, table, condition); err != nil {
+		return 0, err
+	}
+
+	query, args := querybuilder.SelectRaw("COUNT(*)").From(table).Where(conditionExpr(condition)).Build()
+	var count int64
+	err = q.QueryRowContext(ctx, query, args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error counting matches: %v", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,113 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type chanNotifier chan sqliteadmin.Event
+
+func (c chanNotifier) Notify(ctx context.Context, event sqliteadmin.Event) error {
+	c <- event
+	return nil
+}
+
+func TestSubscriptions(t *testing.T) {
+	events := make(chanNotifier, 1)
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.Notifiers = map[sqliteadmin.EventType][]sqliteadmin.Notifier{
+			sqliteadmin.EventSubscriptionMatched: {events},
+		}
+		c.ViewURL = "https://sqliteadmin.dev"
+	})
+	defer close()
+
+	condition := sqliteadmin.Condition{
+		Cases: []sqliteadmin.Case{
+			sqliteadmin.Filter{Column: "name", Operator: sqliteadmin.OperatorEquals, Value: "Zoe"},
+		},
+	}
+
+	cases := []TestCase{
+		{
+			name:           "Failure: Missing Condition",
+			params:         map[string]interface{}{"tableName": "users"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: Invalid condition",
+			},
+		},
+		{
+			name:           "Failure: Unknown Table",
+			params:         map[string]interface{}{"tableName": "unknown", "condition": condition},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid input",
+			},
+		},
+	}
+	runTestCases(cases, sqliteadmin.Subscribe, t, ts.server)
+
+	res, err := http.DefaultClient.Do(makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{
+		Command: sqliteadmin.Subscribe,
+		Params:  map[string]interface{}{"tableName": "users", "condition": condition, "interval": 1},
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	subscription := readBody(t, res.Body)
+	assert.Equal(t, float64(0), subscription["matches"])
+	id := subscription["id"].(string)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ts.admin.RunSubscriptions(ctx)
+
+	_, err = ts.db.Exec("INSERT INTO users (name) VALUES ('Zoe')")
+	assert.NoError(t, err)
+
+	select {
+	case event := <-events:
+		assert.Equal(t, sqliteadmin.EventSubscriptionMatched, event.Type)
+		assert.Equal(t, id, event.Data["subscriptionId"])
+		assert.Equal(t, int64(1), event.Data["matches"])
+		assert.Contains(t, event.Data["link"], "https://sqliteadmin.dev?")
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a subscription notification")
+	}
+
+	res, err = http.DefaultClient.Do(makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{
+		Command: sqliteadmin.ListSubscriptions,
+	}))
+	assert.NoError(t, err)
+	subscriptions := readBody(t, res.Body)["subscriptions"].([]interface{})
+	assert.Len(t, subscriptions, 1)
+	assert.Equal(t, id, subscriptions[0].(map[string]interface{})["id"])
+
+	runTestCases([]TestCase{
+		{
+			name:             "Success: Unsubscribe",
+			params:           map[string]interface{}{"id": id},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok"},
+		},
+		{
+			name:           "Failure: Unknown Id",
+			params:         map[string]interface{}{"id": id},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: unknown subscription id",
+			},
+		},
+	}, sqliteadmin.Unsubscribe, t, ts.server)
+}
@@ -0,0 +1,93 @@
+package sqliteadmin
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// tableParamNames are the params of the commands that name a table.
+var tableParamNames = []string{"tableName", "targetTable", "otherTable"}
+
+// tableAllowed reports whether the table is exposed according to
+// Config.IncludeTables and Config.ExcludeTables. The API keys are never
+// exposed, so that they are only managed with their commands and editors
+// can't grant themselves a role.
+func (a *Admin) tableAllowed(table string) bool {
+	if strings.EqualFold(table, apiKeysTable) {
+		return false
+	}
+	if len(a.includeTables) > 0 && !matchesAny(a.includeTables, table) {
+		return false
+	}
+	return !matchesAny(a.excludeTables, table)
+}
+
+// allowedTables returns the tables that are exposed, in the same order.
+func (a *Admin) allowedTables(tables []string) []string {
+	allowed := []string{}
+	for _, t := range tables {
+		if a.tableAllowed(t) {
+			allowed = append(allowed, t)
+		}
+	}
+	return allowed
+}
+
+// checkTableParams writes an error if a table named in the params isn't
+// exposed. The table is reported as unknown so that its existence isn't
+// disclosed.
+func (a *Admin) checkTableParams(w http.ResponseWriter, params map[string]interface{}) bool {
+	for _, p := range tableParamNames {
+		if table, ok := params[p].(string); ok && !a.tableAllowed(table) {
+			a.logger.Info(fmt.Sprintf("Table not exposed: %s", table))
+			writeError(w, apiErrTableNotFound())
+			return false
+		}
+	}
+	return true
+}
+
+// isInternalTable reports whether the table belongs to SQLite or to the
+// admin itself.
+func isInternalTable(table string) bool {
+	return hasPrefixFold(table, "sqlite_") || hasInternalPrefix(table)
+}
+
+// hasInternalPrefix reports whether the table or trigger is managed by the
+// admin. Names are compared ignoring case, like SQLite does.
+func hasInternalPrefix(name string) bool {
+	return hasPrefixFold(name, internalPrefix)
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// foldTableKeys returns the map keyed by the lowercased table names, so that
+// tables are looked up ignoring case like SQLite does.
+func foldTableKeys[V any](m map[string]V) map[string]V {
+	folded := make(map[string]V, len(m))
+	for table, v := range m {
+		folded[strings.ToLower(table)] = v
+	}
+	return folded
+}
+
+// matchesAny reports whether the name matches one of the patterns, which are
+// names or globs as for path.Match. Case is ignored since SQLite ignores it in
+// table names.
+func matchesAny(patterns []string, name string) bool {
+	name = strings.ToLower(name)
+	for _, p := range patterns {
+		p = strings.ToLower(p)
+		if p == name {
+			return true
+		}
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
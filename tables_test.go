@@ -0,0 +1,134 @@
+package sqliteadmin_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableAllowlist(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.IncludeTables = []string{"users", "tenant_*"}
+		c.ExcludeTables = []string{"tenant_secret"}
+	})
+	defer close()
+
+	_, err := ts.db.Exec(`
+		CREATE TABLE tenant_a (id INTEGER PRIMARY KEY);
+		CREATE TABLE tenant_secret (id INTEGER PRIMARY KEY);
+		CREATE TABLE billing (id INTEGER PRIMARY KEY, user_id INTEGER);
+	`)
+	assert.NoError(t, err)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Success: Only Exposed Tables",
+			params:         map[string]interface{}{},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"tables": []interface{}{"tenant_a", "users"},
+			},
+		},
+		{
+			name:           "Success: Paged After Filtering",
+			params:         map[string]interface{}{"limit": 1, "offset": 1},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"tables": []interface{}{"users"},
+				"total":  float64(2),
+			},
+		},
+	}, sqliteadmin.ListTables, t, ts.server)
+
+	notFound := map[string]interface{}{
+		"statusCode": float64(http.StatusBadRequest),
+		"code":       "TABLE_NOT_FOUND",
+		"message":    "Bad request: unknown table",
+	}
+	runTestCases([]TestCase{
+		{
+			name:             "Failure: Excluded Table",
+			params:           map[string]interface{}{"tableName": "tenant_secret"},
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: notFound,
+		},
+		{
+			name:             "Failure: Table Not Included",
+			params:           map[string]interface{}{"tableName": "billing"},
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: notFound,
+		},
+	}, sqliteadmin.GetTable, t, ts.server)
+
+	runTestCases([]TestCase{
+		{
+			name:             "Failure: Other Table Not Included",
+			params:           map[string]interface{}{"tableName": "users", "otherTable": "billing", "on": map[string]interface{}{"id": "user_id"}},
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: notFound,
+		},
+	}, sqliteadmin.JoinTables, t, ts.server)
+}
+
+func TestTableAllowlistIgnoresCase(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.ExcludeTables = []string{"secret", "TENANT_*"}
+	})
+	defer close()
+
+	_, err := ts.db.Exec(`
+		CREATE TABLE secret (id INTEGER PRIMARY KEY, value TEXT);
+		INSERT INTO secret (value) VALUES ('hunter2');
+		CREATE TABLE tenant_a (id INTEGER PRIMARY KEY);
+		INSERT INTO tenant_a (id) VALUES (1);
+	`)
+	assert.NoError(t, err)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Success: Patterns Match Any Case",
+			params:         map[string]interface{}{},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"tables": []interface{}{"users"},
+			},
+		},
+	}, sqliteadmin.ListTables, t, ts.server)
+
+	for command, params := range map[sqliteadmin.Command]map[string]interface{}{
+		sqliteadmin.GetTable:       {"tableName": "SECRET"},
+		sqliteadmin.PreviewTable:   {"tableName": "Secret"},
+		sqliteadmin.GetColumnStats: {"tableName": "SECRET", "column": "value"},
+		sqliteadmin.InsertRow:      {"tableName": "SECRET", "row": map[string]interface{}{"value": "x"}},
+		sqliteadmin.InsertRows:     {"tableName": "sEcReT", "rows": []interface{}{map[string]interface{}{"value": "x"}}},
+		sqliteadmin.DeleteRows:     {"tableName": "Tenant_A", "ids": []interface{}{1}},
+	} {
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: command, Params: params})
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, command)
+		assert.Equal(t, "TABLE_NOT_FOUND", readBody(t, res.Body)["code"], command)
+	}
+
+	var n int
+	assert.NoError(t, ts.db.QueryRow("SELECT (SELECT COUNT(*) FROM secret) + (SELECT COUNT(*) FROM tenant_a)").Scan(&n))
+	assert.Equal(t, 2, n)
+
+	// Included tables are matched ignoring case too
+	ts, close = setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.IncludeTables = []string{"USERS"}
+	})
+	defer close()
+	runTestCases([]TestCase{
+		{
+			name:           "Success: Included Whatever The Case",
+			params:         map[string]interface{}{},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"tables": []interface{}{"users"},
+			},
+		},
+	}, sqliteadmin.ListTables, t, ts.server)
+}
@@ -0,0 +1,78 @@
+package sqliteadmin
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// localTimeLayouts are the layouts of date filter values without an offset
+// that are interpreted in the requested time zone.
+var localTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+}
+
+// getTimeZone returns the time zone requested with the "timeZone" param,
+// falling back to the one from the Config. It returns nil when neither is set,
+// in which case timestamps are returned as stored.
+func (a *Admin) getTimeZone(params map[string]interface{}) (*time.Location, error) {
+	name, ok := params["timeZone"].(string)
+	if !ok || name == "" {
+		return a.timeZone, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %s: %v", name, err)
+	}
+	return loc, nil
+}
+
+// isTimestampType reports whether a column declared with the given type holds
+// a point in time. Plain DATE columns hold calendar dates which must not be
+// shifted between time zones.
+func isTimestampType(dataType string) bool {
+	return strings.Contains(strings.ToUpper(dataType), "TIME")
+}
+
+// localizeRows converts the values of timestamp columns to the time zone.
+func localizeRows(rows []map[string]interface{}, types map[string]string, loc *time.Location) {
+	for _, row := range rows {
+		for column, value := range row {
+			if t, ok := value.(time.Time); ok && isTimestampType(types[column]) {
+				row[column] = t.In(loc)
+			}
+		}
+	}
+}
+
+// localizeCondition returns a copy of the condition where the values of
+// filters on timestamp columns, which are given in the time zone, are
+// converted to UTC so that they can be compared with the stored values.
+func localizeCondition(condition *Condition, types map[string]string, loc *time.Location) *Condition {
+	localized := &Condition{LogicalOperator: condition.LogicalOperator}
+	for _, c := range condition.Cases {
+		switch c := c.(type) {
+		case Condition:
+			localized.Cases = append(localized.Cases, *localizeCondition(&c, types, loc))
+		case Filter:
+			if isTimestampType(types[c.Column]) {
+				c.Value = toUTC(c.Value, loc)
+			}
+			localized.Cases = append(localized.Cases, c)
+		}
+	}
+	return localized
+}
+
+func toUTC(value string, loc *time.Location) string {
+	for _, layout := range localTimeLayouts {
+		t, err := time.ParseInLocation(layout, value, loc)
+		if err == nil {
+			return t.UTC().Format(layout)
+		}
+	}
+	return value
+}
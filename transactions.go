@@ -0,0 +1,400 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTransactionTimeout is how long a transaction session can stay
+	// idle before it is rolled back, when no timeout is provided.
+	DefaultTransactionTimeout = 5 * time.Minute
+	// MaxTransactionTimeout bounds the timeout of transaction sessions, as
+	// they hold the write lock of their database until they end.
+	MaxTransactionTimeout = 30 * time.Minute
+)
+
+// commandSavepoint wraps each command run in a transaction session so that a
+// failing command is undone without aborting the whole session.
+const commandSavepoint = "sqliteadmin_command"
+
+// transactionSession is a transaction opened by BeginTransaction which
+// mutating commands can join with the "transaction" param until it is
+// committed, rolled back or times out. Commands of a session are serialized
+// by mu.
+type transactionSession struct {
+	mu   sync.Mutex
+	name string
+	user string
+	db   *sql.DB
+	tx   *sql.Tx
+	// conn is the connection of the session when it changed the foreign
+	// keys setting, which is restored to prevForeignKeys once it ends.
+	conn            *sql.Conn
+	prevForeignKeys bool
+	timeout         time.Duration
+	timer           *time.Timer
+	deadline        time.Time
+	commands        int
+	// after holds the After hooks and events of the commands, which only
+	// run once the session is committed.
+	after []func(context.Context)
+	done  bool
+}
+
+// beginTransaction opens a transaction session on the database. The mutating
+// commands given its id in the "transaction" param (InsertRow, InsertRows,
+// UpdateRow, UpdateCell, DeleteRows, DeleteDuplicates, MergeRows and CopyRows)
+// are applied together by CommitTransaction, or discarded by
+// RollbackTransaction. Sessions idle for longer than their timeout are rolled
+// back. Other writes to the database wait on the session until it ends, which
+// is why the database needs more than one connection.
+//
+// The "foreignKeys" param enables or disables the enforcement of foreign keys
+// for the session, e.g. to reorder rows referencing each other. Only the
+// connection of the session is affected and its setting is restored once the
+// session ends.
+func (a *Admin) beginTransaction(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return
+	}
+
+	timeout := DefaultTransactionTimeout
+	if params["timeout"] != nil {
+		seconds, ok := params["timeout"].(float64)
+		if !ok || seconds <= 0 {
+			writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+			return
+		}
+		timeout = min(time.Duration(seconds*float64(time.Second)), MaxTransactionTimeout)
+	}
+	name, _ := params["name"].(string)
+	foreignKeys, setForeignKeys := params["foreignKeys"].(bool)
+	if params["foreignKeys"] != nil && !setForeignKeys {
+		writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: BeginTransaction, name=%s, timeout=%s, foreignKeys=%v", name, timeout, params["foreignKeys"]))
+
+	if db.Stats().MaxOpenConnections == 1 {
+		writeError(w, apiErrBadRequest(ErrTransactionsUnsupported.Error()))
+		return
+	}
+
+	id, err := newID()
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error generating transaction id: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	session := &transactionSession{
+		name:     name,
+		user:     UserFromContext(ctx),
+		db:       db,
+		timeout:  timeout,
+		deadline: time.Now().Add(timeout),
+	}
+	// The transaction outlives the request, so it isn't bound to its context
+	if setForeignKeys {
+		session.conn, session.prevForeignKeys, err = setConnForeignKeys(db, foreignKeys)
+		if err == nil {
+			session.tx, err = session.conn.BeginTx(context.Background(), nil)
+			if err != nil {
+				session.restoreForeignKeys()
+			}
+		}
+	} else {
+		session.tx, err = db.BeginTx(context.Background(), nil)
+	}
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error starting transaction: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.mu.Lock()
+	a.transactions[id] = session
+	session.timer = time.AfterFunc(timeout, func() {
+		a.expireTransaction(id)
+	})
+	a.mu.Unlock()
+	a.logger.Info(fmt.Sprintf("Audit: transaction %s (%s) opened", id, name))
+
+	json.NewEncoder(w).Encode(map[string]string{"transactionId": id})
+}
+
+// endTransaction commits or rolls back a transaction session.
+func (a *Admin) endTransaction(ctx context.Context, w http.ResponseWriter, params map[string]interface{}, commit bool) {
+	command := RollbackTransaction
+	if commit {
+		command = CommitTransaction
+	}
+	id, ok := params["transactionId"].(string)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrMissingTransactionID.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: %s, transactionId=%s", command, id))
+
+	session := a.lockTransaction(ctx, id)
+	if session == nil {
+		writeError(w, apiErrBadRequest(ErrUnknownTransaction.Error()))
+		return
+	}
+	defer session.mu.Unlock()
+
+	// Rows left referencing missing rows while foreign keys were disabled
+	// are reported rather than preventing the commit
+	violations := -1
+	if commit && session.conn != nil && session.prevForeignKeys {
+		var err error
+		if violations, err = countForeignKeyViolations(ctx, session.tx); err != nil {
+			a.logger.Error(fmt.Sprintf("Error checking foreign keys: %v", err))
+			writeError(w, a.apiErr(err))
+			return
+		}
+	}
+	if err := a.closeTransaction(id, session, commit); err != nil {
+		a.logger.Error(fmt.Sprintf("Error ending transaction: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if commit {
+		for _, f := range session.after {
+			f(ctx)
+		}
+		a.logger.Info(fmt.Sprintf("Audit: transaction %s (%s) committed %d command(s)", id, session.name, session.commands))
+	} else {
+		a.logger.Info(fmt.Sprintf("Audit: transaction %s (%s) rolled back", id, session.name))
+	}
+
+	response := map[string]interface{}{"status": "ok", "commands": session.commands}
+	if violations >= 0 {
+		response["foreignKeyViolations"] = violations
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// lockTransaction returns the transaction session with its mutex held, or nil
+// if there is no such session or it belongs to another user.
+func (a *Admin) lockTransaction(ctx context.Context, id string) *transactionSession {
+	a.mu.RLock()
+	session := a.transactions[id]
+	a.mu.RUnlock()
+	if session == nil || session.user != UserFromContext(ctx) {
+		return nil
+	}
+
+	session.mu.Lock()
+	if session.done {
+		session.mu.Unlock()
+		return nil
+	}
+	return session
+}
+
+// closeTransaction ends the transaction session. It must be called with
+// session.mu held.
+func (a *Admin) closeTransaction(id string, session *transactionSession, commit bool) error {
+	session.done = true
+	session.timer.Stop()
+	a.mu.Lock()
+	delete(a.transactions, id)
+	a.mu.Unlock()
+
+	if session.conn != nil {
+		defer session.restoreForeignKeys()
+	}
+	if commit {
+		return session.tx.Commit()
+	}
+	return session.tx.Rollback()
+}
+
+// setConnForeignKeys reserves a connection of db and sets its enforcement of
+// foreign keys, which can't be changed inside a transaction. It returns the
+// connection along with its previous setting.
+func setConnForeignKeys(db *sql.DB, enabled bool) (*sql.Conn, bool, error) {
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return nil, false, err
+	}
+	var previous bool
+	if err := conn.QueryRowContext(context.Background(), "PRAGMA foreign_keys").Scan(&previous); err != nil {
+		conn.Close()
+		return nil, false, fmt.Errorf("error reading foreign_keys: %v", err)
+	}
+	if _, err := conn.ExecContext(context.Background(), fmt.Sprintf("PRAGMA foreign_keys = %t", enabled)); err != nil {
+		conn.Close()
+		return nil, false, fmt.Errorf("error setting foreign_keys: %v", err)
+	}
+	return conn, previous, nil
+}
+
+// restoreForeignKeys restores the foreign keys setting of the connection of
+// the session and releases it. A connection whose setting can't be restored
+// is discarded rather than returned to the pool.
+func (s *transactionSession) restoreForeignKeys() {
+	ctx := context.Background()
+	if _, err := s.conn.ExecContext(ctx, fmt.Sprintf("PRAGMA foreign_keys = %t", s.prevForeignKeys)); err != nil {
+		s.conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	}
+	s.conn.Close()
+}
+
+// countForeignKeyViolations returns the number of rows referencing missing
+// rows.
+func countForeignKeyViolations(ctx context.Context, q queryer) (int, error) {
+	rows, err := q.QueryContext(ctx, "PRAGMA foreign_key_check")
+	if err != nil {
+		return 0, fmt.Errorf("error checking foreign keys: %v", err)
+	}
+	defer rows.Close()
+	violations := 0
+	for rows.Next() {
+		violations++
+	}
+	return violations, rows.Err()
+}
+
+// expireTransaction rolls back the transaction session once it has been idle
+// for its timeout.
+func (a *Admin) expireTransaction(id string) {
+	a.mu.RLock()
+	session := a.transactions[id]
+	a.mu.RUnlock()
+	if session == nil {
+		return
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	// A command may have reset the timer while the lock was awaited
+	if session.done || time.Now().Before(session.deadline) {
+		return
+	}
+	if err := a.closeTransaction(id, session, false); err != nil {
+		a.logger.Error(fmt.Sprintf("Error rolling back transaction: %v", err))
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: transaction %s (%s) timed out and was rolled back", id, session.name))
+}
+
+// mutationTx is the transaction a mutating command runs in: either its own,
+// or a savepoint of the transaction session given by the "transaction" param.
+type mutationTx interface {
+	queryer
+	Commit() error
+	Rollback() error
+	// afterCommit runs f once the changes of the command are committed,
+	// which for a transaction session is when the session is committed.
+	afterCommit(ctx context.Context, f func(context.Context))
+}
+
+type ownTx struct {
+	*immediateTx
+}
+
+func (t ownTx) afterCommit(ctx context.Context, f func(context.Context)) {
+	f(ctx)
+}
+
+// savepointTx runs a command inside a transaction session. The session is
+// unlocked once the command is committed or rolled back.
+type savepointTx struct {
+	*sql.Tx
+	session *transactionSession
+	ended   bool
+}
+
+func (t *savepointTx) Commit() error {
+	if t.ended {
+		return sql.ErrTxDone
+	}
+	if _, err := t.Tx.ExecContext(context.Background(), "RELEASE "+commandSavepoint); err != nil {
+		return err
+	}
+	t.session.commands++
+	t.end()
+	return nil
+}
+
+func (t *savepointTx) Rollback() error {
+	if t.ended {
+		return sql.ErrTxDone
+	}
+	defer t.end()
+	// The background context makes sure the command is undone even if the
+	// request was canceled.
+	_, err := t.Tx.ExecContext(context.Background(), "ROLLBACK TO "+commandSavepoint)
+	if err == nil {
+		_, err = t.Tx.ExecContext(context.Background(), "RELEASE "+commandSavepoint)
+	}
+	return err
+}
+
+func (t *savepointTx) afterCommit(ctx context.Context, f func(context.Context)) {
+	t.session.after = append(t.session.after, f)
+}
+
+func (t *savepointTx) end() {
+	t.ended = true
+	t.session.deadline = time.Now().Add(t.session.timeout)
+	t.session.timer.Reset(t.session.timeout)
+	t.session.mu.Unlock()
+}
+
+// beginMutation starts the transaction of a mutating command writing to the
+// table of db, joining the transaction session of the "transaction" param if
+// any. It writes an error response and returns false if the transaction
+// couldn't be started or the table is a shadow table.
+func (a *Admin) beginMutation(ctx context.Context, w http.ResponseWriter, db *sql.DB, table string, params map[string]interface{}) (mutationTx, bool) {
+	tx, ok := a.beginMutationTx(ctx, w, db, params)
+	if !ok {
+		return nil, false
+	}
+	if !a.checkNotShadowTable(ctx, w, tx, table) {
+		tx.Rollback()
+		return nil, false
+	}
+	return tx, true
+}
+
+func (a *Admin) beginMutationTx(ctx context.Context, w http.ResponseWriter, db *sql.DB, params map[string]interface{}) (mutationTx, bool) {
+	id, _ := params["transaction"].(string)
+	if id == "" {
+		tx, err := a.beginImmediateTx(ctx, db)
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Error starting transaction: %v", err))
+			writeError(w, a.apiErr(err))
+			return nil, false
+		}
+		return ownTx{tx}, true
+	}
+
+	session := a.lockTransaction(ctx, id)
+	if session == nil {
+		writeError(w, apiErrBadRequest(ErrUnknownTransaction.Error()))
+		return nil, false
+	}
+	if session.db != db {
+		session.mu.Unlock()
+		writeError(w, apiErrBadRequest(ErrTransactionDatabase.Error()))
+		return nil, false
+	}
+	if _, err := session.tx.ExecContext(ctx, "SAVEPOINT "+commandSavepoint); err != nil {
+		session.mu.Unlock()
+		a.logger.Error(fmt.Sprintf("Error starting savepoint: %v", err))
+		writeError(w, a.apiErr(err))
+		return nil, false
+	}
+	return &savepointTx{Tx: session.tx, session: session}, true
+}
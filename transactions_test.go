@@ -0,0 +1,225 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactions(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "app.db"))
+	assert.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("CREATE TABLE t (id INTEGER PRIMARY KEY, name TEXT)")
+	assert.NoError(t, err)
+	_, err = db.Exec("INSERT INTO t (id, name) VALUES (1, 'a'), (2, 'b'), (3, 'c')")
+	assert.NoError(t, err)
+	ts.admin.AddDatabase("app", db)
+
+	send := func(command sqliteadmin.Command, params map[string]interface{}) (int, map[string]interface{}) {
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: command, Params: params})
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res.StatusCode, readBody(t, res.Body)
+	}
+	begin := func(params map[string]interface{}) string {
+		params["database"] = "app"
+		status, body := send(sqliteadmin.BeginTransaction, params)
+		assert.Equal(t, http.StatusOK, status)
+		id, _ := body["transactionId"].(string)
+		assert.NotEmpty(t, id)
+		return id
+	}
+	names := func() []string {
+		rows, err := db.Query("SELECT name FROM t ORDER BY id")
+		assert.NoError(t, err)
+		defer rows.Close()
+		var names []string
+		for rows.Next() {
+			var name string
+			assert.NoError(t, rows.Scan(&name))
+			names = append(names, name)
+		}
+		return names
+	}
+
+	t.Run("Commit", func(t *testing.T) {
+		id := begin(map[string]interface{}{"name": "cleanup"})
+
+		status, _ := send(sqliteadmin.UpdateRow, map[string]interface{}{
+			"database": "app", "tableName": "t", "row": map[string]interface{}{"id": 1, "name": "x"}, "transaction": id,
+		})
+		assert.Equal(t, http.StatusOK, status)
+		status, _ = send(sqliteadmin.DeleteRows, map[string]interface{}{
+			"database": "app", "tableName": "t", "ids": []interface{}{"3"}, "transaction": id,
+		})
+		assert.Equal(t, http.StatusOK, status)
+		// A failing command doesn't abort the session
+		status, _ = send(sqliteadmin.UpdateRow, map[string]interface{}{
+			"database": "app", "tableName": "t", "row": map[string]interface{}{"id": 2, "missing": "y"}, "transaction": id,
+		})
+		assert.Equal(t, http.StatusBadRequest, status)
+
+		// Nothing is visible outside the session before it is committed
+		assert.Equal(t, []string{"a", "b", "c"}, names())
+
+		status, body := send(sqliteadmin.CommitTransaction, map[string]interface{}{"transactionId": id})
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, map[string]interface{}{"status": "ok", "commands": float64(2)}, body)
+		assert.Equal(t, []string{"x", "b"}, names())
+
+		status, body = send(sqliteadmin.CommitTransaction, map[string]interface{}{"transactionId": id})
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Equal(t, "Bad request: "+sqliteadmin.ErrUnknownTransaction.Error(), body["message"])
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		id := begin(map[string]interface{}{})
+
+		status, _ := send(sqliteadmin.UpdateRow, map[string]interface{}{
+			"database": "app", "tableName": "t", "row": map[string]interface{}{"id": 2, "name": "y"}, "transaction": id,
+		})
+		assert.Equal(t, http.StatusOK, status)
+
+		status, _ = send(sqliteadmin.RollbackTransaction, map[string]interface{}{"transactionId": id})
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, []string{"x", "b"}, names())
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		id := begin(map[string]interface{}{"timeout": 0.1})
+
+		status, _ := send(sqliteadmin.UpdateRow, map[string]interface{}{
+			"database": "app", "tableName": "t", "row": map[string]interface{}{"id": 2, "name": "z"}, "transaction": id,
+		})
+		assert.Equal(t, http.StatusOK, status)
+
+		time.Sleep(300 * time.Millisecond)
+		status, body := send(sqliteadmin.UpdateRow, map[string]interface{}{
+			"database": "app", "tableName": "t", "row": map[string]interface{}{"id": 2, "name": "z"}, "transaction": id,
+		})
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Equal(t, "Bad request: "+sqliteadmin.ErrUnknownTransaction.Error(), body["message"])
+		assert.Equal(t, []string{"x", "b"}, names())
+	})
+
+	t.Run("Other Database", func(t *testing.T) {
+		id := begin(map[string]interface{}{})
+		defer send(sqliteadmin.RollbackTransaction, map[string]interface{}{"transactionId": id})
+
+		status, body := send(sqliteadmin.DeleteRows, map[string]interface{}{
+			"tableName": "users", "ids": []interface{}{"1"}, "transaction": id,
+		})
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Equal(t, "Bad request: "+sqliteadmin.ErrTransactionDatabase.Error(), body["message"])
+	})
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Single Connection",
+			params:         map[string]interface{}{},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(400),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: " + sqliteadmin.ErrTransactionsUnsupported.Error(),
+			},
+		},
+		{
+			name:           "Failure: Invalid Timeout",
+			params:         map[string]interface{}{"database": "app", "timeout": -1},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(400),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: " + sqliteadmin.ErrInvalidInput.Error(),
+			},
+		},
+	}, sqliteadmin.BeginTransaction, t, ts.server)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Missing Transaction ID",
+			params:         map[string]interface{}{},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(400),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: " + sqliteadmin.ErrMissingTransactionID.Error(),
+			},
+		},
+	}, sqliteadmin.RollbackTransaction, t, ts.server)
+}
+
+func TestTransactionForeignKeys(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "app.db")+"?_pragma=foreign_keys(1)")
+	assert.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(`
+		CREATE TABLE authors (id INTEGER PRIMARY KEY, name TEXT);
+		CREATE TABLE books (id INTEGER PRIMARY KEY, author_id INTEGER REFERENCES authors (id));
+		INSERT INTO authors VALUES (1, 'a'), (2, 'b');
+		INSERT INTO books VALUES (1, 1), (2, 2);
+	`)
+	assert.NoError(t, err)
+	ts.admin.AddDatabase("app", db)
+
+	send := func(command sqliteadmin.Command, params map[string]interface{}) (int, map[string]interface{}) {
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: command, Params: params})
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res.StatusCode, readBody(t, res.Body)
+	}
+	deleteAuthor := func(id, transaction string) int {
+		status, _ := send(sqliteadmin.DeleteRows, map[string]interface{}{
+			"database": "app", "tableName": "authors", "ids": []interface{}{id}, "transaction": transaction,
+		})
+		return status
+	}
+
+	status, body := send(sqliteadmin.BeginTransaction, map[string]interface{}{"database": "app", "foreignKeys": "no"})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "Bad request: invalid input", body["message"])
+
+	// Foreign keys are enforced by default
+	status, body = send(sqliteadmin.BeginTransaction, map[string]interface{}{"database": "app"})
+	assert.Equal(t, http.StatusOK, status)
+	id, _ := body["transactionId"].(string)
+	assert.Equal(t, http.StatusConflict, deleteAuthor("1", id))
+	status, _ = send(sqliteadmin.RollbackTransaction, map[string]interface{}{"transactionId": id})
+	assert.Equal(t, http.StatusOK, status)
+
+	status, body = send(sqliteadmin.BeginTransaction, map[string]interface{}{"database": "app", "foreignKeys": false})
+	assert.Equal(t, http.StatusOK, status)
+	id, _ = body["transactionId"].(string)
+	assert.Equal(t, http.StatusOK, deleteAuthor("1", id))
+	status, body = send(sqliteadmin.CommitTransaction, map[string]interface{}{"transactionId": id})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]interface{}{"status": "ok", "commands": float64(1), "foreignKeyViolations": float64(1)}, body)
+
+	// The connection of the session enforces foreign keys again
+	assert.Equal(t, http.StatusConflict, deleteAuthor("2", ""))
+	conns := make([]*sql.Conn, db.Stats().OpenConnections)
+	for i := range conns {
+		conns[i], err = db.Conn(context.Background())
+		assert.NoError(t, err)
+		var enabled bool
+		assert.NoError(t, conns[i].QueryRowContext(context.Background(), "PRAGMA foreign_keys").Scan(&enabled))
+		assert.True(t, enabled)
+	}
+	for _, conn := range conns {
+		conn.Close()
+	}
+}
@@ -0,0 +1,93 @@
+package sqliteadmin
+
+import (
+	"context"
+	"fmt"
+)
+
+// ColumnTransform converts the values of a column the application stores
+// encrypted or encoded itself, so that they stay encrypted at rest while the
+// users allowed to unseal values, see Config.CanUnseal, view and edit them in
+// plaintext. Other users are shown SealedValue.
+type ColumnTransform struct {
+	// Decode converts a value read from the database to the value shown.
+	Decode func(ctx context.Context, value interface{}) (interface{}, error)
+	// Encode converts a value sent by a client to the value stored.
+	Encode func(ctx context.Context, value interface{}) (interface{}, error)
+}
+
+// RegisterColumnTransform registers the transform of a column of the table,
+// replacing the previous one. NULL values aren't transformed.
+func (a *Admin) RegisterColumnTransform(table, column string, t ColumnTransform) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.transforms[table] == nil {
+		a.transforms[table] = make(map[string]ColumnTransform)
+	}
+	a.transforms[table][column] = t
+}
+
+func (a *Admin) tableTransforms(table string) map[string]ColumnTransform {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.transforms[table]
+}
+
+// decodeRows decodes the values of the transformed columns of the rows, or
+// seals them if the request isn't allowed to see them.
+func (a *Admin) decodeRows(ctx context.Context, table string, rows []map[string]interface{}) {
+	transforms := a.tableTransforms(table)
+	if len(transforms) == 0 {
+		return
+	}
+	unseal := canUnseal(ctx)
+	for _, row := range rows {
+		for column, t := range transforms {
+			v, ok := row[column]
+			if !ok || v == nil {
+				continue
+			}
+			if !unseal || t.Decode == nil {
+				row[column] = SealedValue
+				continue
+			}
+			plain, err := t.Decode(ctx, v)
+			if err != nil {
+				a.logger.Error(fmt.Sprintf("Error decoding %s.%s: %v", table, column, err))
+				plain = SealedValue
+			}
+			row[column] = plain
+		}
+	}
+}
+
+// encodeRow encodes the values of the transformed columns of the row about
+// to be written. Sealed values sent back by clients are left unchanged.
+func (a *Admin) encodeRow(ctx context.Context, table string, row map[string]interface{}) error {
+	for column, t := range a.tableTransforms(table) {
+		value, ok := row[column]
+		if !ok {
+			continue
+		}
+		if value == SealedValue {
+			delete(row, column)
+			continue
+		}
+		if value == nil || t.Encode == nil {
+			continue
+		}
+		encoded, err := t.Encode(ctx, value)
+		if err != nil {
+			return fmt.Errorf("error encoding %s: %v", column, err)
+		}
+		row[column] = encoded
+	}
+	return nil
+}
+
+// transformed reports whether the column of the table has a transform.
+func (a *Admin) transformed(table, column string) bool {
+	_, ok := a.tableTransforms(table)[column]
+	return ok
+}
@@ -0,0 +1,76 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumnTransforms(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.CanUnseal = func(r *http.Request) bool {
+			return r.Header.Get("X-Unseal") == "yes"
+		}
+	})
+	defer close()
+
+	ts.admin.RegisterColumnTransform("users", "email", sqliteadmin.ColumnTransform{
+		Decode: func(ctx context.Context, value interface{}) (interface{}, error) {
+			plain, err := base64.StdEncoding.DecodeString(fmt.Sprint(value))
+			return string(plain), err
+		},
+		Encode: func(ctx context.Context, value interface{}) (interface{}, error) {
+			return base64.StdEncoding.EncodeToString([]byte(fmt.Sprint(value))), nil
+		},
+	})
+
+	do := func(unseal bool, command sqliteadmin.Command, params map[string]interface{}) (int, map[string]interface{}) {
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: command, Params: params})
+		if unseal {
+			req.Header.Set("X-Unseal", "yes")
+		}
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res.StatusCode, readBody(t, res.Body)
+	}
+	email := func(unseal bool, id int) interface{} {
+		_, body := do(unseal, sqliteadmin.GetTable, map[string]interface{}{"tableName": "users"})
+		return body["rows"].([]interface{})[id-1].(map[string]interface{})["email"]
+	}
+	stored := func() string {
+		var s string
+		err := ts.db.QueryRow("SELECT email FROM users WHERE id = 1").Scan(&s)
+		assert.NoError(t, err)
+		return s
+	}
+
+	status, _ := do(true, sqliteadmin.UpdateRow, map[string]interface{}{"tableName": "users", "row": map[string]interface{}{"id": 1, "email": "alice@example.com"}})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("alice@example.com")), stored())
+
+	assert.Equal(t, "alice@example.com", email(true, 1))
+	assert.Equal(t, sqliteadmin.SealedValue, email(false, 1))
+	// Values which can't be decoded are sealed
+	assert.Equal(t, sqliteadmin.SealedValue, email(true, 2))
+
+	// Sending the sealed value back leaves it unchanged
+	before := stored()
+	status, _ = do(false, sqliteadmin.UpdateRow, map[string]interface{}{"tableName": "users", "row": map[string]interface{}{"id": 1, "name": "Alicia", "email": sqliteadmin.SealedValue}})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, before, stored())
+
+	// Filtering would compare the plaintext to the stored values
+	status, body := do(true, sqliteadmin.GetTable, map[string]interface{}{
+		"tableName": "users",
+		"condition": map[string]interface{}{
+			"cases": []interface{}{map[string]interface{}{"column": "email", "operator": "eq", "value": "alice@example.com"}},
+		},
+	})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "Bad request: cannot filter on a redacted or anonymized column: email", body["message"])
+}
@@ -0,0 +1,230 @@
+package sqliteadmin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Trigger is a trigger as stored in sqlite_master.
+type Trigger struct {
+	Name      string `json:"name"`
+	TableName string `json:"tableName"`
+	SQL       string `json:"sql"`
+}
+
+// internalPrefix is the prefix of the tables and triggers managed by the
+// package itself, e.g. for change tracking.
+const internalPrefix = "_sqliteadmin_"
+
+// getTriggers returns the triggers of the table, or of every table if table is
+// empty.
+func getTriggers(ctx context.Context, q queryer, table string) ([]Trigger, error) {
+	rows, err := q.QueryContext(ctx, `SELECT name, tbl_name, sql FROM sqlite_master
+		WHERE type = 'trigger' AND (? = '' OR tbl_name = ?) ORDER BY tbl_name, name`, table, table)
+	if err != nil {
+		return nil, fmt.Errorf("error listing triggers: %v", err)
+	}
+	defer rows.Close()
+
+	triggers := []Trigger{}
+	for rows.Next() {
+		var t Trigger
+		if err := rows.Scan(&t.Name, &t.TableName, &t.SQL); err != nil {
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		triggers = append(triggers, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %v", err)
+	}
+	return triggers, nil
+}
+
+func (a *Admin) listTriggers(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getReadDB(w, params)
+	if !ok {
+		return
+	}
+
+	table, _ := params["tableName"].(string)
+	a.logger.Info(fmt.Sprintf("Command: ListTriggers, table=%s", table))
+
+	triggers, err := getTriggers(ctx, a.cached(db), table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error listing triggers: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+
+	// The triggers of the tables hidden from the request are left out
+	visible := []Trigger{}
+	for _, t := range triggers {
+		if !isInternalTable(t.TableName) && a.tableAllowed(t.TableName) {
+			visible = append(visible, t)
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"triggers": visible})
+}
+
+var createTriggerRegexp = regexp.MustCompile(`(?is)^\s*CREATE\s+(TEMP\s+|TEMPORARY\s+)?TRIGGER\s`)
+
+// createTrigger runs a single CREATE TRIGGER statement. Anything else, such
+// as additional statements after the trigger, is rejected.
+func (a *Admin) createTrigger(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return
+	}
+
+	stmt, ok := params["sql"].(string)
+	if !ok || !createTriggerRegexp.MatchString(stmt) || !isSingleTrigger(stmt) {
+		writeError(w, apiErrBadRequest(ErrInvalidTrigger.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: CreateTrigger, sql=%s", stmt))
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error starting transaction: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	defer tx.Rollback()
+
+	before, err := getTriggers(ctx, tx, "")
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error listing triggers: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if _, err := tx.ExecContext(ctx, stmt); err != nil {
+		a.logger.Error(fmt.Sprintf("Error creating trigger: %v", err))
+		writeError(w, apiErrBadRequest(fmt.Sprintf("%s: %v", ErrInvalidTrigger, err)))
+		return
+	}
+	after, err := getTriggers(ctx, tx, "")
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error listing triggers: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+
+	var created *Trigger
+	for i, t := range after {
+		if !containsTrigger(before, t.Name) {
+			created = &after[i]
+		}
+	}
+	if created == nil || len(after) != len(before)+1 || hasInternalPrefix(created.Name) {
+		writeError(w, apiErrBadRequest(ErrInvalidTrigger.Error()))
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		a.logger.Error(fmt.Sprintf("Error committing transaction: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: created trigger %s on %s", created.Name, created.TableName))
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"trigger": created})
+}
+
+func (a *Admin) dropTrigger(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return
+	}
+
+	name, ok := params["name"].(string)
+	if !ok || name == "" {
+		writeError(w, apiErrBadRequest(ErrMissingTriggerName.Error()))
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: DropTrigger, name=%s", name))
+
+	// Triggers managed by the package are removed through their own commands
+	triggers, err := getTriggers(ctx, db, "")
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error listing triggers: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if !containsTrigger(triggers, name) || hasInternalPrefix(name) {
+		writeError(w, apiErrBadRequest(ErrUnknownTrigger.Error()))
+		return
+	}
+
+	if _, err := db.ExecContext(ctx, fmt.Sprintf("DROP TRIGGER %s", quoteIdent(name))); err != nil {
+		a.logger.Error(fmt.Sprintf("Error dropping trigger: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: dropped trigger %s", name))
+
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+func containsTrigger(triggers []Trigger, name string) bool {
+	for _, t := range triggers {
+		if strings.EqualFold(t.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSingleTrigger reports whether the statement ends with the END closing the
+// body of the trigger, i.e. it isn't followed by other statements.
+func isSingleTrigger(stmt string) bool {
+	sc := &sqlScanner{s: stmt}
+	depth := 0
+	began, closed := false, false
+	var word strings.Builder
+
+	endWord := func() {
+		switch strings.ToUpper(word.String()) {
+		case "BEGIN":
+			began = true
+			depth++
+		case "CASE":
+			// CASE expressions in the WHEN clause come before the body
+			if began {
+				depth++
+			}
+		case "END":
+			if began {
+				depth--
+				closed = depth == 0
+			}
+		}
+		word.Reset()
+	}
+
+	for c := sc.next(); c != 0; c = sc.next() {
+		if c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+			if closed {
+				return false
+			}
+			word.WriteByte(c)
+			continue
+		}
+		if word.Len() > 0 {
+			endWord()
+		}
+		if closed && !strings.ContainsRune("; \t\r\n", rune(c)) {
+			return false
+		}
+	}
+	if word.Len() > 0 {
+		endWord()
+	}
+	return closed
+}
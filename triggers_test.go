@@ -0,0 +1,127 @@
+package sqliteadmin_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTriggers(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	createSQL := `CREATE TRIGGER users_lowercase AFTER INSERT ON users
+    WHEN CASE WHEN NEW.email IS NULL THEN 0 ELSE 1 END
+    BEGIN
+      UPDATE users SET email = lower(NEW.email) WHERE id = NEW.id;
+      SELECT CASE WHEN NEW.name = '' THEN RAISE(ABORT, 'end') END;
+    END;`
+
+	invalidTrigger := map[string]interface{}{
+		"statusCode": float64(http.StatusBadRequest),
+		"code":       "BAD_REQUEST",
+		"message":    "Bad request: invalid trigger",
+	}
+
+	runTestCases([]TestCase{
+		{
+			name:             "Failure: Not A Trigger",
+			params:           map[string]interface{}{"sql": "DROP TABLE users"},
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: invalidTrigger,
+		},
+		{
+			name:             "Failure: Trailing Statement",
+			params:           map[string]interface{}{"sql": createSQL + " DROP TABLE users;"},
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: invalidTrigger,
+		},
+		{
+			name:           "Success",
+			params:         map[string]interface{}{"sql": createSQL},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"trigger": map[string]interface{}{
+					"name":      "users_lowercase",
+					"tableName": "users",
+					"sql":       createSQL[:len(createSQL)-1],
+				},
+			},
+		},
+	}, sqliteadmin.CreateTrigger, t, ts.server)
+
+	_, err := ts.db.Exec("INSERT INTO users (name, email) VALUES ('Jack', 'JACK@GMAIL.COM')")
+	assert.NoError(t, err)
+	var email string
+	assert.NoError(t, ts.db.QueryRow("SELECT email FROM users WHERE name = 'Jack'").Scan(&email))
+	assert.Equal(t, "jack@gmail.com", email)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Success",
+			params:         map[string]interface{}{"tableName": "users"},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"triggers": []interface{}{
+					map[string]interface{}{
+						"name":      "users_lowercase",
+						"tableName": "users",
+						"sql":       createSQL[:len(createSQL)-1],
+					},
+				},
+			},
+		},
+	}, sqliteadmin.ListTriggers, t, ts.server)
+
+	runTestCases([]TestCase{
+		{
+			name:             "Success",
+			params:           map[string]interface{}{"name": "users_lowercase"},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok"},
+		},
+		{
+			name:           "Failure: Unknown Trigger",
+			params:         map[string]interface{}{"name": "users_lowercase"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: unknown trigger",
+			},
+		},
+	}, sqliteadmin.DropTrigger, t, ts.server)
+}
+
+func TestTriggersHiddenTables(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.ExcludeTables = []string{"secret"}
+	})
+	defer close()
+
+	_, err := ts.db.Exec(`
+		CREATE TABLE secret (id INTEGER PRIMARY KEY, value TEXT);
+		CREATE TRIGGER secret_audit AFTER INSERT ON secret BEGIN SELECT 1; END;
+		CREATE TRIGGER users_audit AFTER INSERT ON users BEGIN SELECT 1; END;
+	`)
+	assert.NoError(t, err)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Success",
+			params:         map[string]interface{}{},
+			expectedStatus: http.StatusOK,
+			expectedResponse: map[string]interface{}{
+				"triggers": []interface{}{
+					map[string]interface{}{
+						"name":      "users_audit",
+						"tableName": "users",
+						"sql":       "CREATE TRIGGER users_audit AFTER INSERT ON users BEGIN SELECT 1; END",
+					},
+				},
+			},
+		},
+	}, sqliteadmin.ListTriggers, t, ts.server)
+}
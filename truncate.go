@@ -0,0 +1,232 @@
+package sqliteadmin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TruncateConfirmationTTL is how long the confirmation token returned by
+// TruncateTable stays valid.
+const TruncateConfirmationTTL = time.Minute
+
+// Values of the "mode" param of TruncateTable.
+const (
+	// truncateModeDelete deletes the rows of the table, optionally resetting
+	// its AUTOINCREMENT sequence with "resetSequence".
+	truncateModeDelete = "delete"
+	// truncateModeRecreate drops the table and recreates it with its
+	// indexes and triggers, which also resets its sequence.
+	truncateModeRecreate = "recreate"
+)
+
+// truncation is a truncation waiting to be confirmed.
+type truncation struct {
+	db            *sql.DB
+	table         string
+	mode          string
+	resetSequence bool
+	user          string
+	expires       time.Time
+}
+
+// truncateTable deletes all the rows of a table in two steps: without a
+// "confirmationToken" param it returns the number of rows of the table along
+// with a token, and the table is only truncated once the same command is sent
+// again with the token. Tokens are single use and expire after
+// TruncateConfirmationTTL.
+func (a *Admin) truncateTable(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	db, ok := a.getDB(w, params)
+	if !ok {
+		return
+	}
+	table, ok := params["tableName"].(string)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrMissingTableName.Error()))
+		return
+	}
+	mode, _ := params["mode"].(string)
+	if mode == "" {
+		mode = truncateModeDelete
+	}
+	if mode != truncateModeDelete && mode != truncateModeRecreate {
+		writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+		return
+	}
+	resetSequence := params["resetSequence"] == true
+	token, _ := params["confirmationToken"].(string)
+
+	a.logger.Info(fmt.Sprintf("Command: TruncateTable, table=%s, mode=%s, confirmed=%t", table, mode, token != ""))
+
+	exists, err := checkTableExists(ctx, a.cached(db), table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error checking table existence: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if !exists || hasInternalPrefix(table) {
+		writeError(w, apiErrTableNotFound())
+		return
+	}
+	if !a.checkNotShadowTable(ctx, w, a.cached(db), table) {
+		return
+	}
+	// Rows out of the scope would be deleted along with the others
+	if a.scoped(table) {
+		writeError(w, apiErrForbidden(ErrOutOfScope.Error()))
+		return
+	}
+
+	t := truncation{db: db, table: table, mode: mode, resetSequence: resetSequence, user: UserFromContext(ctx)}
+	if token == "" {
+		var rows int64
+		if err := a.cached(db).QueryRowContext(ctx, fmt.Sprintf("SELECT count(*) FROM %s", quoteIdent(table))).Scan(&rows); err != nil {
+			a.logger.Error(fmt.Sprintf("Error counting rows: %v", err))
+			writeError(w, a.apiErr(err))
+			return
+		}
+		token, err := newID()
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Error generating confirmation token: %v", err))
+			writeError(w, a.apiErr(err))
+			return
+		}
+		t.expires = time.Now().Add(TruncateConfirmationTTL)
+		a.mu.Lock()
+		a.pruneTruncations()
+		a.truncations[token] = t
+		a.mu.Unlock()
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"confirmationToken": token, "rows": rows})
+		return
+	}
+
+	a.mu.Lock()
+	a.pruneTruncations()
+	pending, ok := a.truncations[token]
+	delete(a.truncations, token)
+	a.mu.Unlock()
+	pending.expires = time.Time{}
+	if !ok || pending != t {
+		writeError(w, apiErrBadRequest(ErrInvalidConfirmation.Error()))
+		return
+	}
+
+	deleted, err := a.truncate(ctx, db, table, mode, resetSequence)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error truncating table: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	a.logger.Info(fmt.Sprintf("Audit: truncated %s (%s), %d row(s) deleted", table, mode, deleted))
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "rowsDeleted": deleted})
+}
+
+// pruneTruncations discards the expired confirmation tokens. It must be called
+// with a.mu held.
+func (a *Admin) pruneTruncations() {
+	now := time.Now()
+	for token, t := range a.truncations {
+		if now.After(t.expires) {
+			delete(a.truncations, token)
+		}
+	}
+}
+
+// truncate deletes the rows of the table in a transaction and returns how many
+// were deleted.
+func (a *Admin) truncate(ctx context.Context, db *sql.DB, table, mode string, resetSequence bool) (int64, error) {
+	var queryOnly bool
+	if err := db.QueryRowContext(ctx, "PRAGMA query_only").Scan(&queryOnly); err != nil {
+		return 0, fmt.Errorf("error reading query_only: %v", err)
+	}
+	if queryOnly {
+		return 0, ErrReadOnlyDatabase
+	}
+
+	tx, err := a.beginImmediateTx(ctx, db)
+	if err != nil {
+		return 0, readOnlyError(fmt.Errorf("error starting transaction: %v", err))
+	}
+	defer tx.Rollback()
+
+	var deleted int64
+	if mode == truncateModeRecreate {
+		deleted, err = recreateTable(ctx, tx, table)
+	} else {
+		var result sql.Result
+		result, err = tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s", quoteIdent(table)))
+		if err == nil {
+			deleted, err = result.RowsAffected()
+		}
+		if err == nil && resetSequence {
+			err = resetTableSequence(ctx, tx, table)
+		}
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	return deleted, readOnlyError(err)
+}
+
+// readOnlyError returns ErrReadOnlyDatabase for the errors of writes to a
+// database opened read-only, which only fail once written to.
+func readOnlyError(err error) error {
+	if err != nil && strings.Contains(err.Error(), "readonly") {
+		return ErrReadOnlyDatabase
+	}
+	return err
+}
+
+// recreateTable drops the table and creates it again along with its indexes
+// and triggers.
+func recreateTable(ctx context.Context, tx queryer, table string) (int64, error) {
+	var deleted int64
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT count(*) FROM %s", quoteIdent(table))).Scan(&deleted); err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT sql FROM sqlite_master WHERE tbl_name = ? AND sql IS NOT NULL ORDER BY type = 'table' DESC", table)
+	if err != nil {
+		return 0, fmt.Errorf("error reading table definition: %v", err)
+	}
+	var statements []string
+	for rows.Next() {
+		var statement string
+		if err := rows.Scan(&statement); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		statements = append(statements, statement)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("DROP TABLE %s", quoteIdent(table))); err != nil {
+		return 0, fmt.Errorf("error dropping table: %v", err)
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return 0, fmt.Errorf("error recreating table: %v", err)
+		}
+	}
+	return deleted, nil
+}
+
+// resetTableSequence resets the AUTOINCREMENT sequence of the table, if any.
+func resetTableSequence(ctx context.Context, tx queryer, table string) error {
+	var sequences int
+	err := tx.QueryRowContext(ctx, "SELECT count(*) FROM sqlite_master WHERE name = 'sqlite_sequence'").Scan(&sequences)
+	if err != nil || sequences == 0 {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "DELETE FROM sqlite_sequence WHERE name = ?", table)
+	return err
+}
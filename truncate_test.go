@@ -0,0 +1,124 @@
+package sqliteadmin_test
+
+import (
+	"database/sql"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateTable(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	_, err := ts.db.Exec(`
+		CREATE TABLE posts (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT);
+		CREATE INDEX posts_title ON posts (title);
+		INSERT INTO posts (title) VALUES ('First'), ('Second');
+	`)
+	assert.NoError(t, err)
+
+	send := func(params map[string]interface{}) (int, map[string]interface{}) {
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: sqliteadmin.TruncateTable, Params: params})
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res.StatusCode, readBody(t, res.Body)
+	}
+	confirm := func(params map[string]interface{}) (int, map[string]interface{}) {
+		status, body := send(params)
+		assert.Equal(t, http.StatusOK, status)
+		token, _ := body["confirmationToken"].(string)
+		assert.NotEmpty(t, token)
+		params["confirmationToken"] = token
+		return send(params)
+	}
+	insertPost := func() int64 {
+		res, err := ts.db.Exec("INSERT INTO posts (title) VALUES ('New')")
+		assert.NoError(t, err)
+		id, err := res.LastInsertId()
+		assert.NoError(t, err)
+		return id
+	}
+
+	t.Run("Confirmation", func(t *testing.T) {
+		status, body := send(map[string]interface{}{"tableName": "posts"})
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, float64(2), body["rows"])
+
+		// The token only confirms the truncation it was returned for
+		status, body = send(map[string]interface{}{"tableName": "posts", "mode": "recreate", "confirmationToken": body["confirmationToken"]})
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Equal(t, "Bad request: "+sqliteadmin.ErrInvalidConfirmation.Error(), body["message"])
+
+		var rows int
+		assert.NoError(t, ts.db.QueryRow("SELECT count(*) FROM posts").Scan(&rows))
+		assert.Equal(t, 2, rows)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		status, body := confirm(map[string]interface{}{"tableName": "posts"})
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, map[string]interface{}{"status": "ok", "rowsDeleted": float64(2)}, body)
+		assert.Equal(t, int64(3), insertPost())
+	})
+
+	t.Run("Reset Sequence", func(t *testing.T) {
+		status, _ := confirm(map[string]interface{}{"tableName": "posts", "resetSequence": true})
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, int64(1), insertPost())
+	})
+
+	t.Run("Recreate", func(t *testing.T) {
+		status, body := confirm(map[string]interface{}{"tableName": "posts", "mode": "recreate"})
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, map[string]interface{}{"status": "ok", "rowsDeleted": float64(1)}, body)
+		assert.Equal(t, int64(1), insertPost())
+
+		var indexes int
+		assert.NoError(t, ts.db.QueryRow("SELECT count(*) FROM sqlite_master WHERE name = 'posts_title'").Scan(&indexes))
+		assert.Equal(t, 1, indexes)
+	})
+
+	t.Run("Read-Only Database", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "ro.db")
+		db, err := sql.Open("sqlite", path)
+		assert.NoError(t, err)
+		_, err = db.Exec("CREATE TABLE t (id INTEGER PRIMARY KEY); INSERT INTO t VALUES (1)")
+		assert.NoError(t, err)
+		db.Close()
+		db, err = sql.Open("sqlite", "file:"+path+"?_pragma=query_only(1)")
+		assert.NoError(t, err)
+		defer db.Close()
+		ts.admin.AddDatabase("ro", db)
+
+		status, body := confirm(map[string]interface{}{"database": "ro", "tableName": "t"})
+		assert.Equal(t, http.StatusForbidden, status)
+		assert.Equal(t, "Forbidden: "+sqliteadmin.ErrReadOnlyDatabase.Error(), body["message"])
+	})
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Unknown Token",
+			params:         map[string]interface{}{"tableName": "posts", "confirmationToken": "abc"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(400),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: " + sqliteadmin.ErrInvalidConfirmation.Error(),
+			},
+		},
+		{
+			name:           "Failure: Unknown Table",
+			params:         map[string]interface{}{"tableName": "missing"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(400),
+				"code":       "TABLE_NOT_FOUND",
+				"message":    "Bad request: " + sqliteadmin.ErrUnknownTable.Error(),
+			},
+		},
+	}, sqliteadmin.TruncateTable, t, ts.server)
+}
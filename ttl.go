@@ -0,0 +1,130 @@
+package sqliteadmin
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultTTLSweepInterval is how often RunTTLSweeper deletes expired rows when
+// no interval is provided.
+const DefaultTTLSweepInterval = time.Minute
+
+// TTLStats reports what the TTL sweeper did.
+type TTLStats struct {
+	// Columns maps each table with a TTL to its expiration column.
+	Columns map[string]string `json:"columns"`
+	// Deleted is the number of expired rows deleted from each table.
+	Deleted   map[string]int64 `json:"deleted"`
+	LastSweep *time.Time       `json:"lastSweep"`
+}
+
+// SetTTLColumn declares that rows of the table expire at the time stored in
+// the column, either as a timestamp or as seconds since the Unix epoch. Rows
+// where the column is NULL never expire. Passing an empty column removes the
+// TTL of the table.
+func (a *Admin) SetTTLColumn(table, column string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if column == "" {
+		delete(a.ttlColumns, table)
+		return
+	}
+	a.ttlColumns[table] = column
+}
+
+// SweepExpired deletes the expired rows of every table with a TTL column from
+// the default database and returns the number of rows deleted per table.
+// Tables that can't be swept are logged and skipped.
+func (a *Admin) SweepExpired(ctx context.Context) map[string]int64 {
+	a.mu.RLock()
+	columns := make(map[string]string, len(a.ttlColumns))
+	for table, column := range a.ttlColumns {
+		columns[table] = column
+	}
+	a.mu.RUnlock()
+
+	deleted := make(map[string]int64)
+	if a.db == nil {
+		return deleted
+	}
+	for table, column := range columns {
+		n, err := deleteExpired(ctx, a.db, table, column)
+		if err != nil {
+			a.logger.Error(fmt.Sprintf("Error deleting expired rows from %s: %v", table, err))
+			continue
+		}
+		deleted[table] = n
+		if n > 0 {
+			a.logger.Info(fmt.Sprintf("Deleted %d expired row(s) from %s", n, table))
+		}
+	}
+
+	now := time.Now()
+	a.mu.Lock()
+	for table, n := range deleted {
+		a.ttlDeleted[table] += n
+	}
+	a.ttlLastSweep = &now
+	a.mu.Unlock()
+
+	return deleted
+}
+
+// RunTTLSweeper calls SweepExpired at the given interval until ctx is done.
+func (a *Admin) RunTTLSweeper(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultTTLSweepInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.SweepExpired(ctx)
+		}
+	}
+}
+
+func (a *Admin) ttlStats() TTLStats {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	stats := TTLStats{
+		Columns:   make(map[string]string, len(a.ttlColumns)),
+		Deleted:   make(map[string]int64, len(a.ttlDeleted)),
+		LastSweep: a.ttlLastSweep,
+	}
+	for table, column := range a.ttlColumns {
+		stats.Columns[table] = column
+	}
+	for table, n := range a.ttlDeleted {
+		stats.Deleted[table] = n
+	}
+	return stats
+}
+
+func deleteExpired(ctx context.Context, q queryer, table, column string) (int64, error) {
+	names, err := getColumnNames(ctx, q, table)
+	if err != nil {
+		return 0, err
+	}
+	if !contains(names, column) {
+		return 0, fmt.Errorf("column %s does not exist", column)
+	}
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE CASE
+		WHEN typeof(%s) IN ('integer', 'real') THEN %s <= unixepoch()
+		ELSE julianday(%s) <= julianday('now')
+	END`, quoteIdent(table), quoteIdent(column), quoteIdent(column), quoteIdent(column))
+	result, err := q.ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting expired rows: %v", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,34 @@
+package sqliteadmin
+
+import "fmt"
+
+// Validator checks a row before it is written. The row has all the columns of
+// the table with the values they will have once written. Returning an error
+// rejects the write and its message is returned to the client with a 400.
+type Validator func(row map[string]any) error
+
+// RegisterValidator registers a validator for the rows written to the table
+// by InsertRow, UpdateRow, MergeRows and DiffTable. Validators of a table run in the order they were registered.
+func (a *Admin) RegisterValidator(table string, v Validator) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.validators[table] = append(a.validators[table], v)
+}
+
+// validateRows runs the validators of the table on the rows, returning the
+// first error.
+func (a *Admin) validateRows(table string, rows []map[string]interface{}) error {
+	a.mu.RLock()
+	validators := a.validators[table]
+	a.mu.RUnlock()
+
+	for _, row := range rows {
+		for _, v := range validators {
+			if err := v(row); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidRow, err)
+			}
+		}
+	}
+	return nil
+}
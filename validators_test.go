@@ -0,0 +1,55 @@
+package sqliteadmin_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidators(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	ts.admin.RegisterValidator("users", func(row map[string]any) error {
+		email, _ := row["email"].(string)
+		if row["email"] != nil && !strings.Contains(email, "@") {
+			return errors.New("email must contain an @")
+		}
+		return nil
+	})
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Invalid Row",
+			params:         map[string]interface{}{"tableName": "users", "row": map[string]interface{}{"id": 1, "email": "alice"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid row: email must contain an @",
+			},
+		},
+		{
+			name:             "Success",
+			params:           map[string]interface{}{"tableName": "users", "row": map[string]interface{}{"id": 1, "email": "alice@example.com"}},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok"},
+		},
+		{
+			// The validator is given the whole row, not just the updated columns
+			name:             "Success: Row With Null Email",
+			params:           map[string]interface{}{"tableName": "users", "row": map[string]interface{}{"id": 9, "name": "Ivy Jones"}},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: map[string]interface{}{"status": "ok"},
+		},
+	}, sqliteadmin.UpdateRow, t, ts.server)
+
+	var email string
+	err := ts.db.QueryRow("SELECT email FROM users WHERE id = 1").Scan(&email)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice@example.com", email)
+}
@@ -0,0 +1,92 @@
+package sqliteadmin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var virtualTableModule = regexp.MustCompile(`(?is)^\s*CREATE\s+VIRTUAL\s+TABLE\s.*?\bUSING\s+(\w+)`)
+
+// virtualTables describes the virtual tables of a database, like fts5 or
+// rtree tables, and the shadow tables where SQLite keeps their data.
+type virtualTables struct {
+	// modules maps the virtual tables to their module.
+	modules map[string]string
+	// shadows maps the shadow tables to their virtual table.
+	shadows map[string]string
+}
+
+// getVirtualTables returns the virtual and shadow tables of the database.
+// PRAGMA table_list tells the shadow tables apart, which are named after
+// their virtual table followed by an underscore and a suffix.
+func getVirtualTables(ctx context.Context, q queryer) (*virtualTables, error) {
+	vt := &virtualTables{modules: map[string]string{}, shadows: map[string]string{}}
+	rows, err := q.QueryContext(ctx, "SELECT name, sql FROM sqlite_master WHERE type = 'table' AND sql LIKE 'CREATE VIRTUAL TABLE%'")
+	if err != nil {
+		return nil, fmt.Errorf("error listing virtual tables: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, createSQL string
+		if err := rows.Scan(&name, &createSQL); err != nil {
+			return nil, fmt.Errorf("error listing virtual tables: %v", err)
+		}
+		if m := virtualTableModule.FindStringSubmatch(createSQL); m != nil {
+			vt.modules[name] = strings.ToLower(m[1])
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing virtual tables: %v", err)
+	}
+	if len(vt.modules) == 0 {
+		return vt, nil
+	}
+
+	shadows, err := queryNames(ctx, q, "SELECT name FROM pragma_table_list WHERE schema = 'main' AND type = 'shadow'")
+	if err != nil {
+		return nil, fmt.Errorf("error listing shadow tables: %v", err)
+	}
+	for shadow := range shadows {
+		// The longest name wins for virtual tables prefixing each other
+		for table := range vt.modules {
+			if strings.HasPrefix(shadow, table+"_") && len(table) > len(vt.shadows[shadow]) {
+				vt.shadows[shadow] = table
+			}
+		}
+	}
+	return vt, nil
+}
+
+// shadowTablesOf returns the shadow tables of the virtual table, sorted.
+func (vt *virtualTables) shadowTablesOf(table string) []string {
+	var shadows []string
+	for shadow, owner := range vt.shadows {
+		if owner == table {
+			shadows = append(shadows, shadow)
+		}
+	}
+	sort.Strings(shadows)
+	return shadows
+}
+
+// checkNotShadowTable writes an error and returns false if the table is a
+// shadow table. Writing to them directly can corrupt the index of their
+// virtual table, which is edited instead.
+func (a *Admin) checkNotShadowTable(ctx context.Context, w http.ResponseWriter, q queryer, table string) bool {
+	var shadow bool
+	err := q.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM pragma_table_list WHERE schema = 'main' AND name = ? AND type = 'shadow')", table).Scan(&shadow)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error checking for shadow table: %v", err))
+		writeError(w, a.apiErr(err))
+		return false
+	}
+	if shadow {
+		writeError(w, apiErrBadRequest(fmt.Sprintf("%s: %s", ErrShadowTable, table)))
+		return false
+	}
+	return true
+}
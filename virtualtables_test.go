@@ -0,0 +1,76 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVirtualTables(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	_, err := ts.db.Exec(`
+		CREATE VIRTUAL TABLE docs USING fts5(title, body);
+		CREATE VIRTUAL TABLE boxes USING rtree(id, min_x, max_x);
+		CREATE TABLE docs_tags (doc_id INTEGER PRIMARY KEY, tag TEXT);
+		INSERT INTO docs VALUES ('Intro', 'Hello world');
+		INSERT INTO boxes VALUES (1, 0, 10);
+	`)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	tables, err := ts.admin.ListTables(ctx, sqliteadmin.ListTablesParams{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"docs": "fts5", "boxes": "rtree"}, tables.VirtualTables)
+	assert.Equal(t, "docs", tables.ShadowTables["docs_data"])
+	assert.Equal(t, "boxes", tables.ShadowTables["boxes_node"])
+	assert.NotContains(t, tables.ShadowTables, "docs_tags")
+
+	res, err := ts.admin.GetTable(ctx, sqliteadmin.GetTableParams{TableName: "docs", IncludeInfo: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello world", res.Rows[0]["body"])
+	assert.Equal(t, "fts5", res.TableInfo.Module)
+	assert.Equal(t, []string{"docs_config", "docs_content", "docs_data", "docs_docsize", "docs_idx"}, res.TableInfo.ShadowTables)
+
+	// Shadow tables can be queried
+	res, err = ts.admin.GetTable(ctx, sqliteadmin.GetTableParams{TableName: "boxes_rowid", IncludeInfo: true})
+	assert.NoError(t, err)
+	assert.Len(t, res.Rows, 1)
+	assert.Equal(t, "boxes", res.TableInfo.ShadowOf)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Shadow table",
+			params:         map[string]interface{}{"tableName": "docs_content", "row": map[string]interface{}{"c0": "Orphan"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: shadow tables can't be edited directly, edit their virtual table instead: docs_content",
+			},
+		},
+	}, sqliteadmin.InsertRow, t, ts.server)
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Shadow table",
+			params:         map[string]interface{}{"tableName": "boxes_node"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: shadow tables can't be edited directly, edit their virtual table instead: boxes_node",
+			},
+		},
+	}, sqliteadmin.TruncateTable, t, ts.server)
+
+	_, err = ts.admin.DeleteRows(ctx, sqliteadmin.DeleteRowsParams{TableName: "docs_docsize", IDs: []string{"1"}})
+	assert.ErrorContains(t, err, "Bad request: shadow tables can't be edited directly")
+	res, err = ts.admin.GetTable(ctx, sqliteadmin.GetTableParams{TableName: "docs_docsize"})
+	assert.NoError(t, err)
+	assert.Len(t, res.Rows, 1)
+}
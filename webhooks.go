@@ -0,0 +1,284 @@
+package sqliteadmin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// webhooksTable is where the webhooks added at runtime are persisted, in the
+// default database.
+const webhooksTable = "_sqliteadmin_webhooks"
+
+// EventWebhookTest is sent to a webhook by the TestWebhook command.
+const EventWebhookTest EventType = "webhook.test"
+
+// Webhook is a webhook added at runtime with the AddWebhook command.
+type Webhook struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Events are the types of events sent to the webhook, all of them if
+	// empty.
+	Events  []EventType       `json:"events"`
+	Headers map[string]string `json:"-"`
+}
+
+func (h Webhook) handles(eventType EventType) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+	for _, t := range h.Events {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func (h Webhook) notifier() Notifier {
+	return &WebhookNotifier{URL: h.URL, Headers: h.Headers}
+}
+
+// webhookNotifiers returns the notifiers of the runtime webhooks handling the
+// event type.
+func (a *Admin) webhookNotifiers(eventType EventType) []Notifier {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	var notifiers []Notifier
+	for _, h := range a.webhooks {
+		if h.handles(eventType) {
+			notifiers = append(notifiers, h.notifier())
+		}
+	}
+	return notifiers
+}
+
+// loadWebhooks loads the webhooks persisted in the default database.
+func (a *Admin) loadWebhooks(ctx context.Context) error {
+	if a.db == nil {
+		return nil
+	}
+	exists, err := checkTableExists(ctx, a.db, webhooksTable)
+	if err != nil || !exists {
+		return err
+	}
+
+	rows, err := a.db.QueryContext(ctx, fmt.Sprintf("SELECT id, url, events, headers FROM %s", quoteIdent(webhooksTable)))
+	if err != nil {
+		return fmt.Errorf("error querying webhooks: %v", err)
+	}
+	defer rows.Close()
+
+	webhooks := make(map[string]Webhook)
+	for rows.Next() {
+		var h Webhook
+		var events, headers string
+		if err := rows.Scan(&h.ID, &h.URL, &events, &headers); err != nil {
+			return fmt.Errorf("error scanning row: %v", err)
+		}
+		if err := json.Unmarshal([]byte(events), &h.Events); err != nil {
+			return fmt.Errorf("error decoding events of webhook %s: %v", h.ID, err)
+		}
+		if err := json.Unmarshal([]byte(headers), &h.Headers); err != nil {
+			return fmt.Errorf("error decoding headers of webhook %s: %v", h.ID, err)
+		}
+		webhooks[h.ID] = h
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error reading rows: %v", err)
+	}
+
+	a.mu.Lock()
+	a.webhooks = webhooks
+	a.mu.Unlock()
+	return nil
+}
+
+type webhookInfo struct {
+	ID          string      `json:"id"`
+	URL         string      `json:"url"`
+	Events      []EventType `json:"events"`
+	HeaderNames []string    `json:"headerNames"`
+}
+
+func (a *Admin) listWebhooks(w http.ResponseWriter) {
+	a.logger.Info("Command: ListWebhooks")
+
+	a.mu.RLock()
+	// Header values usually contain secrets so only their names are listed
+	webhooks := make([]webhookInfo, 0, len(a.webhooks))
+	for _, h := range a.webhooks {
+		info := webhookInfo{ID: h.ID, URL: h.URL, Events: h.Events, HeaderNames: []string{}}
+		for name := range h.Headers {
+			info.HeaderNames = append(info.HeaderNames, name)
+		}
+		sort.Strings(info.HeaderNames)
+		webhooks = append(webhooks, info)
+	}
+	a.mu.RUnlock()
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].ID < webhooks[j].ID
+	})
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"webhooks": webhooks})
+}
+
+func (a *Admin) addWebhook(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	if a.db == nil {
+		writeError(w, apiErrBadRequest(ErrMissingDatabase.Error()))
+		return
+	}
+
+	url, ok := params["url"].(string)
+	if !ok || url == "" {
+		writeError(w, apiErrBadRequest(ErrMissingURL.Error()))
+		return
+	}
+
+	h := Webhook{URL: url, Events: []EventType{}, Headers: map[string]string{}}
+	if params["events"] != nil {
+		events, ok := params["events"].([]interface{})
+		if !ok {
+			writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+			return
+		}
+		for _, e := range events {
+			eventType, ok := e.(string)
+			if !ok {
+				writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+				return
+			}
+			h.Events = append(h.Events, EventType(eventType))
+		}
+	}
+	if params["headers"] != nil {
+		headers, ok := params["headers"].(map[string]interface{})
+		if !ok {
+			writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+			return
+		}
+		for name, value := range headers {
+			v, ok := value.(string)
+			if !ok {
+				writeError(w, apiErrBadRequest(ErrInvalidInput.Error()))
+				return
+			}
+			h.Headers[name] = v
+		}
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: AddWebhook, url=%s, events=%v", h.URL, h.Events))
+
+	id, err := newID()
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error generating webhook id: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	h.ID = id
+
+	if err := saveWebhook(ctx, a.db, h); err != nil {
+		a.logger.Error(fmt.Sprintf("Error saving webhook: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+
+	a.mu.Lock()
+	a.webhooks[h.ID] = h
+	a.mu.Unlock()
+	a.logger.Info(fmt.Sprintf("Audit: added webhook %s", h.ID))
+
+	json.NewEncoder(w).Encode(map[string]string{"id": h.ID})
+}
+
+func saveWebhook(ctx context.Context, q queryer, h Webhook) error {
+	_, err := q.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id TEXT PRIMARY KEY,
+		url TEXT NOT NULL,
+		events TEXT NOT NULL,
+		headers TEXT NOT NULL
+	)`, quoteIdent(webhooksTable)))
+	if err != nil {
+		return fmt.Errorf("error creating webhooks table: %v", err)
+	}
+
+	events, err := json.Marshal(h.Events)
+	if err != nil {
+		return err
+	}
+	headers, err := json.Marshal(h.Headers)
+	if err != nil {
+		return err
+	}
+	_, err = q.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (id, url, events, headers) VALUES (?, ?, ?, ?)", quoteIdent(webhooksTable)),
+		h.ID, h.URL, string(events), string(headers))
+	if err != nil {
+		return fmt.Errorf("error inserting webhook: %v", err)
+	}
+	return nil
+}
+
+func (a *Admin) removeWebhook(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	h, ok := a.getWebhook(w, params)
+	if !ok {
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: RemoveWebhook, id=%s", h.ID))
+
+	_, err := a.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE id = ?", quoteIdent(webhooksTable)), h.ID)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error deleting webhook: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+
+	a.mu.Lock()
+	delete(a.webhooks, h.ID)
+	a.mu.Unlock()
+	a.logger.Info(fmt.Sprintf("Audit: removed webhook %s", h.ID))
+
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// testWebhook synchronously sends a test event to the webhook so that the
+// delivery error, if any, can be shown to the user.
+func (a *Admin) testWebhook(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	h, ok := a.getWebhook(w, params)
+	if !ok {
+		return
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: TestWebhook, id=%s", h.ID))
+
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	event := Event{Type: EventWebhookTest, Time: time.Now(), Message: "Test notification from SQLite Admin"}
+	if err := h.notifier().Notify(ctx, event); err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"delivered": false, "error": err.Error()})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"delivered": true})
+}
+
+func (a *Admin) getWebhook(w http.ResponseWriter, params map[string]interface{}) (Webhook, bool) {
+	id, ok := params["id"].(string)
+	if !ok || id == "" {
+		writeError(w, apiErrBadRequest(ErrMissingWebhookID.Error()))
+		return Webhook{}, false
+	}
+
+	a.mu.RLock()
+	h, ok := a.webhooks[id]
+	a.mu.RUnlock()
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrUnknownWebhookID.Error()))
+		return Webhook{}, false
+	}
+	return h, true
+}
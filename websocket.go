@@ -0,0 +1,331 @@
+package sqliteadmin
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+// Commands that are only supported over WebSocket connections.
+const (
+	// Authenticate sets the credentials ("credentials", formatted like the
+	// Authorization header) or the CSRF token of the session ("csrfToken")
+	// used by the next commands, for clients that can't set headers on the
+	// WebSocket handshake.
+	Authenticate Command = "Authenticate"
+	// Watch pushes a TableEvent whenever the table may have changed.
+	Watch Command = "Watch"
+	// Unwatch stops the events of a table.
+	Unwatch Command = "Unwatch"
+)
+
+// WebSocketRequest is a command sent over a WebSocket connection. The ID is
+// echoed in its response so that clients can have several commands in flight.
+type WebSocketRequest struct {
+	ID      string                 `json:"id"`
+	Command Command                `json:"command"`
+	Params  map[string]interface{} `json:"params"`
+}
+
+// WebSocketMessage is either the response to a WebSocketRequest, with the
+// status and body HandlePost would have responded with, or an event.
+type WebSocketMessage struct {
+	ID     string      `json:"id,omitempty"`
+	Status int         `json:"status,omitempty"`
+	Body   interface{} `json:"body,omitempty"`
+	Event  *TableEvent `json:"event,omitempty"`
+}
+
+// TableEvent is pushed to the clients watching a table when its database
+// changed. The data version of SQLite doesn't tell which tables changed, so
+// clients are told about every watched table of the database.
+type TableEvent struct {
+	Database  string `json:"database"`
+	TableName string `json:"tableName"`
+	Version   string `json:"version"`
+}
+
+// HandleWebSocket serves the same commands as HandlePost over a WebSocket
+// connection, along with Watch to receive events when tables change. Each
+// command goes through HandlePost with the headers of the handshake, so
+// authentication, policies and logging work the same way.
+func (a *Admin) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{OriginPatterns: a.webSocketOrigins})
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error accepting WebSocket connection: %v", err))
+		return
+	}
+	defer conn.CloseNow()
+	if a.maxBodySize >= 0 {
+		conn.SetReadLimit(a.maxBodySize)
+	}
+
+	c := &wsConn{
+		a:        a,
+		conn:     conn,
+		r:        r,
+		header:   r.Header.Clone(),
+		watched:  make(map[*sql.DB]map[string]string),
+		versions: make(map[*sql.DB]int64),
+	}
+	// Responses are sent as JSON whatever the client accepts
+	c.header.Del("Accept-Encoding")
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go c.watch(ctx)
+
+	for {
+		var req WebSocketRequest
+		if err := wsjson.Read(ctx, conn, &req); err != nil {
+			if websocket.CloseStatus(err) == -1 && ctx.Err() == nil {
+				a.logger.Error(fmt.Sprintf("Error reading WebSocket message: %v", err))
+			}
+			return
+		}
+		go c.handle(ctx, req)
+	}
+}
+
+type wsConn struct {
+	a    *Admin
+	conn *websocket.Conn
+	r    *http.Request
+
+	mu     sync.Mutex
+	header http.Header
+	// watched maps the databases to their watched tables, with the name of
+	// the database they were watched with.
+	watched map[*sql.DB]map[string]string
+	// versions are the last data versions seen of the watched databases.
+	versions map[*sql.DB]int64
+}
+
+func (c *wsConn) handle(ctx context.Context, req WebSocketRequest) {
+	res := &bufferedResponse{header: make(http.Header)}
+	switch req.Command {
+	case Authenticate:
+		c.authenticate(res, req.Params)
+	case Watch, Unwatch:
+		if c.allowed(ctx, res, req.Command, req.Params) {
+			c.setWatched(ctx, res, req.Command, req.Params)
+		}
+	default:
+		body, _ := json.Marshal(CommandRequest{Command: req.Command, Params: req.Params})
+		c.a.HandlePost(res, c.request(ctx, body))
+	}
+
+	res.WriteHeader(http.StatusOK)
+	msg := WebSocketMessage{ID: req.ID, Status: res.status, Body: res.body.String()}
+	if json.Valid(res.body.Bytes()) {
+		msg.Body = json.RawMessage(res.body.Bytes())
+	}
+	if err := wsjson.Write(ctx, c.conn, msg); err != nil && ctx.Err() == nil {
+		c.a.logger.Error(fmt.Sprintf("Error writing WebSocket message: %v", err))
+	}
+}
+
+// request returns a request like the handshake, with the credentials of the
+// connection, for HandlePost to handle.
+func (c *wsConn) request(ctx context.Context, body []byte) *http.Request {
+	r := c.r.Clone(ctx)
+	r.Method = http.MethodPost
+	c.mu.Lock()
+	r.Header = c.header.Clone()
+	c.mu.Unlock()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	return r
+}
+
+func (c *wsConn) authenticate(w http.ResponseWriter, params map[string]interface{}) {
+	c.a.logger.Info("Command: Authenticate")
+
+	c.mu.Lock()
+	previous := c.header.Clone()
+	if credentials, ok := params["credentials"].(string); ok {
+		c.header.Set("Authorization", credentials)
+	}
+	if token, ok := params["csrfToken"].(string); ok {
+		c.header.Set(CSRFHeader, token)
+	}
+	c.mu.Unlock()
+
+	if !c.authorized(w) {
+		c.mu.Lock()
+		c.header = previous
+		c.mu.Unlock()
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// authorized checks the credentials of the connection for the commands
+// handled without going through HandlePost.
+func (c *wsConn) authorized(w http.ResponseWriter) bool {
+	if !c.a.authEnabled() {
+		return true
+	}
+	if _, ok := c.a.authenticate(c.request(context.Background(), nil)); !ok {
+		writeError(w, apiErrUnauthorized())
+		return false
+	}
+	return true
+}
+
+// allowed checks the credentials, the role and the tables of the commands
+// handled without going through HandlePost, as HandlePost does.
+func (c *wsConn) allowed(ctx context.Context, w http.ResponseWriter, command Command, params map[string]interface{}) bool {
+	r := c.request(ctx, nil)
+	if c.a.authEnabled() {
+		id, ok := c.a.authenticate(r)
+		if !ok {
+			writeError(w, apiErrUnauthorized())
+			return false
+		}
+		ctx = context.WithValue(ctx, userKey, id.user)
+		ctx = context.WithValue(ctx, roleKey, id.role)
+		if id.commands != nil {
+			ctx = context.WithValue(ctx, commandsKey, id.commands)
+		}
+	}
+	return c.a.checkRole(w, r.WithContext(ctx), command) && c.a.checkTableParams(w, params)
+}
+
+func (c *wsConn) setWatched(ctx context.Context, w http.ResponseWriter, command Command, params map[string]interface{}) {
+	db, ok := c.a.getReadDB(w, params)
+	if !ok {
+		return
+	}
+	table, ok := params["tableName"].(string)
+	if !ok {
+		writeError(w, apiErrBadRequest(ErrMissingTableName.Error()))
+		return
+	}
+	name, _ := params["database"].(string)
+	c.a.logger.Info(fmt.Sprintf("Command: %s, table=%s, database=%s", command, table, name))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if command == Unwatch {
+		delete(c.watched[db], table)
+		if len(c.watched[db]) == 0 {
+			delete(c.watched, db)
+			delete(c.versions, db)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+		return
+	}
+
+	if db.Stats().MaxOpenConnections == 1 {
+		writeError(w, apiErrBadRequest(ErrLongPollUnsupported.Error()))
+		return
+	}
+	exists, err := checkTableExists(ctx, c.a.cached(db), table)
+	if err != nil {
+		c.a.logger.Error(fmt.Sprintf("Error checking table existence: %v", err))
+		writeError(w, c.a.apiErr(err))
+		return
+	}
+	if !exists || isInternalTable(table) {
+		writeError(w, apiErrTableNotFound())
+		return
+	}
+	if c.watched[db] == nil {
+		// Changes are reported from now on rather than from the next poll
+		version, err := c.a.versions.version(ctx, db)
+		if err != nil {
+			c.a.logger.Error(fmt.Sprintf("Error reading data version: %v", err))
+			writeError(w, c.a.apiErr(err))
+			return
+		}
+		c.watched[db] = make(map[string]string)
+		c.versions[db] = version
+	}
+	c.watched[db][table] = name
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// watch polls the data version of the watched databases and pushes an event
+// for their tables when it changes.
+func (c *wsConn) watch(ctx context.Context) {
+	ticker := time.NewTicker(waitPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		c.mu.Lock()
+		watched := make(map[*sql.DB]map[string]string, len(c.watched))
+		for db, tables := range c.watched {
+			watched[db] = make(map[string]string, len(tables))
+			for table, name := range tables {
+				watched[db][table] = name
+			}
+		}
+		c.mu.Unlock()
+
+		for db, tables := range watched {
+			version, err := c.a.versions.version(ctx, db)
+			if err != nil {
+				if ctx.Err() == nil {
+					c.a.logger.Error(fmt.Sprintf("Error reading data version: %v", err))
+				}
+				continue
+			}
+			c.mu.Lock()
+			previous, ok := c.versions[db]
+			if ok {
+				c.versions[db] = version
+			}
+			c.mu.Unlock()
+			if !ok || previous == version {
+				continue
+			}
+			for table, name := range tables {
+				event := &TableEvent{Database: name, TableName: table, Version: strconv.FormatInt(version, 10)}
+				if err := wsjson.Write(ctx, c.conn, WebSocketMessage{Event: event}); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// bufferedResponse collects the response of a command to send it as a
+// WebSocket message or to return it from Do.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+	return b.body.Write(p)
+}
@@ -0,0 +1,162 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebSocket(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "app.db"))
+	assert.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("CREATE TABLE t (id INTEGER PRIMARY KEY)")
+	assert.NoError(t, err)
+	ts.admin.AddDatabase("app", db)
+
+	server := httptest.NewServer(http.HandlerFunc(ts.admin.HandleWebSocket))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	send := func(conn *websocket.Conn, id string, command sqliteadmin.Command, params map[string]interface{}) map[string]interface{} {
+		err := wsjson.Write(ctx, conn, sqliteadmin.WebSocketRequest{ID: id, Command: command, Params: params})
+		assert.NoError(t, err)
+		var msg map[string]interface{}
+		assert.NoError(t, wsjson.Read(ctx, conn, &msg))
+		return msg
+	}
+
+	t.Run("Commands", func(t *testing.T) {
+		conn, _, err := websocket.Dial(ctx, url, &websocket.DialOptions{
+			HTTPHeader: http.Header{"Authorization": {"user:password"}},
+		})
+		assert.NoError(t, err)
+		defer conn.CloseNow()
+
+		msg := send(conn, "1", sqliteadmin.GetTable, map[string]interface{}{"tableName": "users", "limit": 1})
+		assert.Equal(t, "1", msg["id"])
+		assert.Equal(t, float64(http.StatusOK), msg["status"])
+		assert.Equal(t, map[string]interface{}{
+			"rows": []interface{}{
+				map[string]interface{}{"id": float64(1), "name": "Alice", "email": "alice@gmail.com"},
+			},
+		}, msg["body"])
+
+		msg = send(conn, "2", sqliteadmin.GetTable, map[string]interface{}{})
+		assert.Equal(t, float64(http.StatusBadRequest), msg["status"])
+	})
+
+	t.Run("Authenticate", func(t *testing.T) {
+		conn, _, err := websocket.Dial(ctx, url, nil)
+		assert.NoError(t, err)
+		defer conn.CloseNow()
+
+		msg := send(conn, "1", sqliteadmin.Ping, nil)
+		assert.Equal(t, float64(http.StatusUnauthorized), msg["status"])
+
+		msg = send(conn, "2", sqliteadmin.Authenticate, map[string]interface{}{"credentials": "user:wrong"})
+		assert.Equal(t, float64(http.StatusUnauthorized), msg["status"])
+
+		msg = send(conn, "3", sqliteadmin.Authenticate, map[string]interface{}{"credentials": "user:password"})
+		assert.Equal(t, float64(http.StatusOK), msg["status"])
+
+		msg = send(conn, "4", sqliteadmin.Ping, nil)
+		assert.Equal(t, float64(http.StatusOK), msg["status"])
+	})
+
+	t.Run("Watch", func(t *testing.T) {
+		conn, _, err := websocket.Dial(ctx, url, &websocket.DialOptions{
+			HTTPHeader: http.Header{"Authorization": {"user:password"}},
+		})
+		assert.NoError(t, err)
+		defer conn.CloseNow()
+
+		msg := send(conn, "1", sqliteadmin.Watch, map[string]interface{}{"database": "app", "tableName": "t"})
+		assert.Equal(t, float64(http.StatusOK), msg["status"])
+
+		_, err = db.Exec("INSERT INTO t (id) VALUES (1)")
+		assert.NoError(t, err)
+
+		var event sqliteadmin.WebSocketMessage
+		assert.NoError(t, wsjson.Read(ctx, conn, &event))
+		if assert.NotNil(t, event.Event) {
+			assert.Equal(t, "app", event.Event.Database)
+			assert.Equal(t, "t", event.Event.TableName)
+		}
+
+		msg = send(conn, "2", sqliteadmin.Watch, map[string]interface{}{"tableName": "users"})
+		assert.Equal(t, float64(http.StatusBadRequest), msg["status"])
+		body, _ := json.Marshal(msg["body"])
+		assert.Contains(t, string(body), "more than one connection")
+	})
+}
+
+func TestWebSocketWatchChecks(t *testing.T) {
+	ts, close := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.Users = []sqliteadmin.User{{Username: "typo", Password: "secret", Role: "owner"}}
+		c.ExcludeTables = []string{"secret"}
+	})
+	defer close()
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "app.db"))
+	assert.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("CREATE TABLE t (id INTEGER PRIMARY KEY); CREATE TABLE secret (id INTEGER PRIMARY KEY); CREATE TABLE _sqliteadmin_notes (id INTEGER PRIMARY KEY)")
+	assert.NoError(t, err)
+	ts.admin.AddDatabase("app", db)
+
+	key, err := ts.admin.CreateAPIKey(context.Background(), sqliteadmin.CreateAPIKeyParams{
+		Name:     "reports",
+		Role:     sqliteadmin.RoleViewer,
+		Commands: []sqliteadmin.Command{sqliteadmin.GetTable},
+	})
+	assert.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(ts.admin.HandleWebSocket))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	watch := func(credentials, table string) float64 {
+		conn, _, err := websocket.Dial(ctx, url, &websocket.DialOptions{
+			HTTPHeader: http.Header{"Authorization": {credentials}},
+		})
+		assert.NoError(t, err)
+		defer conn.CloseNow()
+
+		err = wsjson.Write(ctx, conn, sqliteadmin.WebSocketRequest{ID: "1", Command: sqliteadmin.Watch, Params: map[string]interface{}{"database": "app", "tableName": table}})
+		assert.NoError(t, err)
+		var msg map[string]interface{}
+		assert.NoError(t, wsjson.Read(ctx, conn, &msg))
+		return msg["status"].(float64)
+	}
+
+	// Principals denied the command by their role or API key can't watch
+	assert.Equal(t, float64(http.StatusForbidden), watch("typo:secret", "t"))
+	assert.Equal(t, float64(http.StatusForbidden), watch("Bearer "+key.Key, "t"))
+
+	// Nor can excluded and internal tables be watched
+	assert.Equal(t, float64(http.StatusBadRequest), watch("user:password", "secret"))
+	assert.Equal(t, float64(http.StatusBadRequest), watch("user:password", "SECRET"))
+	assert.Equal(t, float64(http.StatusBadRequest), watch("user:password", "_sqliteadmin_notes"))
+	assert.Equal(t, float64(http.StatusOK), watch("user:password", "t"))
+}
@@ -0,0 +1,251 @@
+package sqliteadmin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/joelseq/sqliteadmin-go/querybuilder"
+)
+
+// windowKeyPrefix prefixes the aliases of the key columns selected along with
+// the rows of a window.
+const windowKeyPrefix = "_sqliteadmin_key"
+
+// GetTableWindowParams are the params of GetTableWindow.
+type GetTableWindowParams struct {
+	Database  string     `json:"database,omitempty" mapstructure:"database"`
+	TableName string     `json:"tableName" mapstructure:"tableName"`
+	Condition *Condition `json:"condition,omitempty" mapstructure:"condition"`
+	// After and Before are keys returned by a previous window. The window
+	// holds the rows following After, or preceding Before when only Before
+	// is set, or between both. It starts at the first row when neither is.
+	After  interface{} `json:"after,omitempty" mapstructure:"after"`
+	Before interface{} `json:"before,omitempty" mapstructure:"before"`
+	// Count is the maximum number of rows, the default limit if zero.
+	Count int `json:"count,omitempty" mapstructure:"count"`
+}
+
+func (p *GetTableWindowParams) validate() error {
+	if p.TableName == "" {
+		return ErrMissingTableName
+	}
+	if p.Count < 0 {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+// TableWindow is the response of GetTableWindow.
+type TableWindow struct {
+	Rows []map[string]interface{} `json:"rows"`
+	// Keys are the keys of the rows, to send as After or Before. They are
+	// the rowids, or the primary keys of WITHOUT ROWID tables, as arrays if
+	// they have several columns.
+	Keys []interface{} `json:"keys"`
+	// More is set when there are rows past the window, following it or
+	// preceding it when only Before was given.
+	More bool `json:"more"`
+}
+
+// getTableWindow returns the rows of a table around a key, ordered by key, for
+// grids scrolling through the rows. Unlike a page at an offset, which has to
+// step over the rows before it, the window is found with the index of the key
+// however deep the grid is scrolled, and rows inserted or deleted above it
+// don't shift it.
+func (a *Admin) getTableWindow(ctx context.Context, w http.ResponseWriter, params map[string]interface{}) {
+	var p GetTableWindowParams
+	if err := a.decodeParams(params, &p); err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	db, ok := a.getReadDB(w, params)
+	if !ok {
+		return
+	}
+	table := p.TableName
+
+	count := a.defaultLimit
+	if p.Count > 0 {
+		count = p.Count
+	}
+	// Windows are bounded even when pages aren't
+	if count < 0 {
+		count = DefaultLimit
+	}
+	if exceeds(count, a.maxLimit) {
+		count = a.maxLimit
+	}
+
+	a.logger.Info(fmt.Sprintf("Command: GetTableWindow, table=%s, after=%v, before=%v, count=%d", table, p.After, p.Before, count))
+
+	condition := p.Condition
+	if condition != nil {
+		if exceeds(conditionDepth(condition), a.maxConditionDepth) {
+			writeError(w, apiErrBadRequest(ErrConditionTooDeep.Error()))
+			return
+		}
+		if !a.checkCondition(w, table, condition) {
+			return
+		}
+	}
+	q := a.cached(db)
+	exists, err := checkTableExists(ctx, q, table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error checking table existence: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	if !exists {
+		writeError(w, apiErrTableNotFound())
+		return
+	}
+	if err := checkConditionColumns(ctx, q, table, condition); err != nil {
+		writeError(w, a.apiErr(err))
+		return
+	}
+
+	key, err := getWindowKey(ctx, q, table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error getting key: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	for _, c := range key {
+		// The keys would reveal the hidden values
+		if policy, ok := a.columnPolicy(table, c); ok && policy.hides() {
+			writeError(w, apiErrBadRequest(fmt.Sprintf("%s: %s", ErrHiddenColumn, c)))
+			return
+		}
+	}
+	after, err := keyValues(p.After, len(key))
+	if err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+	before, err := keyValues(p.Before, len(key))
+	if err != nil {
+		writeError(w, apiErrBadRequest(err.Error()))
+		return
+	}
+
+	scope, err := a.rowScope(ctx, table)
+	if err != nil {
+		a.writeScopeError(w, err)
+		return
+	}
+	if scope != nil {
+		condition = scopeCondition(scope, condition)
+	}
+
+	res, err := queryWindow(ctx, q, table, key, condition, after, before, count)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error querying table: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	columnTypes, err := getColumnTypes(ctx, q, table)
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Error getting column types: %v", err))
+		writeError(w, a.apiErr(err))
+		return
+	}
+	convertBooleans(res.Rows, columnTypes)
+	a.protectRows(ctx, table, res.Rows)
+	a.encodeRows(res.Rows)
+	a.recordRows(len(res.Rows))
+
+	json.NewEncoder(w).Encode(res)
+}
+
+// getWindowKey returns the columns ordering the rows of a window: the rowid,
+// or the primary key of WITHOUT ROWID tables.
+func getWindowKey(ctx context.Context, q queryer, table string) ([]string, error) {
+	withoutRowid, err := isWithoutRowidTable(ctx, q, table)
+	if err != nil {
+		return nil, err
+	}
+	if !withoutRowid {
+		return []string{"rowid"}, nil
+	}
+	return getPrimaryKeyColumns(ctx, q, table)
+}
+
+// keyValues returns the values of a key given as a value, or as an array for
+// keys of several columns, and nil if there is no key.
+func keyValues(key interface{}, columns int) ([]interface{}, error) {
+	if key == nil {
+		return nil, nil
+	}
+	values, ok := key.([]interface{})
+	if !ok {
+		values = []interface{}{key}
+	}
+	if len(values) != columns {
+		return nil, fmt.Errorf("%w: the key has %d column(s)", ErrInvalidKey, columns)
+	}
+	return values, nil
+}
+
+// queryWindow reads up to count rows of the table after and before the keys,
+// plus one to tell whether there are more.
+func queryWindow(ctx context.Context, q queryer, table string, key []string, condition *Condition, after, before []interface{}, count int) (*TableWindow, error) {
+	// Row values compare the columns of the key in order
+	columns := "(" + strings.Join(querybuilder.Idents(key), ", ") + ")"
+	placeholders := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(key)), ", ") + ")"
+	selected := make([]string, 0, len(key)+1)
+	for i, c := range key {
+		selected = append(selected, fmt.Sprintf("%s AS %s", querybuilder.Ident(c), querybuilder.Ident(fmt.Sprint(windowKeyPrefix, i))))
+	}
+	selected = append(selected, "*")
+
+	b := querybuilder.SelectRaw(selected...).From(table).Where(conditionExpr(condition))
+	if after != nil {
+		b.Where(querybuilder.Raw(columns+" > "+placeholders, after...))
+	}
+	if before != nil {
+		b.Where(querybuilder.Raw(columns+" < "+placeholders, before...))
+	}
+	// Reading backwards from Before finds the rows right before it
+	backwards := before != nil && after == nil
+	for _, c := range key {
+		if backwards {
+			b.OrderBy(querybuilder.Ident(c) + " DESC")
+		} else {
+			b.OrderBy(querybuilder.Ident(c))
+		}
+	}
+	query, args := b.Limit(count + 1).Build()
+	rows, err := queryRows(ctx, q, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &TableWindow{Rows: []map[string]interface{}{}, Keys: []interface{}{}}
+	if len(rows) > count {
+		res.More = true
+		rows = rows[:count]
+	}
+	if backwards {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	for _, row := range rows {
+		values := make([]interface{}, len(key))
+		for i := range key {
+			alias := fmt.Sprint(windowKeyPrefix, i)
+			values[i] = row[alias]
+			delete(row, alias)
+		}
+		if len(values) == 1 {
+			res.Keys = append(res.Keys, values[0])
+		} else {
+			res.Keys = append(res.Keys, values)
+		}
+		res.Rows = append(res.Rows, row)
+	}
+	return res, nil
+}
@@ -0,0 +1,95 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTableWindow(t *testing.T) {
+	ts, close := setupTestServer(t)
+	defer close()
+
+	_, err := ts.db.Exec(`
+		CREATE TABLE memberships (org TEXT, user_id INTEGER, role TEXT, PRIMARY KEY (org, user_id)) WITHOUT ROWID;
+		INSERT INTO memberships VALUES ('acme', 2, 'admin'), ('acme', 1, 'member'), ('beta', 1, 'owner');
+	`)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	names := func(w *sqliteadmin.TableWindow) []interface{} {
+		var names []interface{}
+		for _, row := range w.Rows {
+			names = append(names, row["name"])
+		}
+		return names
+	}
+
+	first, err := ts.admin.GetTableWindow(ctx, sqliteadmin.GetTableWindowParams{TableName: "users", Count: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"Alice", "Bob", "Charlie"}, names(first))
+	assert.Equal(t, []interface{}{float64(1), float64(2), float64(3)}, first.Keys)
+	assert.True(t, first.More)
+
+	next, err := ts.admin.GetTableWindow(ctx, sqliteadmin.GetTableWindowParams{TableName: "users", After: first.Keys[2], Count: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{float64(4), float64(5), float64(6)}, next.Keys)
+
+	last, err := ts.admin.GetTableWindow(ctx, sqliteadmin.GetTableWindowParams{TableName: "users", After: 6, Count: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{float64(7), float64(8), float64(9)}, last.Keys)
+	assert.False(t, last.More)
+
+	// Scrolling up reads the rows right before the key, in order
+	previous, err := ts.admin.GetTableWindow(ctx, sqliteadmin.GetTableWindowParams{TableName: "users", Before: 7, Count: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{float64(5), float64(6)}, previous.Keys)
+	assert.True(t, previous.More)
+
+	between, err := ts.admin.GetTableWindow(ctx, sqliteadmin.GetTableWindowParams{
+		TableName: "users",
+		After:     2,
+		Before:    8,
+		Condition: &sqliteadmin.Condition{Cases: []sqliteadmin.Case{
+			sqliteadmin.Filter{Column: "name", Operator: sqliteadmin.OperatorNotEquals, Value: "Eve"},
+		}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{float64(3), float64(4), float64(6), float64(7)}, between.Keys)
+	assert.False(t, between.More)
+
+	composite, err := ts.admin.GetTableWindow(ctx, sqliteadmin.GetTableWindowParams{
+		TableName: "memberships",
+		After:     []interface{}{"acme", 1},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{[]interface{}{"acme", float64(2)}, []interface{}{"beta", float64(1)}}, composite.Keys)
+	assert.Equal(t, "admin", composite.Rows[0]["role"])
+	assert.NotContains(t, composite.Rows[0], "_sqliteadmin_key0")
+
+	runTestCases([]TestCase{
+		{
+			name:           "Failure: Key of the wrong size",
+			params:         map[string]interface{}{"tableName": "memberships", "after": "acme"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "BAD_REQUEST",
+				"message":    "Bad request: invalid key: the key has 2 column(s)",
+			},
+		},
+		{
+			name:           "Failure: Unknown table",
+			params:         map[string]interface{}{"tableName": "unknown"},
+			expectedStatus: http.StatusBadRequest,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusBadRequest),
+				"code":       "TABLE_NOT_FOUND",
+				"message":    "Bad request: unknown table",
+			},
+		},
+	}, sqliteadmin.GetTableWindow, t, ts.server)
+}
@@ -0,0 +1,117 @@
+package sqliteadmin
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultWriteQueueTimeout is how long a mutating command waits for the ones
+// ahead of it when Config.WriteQueueTimeout is zero.
+const DefaultWriteQueueTimeout = 30 * time.Second
+
+// mutatingCommands are the commands that write to a database, which are run
+// one at a time so that the edits of admins working simultaneously don't
+// interleave with each other and with the writes of the application.
+var mutatingCommands = map[Command]bool{
+	DeleteRows:           true,
+	UpdateRow:            true,
+	UpdateCell:           true,
+	InsertRow:            true,
+	InsertRows:           true,
+	MergeRows:            true,
+	RunAction:            true,
+	CommitOperation:      true,
+	AddWebhook:           true,
+	RemoveWebhook:        true,
+	DiffTable:            true,
+	AddCheckConstraint:   true,
+	DropCheckConstraint:  true,
+	CreateTrigger:        true,
+	DropTrigger:          true,
+	SetFlag:              true,
+	CreateFlagsTable:     true,
+	CreateSavedQuery:     true,
+	DeleteSavedQuery:     true,
+	UploadArchiveFile:    true,
+	DeleteArchiveFile:    true,
+	RotateEncryptionKeys: true,
+	CommitTransaction:    true,
+	CopyRows:             true,
+	SeedFixtures:         true,
+	SeedTable:            true,
+	AnonymizeTable:       true,
+	TruncateTable:        true,
+	ResetSequence:        true,
+	DeleteDuplicates:     true,
+	IncrementalVacuum:    true,
+	CreateAPIKey:         true,
+	RevokeAPIKey:         true,
+}
+
+// writeQueue serializes the mutating commands. The lock is a channel rather
+// than a mutex so that waiting for it can time out.
+type writeQueue struct {
+	lock    chan struct{}
+	timeout time.Duration
+
+	waiting  atomic.Int64
+	timeouts atomic.Int64
+}
+
+// WriteQueueStats reports the mutating commands waiting for their turn.
+type WriteQueueStats struct {
+	// Waiting is the number of commands queued behind the one writing.
+	Waiting int64 `json:"waiting"`
+	Writing bool  `json:"writing"`
+	// Timeouts is the number of commands that gave up waiting.
+	Timeouts int64 `json:"timeouts"`
+}
+
+func newWriteQueue(timeout time.Duration) *writeQueue {
+	if timeout == 0 {
+		timeout = DefaultWriteQueueTimeout
+	}
+	return &writeQueue{lock: make(chan struct{}, 1), timeout: timeout}
+}
+
+// acquire waits for the commands ahead to complete, up to the timeout of the
+// queue, and returns the function releasing the lock. Commands are served in
+// no particular order.
+func (q *writeQueue) acquire(ctx context.Context) (func(), error) {
+	select {
+	case q.lock <- struct{}{}:
+		return q.release, nil
+	default:
+	}
+
+	q.waiting.Add(1)
+	defer q.waiting.Add(-1)
+	var timeout <-chan time.Time
+	if q.timeout > 0 {
+		t := time.NewTimer(q.timeout)
+		defer t.Stop()
+		timeout = t.C
+	}
+	select {
+	case q.lock <- struct{}{}:
+		return q.release, nil
+	case <-timeout:
+		q.timeouts.Add(1)
+		return nil, ErrWriteQueueTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (q *writeQueue) release() {
+	<-q.lock
+}
+
+func (q *writeQueue) stats() WriteQueueStats {
+	return WriteQueueStats{
+		Waiting:  q.waiting.Load(),
+		Writing:  len(q.lock) > 0,
+		Timeouts: q.timeouts.Load(),
+	}
+}
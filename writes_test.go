@@ -0,0 +1,81 @@
+package sqliteadmin_test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/joelseq/sqliteadmin-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteQueue(t *testing.T) {
+	ts, closeServer := setupTestServerWithConfig(t, func(c *sqliteadmin.Config) {
+		c.WriteQueueTimeout = 50 * time.Millisecond
+	})
+	defer closeServer()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	ts.admin.RegisterAction("users", sqliteadmin.Action{
+		Name: "Slow",
+		Handler: func(ctx context.Context, db *sql.DB, ids []string) (string, error) {
+			close(started)
+			<-release
+			return "done", nil
+		},
+	})
+
+	done := make(chan int)
+	go func() {
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{
+			Command: sqliteadmin.RunAction,
+			Params:  map[string]interface{}{"tableName": "users", "action": "Slow", "ids": []string{"1"}},
+		})
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		done <- res.StatusCode
+	}()
+	<-started
+
+	getStats := func() map[string]interface{} {
+		req := makeRequest(t, ts.server.URL, sqliteadmin.CommandRequest{Command: sqliteadmin.GetStats})
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return readBody(t, res.Body)["writeQueue"].(map[string]interface{})
+	}
+	assert.Equal(t, map[string]interface{}{"waiting": float64(0), "writing": true, "timeouts": float64(0)}, getStats())
+
+	// Writes wait for the one in progress, reads don't
+	runTestCases([]TestCase{
+		{
+			name: "Failure: Timed out waiting",
+			params: map[string]interface{}{
+				"tableName": "users",
+				"row":       map[string]interface{}{"name": "Dave", "email": "dave@gmail.com"},
+			},
+			expectedStatus: http.StatusConflict,
+			expectedResponse: map[string]interface{}{
+				"statusCode": float64(http.StatusConflict),
+				"code":       "DATABASE_BUSY",
+				"message":    "Conflict: timed out waiting for other writes, retry later",
+				"detail":     "timed out waiting for other writes, retry later",
+			},
+		},
+	}, sqliteadmin.InsertRow, t, ts.server)
+	res, err := ts.admin.GetTable(context.Background(), sqliteadmin.GetTableParams{TableName: "users"})
+	assert.NoError(t, err)
+	assert.Len(t, res.Rows, 9)
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-done)
+	assert.Equal(t, map[string]interface{}{"waiting": float64(0), "writing": false, "timeouts": float64(1)}, getStats())
+
+	_, err = ts.admin.InsertRow(context.Background(), sqliteadmin.InsertRowParams{
+		TableName: "users",
+		Row:       map[string]interface{}{"name": "Dave", "email": "dave@gmail.com"},
+	})
+	assert.NoError(t, err)
+}